@@ -11,9 +11,9 @@ import (
 	"github.com/ethereum-optimism/optimism/batch-submitter/bindings/scc"
 	"github.com/ethereum-optimism/optimism/bss-core/drivers"
 	"github.com/ethereum-optimism/optimism/bss-core/metrics"
-	"github.com/ethereum-optimism/optimism/bss-core/txmgr"
 	l2ethclient "github.com/ethereum-optimism/optimism/l2geth/ethclient"
 	"github.com/ethereum-optimism/optimism/l2geth/log"
+	"github.com/ethereum-optimism/optimism/op-service/txmgr"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
@@ -10,8 +10,8 @@ import (
 	"github.com/ethereum-optimism/optimism/batch-submitter/bindings/ctc"
 	"github.com/ethereum-optimism/optimism/bss-core/drivers"
 	"github.com/ethereum-optimism/optimism/bss-core/metrics"
-	"github.com/ethereum-optimism/optimism/bss-core/txmgr"
 	l2ethclient "github.com/ethereum-optimism/optimism/l2geth/ethclient"
+	"github.com/ethereum-optimism/optimism/op-service/txmgr"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
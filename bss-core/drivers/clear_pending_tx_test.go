@@ -10,7 +10,7 @@ import (
 
 	"github.com/ethereum-optimism/optimism/bss-core/drivers"
 	"github.com/ethereum-optimism/optimism/bss-core/mock"
-	"github.com/ethereum-optimism/optimism/bss-core/txmgr"
+	"github.com/ethereum-optimism/optimism/op-service/txmgr"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
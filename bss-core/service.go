@@ -8,11 +8,12 @@ import (
 	"time"
 
 	"github.com/ethereum-optimism/optimism/bss-core/metrics"
-	"github.com/ethereum-optimism/optimism/bss-core/txmgr"
+	"github.com/ethereum-optimism/optimism/op-service/txmgr"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 var (
@@ -93,8 +94,14 @@ type Service struct {
 func NewService(cfg ServiceConfig) *Service {
 	ctx, cancel := context.WithCancel(cfg.Context)
 
+	txMgrConfig := cfg.TxManagerConfig
+	if txMgrConfig.Metrics == nil {
+		txMgrConfig.Metrics = txmgr.NewMetrics(
+			cfg.Driver.Metrics().SubsystemName(), prometheus.DefaultRegisterer,
+		)
+	}
 	txMgr := txmgr.NewSimpleTxManager(
-		cfg.Driver.Name(), cfg.TxManagerConfig, cfg.L1Client,
+		cfg.Driver.Name(), txMgrConfig, cfg.L1Client,
 	)
 
 	return &Service{
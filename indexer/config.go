@@ -73,6 +73,10 @@ type Config struct {
 	// batch.
 	MaxHeaderBatchSize uint64
 
+	// BridgeRegistry is the path of the JSON file with the custom bridges to
+	// index. The built-in bridges of the chain are indexed if empty.
+	BridgeRegistry string
+
 	// RESTHostname is the hostname at which the REST server is running.
 	RESTHostname string
 
@@ -98,6 +102,10 @@ type Config struct {
 	BedrockL1StandardBridgeAddress common.Address
 
 	BedrockOptimismPortalAddress common.Address
+
+	// BedrockFinalizationPeriod is the time in seconds after which proven
+	// withdrawals can be finalized. It is read from the portal if zero.
+	BedrockFinalizationPeriod uint64
 }
 
 // NewConfig parses the Config from the provided flags or environment variables.
@@ -118,12 +126,14 @@ func NewConfig(ctx *cli.Context) (Config, error) {
 		Bedrock:                        ctx.GlobalBool(flags.BedrockFlag.Name),
 		BedrockL1StandardBridgeAddress: common.HexToAddress(ctx.GlobalString(flags.BedrockL1StandardBridgeAddress.Name)),
 		BedrockOptimismPortalAddress:   common.HexToAddress(ctx.GlobalString(flags.BedrockOptimismPortalAddress.Name)),
+		BedrockFinalizationPeriod:      ctx.GlobalUint64(flags.BedrockFinalizationPeriodFlag.Name),
 		DisableIndexer:                 ctx.GlobalBool(flags.DisableIndexer.Name),
 		LogLevel:                       ctx.GlobalString(flags.LogLevelFlag.Name),
 		LogTerminal:                    ctx.GlobalBool(flags.LogTerminalFlag.Name),
 		L1StartBlockNumber:             ctx.GlobalUint64(flags.L1StartBlockNumberFlag.Name),
 		ConfDepth:                      ctx.GlobalUint64(flags.ConfDepthFlag.Name),
 		MaxHeaderBatchSize:             ctx.GlobalUint64(flags.MaxHeaderBatchSizeFlag.Name),
+		BridgeRegistry:                 ctx.GlobalString(flags.BridgeRegistryFlag.Name),
 		MetricsServerEnable:            ctx.GlobalBool(flags.MetricsServerEnableFlag.Name),
 		RESTHostname:                   ctx.GlobalString(flags.RESTHostnameFlag.Name),
 		RESTPort:                       ctx.GlobalUint64(flags.RESTPortFlag.Name),
@@ -0,0 +1,45 @@
+package db
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Layer is the chain that a bridge is deployed on.
+type Layer int
+
+const (
+	L1 Layer = 1
+	L2 Layer = 2
+)
+
+// IndexedBridge is a custom bridge known to the indexer, with the range of
+// blocks that still has to be backfilled. The blocks after the range are
+// indexed together with the other bridges.
+type IndexedBridge struct {
+	Layer   Layer
+	Address common.Address
+	Name    string
+	// BackfillNext is the next block to backfill.
+	BackfillNext uint64
+	// BackfillEnd is the last block to backfill, inclusive.
+	BackfillEnd uint64
+	// RemovedAfter is the last block indexed for the bridge before it was
+	// removed from the bridge registry, nil while the bridge is registered.
+	RemovedAfter *uint64
+}
+
+// Backfilled returns true if no blocks are left to backfill.
+func (b IndexedBridge) Backfilled() bool {
+	return b.BackfillNext > b.BackfillEnd
+}
+
+// NextBackfill returns the next range of at most size blocks to backfill,
+// inclusive. It must only be called if the bridge is not backfilled yet.
+func (b IndexedBridge) NextBackfill(size uint64) (start, end uint64) {
+	start = b.BackfillNext
+	end = start + size - 1
+	if end > b.BackfillEnd {
+		end = b.BackfillEnd
+	}
+	return start, end
+}
@@ -0,0 +1,24 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexedBridgeNextBackfill(t *testing.T) {
+	bridge := IndexedBridge{BackfillNext: 10, BackfillEnd: 34}
+
+	var ranges [][2]uint64
+	for !bridge.Backfilled() {
+		start, end := bridge.NextBackfill(10)
+		ranges = append(ranges, [2]uint64{start, end})
+		bridge.BackfillNext = end + 1
+	}
+	require.Equal(t, [][2]uint64{{10, 19}, {20, 29}, {30, 34}}, ranges)
+
+	require.True(t, IndexedBridge{BackfillNext: 11, BackfillEnd: 10}.Backfilled())
+	start, end := IndexedBridge{BackfillNext: 10, BackfillEnd: 10}.NextBackfill(10)
+	require.Equal(t, uint64(10), start)
+	require.Equal(t, uint64(10), end)
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 
@@ -198,6 +199,17 @@ func (d *Database) AddIndexedL1Block(block *IndexedL1Block) error {
 		($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 	`
 
+	const insertOutputStatement = `
+	INSERT INTO l2_outputs
+		(l2_block_number, output_root, tx_hash, log_index, block_hash, timestamp)
+	VALUES
+		($1, $2, $3, $4, $5, $6)
+	`
+
+	const deleteOutputStatement = `
+	DELETE FROM l2_outputs WHERE l2_block_number = $1
+	`
+
 	const updateWithdrawalStatement = `
 	UPDATE withdrawals SET (br_withdrawal_finalized_tx_hash, br_withdrawal_finalized_log_index, br_withdrawal_finalized_success) = ($1, $2, $3)
 	WHERE br_withdrawal_hash = $4
@@ -236,6 +248,26 @@ func (d *Database) AddIndexedL1Block(block *IndexedL1Block) error {
 			}
 		}
 
+		for _, output := range block.OutputProposals {
+			if output.Deleted {
+				_, err = tx.Exec(deleteOutputStatement, output.L2BlockNumber)
+			} else {
+				// The oracle records the timestamp of the proposing block.
+				_, err = tx.Exec(
+					insertOutputStatement,
+					output.L2BlockNumber,
+					output.OutputRoot.String(),
+					output.TxHash.String(),
+					output.LogIndex,
+					block.Hash.String(),
+					block.Timestamp,
+				)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
 		if len(block.FinalizedWithdrawals) > 0 {
 			for _, wd := range block.FinalizedWithdrawals {
 				_, err = tx.Exec(
@@ -476,9 +508,28 @@ func (d *Database) GetWithdrawalBatch(hash common.Hash) (*StateBatchJSON, error)
 	return batch, nil
 }
 
+// withdrawalOutputJoin joins the first L2 output proposed at or after the
+// block of a Bedrock withdrawal, which is the earliest output that proves it.
+const withdrawalOutputJoin = `
+		LEFT JOIN LATERAL (
+			SELECT l2_outputs.tx_hash, l2_outputs.l2_block_number, l2_outputs.timestamp
+			FROM l2_outputs
+			WHERE l2_outputs.l2_block_number >= l2_blocks.number
+			ORDER BY l2_outputs.l2_block_number LIMIT 1
+		) AS outputs ON withdrawals.br_withdrawal_hash IS NOT NULL
+`
+
 // GetWithdrawalsByAddress returns the list of Withdrawals indexed for the given
-// address paginated by the given params.
-func (d *Database) GetWithdrawalsByAddress(address common.Address, page PaginationParam, state FinalizationState) (*PaginatedWithdrawals, error) {
+// address paginated by the given params. The status of Bedrock withdrawals is
+// computed with the given finalization period, in seconds.
+func (d *Database) GetWithdrawalsByAddress(address common.Address, page PaginationParam, state FinalizationState, status WithdrawalStatus, finalizationPeriod uint64) (*PaginatedWithdrawals, error) {
+	now := uint64(time.Now().Unix())
+	var readyBefore uint64
+	if now > finalizationPeriod {
+		readyBefore = now - finalizationPeriod
+	}
+	filter := state.SQL() + " " + status.SQL(readyBefore)
+
 	selectWithdrawalsStatement := fmt.Sprintf(`
 	SELECT
 	    withdrawals.guid, withdrawals.from_address, withdrawals.to_address,
@@ -486,13 +537,15 @@ func (d *Database) GetWithdrawalsByAddress(address common.Address, page Paginati
 		withdrawals.l1_token, withdrawals.l2_token,
 		l2_tokens.name, l2_tokens.symbol, l2_tokens.decimals,
 		l2_blocks.number, l2_blocks.timestamp, withdrawals.br_withdrawal_hash,
+		outputs.tx_hash, outputs.l2_block_number, outputs.timestamp,
 		withdrawals.br_withdrawal_finalized_tx_hash, withdrawals.br_withdrawal_finalized_log_index,
 		withdrawals.br_withdrawal_finalized_success
 	FROM withdrawals
 		INNER JOIN l2_blocks ON withdrawals.block_hash=l2_blocks.hash
 		INNER JOIN l2_tokens ON withdrawals.l2_token=l2_tokens.address
+		%s
 	WHERE withdrawals.from_address = $1 %s ORDER BY l2_blocks.timestamp LIMIT $2 OFFSET $3;
-	`, state.SQL())
+	`, withdrawalOutputJoin, filter)
 	var withdrawals []WithdrawalJSON
 
 	err := txn(d.db, func(tx *sql.Tx) error {
@@ -506,6 +559,9 @@ func (d *Database) GetWithdrawalsByAddress(address common.Address, page Paginati
 			var withdrawal WithdrawalJSON
 			var l2Token Token
 			var wdHash sql.NullString
+			var outputTxHash sql.NullString
+			var outputL2BlockNumber sql.NullInt64
+			var outputTimestamp sql.NullInt64
 			var finTxHash sql.NullString
 			var finLogIndex sql.NullInt32
 			var finSuccess sql.NullBool
@@ -515,7 +571,8 @@ func (d *Database) GetWithdrawalsByAddress(address common.Address, page Paginati
 				&withdrawal.L1Token, &l2Token.Address,
 				&l2Token.Name, &l2Token.Symbol, &l2Token.Decimals,
 				&withdrawal.BlockNumber, &withdrawal.BlockTimestamp,
-				&wdHash, &finTxHash, &finLogIndex, &finSuccess,
+				&wdHash, &outputTxHash, &outputL2BlockNumber, &outputTimestamp,
+				&finTxHash, &finLogIndex, &finSuccess,
 			); err != nil {
 				return err
 			}
@@ -523,6 +580,17 @@ func (d *Database) GetWithdrawalsByAddress(address common.Address, page Paginati
 			if wdHash.Valid {
 				withdrawal.BedrockWithdrawalHash = &wdHash.String
 			}
+			if outputTxHash.Valid {
+				withdrawal.BedrockOutputTxHash = &outputTxHash.String
+			}
+			if outputL2BlockNumber.Valid {
+				number := uint64(outputL2BlockNumber.Int64)
+				withdrawal.BedrockOutputL2BlockNumber = &number
+			}
+			if outputTimestamp.Valid {
+				ts := uint64(outputTimestamp.Int64)
+				withdrawal.BedrockOutputTimestamp = &ts
+			}
 			if finTxHash.Valid {
 				withdrawal.BedrockFinalizedTxHash = &finTxHash.String
 			}
@@ -533,6 +601,10 @@ func (d *Database) GetWithdrawalsByAddress(address common.Address, page Paginati
 			if finSuccess.Valid {
 				withdrawal.BedrockFinalizedSuccess = &finSuccess.Bool
 			}
+			if wdHash.Valid {
+				withdrawal.Status, withdrawal.BedrockFinalizableAt = ComputeWithdrawalStatus(
+					withdrawal.BedrockOutputTimestamp, finTxHash.Valid, finalizationPeriod, now)
+			}
 			withdrawals = append(withdrawals, withdrawal)
 		}
 
@@ -548,14 +620,15 @@ func (d *Database) GetWithdrawalsByAddress(address common.Address, page Paginati
 		withdrawals[i].Batch = batch
 	}
 
-	const selectWithdrawalCountStatement = `
+	selectWithdrawalCountStatement := fmt.Sprintf(`
 	SELECT
 		count(*)
 	FROM withdrawals
 		INNER JOIN l2_blocks ON withdrawals.block_hash=l2_blocks.hash
 		INNER JOIN l2_tokens ON withdrawals.l2_token=l2_tokens.address
-	WHERE withdrawals.from_address = $1;
-	`
+		%s
+	WHERE withdrawals.from_address = $1 %s;
+	`, withdrawalOutputJoin, filter)
 
 	var count uint64
 	err = txn(d.db, func(tx *sql.Tx) error {
@@ -735,6 +808,237 @@ func (d *Database) GetAirdrop(address common.Address) (*Airdrop, error) {
 	return airdrop, nil
 }
 
+// GetIndexedBridges returns the custom bridges of the layer that were
+// registered with the indexer.
+func (d *Database) GetIndexedBridges(layer Layer) ([]IndexedBridge, error) {
+	const selectBridgesStatement = `
+	SELECT address, name, backfill_next, backfill_end, removed_after FROM bridges WHERE layer = $1
+	`
+
+	var bridges []IndexedBridge
+	err := txn(d.db, func(tx *sql.Tx) error {
+		rows, err := tx.Query(selectBridgesStatement, layer)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			bridge := IndexedBridge{Layer: layer}
+			var address string
+			var removedAfter sql.NullInt64
+			if err := rows.Scan(&address, &bridge.Name, &bridge.BackfillNext, &bridge.BackfillEnd, &removedAfter); err != nil {
+				return err
+			}
+			bridge.Address = common.HexToAddress(address)
+			if removedAfter.Valid {
+				number := uint64(removedAfter.Int64)
+				bridge.RemovedAfter = &number
+			}
+			bridges = append(bridges, bridge)
+		}
+
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return bridges, nil
+}
+
+// AddIndexedBridge registers a custom bridge with the indexer.
+func (d *Database) AddIndexedBridge(bridge IndexedBridge) error {
+	const insertBridgeStatement = `
+	INSERT INTO bridges
+		(layer, address, name, backfill_next, backfill_end, removed_after)
+	VALUES
+		($1, $2, $3, $4, $5, $6)
+	`
+
+	return txn(d.db, func(tx *sql.Tx) error {
+		_, err := tx.Exec(
+			insertBridgeStatement,
+			bridge.Layer,
+			bridge.Address.String(),
+			bridge.Name,
+			bridge.BackfillNext,
+			bridge.BackfillEnd,
+			bridge.RemovedAfter,
+		)
+		return err
+	})
+}
+
+// UpdateIndexedBridge updates the name, backfill range and removal of a
+// registered bridge.
+func (d *Database) UpdateIndexedBridge(bridge IndexedBridge) error {
+	const updateBridgeStatement = `
+	UPDATE bridges SET (name, backfill_next, backfill_end, removed_after) = ($1, $2, $3, $4)
+	WHERE layer = $5 AND address = $6
+	`
+
+	return txn(d.db, func(tx *sql.Tx) error {
+		_, err := tx.Exec(
+			updateBridgeStatement,
+			bridge.Name,
+			bridge.BackfillNext,
+			bridge.BackfillEnd,
+			bridge.RemovedAfter,
+			bridge.Layer,
+			bridge.Address.String(),
+		)
+		return err
+	})
+}
+
+// UpdateBridgeBackfill records the next block to backfill for the bridge.
+func (d *Database) UpdateBridgeBackfill(layer Layer, address common.Address, next uint64) error {
+	const updateBridgeStatement = `
+	UPDATE bridges SET backfill_next = $1 WHERE layer = $2 AND address = $3
+	`
+
+	return txn(d.db, func(tx *sql.Tx) error {
+		_, err := tx.Exec(updateBridgeStatement, next, layer, address.String())
+		return err
+	})
+}
+
+// AddBackfilledL1Block inserts the deposits of a backfilled bridge in the
+// block, and the block itself if it was not indexed yet. Deposits that were
+// already indexed are skipped, so a block can be backfilled again.
+func (d *Database) AddBackfilledL1Block(block *IndexedL1Block) error {
+	const insertBlockStatement = `
+	INSERT INTO l1_blocks
+		(hash, parent_hash, number, timestamp)
+	VALUES
+		($1, $2, $3, $4)
+	ON CONFLICT DO NOTHING
+	`
+
+	const selectDepositStatement = `
+	SELECT count(*) FROM deposits WHERE tx_hash = $1 AND log_index = $2
+	`
+
+	const insertDepositStatement = `
+	INSERT INTO deposits
+		(guid, from_address, to_address, l1_token, l2_token, amount, tx_hash, log_index, block_hash, data)
+	VALUES
+		($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	return txn(d.db, func(tx *sql.Tx) error {
+		_, err := tx.Exec(
+			insertBlockStatement,
+			block.Hash.String(),
+			block.ParentHash.String(),
+			block.Number,
+			block.Timestamp,
+		)
+		if err != nil {
+			return err
+		}
+
+		for _, deposit := range block.Deposits {
+			var count uint64
+			err := tx.QueryRow(selectDepositStatement, deposit.TxHash.String(), deposit.LogIndex).Scan(&count)
+			if err != nil {
+				return err
+			}
+			if count > 0 {
+				continue
+			}
+
+			_, err = tx.Exec(
+				insertDepositStatement,
+				NewGUID(),
+				deposit.FromAddress.String(),
+				deposit.ToAddress.String(),
+				deposit.L1Token.String(),
+				deposit.L2Token.String(),
+				deposit.Amount.String(),
+				deposit.TxHash.String(),
+				deposit.LogIndex,
+				block.Hash.String(),
+				deposit.Data,
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// AddBackfilledL2Block inserts the withdrawals of a backfilled bridge in the
+// block, and the block itself if it was not indexed yet. Withdrawals that were
+// already indexed are skipped, so a block can be backfilled again.
+func (d *Database) AddBackfilledL2Block(block *IndexedL2Block) error {
+	const insertBlockStatement = `
+	INSERT INTO l2_blocks
+		(hash, parent_hash, number, timestamp)
+	VALUES
+		($1, $2, $3, $4)
+	ON CONFLICT DO NOTHING
+	`
+
+	const selectWithdrawalStatement = `
+	SELECT count(*) FROM withdrawals WHERE tx_hash = $1 AND log_index = $2
+	`
+
+	const insertWithdrawalStatement = `
+	INSERT INTO withdrawals
+		(guid, from_address, to_address, l1_token, l2_token, amount, tx_hash, log_index, block_hash, data, br_withdrawal_hash)
+	VALUES
+		($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+
+	return txn(d.db, func(tx *sql.Tx) error {
+		_, err := tx.Exec(
+			insertBlockStatement,
+			block.Hash.String(),
+			block.ParentHash.String(),
+			block.Number,
+			block.Timestamp,
+		)
+		if err != nil {
+			return err
+		}
+
+		for _, withdrawal := range block.Withdrawals {
+			var count uint64
+			err := tx.QueryRow(selectWithdrawalStatement, withdrawal.TxHash.String(), withdrawal.LogIndex).Scan(&count)
+			if err != nil {
+				return err
+			}
+			if count > 0 {
+				continue
+			}
+
+			_, err = tx.Exec(
+				insertWithdrawalStatement,
+				NewGUID(),
+				withdrawal.FromAddress.String(),
+				withdrawal.ToAddress.String(),
+				withdrawal.L1Token.String(),
+				withdrawal.L2Token.String(),
+				withdrawal.Amount.String(),
+				withdrawal.TxHash.String(),
+				withdrawal.LogIndex,
+				block.Hash.String(),
+				withdrawal.Data,
+				nullableHash(withdrawal.BedrockHash),
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
 func nullableHash(in *common.Hash) *string {
 	if in == nil {
 		return nil
@@ -130,6 +130,29 @@ ALTER TABLE withdrawals ADD COLUMN IF NOT EXISTS br_withdrawal_finalized_success
 CREATE INDEX IF NOT EXISTS withdrawals_br_withdrawal_hash ON withdrawals(br_withdrawal_hash);
 `
 
+const createL2OutputsTable = `
+CREATE TABLE IF NOT EXISTS l2_outputs (
+	l2_block_number INTEGER NOT NULL PRIMARY KEY,
+	output_root VARCHAR NOT NULL,
+	tx_hash VARCHAR NOT NULL,
+	log_index INTEGER NOT NULL,
+	block_hash VARCHAR NOT NULL REFERENCES l1_blocks(hash),
+	timestamp INTEGER NOT NULL
+)
+`
+
+const createBridgesTable = `
+CREATE TABLE IF NOT EXISTS bridges (
+	layer INTEGER NOT NULL,
+	address VARCHAR NOT NULL,
+	name VARCHAR NOT NULL,
+	backfill_next INTEGER NOT NULL,
+	backfill_end INTEGER NOT NULL,
+	removed_after INTEGER NULL,
+	PRIMARY KEY (layer, address)
+)
+`
+
 var schema = []string{
 	createL1BlocksTable,
 	createL2BlocksTable,
@@ -143,4 +166,6 @@ var schema = []string{
 	createL1L2NumberIndex,
 	createAirdropsTable,
 	updateWithdrawalsTable,
+	createL2OutputsTable,
+	createBridgesTable,
 }
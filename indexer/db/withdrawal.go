@@ -1,6 +1,7 @@
 package db
 
 import (
+	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -27,22 +28,28 @@ func (w Withdrawal) String() string {
 
 // WithdrawalJSON contains Withdrawal data suitable for JSON serialization.
 type WithdrawalJSON struct {
-	GUID                     string          `json:"guid"`
-	FromAddress              string          `json:"from"`
-	ToAddress                string          `json:"to"`
-	L1Token                  string          `json:"l1Token"`
-	L2Token                  *Token          `json:"l2Token"`
-	Amount                   string          `json:"amount"`
-	Data                     []byte          `json:"data"`
-	LogIndex                 uint64          `json:"logIndex"`
-	BlockNumber              uint64          `json:"blockNumber"`
-	BlockTimestamp           string          `json:"blockTimestamp"`
-	TxHash                   string          `json:"transactionHash"`
-	Batch                    *StateBatchJSON `json:"batch"`
-	BedrockWithdrawalHash    *string         `json:"bedrockWithdrawalHash"`
-	BedrockFinalizedTxHash   *string         `json:"bedrockFinalizedTxHash"`
-	BedrockFinalizedLogIndex *int            `json:"bedrockFinalizedLogIndex"`
-	BedrockFinalizedSuccess  *bool           `json:"bedrockFinalizedSuccess"`
+	GUID                       string          `json:"guid"`
+	FromAddress                string          `json:"from"`
+	ToAddress                  string          `json:"to"`
+	L1Token                    string          `json:"l1Token"`
+	L2Token                    *Token          `json:"l2Token"`
+	Amount                     string          `json:"amount"`
+	Data                       []byte          `json:"data"`
+	LogIndex                   uint64          `json:"logIndex"`
+	BlockNumber                uint64          `json:"blockNumber"`
+	BlockTimestamp             string          `json:"blockTimestamp"`
+	TxHash                     string          `json:"transactionHash"`
+	Batch                      *StateBatchJSON `json:"batch"`
+	BedrockWithdrawalHash      *string         `json:"bedrockWithdrawalHash"`
+	BedrockOutputTxHash        *string         `json:"bedrockOutputTxHash"`
+	BedrockOutputL2BlockNumber *uint64         `json:"bedrockOutputL2BlockNumber"`
+	BedrockOutputTimestamp     *uint64         `json:"bedrockOutputTimestamp"`
+	BedrockFinalizableAt       *uint64         `json:"bedrockFinalizableAt"`
+	BedrockFinalizedTxHash     *string         `json:"bedrockFinalizedTxHash"`
+	BedrockFinalizedLogIndex   *int            `json:"bedrockFinalizedLogIndex"`
+	BedrockFinalizedSuccess    *bool           `json:"bedrockFinalizedSuccess"`
+	// Status is the WithdrawalStatus of Bedrock withdrawals, empty for legacy withdrawals.
+	Status WithdrawalStatus `json:"status,omitempty"`
 }
 
 type FinalizationState int
@@ -75,6 +82,92 @@ func (f FinalizationState) SQL() string {
 	return ""
 }
 
+// WithdrawalStatus is the stage of a Bedrock withdrawal in the proving lifecycle.
+// The portal proves withdrawals against the L2 outputs of the L2OutputOracle, so
+// a withdrawal is proven once an output at or after its L2 block is proposed.
+type WithdrawalStatus string
+
+const (
+	// WithdrawalStatusAny matches withdrawals in any stage.
+	WithdrawalStatusAny WithdrawalStatus = ""
+	// WithdrawalStatusInitiated is a withdrawal not covered by an L2 output yet.
+	WithdrawalStatusInitiated WithdrawalStatus = "initiated"
+	// WithdrawalStatusProven is a proven withdrawal within the finalization period.
+	WithdrawalStatusProven WithdrawalStatus = "proven"
+	// WithdrawalStatusReadyToFinalize is a proven withdrawal past the finalization period.
+	WithdrawalStatusReadyToFinalize WithdrawalStatus = "ready_to_finalize"
+	// WithdrawalStatusFinalized is a withdrawal that was finalized on L1.
+	WithdrawalStatusFinalized WithdrawalStatus = "finalized"
+)
+
+// ParseWithdrawalStatus parses the status query parameter. An empty status
+// matches any withdrawal.
+func ParseWithdrawalStatus(in string) (WithdrawalStatus, error) {
+	switch status := WithdrawalStatus(in); status {
+	case WithdrawalStatusAny, WithdrawalStatusInitiated, WithdrawalStatusProven,
+		WithdrawalStatusReadyToFinalize, WithdrawalStatusFinalized:
+		return status, nil
+	default:
+		return WithdrawalStatusAny, fmt.Errorf("unknown withdrawal status: %s", in)
+	}
+}
+
+// SQL returns the condition to filter withdrawals by the status, where
+// withdrawals with an output proposed before readyBefore can be finalized.
+// The condition refers to the output joined as "outputs".
+func (s WithdrawalStatus) SQL(readyBefore uint64) string {
+	switch s {
+	case WithdrawalStatusInitiated:
+		return "AND withdrawals.br_withdrawal_hash IS NOT NULL " +
+			"AND outputs.l2_block_number IS NULL " +
+			"AND withdrawals.br_withdrawal_finalized_tx_hash IS NULL"
+	case WithdrawalStatusProven:
+		return fmt.Sprintf("AND outputs.timestamp >= %d "+
+			"AND withdrawals.br_withdrawal_finalized_tx_hash IS NULL", readyBefore)
+	case WithdrawalStatusReadyToFinalize:
+		return fmt.Sprintf("AND outputs.timestamp < %d "+
+			"AND withdrawals.br_withdrawal_finalized_tx_hash IS NULL", readyBefore)
+	case WithdrawalStatusFinalized:
+		return "AND withdrawals.br_withdrawal_finalized_tx_hash IS NOT NULL"
+	}
+
+	return ""
+}
+
+// ComputeWithdrawalStatus returns the status of a Bedrock withdrawal at the
+// given time, and the time from which it can be finalized if it was proven.
+// Like the portal, it only considers an output final once the finalization
+// period has fully elapsed since it was proposed.
+func ComputeWithdrawalStatus(outputTimestamp *uint64, finalized bool, finalizationPeriod, now uint64) (WithdrawalStatus, *uint64) {
+	var finalizableAt *uint64
+	if outputTimestamp != nil {
+		t := *outputTimestamp + finalizationPeriod + 1
+		finalizableAt = &t
+	}
+
+	switch {
+	case finalized:
+		return WithdrawalStatusFinalized, finalizableAt
+	case finalizableAt == nil:
+		return WithdrawalStatusInitiated, nil
+	case *finalizableAt <= now:
+		return WithdrawalStatusReadyToFinalize, finalizableAt
+	default:
+		return WithdrawalStatusProven, finalizableAt
+	}
+}
+
+// OutputProposal is an L2 output proposed to, or deleted from, the
+// L2OutputOracle.
+type OutputProposal struct {
+	OutputRoot    common.Hash
+	L2BlockNumber uint64
+	TxHash        common.Hash
+	LogIndex      uint
+	// Deleted is set if the output was deleted from the oracle.
+	Deleted bool
+}
+
 type FinalizedWithdrawal struct {
 	WithdrawalHash common.Hash
 	TxHash         common.Hash
@@ -0,0 +1,52 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeWithdrawalStatus(t *testing.T) {
+	proposed := uint64(1000)
+	// the portal requires the finalization period to have fully elapsed
+	finalizableAt := uint64(1101)
+
+	status, at := ComputeWithdrawalStatus(nil, false, 100, 2000)
+	require.Equal(t, WithdrawalStatusInitiated, status)
+	require.Nil(t, at)
+
+	status, at = ComputeWithdrawalStatus(&proposed, false, 100, 1100)
+	require.Equal(t, WithdrawalStatusProven, status)
+	require.Equal(t, &finalizableAt, at)
+
+	status, at = ComputeWithdrawalStatus(&proposed, false, 100, 1101)
+	require.Equal(t, WithdrawalStatusReadyToFinalize, status)
+	require.Equal(t, &finalizableAt, at)
+
+	status, at = ComputeWithdrawalStatus(&proposed, true, 100, 1101)
+	require.Equal(t, WithdrawalStatusFinalized, status)
+	require.Equal(t, &finalizableAt, at)
+
+	// outputs may be deleted after the withdrawal was finalized
+	status, at = ComputeWithdrawalStatus(nil, true, 100, 1101)
+	require.Equal(t, WithdrawalStatusFinalized, status)
+	require.Nil(t, at)
+}
+
+func TestParseWithdrawalStatus(t *testing.T) {
+	for _, in := range []string{"", "initiated", "proven", "ready_to_finalize", "finalized"} {
+		status, err := ParseWithdrawalStatus(in)
+		require.NoError(t, err)
+		require.Equal(t, WithdrawalStatus(in), status)
+	}
+	_, err := ParseWithdrawalStatus("pending")
+	require.Error(t, err)
+}
+
+func TestWithdrawalStatusSQL(t *testing.T) {
+	require.Empty(t, WithdrawalStatusAny.SQL(1000))
+	require.Contains(t, WithdrawalStatusInitiated.SQL(1000), "outputs.l2_block_number IS NULL")
+	require.Contains(t, WithdrawalStatusProven.SQL(1000), "outputs.timestamp >= 1000")
+	require.Contains(t, WithdrawalStatusReadyToFinalize.SQL(1000), "outputs.timestamp < 1000")
+	require.Contains(t, WithdrawalStatusFinalized.SQL(1000), "br_withdrawal_finalized_tx_hash IS NOT NULL")
+}
@@ -93,6 +93,11 @@ var (
 		Usage:  "Address of the portal",
 		EnvVar: prefixEnvVar("BEDROCK_OPTIMISM_PORTAL"),
 	}
+	BedrockFinalizationPeriodFlag = cli.Uint64Flag{
+		Name:   "bedrock.finalization-period",
+		Usage:  "Time in seconds after an L2 output is proposed before withdrawals proven by it can be finalized. Read from the portal if not set",
+		EnvVar: prefixEnvVar("BEDROCK_FINALIZATION_PERIOD"),
+	}
 
 	/* Optional Flags */
 
@@ -143,6 +148,11 @@ var (
 		Value:  20,
 		EnvVar: prefixEnvVar("CONF_DEPTH"),
 	}
+	BridgeRegistryFlag = cli.StringFlag{
+		Name:   "bridge-registry",
+		Usage:  "Path of the JSON file with the custom bridges to index. Defaults to the built-in bridges of the chain",
+		EnvVar: prefixEnvVar("BRIDGE_REGISTRY"),
+	}
 	MaxHeaderBatchSizeFlag = cli.Uint64Flag{
 		Name:   "max-header-batch-size",
 		Usage:  "The maximum number of headers to request as a batch",
@@ -197,6 +207,7 @@ var optionalFlags = []cli.Flag{
 	BedrockFlag,
 	BedrockL1StandardBridgeAddress,
 	BedrockOptimismPortalAddress,
+	BedrockFinalizationPeriodFlag,
 	DisableIndexer,
 	LogLevelFlag,
 	LogTerminalFlag,
@@ -205,6 +216,7 @@ var optionalFlags = []cli.Flag{
 	SentryTraceRateFlag,
 	ConfDepthFlag,
 	MaxHeaderBatchSizeFlag,
+	BridgeRegistryFlag,
 	L1StartBlockNumberFlag,
 	RESTHostnameFlag,
 	RESTPortFlag,
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/ethereum-optimism/optimism/indexer/services"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 
 	"github.com/ethereum-optimism/optimism/indexer/metrics"
@@ -156,6 +157,29 @@ func NewIndexer(cfg Config) (*Indexer, error) {
 		return nil, err
 	}
 
+	finalizationPeriod := cfg.BedrockFinalizationPeriod
+	if cfg.Bedrock && finalizationPeriod == 0 {
+		_, portal := addrManager.OptimismPortal()
+		period, err := portal.FINALIZATIONPERIODSECONDS(&bind.CallOpts{Context: ctx})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read finalization period: %w", err)
+		}
+		finalizationPeriod = period.Uint64()
+	}
+
+	// The built-in bridges were hard-coded before the bridge registry.
+	legacyBridges, err := services.DefaultBridgeRegistry(cfg.ChainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load bridge registry: %w", err)
+	}
+	bridges := legacyBridges
+	if cfg.BridgeRegistry != "" {
+		bridges, err = services.LoadBridgeRegistry(cfg.BridgeRegistry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load bridge registry: %w", err)
+		}
+	}
+
 	l1IndexingService, err := l1.NewService(l1.ServiceConfig{
 		Context:            ctx,
 		Metrics:            m,
@@ -168,6 +192,8 @@ func NewIndexer(cfg Config) (*Indexer, error) {
 		MaxHeaderBatchSize: cfg.MaxHeaderBatchSize,
 		StartBlockNumber:   cfg.L1StartBlockNumber,
 		Bedrock:            cfg.Bedrock,
+		Bridges:            bridges,
+		LegacyBridges:      legacyBridges,
 	})
 	if err != nil {
 		return nil, err
@@ -183,6 +209,9 @@ func NewIndexer(cfg Config) (*Indexer, error) {
 		MaxHeaderBatchSize: cfg.MaxHeaderBatchSize,
 		StartBlockNumber:   uint64(0),
 		Bedrock:            cfg.Bedrock,
+		FinalizationPeriod: finalizationPeriod,
+		Bridges:            bridges,
+		LegacyBridges:      legacyBridges,
 	})
 	if err != nil {
 		return nil, err
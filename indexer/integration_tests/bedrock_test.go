@@ -173,6 +173,8 @@ func TestBedrockIndexer(t *testing.T) {
 		require.Equal(t, 1, len(wdPage.Withdrawals))
 		withdrawal := wdPage.Withdrawals[0]
 		require.Nil(t, withdrawal.BedrockFinalizedTxHash)
+		// The proposer may already have proposed an output covering the withdrawal.
+		require.Contains(t, []db.WithdrawalStatus{db.WithdrawalStatusInitiated, db.WithdrawalStatusProven}, withdrawal.Status)
 		require.Equal(t, big.NewInt(0.5*params.Ether).String(), withdrawal.Amount)
 		require.Equal(t, wdTx.Hash().String(), withdrawal.TxHash)
 		require.Equal(t, wdReceipt.BlockNumber.Uint64(), withdrawal.BlockNumber)
@@ -194,6 +196,30 @@ func TestBedrockIndexer(t *testing.T) {
 		finHeader, err := l2Client.HeaderByNumber(context.Background(), big.NewInt(int64(finBlockNum)))
 		require.NoError(t, err)
 
+		// The withdrawal is proven by the first output at or after its block,
+		// and is ready once the finalization period of that output elapsed.
+		wdPage = nil
+		require.NoError(t, e2eutils.WaitFor(e2eutils.TimeoutCtx(t, 30*time.Second), 100*time.Millisecond, func() (bool, error) {
+			res := new(db.PaginatedWithdrawals)
+			err := getJSON(makeURL(fmt.Sprintf("v1/withdrawals/%s?status=ready_to_finalize", fromAddr)), res)
+			if err != nil {
+				return false, err
+			}
+
+			if len(res.Withdrawals) == 0 {
+				return false, nil
+			}
+
+			wdPage = res
+			return true, nil
+		}))
+
+		withdrawal = wdPage.Withdrawals[0]
+		require.Equal(t, db.WithdrawalStatusReadyToFinalize, withdrawal.Status)
+		require.NotNil(t, withdrawal.BedrockOutputTxHash)
+		require.Equal(t, finBlockNum, *withdrawal.BedrockOutputL2BlockNumber)
+		require.LessOrEqual(t, *withdrawal.BedrockFinalizableAt, uint64(time.Now().Unix()))
+
 		rpcClient, err := rpc.Dial(sys.Nodes["sequencer"].HTTPEndpoint())
 		require.NoError(t, err)
 		proofClient := withdrawals.NewClient(rpcClient)
@@ -239,6 +265,17 @@ func TestBedrockIndexer(t *testing.T) {
 		wd := wdPage.Withdrawals[0]
 		require.Equal(t, finReceipt.TxHash.String(), *wd.BedrockFinalizedTxHash)
 		require.True(t, *wd.BedrockFinalizedSuccess)
+		require.Equal(t, db.WithdrawalStatusFinalized, wd.Status)
+
+		wdPage = new(db.PaginatedWithdrawals)
+		err = getJSON(makeURL(fmt.Sprintf("v1/withdrawals/%s?status=finalized", fromAddr)), wdPage)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(wdPage.Withdrawals))
+
+		wdPage = new(db.PaginatedWithdrawals)
+		err = getJSON(makeURL(fmt.Sprintf("v1/withdrawals/%s?status=initiated", fromAddr)), wdPage)
+		require.NoError(t, err)
+		require.Equal(t, 0, len(wdPage.Withdrawals))
 
 		wdPage = new(db.PaginatedWithdrawals)
 		err = getJSON(makeURL(fmt.Sprintf("v1/withdrawals/%s?finalized=false", fromAddr)), wdPage)
@@ -0,0 +1,114 @@
+package integration_tests
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/indexer/db"
+	"github.com/ethereum-optimism/optimism/indexer/services"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDatabase(t *testing.T) *db.Database {
+	dbParams := createTestDB(t)
+	dsn := fmt.Sprintf("host=%s port=%d dbname=%s sslmode=disable", dbParams.Host, dbParams.Port, dbParams.Name)
+	if dbParams.User != "" {
+		dsn += fmt.Sprintf(" user=%s", dbParams.User)
+	}
+	database, err := db.NewDatabase(dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, database.Close())
+	})
+	return database
+}
+
+func TestBridgeBackfillProgress(t *testing.T) {
+	database := newTestDatabase(t)
+
+	standard := common.HexToAddress("0x10")
+	registry := services.BridgeRegistry{
+		{Name: "A", Impl: services.StandardBridgeImpl, L1: &services.BridgeDeployment{Address: common.HexToAddress("0x1"), StartBlock: 5}},
+	}
+	_, err := services.RegisterBridges(database, db.L1, standard, nil, nil, nil)
+	require.NoError(t, err)
+	backfills, err := services.RegisterBridges(database, db.L1, standard, registry, nil, &db.BlockLocator{Number: 20})
+	require.NoError(t, err)
+	require.Len(t, backfills, 1)
+	require.Equal(t, uint64(5), backfills[0].BackfillNext)
+
+	require.NoError(t, database.UpdateBridgeBackfill(db.L1, registry[0].L1.Address, 11))
+	backfills, err = services.RegisterBridges(database, db.L1, standard, registry, nil, &db.BlockLocator{Number: 30})
+	require.NoError(t, err)
+	require.Len(t, backfills, 1)
+	require.Equal(t, uint64(11), backfills[0].BackfillNext)
+	require.Equal(t, uint64(20), backfills[0].BackfillEnd)
+
+	// Removing and adding the bridge again backfills the blocks in between.
+	require.NoError(t, database.UpdateBridgeBackfill(db.L1, registry[0].L1.Address, 21))
+	_, err = services.RegisterBridges(database, db.L1, standard, nil, nil, &db.BlockLocator{Number: 30})
+	require.NoError(t, err)
+	backfills, err = services.RegisterBridges(database, db.L1, standard, registry, nil, &db.BlockLocator{Number: 40})
+	require.NoError(t, err)
+	require.Len(t, backfills, 1)
+	require.Equal(t, uint64(31), backfills[0].BackfillNext)
+	require.Equal(t, uint64(40), backfills[0].BackfillEnd)
+}
+
+func TestAddBackfilledBlocks(t *testing.T) {
+	database := newTestDatabase(t)
+	from := common.HexToAddress("0x1234")
+
+	deposit := func(logIndex uint) db.Deposit {
+		return db.Deposit{
+			TxHash:      common.Hash{0x01},
+			L1Token:     db.ETHL1Address,
+			FromAddress: from,
+			ToAddress:   from,
+			Amount:      big.NewInt(1),
+			Data:        []byte{},
+			LogIndex:    logIndex,
+		}
+	}
+	l1Block := &db.IndexedL1Block{
+		Hash:     common.Hash{0x0a},
+		Number:   10,
+		Deposits: []db.Deposit{deposit(0)},
+	}
+	require.NoError(t, database.AddIndexedL1Block(l1Block))
+
+	// The block and its deposits may be indexed already, or backfilled before.
+	l1Block.Deposits = []db.Deposit{deposit(0), deposit(1)}
+	require.NoError(t, database.AddBackfilledL1Block(l1Block))
+	require.NoError(t, database.AddBackfilledL1Block(l1Block))
+	deposits, err := database.GetDepositsByAddress(from, db.PaginationParam{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, deposits.Deposits, 2)
+
+	withdrawal := func(logIndex uint) db.Withdrawal {
+		return db.Withdrawal{
+			TxHash:      common.Hash{0x02},
+			L2Token:     common.HexToAddress(db.ETHL2Token.Address),
+			FromAddress: from,
+			ToAddress:   from,
+			Amount:      big.NewInt(1),
+			Data:        []byte{},
+			LogIndex:    logIndex,
+		}
+	}
+	l2Block := &db.IndexedL2Block{
+		Hash:        common.Hash{0x0b},
+		Number:      10,
+		Withdrawals: []db.Withdrawal{withdrawal(0)},
+	}
+	require.NoError(t, database.AddIndexedL2Block(l2Block))
+
+	l2Block.Withdrawals = []db.Withdrawal{withdrawal(0), withdrawal(1)}
+	require.NoError(t, database.AddBackfilledL2Block(l2Block))
+	require.NoError(t, database.AddBackfilledL2Block(l2Block))
+	withdrawals, err := database.GetWithdrawalsByAddress(from, db.PaginationParam{Limit: 10}, db.FinalizationStateAny, db.WithdrawalStatusAny, 0)
+	require.NoError(t, err)
+	require.Len(t, withdrawals.Withdrawals, 2)
+}
@@ -11,6 +11,7 @@ type AddressManager interface {
 	L1StandardBridge() (common.Address, *bindings.L1StandardBridge)
 	StateCommitmentChain() (common.Address, *scc.StateCommitmentChain)
 	OptimismPortal() (common.Address, *bindings.OptimismPortal)
+	L2OutputOracle() (common.Address, *bindings.L2OutputOracle)
 }
 
 type LegacyAddresses struct {
@@ -65,11 +66,17 @@ func (a *LegacyAddresses) OptimismPortal() (common.Address, *bindings.OptimismPo
 	panic("OptimismPortal not configured on legacy networks - this is a programmer error")
 }
 
+func (a *LegacyAddresses) L2OutputOracle() (common.Address, *bindings.L2OutputOracle) {
+	panic("L2OutputOracle not configured on legacy networks - this is a programmer error")
+}
+
 type BedrockAddresses struct {
 	l1SB       *bindings.L1StandardBridge
 	l1SBAddr   common.Address
 	portal     *bindings.OptimismPortal
 	portalAddr common.Address
+	l2OO       *bindings.L2OutputOracle
+	l2OOAddr   common.Address
 }
 
 var _ AddressManager = (*BedrockAddresses)(nil)
@@ -83,12 +90,22 @@ func NewBedrockAddresses(client bind.ContractBackend, l1SBAddr, portalAddr commo
 	if err != nil {
 		return nil, err
 	}
+	l2OOAddr, err := portal.L2ORACLE(nil)
+	if err != nil {
+		return nil, err
+	}
+	l2OO, err := bindings.NewL2OutputOracle(l2OOAddr, client)
+	if err != nil {
+		return nil, err
+	}
 
 	return &BedrockAddresses{
 		l1SB:       l1SB,
 		l1SBAddr:   l1SBAddr,
 		portal:     portal,
 		portalAddr: portalAddr,
+		l2OO:       l2OO,
+		l2OOAddr:   l2OOAddr,
 	}, nil
 }
 
@@ -103,3 +120,7 @@ func (b *BedrockAddresses) StateCommitmentChain() (common.Address, *scc.StateCom
 func (b *BedrockAddresses) OptimismPortal() (common.Address, *bindings.OptimismPortal) {
 	return b.portalAddr, b.portal
 }
+
+func (b *BedrockAddresses) L2OutputOracle() (common.Address, *bindings.L2OutputOracle) {
+	return b.l2OOAddr, b.l2OO
+}
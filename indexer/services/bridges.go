@@ -0,0 +1,250 @@
+package services
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/ethereum-optimism/optimism/indexer/db"
+)
+
+// Bridge implementations supported by the indexer.
+const (
+	StandardBridgeImpl = "StandardBridge"
+	ETHBridgeImpl      = "ETHBridge"
+)
+
+// Names of the standard bridges of the network, which are always indexed.
+const (
+	StandardBridgeName = "Standard"
+	ETHBridgeName      = "ETH"
+)
+
+// builtinBridges are the bridge registries used when no registry file is
+// configured, keyed by L1 chain ID.
+var builtinBridges = map[uint64]string{
+	1:  "bridges/mainnet.json",
+	42: "bridges/kovan.json",
+}
+
+//go:embed bridges/*.json
+var builtinBridgesFS embed.FS
+
+// BridgeDeployment is the deployment of a bridge on one layer.
+type BridgeDeployment struct {
+	Address common.Address `json:"address"`
+
+	// StartBlock is the block to backfill the bridge from, when the bridge is
+	// added to an indexer that already indexed past it.
+	StartBlock uint64 `json:"startBlock"`
+}
+
+// BridgeConfig defines a custom token bridge, in addition to the standard
+// bridges of the network. The bridge is indexed on the layers it is deployed on.
+type BridgeConfig struct {
+	Name string            `json:"name"`
+	Impl string            `json:"impl"`
+	L1   *BridgeDeployment `json:"l1,omitempty"`
+	L2   *BridgeDeployment `json:"l2,omitempty"`
+}
+
+// BridgeRegistry is the list of custom token bridges to index.
+type BridgeRegistry []BridgeConfig
+
+// LoadBridgeRegistry reads the bridge registry from a JSON file.
+func LoadBridgeRegistry(path string) (BridgeRegistry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseBridgeRegistry(data)
+}
+
+// DefaultBridgeRegistry returns the built-in bridge registry of the L1 chain,
+// which is empty for unknown chains.
+func DefaultBridgeRegistry(l1ChainID uint64) (BridgeRegistry, error) {
+	path, ok := builtinBridges[l1ChainID]
+	if !ok {
+		return nil, nil
+	}
+	data, err := builtinBridgesFS.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseBridgeRegistry(data)
+}
+
+func parseBridgeRegistry(data []byte) (BridgeRegistry, error) {
+	var registry BridgeRegistry
+	if err := json.Unmarshal(data, &registry); err != nil {
+		return nil, fmt.Errorf("invalid bridge registry: %w", err)
+	}
+	if err := registry.Check(); err != nil {
+		return nil, err
+	}
+	return registry, nil
+}
+
+// Check ensures the bridges are well-formed, and have unique names.
+func (r BridgeRegistry) Check() error {
+	names := make(map[string]bool)
+	for _, bridge := range r {
+		if bridge.Name == "" {
+			return errors.New("bridge name must be set")
+		}
+		if bridge.Name == StandardBridgeName || bridge.Name == ETHBridgeName {
+			return fmt.Errorf("bridge name %s is reserved for the standard bridges", bridge.Name)
+		}
+		if names[bridge.Name] {
+			return fmt.Errorf("duplicate bridge %s", bridge.Name)
+		}
+		names[bridge.Name] = true
+		if bridge.Impl != StandardBridgeImpl && bridge.Impl != ETHBridgeImpl {
+			return fmt.Errorf("bridge %s has unsupported implementation %q", bridge.Name, bridge.Impl)
+		}
+		if bridge.L1 == nil && bridge.L2 == nil {
+			return fmt.Errorf("bridge %s is not deployed on L1 nor L2", bridge.Name)
+		}
+		if bridge.Impl == ETHBridgeImpl && bridge.L2 != nil {
+			return fmt.Errorf("bridge %s: %s is only supported on L1", bridge.Name, ETHBridgeImpl)
+		}
+		for _, deployment := range []*BridgeDeployment{bridge.L1, bridge.L2} {
+			if deployment != nil && deployment.Address == (common.Address{}) {
+				return fmt.Errorf("bridge %s must have an address", bridge.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// L1Bridges returns the bridges that are deployed on L1.
+func (r BridgeRegistry) L1Bridges() []BridgeConfig {
+	var bridges []BridgeConfig
+	for _, bridge := range r {
+		if bridge.L1 != nil {
+			bridges = append(bridges, bridge)
+		}
+	}
+	return bridges
+}
+
+// L2Bridges returns the bridges that are deployed on L2.
+func (r BridgeRegistry) L2Bridges() []BridgeConfig {
+	var bridges []BridgeConfig
+	for _, bridge := range r {
+		if bridge.L2 != nil {
+			bridges = append(bridges, bridge)
+		}
+	}
+	return bridges
+}
+
+// Deployment returns the deployment of the bridge on the layer, or nil.
+func (c BridgeConfig) Deployment(layer db.Layer) *BridgeDeployment {
+	if layer == db.L1 {
+		return c.L1
+	}
+	return c.L2
+}
+
+// BridgeStore records the bridges registered with the indexer.
+type BridgeStore interface {
+	GetIndexedBridges(layer db.Layer) ([]db.IndexedBridge, error)
+	AddIndexedBridge(bridge db.IndexedBridge) error
+	UpdateIndexedBridge(bridge db.IndexedBridge) error
+}
+
+// RegisterBridges records the custom bridges of the layer that are new to the
+// indexer, and returns the bridges that have blocks left to backfill. A new
+// bridge is backfilled from its start block up to the highest indexed block,
+// the blocks after that are indexed together with the other bridges.
+//
+// The standard bridge of the layer is registered as well, but never
+// backfilled. When no bridges were registered yet, but blocks were indexed
+// already, the legacy bridges are assumed to be indexed by an indexer without
+// bridge registry.
+//
+// Bridges that are no longer in the registry are marked as removed. When such
+// a bridge is added again, the blocks indexed without it are backfilled.
+func RegisterBridges(store BridgeStore, layer db.Layer, standardBridge common.Address, registry, legacy BridgeRegistry, highest *db.BlockLocator) ([]db.IndexedBridge, error) {
+	indexed, err := store.GetIndexedBridges(layer)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[common.Address]db.IndexedBridge)
+	for _, bridge := range indexed {
+		known[bridge.Address] = bridge
+	}
+
+	var highestNumber uint64
+	if highest != nil {
+		highestNumber = highest.Number
+	}
+	migrated := make(map[common.Address]bool)
+	if len(indexed) == 0 && highest != nil {
+		for _, bridge := range legacy {
+			if deployment := bridge.Deployment(layer); deployment != nil {
+				migrated[deployment.Address] = true
+			}
+		}
+	}
+
+	standard := BridgeConfig{Name: StandardBridgeName, L1: &BridgeDeployment{Address: standardBridge}, L2: &BridgeDeployment{Address: standardBridge}}
+	registered := make(map[common.Address]bool)
+	var backfills []db.IndexedBridge
+	for _, bridge := range append(BridgeRegistry{standard}, registry...) {
+		deployment := bridge.Deployment(layer)
+		if deployment == nil || registered[deployment.Address] {
+			continue
+		}
+		registered[deployment.Address] = true
+
+		indexedBridge, ok := known[deployment.Address]
+		switch {
+		case ok && indexedBridge.RemovedAfter != nil:
+			// Extend a backfill that was left over when the bridge was
+			// removed, as blocks after it were indexed without the bridge.
+			if indexedBridge.Backfilled() {
+				indexedBridge.BackfillNext = *indexedBridge.RemovedAfter + 1
+			}
+			indexedBridge.BackfillEnd = highestNumber
+			indexedBridge.RemovedAfter = nil
+			if err := store.UpdateIndexedBridge(indexedBridge); err != nil {
+				return nil, err
+			}
+		case !ok:
+			indexedBridge = db.IndexedBridge{
+				Layer:        layer,
+				Address:      deployment.Address,
+				Name:         bridge.Name,
+				BackfillNext: deployment.StartBlock,
+				BackfillEnd:  highestNumber,
+			}
+			if highest == nil || migrated[deployment.Address] || deployment.Address == standardBridge {
+				indexedBridge.BackfillNext = highestNumber + 1
+			}
+			if err := store.AddIndexedBridge(indexedBridge); err != nil {
+				return nil, err
+			}
+		}
+		if !indexedBridge.Backfilled() {
+			backfills = append(backfills, indexedBridge)
+		}
+	}
+
+	for _, indexedBridge := range indexed {
+		if registered[indexedBridge.Address] || indexedBridge.RemovedAfter != nil {
+			continue
+		}
+		indexedBridge.RemovedAfter = &highestNumber
+		if err := store.UpdateIndexedBridge(indexedBridge); err != nil {
+			return nil, err
+		}
+	}
+
+	return backfills, nil
+}
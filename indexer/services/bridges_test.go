@@ -0,0 +1,196 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/indexer/db"
+)
+
+func TestDefaultBridgeRegistry(t *testing.T) {
+	for chainID := range builtinBridges {
+		registry, err := DefaultBridgeRegistry(chainID)
+		require.NoError(t, err)
+		require.NotEmpty(t, registry)
+	}
+
+	registry, err := DefaultBridgeRegistry(900)
+	require.NoError(t, err)
+	require.Empty(t, registry)
+}
+
+func TestLoadBridgeRegistry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bridges.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[
+		{"name": "L1Only", "impl": "StandardBridge", "l1": {"address": "0x0000000000000000000000000000000000000001", "startBlock": 100}},
+		{"name": "Both", "impl": "StandardBridge",
+			"l1": {"address": "0x0000000000000000000000000000000000000002"},
+			"l2": {"address": "0x0000000000000000000000000000000000000003", "startBlock": 5}}
+	]`), 0644))
+
+	registry, err := LoadBridgeRegistry(path)
+	require.NoError(t, err)
+	require.Len(t, registry, 2)
+	require.Equal(t, uint64(100), registry[0].L1.StartBlock)
+	require.Len(t, registry.L1Bridges(), 2)
+	l2Bridges := registry.L2Bridges()
+	require.Len(t, l2Bridges, 1)
+	require.Equal(t, common.HexToAddress("0x3"), l2Bridges[0].L2.Address)
+	require.Equal(t, uint64(5), l2Bridges[0].L2.StartBlock)
+}
+
+func TestBridgeRegistryCheck(t *testing.T) {
+	deployment := &BridgeDeployment{Address: common.HexToAddress("0x1")}
+	tests := []struct {
+		name     string
+		registry BridgeRegistry
+	}{
+		{"no name", BridgeRegistry{{Impl: StandardBridgeImpl, L1: deployment}}},
+		{"duplicate name", BridgeRegistry{
+			{Name: "A", Impl: StandardBridgeImpl, L1: deployment},
+			{Name: "A", Impl: StandardBridgeImpl, L2: deployment},
+		}},
+		{"unsupported impl", BridgeRegistry{{Name: "A", Impl: "DAIBridge", L1: deployment}}},
+		{"not deployed", BridgeRegistry{{Name: "A", Impl: StandardBridgeImpl}}},
+		{"no address", BridgeRegistry{{Name: "A", Impl: StandardBridgeImpl, L2: &BridgeDeployment{}}}},
+		{"standard name", BridgeRegistry{{Name: StandardBridgeName, Impl: StandardBridgeImpl, L1: deployment}}},
+		{"eth name", BridgeRegistry{{Name: ETHBridgeName, Impl: ETHBridgeImpl, L1: deployment}}},
+		{"eth bridge on L2", BridgeRegistry{{Name: "A", Impl: ETHBridgeImpl, L1: deployment, L2: deployment}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Error(t, test.registry.Check())
+		})
+	}
+}
+
+// testBridgeStore is an in-memory BridgeStore.
+type testBridgeStore struct {
+	bridges []db.IndexedBridge
+}
+
+func (s *testBridgeStore) GetIndexedBridges(layer db.Layer) ([]db.IndexedBridge, error) {
+	var bridges []db.IndexedBridge
+	for _, bridge := range s.bridges {
+		if bridge.Layer == layer {
+			bridges = append(bridges, bridge)
+		}
+	}
+	return bridges, nil
+}
+
+func (s *testBridgeStore) AddIndexedBridge(bridge db.IndexedBridge) error {
+	s.bridges = append(s.bridges, bridge)
+	return nil
+}
+
+func (s *testBridgeStore) UpdateIndexedBridge(bridge db.IndexedBridge) error {
+	for i := range s.bridges {
+		if s.bridges[i].Layer == bridge.Layer && s.bridges[i].Address == bridge.Address {
+			s.bridges[i] = bridge
+		}
+	}
+	return nil
+}
+
+func (s *testBridgeStore) backfill(address common.Address) {
+	for i := range s.bridges {
+		if s.bridges[i].Address == address {
+			s.bridges[i].BackfillNext = s.bridges[i].BackfillEnd + 1
+		}
+	}
+}
+
+func TestRegisterBridges(t *testing.T) {
+	standard := common.HexToAddress("0x10")
+	legacy := BridgeConfig{Name: "Legacy", Impl: StandardBridgeImpl, L1: &BridgeDeployment{Address: common.HexToAddress("0x1"), StartBlock: 5}}
+	added := BridgeConfig{Name: "Added", Impl: StandardBridgeImpl, L1: &BridgeDeployment{Address: common.HexToAddress("0x2"), StartBlock: 7}}
+	l2Only := BridgeConfig{Name: "L2Only", Impl: StandardBridgeImpl, L2: &BridgeDeployment{Address: common.HexToAddress("0x3")}}
+
+	t.Run("new indexer", func(t *testing.T) {
+		store := new(testBridgeStore)
+		backfills, err := RegisterBridges(store, db.L1, standard, BridgeRegistry{legacy, added, l2Only}, BridgeRegistry{legacy}, nil)
+		require.NoError(t, err)
+		require.Empty(t, backfills)
+		require.Len(t, store.bridges, 3)
+	})
+
+	t.Run("upgraded indexer", func(t *testing.T) {
+		store := new(testBridgeStore)
+		// The legacy bridge was indexed already, but the bridge added along
+		// with the upgrade is backfilled from its start block.
+		backfills, err := RegisterBridges(store, db.L1, standard, BridgeRegistry{legacy, added}, BridgeRegistry{legacy}, &db.BlockLocator{Number: 100})
+		require.NoError(t, err)
+		require.Len(t, backfills, 1)
+		require.Equal(t, added.L1.Address, backfills[0].Address)
+		require.Equal(t, uint64(7), backfills[0].BackfillNext)
+		require.Equal(t, uint64(100), backfills[0].BackfillEnd)
+	})
+
+	t.Run("added bridge", func(t *testing.T) {
+		store := new(testBridgeStore)
+		_, err := RegisterBridges(store, db.L1, standard, BridgeRegistry{legacy}, BridgeRegistry{legacy}, nil)
+		require.NoError(t, err)
+
+		backfills, err := RegisterBridges(store, db.L1, standard, BridgeRegistry{legacy, added}, BridgeRegistry{legacy}, &db.BlockLocator{Number: 100})
+		require.NoError(t, err)
+		require.Len(t, backfills, 1)
+		require.Equal(t, added.L1.Address, backfills[0].Address)
+		require.Equal(t, uint64(7), backfills[0].BackfillNext)
+
+		// The backfill is resumed until it completes.
+		backfills, err = RegisterBridges(store, db.L1, standard, BridgeRegistry{legacy, added}, BridgeRegistry{legacy}, &db.BlockLocator{Number: 150})
+		require.NoError(t, err)
+		require.Len(t, backfills, 1)
+		require.Equal(t, uint64(100), backfills[0].BackfillEnd)
+		store.backfill(added.L1.Address)
+		backfills, err = RegisterBridges(store, db.L1, standard, BridgeRegistry{legacy, added}, BridgeRegistry{legacy}, &db.BlockLocator{Number: 150})
+		require.NoError(t, err)
+		require.Empty(t, backfills)
+	})
+
+	t.Run("re-added bridge", func(t *testing.T) {
+		store := new(testBridgeStore)
+		_, err := RegisterBridges(store, db.L1, standard, BridgeRegistry{legacy}, nil, nil)
+		require.NoError(t, err)
+
+		backfills, err := RegisterBridges(store, db.L1, standard, nil, nil, &db.BlockLocator{Number: 100})
+		require.NoError(t, err)
+		require.Empty(t, backfills)
+		bridges, err := store.GetIndexedBridges(db.L1)
+		require.NoError(t, err)
+		require.Equal(t, legacy.L1.Address, bridges[1].Address)
+		require.Equal(t, uint64(100), *bridges[1].RemovedAfter)
+
+		// A bridge removed again keeps the block it was removed after.
+		_, err = RegisterBridges(store, db.L1, standard, nil, nil, &db.BlockLocator{Number: 120})
+		require.NoError(t, err)
+
+		backfills, err = RegisterBridges(store, db.L1, standard, BridgeRegistry{legacy}, nil, &db.BlockLocator{Number: 200})
+		require.NoError(t, err)
+		require.Len(t, backfills, 1)
+		require.Equal(t, uint64(101), backfills[0].BackfillNext)
+		require.Equal(t, uint64(200), backfills[0].BackfillEnd)
+		require.Nil(t, backfills[0].RemovedAfter)
+	})
+
+	t.Run("re-added bridge with pending backfill", func(t *testing.T) {
+		store := new(testBridgeStore)
+		_, err := RegisterBridges(store, db.L1, standard, nil, nil, nil)
+		require.NoError(t, err)
+		_, err = RegisterBridges(store, db.L1, standard, BridgeRegistry{added}, nil, &db.BlockLocator{Number: 100})
+		require.NoError(t, err)
+		_, err = RegisterBridges(store, db.L1, standard, nil, nil, &db.BlockLocator{Number: 150})
+		require.NoError(t, err)
+
+		backfills, err := RegisterBridges(store, db.L1, standard, BridgeRegistry{added}, nil, &db.BlockLocator{Number: 200})
+		require.NoError(t, err)
+		require.Len(t, backfills, 1)
+		require.Equal(t, uint64(7), backfills[0].BackfillNext)
+		require.Equal(t, uint64(200), backfills[0].BackfillEnd)
+	})
+}
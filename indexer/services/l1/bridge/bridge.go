@@ -3,7 +3,7 @@ package bridge
 import (
 	"context"
 	"errors"
-	"math/big"
+	"fmt"
 
 	"github.com/ethereum-optimism/optimism/indexer/bindings/legacy/scc"
 	"github.com/ethereum-optimism/optimism/indexer/db"
@@ -25,67 +25,54 @@ type InitiatedWithdrawalMap map[common.Hash][]db.Withdrawal
 // objected keyed on block hashes.
 type FinalizedWithdrawalsMap map[common.Hash][]db.FinalizedWithdrawal
 
+// OutputProposalsMap is a collection of L2 output proposal
+// objects keyed on block hashes.
+type OutputProposalsMap map[common.Hash][]db.OutputProposal
+
 type Bridge interface {
 	Address() common.Address
 	GetDepositsByBlockRange(context.Context, uint64, uint64) (DepositsMap, error)
 	String() string
 }
 
-type implConfig struct {
-	name string
-	impl string
-	addr common.Address
-}
-
-var customBridgeCfgs = map[uint64][]*implConfig{
-	// Mainnet
-	1: {
-		{"BitBTC", "StandardBridge", common.HexToAddress("0xaBA2c5F108F7E820C049D5Af70B16ac266c8f128")},
-		{"DAI", "StandardBridge", common.HexToAddress("0x10E6593CDda8c58a1d0f14C5164B376352a55f2F")},
-		{"wstETH", "StandardBridge", common.HexToAddress("0x76943C0D61395d8F2edF9060e1533529cAe05dE6")},
-	},
-	// Kovan
-	42: {
-		{"BitBTC", "StandardBridge", common.HexToAddress("0x0b651A42F32069d62d5ECf4f2a7e5Bd3E9438746")},
-		{"USX", "StandardBridge", common.HexToAddress("0x40E862341b2416345F02c41Ac70df08525150dC7")},
-		{"DAI", "StandardBridge", common.HexToAddress("0xb415e822C4983ecD6B1c1596e8a5f976cf6CD9e3")},
-		{"wstETH", "StandardBridge", common.HexToAddress("0x65321bf24210b81500230dCEce14Faa70a9f50a7")},
-	},
-}
-
-func BridgesByChainID(chainID *big.Int, client bind.ContractBackend, addrs services.AddressManager) (map[string]Bridge, error) {
+// BridgesFromRegistry returns the standard bridges of the network, and the
+// custom bridges of the registry that are deployed on L1.
+func BridgesFromRegistry(client bind.ContractBackend, addrs services.AddressManager, registry services.BridgeRegistry) (map[string]Bridge, error) {
 	l1SBAddr, _ := addrs.L1StandardBridge()
-	allCfgs := []*implConfig{
-		{"Standard", "StandardBridge", l1SBAddr},
-		{"ETH", "ETHBridge", l1SBAddr},
+	allCfgs := []services.BridgeConfig{
+		{Name: services.StandardBridgeName, Impl: services.StandardBridgeImpl, L1: &services.BridgeDeployment{Address: l1SBAddr}},
+		{Name: services.ETHBridgeName, Impl: services.ETHBridgeImpl, L1: &services.BridgeDeployment{Address: l1SBAddr}},
 	}
-	allCfgs = append(allCfgs, customBridgeCfgs[chainID.Uint64()]...)
+	allCfgs = append(allCfgs, registry.L1Bridges()...)
 
 	bridges := make(map[string]Bridge)
 	for _, bridge := range allCfgs {
-		switch bridge.impl {
-		case "StandardBridge":
-			l1SB, err := bindings.NewL1StandardBridge(bridge.addr, client)
+		if _, ok := bridges[bridge.Name]; ok {
+			return nil, fmt.Errorf("duplicate bridge %s", bridge.Name)
+		}
+		switch bridge.Impl {
+		case services.StandardBridgeImpl:
+			l1SB, err := bindings.NewL1StandardBridge(bridge.L1.Address, client)
 			if err != nil {
 				return nil, err
 			}
 			standardBridge := &StandardBridge{
-				name:     bridge.name,
-				address:  bridge.addr,
+				name:     bridge.Name,
+				address:  bridge.L1.Address,
 				contract: l1SB,
 			}
-			bridges[bridge.name] = standardBridge
-		case "ETHBridge":
-			l1SB, err := bindings.NewL1StandardBridge(bridge.addr, client)
+			bridges[bridge.Name] = standardBridge
+		case services.ETHBridgeImpl:
+			l1SB, err := bindings.NewL1StandardBridge(bridge.L1.Address, client)
 			if err != nil {
 				return nil, err
 			}
 			ethBridge := &EthBridge{
-				name:     bridge.name,
-				address:  bridge.addr,
+				name:     bridge.Name,
+				address:  bridge.L1.Address,
 				contract: l1SB,
 			}
-			bridges[bridge.name] = ethBridge
+			bridges[bridge.Name] = ethBridge
 		default:
 			return nil, errors.New("unsupported bridge")
 		}
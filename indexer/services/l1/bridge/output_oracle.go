@@ -0,0 +1,105 @@
+package bridge
+
+import (
+	"context"
+	"sort"
+
+	"github.com/ethereum-optimism/optimism/indexer/db"
+	"github.com/ethereum-optimism/optimism/indexer/services"
+	"github.com/ethereum-optimism/optimism/op-bindings/bindings"
+	"github.com/ethereum-optimism/optimism/op-service/backoff"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// OutputOracle scans the L2OutputOracle used by the portal to prove
+// withdrawals for the L2 outputs proposed to it.
+type OutputOracle struct {
+	address  common.Address
+	contract *bindings.L2OutputOracle
+}
+
+func NewOutputOracle(addrs services.AddressManager) *OutputOracle {
+	address, contract := addrs.L2OutputOracle()
+
+	return &OutputOracle{
+		address:  address,
+		contract: contract,
+	}
+}
+
+func (o *OutputOracle) Address() common.Address {
+	return o.address
+}
+
+// GetOutputProposalsByBlockRange returns the outputs proposed to and deleted
+// from the oracle in the given block range, in log order within each block.
+func (o *OutputOracle) GetOutputProposalsByBlockRange(ctx context.Context, start, end uint64) (OutputProposalsMap, error) {
+	outputsByBlockHash := make(OutputProposalsMap)
+	opts := &bind.FilterOpts{
+		Context: ctx,
+		Start:   start,
+		End:     &end,
+	}
+
+	var proposedIter *bindings.L2OutputOracleOutputProposedIterator
+	err := backoff.Do(3, backoff.Exponential(), func() error {
+		var err error
+		proposedIter, err = o.contract.FilterOutputProposed(opts, nil, nil, nil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer proposedIter.Close()
+	for proposedIter.Next() {
+		outputsByBlockHash[proposedIter.Event.Raw.BlockHash] = append(
+			outputsByBlockHash[proposedIter.Event.Raw.BlockHash], db.OutputProposal{
+				OutputRoot:    proposedIter.Event.OutputRoot,
+				L2BlockNumber: proposedIter.Event.L2BlockNumber.Uint64(),
+				TxHash:        proposedIter.Event.Raw.TxHash,
+				LogIndex:      proposedIter.Event.Raw.Index,
+			},
+		)
+	}
+	if err := proposedIter.Error(); err != nil {
+		return nil, err
+	}
+
+	var deletedIter *bindings.L2OutputOracleOutputDeletedIterator
+	err = backoff.Do(3, backoff.Exponential(), func() error {
+		var err error
+		deletedIter, err = o.contract.FilterOutputDeleted(opts, nil, nil, nil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer deletedIter.Close()
+	for deletedIter.Next() {
+		outputsByBlockHash[deletedIter.Event.Raw.BlockHash] = append(
+			outputsByBlockHash[deletedIter.Event.Raw.BlockHash], db.OutputProposal{
+				OutputRoot:    deletedIter.Event.OutputRoot,
+				L2BlockNumber: deletedIter.Event.L2BlockNumber.Uint64(),
+				TxHash:        deletedIter.Event.Raw.TxHash,
+				LogIndex:      deletedIter.Event.Raw.Index,
+				Deleted:       true,
+			},
+		)
+	}
+	if err := deletedIter.Error(); err != nil {
+		return nil, err
+	}
+
+	// An output may be deleted and proposed again in the same block, so the
+	// events must be applied in the order they were emitted.
+	for _, outputs := range outputsByBlockHash {
+		sort.Slice(outputs, func(i, j int) bool {
+			return outputs[i].LogIndex < outputs[j].LogIndex
+		})
+	}
+
+	return outputsByBlockHash, nil
+}
@@ -0,0 +1,113 @@
+package bridge
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/indexer/db"
+	"github.com/ethereum-optimism/optimism/indexer/services"
+	"github.com/ethereum-optimism/optimism/op-bindings/bindings"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/require"
+)
+
+type testAddresses struct {
+	services.AddressManager
+	l2OOAddr common.Address
+	l2OO     *bindings.L2OutputOracle
+}
+
+func (a *testAddresses) L2OutputOracle() (common.Address, *bindings.L2OutputOracle) {
+	return a.l2OOAddr, a.l2OO
+}
+
+func TestGetOutputProposalsByBlockRange(t *testing.T) {
+	proposerKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	ownerKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	proposer := crypto.PubkeyToAddress(proposerKey.PublicKey)
+	owner := crypto.PubkeyToAddress(ownerKey.PublicKey)
+
+	backend := backends.NewSimulatedBackend(core.GenesisAlloc{
+		proposer: {Balance: big.NewInt(params.Ether)},
+		owner:    {Balance: big.NewInt(params.Ether)},
+	}, 15_000_000)
+	defer backend.Close()
+
+	chainID := big.NewInt(1337)
+	proposerOpts, err := bind.NewKeyedTransactorWithChainID(proposerKey, chainID)
+	require.NoError(t, err)
+	ownerOpts, err := bind.NewKeyedTransactorWithChainID(ownerKey, chainID)
+	require.NoError(t, err)
+
+	l2OOAddr, _, l2OO, err := bindings.DeployL2OutputOracle(
+		ownerOpts, backend,
+		big.NewInt(2), common.Hash{0x01}, common.Big0, common.Big0, common.Big0, common.Big1,
+		proposer, owner,
+	)
+	require.NoError(t, err)
+	backend.Commit()
+
+	mined := func(tx *types.Transaction, err error) *types.Receipt {
+		require.NoError(t, err)
+		backend.Commit()
+		receipt, err := backend.TransactionReceipt(context.Background(), tx.Hash())
+		require.NoError(t, err)
+		require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+		return receipt
+	}
+
+	first := mined(l2OO.ProposeL2Output(proposerOpts, common.Hash{0x02}, big.NewInt(2), common.Hash{}, common.Big0))
+	second := mined(l2OO.ProposeL2Output(proposerOpts, common.Hash{0x04}, big.NewInt(4), common.Hash{}, common.Big0))
+
+	// Replace the latest output in a single block.
+	proposal, err := l2OO.GetL2Output(nil, big.NewInt(4))
+	require.NoError(t, err)
+	deleteTx, err := l2OO.DeleteL2Output(ownerOpts, proposal)
+	require.NoError(t, err)
+	replace := mined(l2OO.ProposeL2Output(proposerOpts, common.Hash{0x05}, big.NewInt(4), common.Hash{}, common.Big0))
+
+	oracle := NewOutputOracle(&testAddresses{l2OOAddr: l2OOAddr, l2OO: l2OO})
+	require.Equal(t, l2OOAddr, oracle.Address())
+
+	outputs, err := oracle.GetOutputProposalsByBlockRange(context.Background(), 0, replace.BlockNumber.Uint64())
+	require.NoError(t, err)
+	require.Len(t, outputs, 3)
+	require.Equal(t, []db.OutputProposal{{
+		OutputRoot:    common.Hash{0x02},
+		L2BlockNumber: 2,
+		TxHash:        first.TxHash,
+		LogIndex:      first.Logs[0].Index,
+	}}, outputs[first.BlockHash])
+	require.Equal(t, []db.OutputProposal{{
+		OutputRoot:    common.Hash{0x04},
+		L2BlockNumber: 4,
+		TxHash:        second.TxHash,
+		LogIndex:      second.Logs[0].Index,
+	}}, outputs[second.BlockHash])
+	require.Equal(t, []db.OutputProposal{{
+		OutputRoot:    common.Hash{0x04},
+		L2BlockNumber: 4,
+		TxHash:        deleteTx.Hash(),
+		LogIndex:      0,
+		Deleted:       true,
+	}, {
+		OutputRoot:    common.Hash{0x05},
+		L2BlockNumber: 4,
+		TxHash:        replace.TxHash,
+		LogIndex:      replace.Logs[0].Index,
+	}}, outputs[replace.BlockHash])
+
+	outputs, err = oracle.GetOutputProposalsByBlockRange(context.Background(), second.BlockNumber.Uint64()+1, replace.BlockNumber.Uint64())
+	require.NoError(t, err)
+	require.Len(t, outputs, 1)
+	require.Len(t, outputs[replace.BlockHash], 2)
+}
@@ -59,6 +59,11 @@ type ServiceConfig struct {
 	StartBlockNumber   uint64
 	DB                 *db.Database
 	Bedrock            bool
+	// Bridges are the custom bridges to index, in addition to the standard bridges.
+	Bridges services.BridgeRegistry
+	// LegacyBridges are the custom bridges indexed by indexers without a bridge
+	// registry, which are not backfilled when such an indexer is upgraded.
+	LegacyBridges services.BridgeRegistry
 }
 
 type Service struct {
@@ -67,7 +72,9 @@ type Service struct {
 	cancel func()
 
 	bridges        map[string]bridge.Bridge
+	backfills      []db.IndexedBridge
 	portal         *bridge.Portal
+	outputOracle   *bridge.OutputOracle
 	batchScanner   *scc.StateCommitmentChainFilterer
 	latestHeader   uint64
 	headerSelector *ConfirmedHeaderSelector
@@ -100,16 +107,18 @@ func NewService(cfg ServiceConfig) (*Service, error) {
 		return nil, fmt.Errorf("chain ID configured with %d but got %d", cfg.ChainID, chainID)
 	}
 
-	bridges, err := bridge.BridgesByChainID(cfg.ChainID, cfg.L1Client, cfg.AddressManager)
+	bridges, err := bridge.BridgesFromRegistry(cfg.L1Client, cfg.AddressManager, cfg.Bridges)
 	if err != nil {
 		cancel()
 		return nil, err
 	}
 
 	var portal *bridge.Portal
+	var outputOracle *bridge.OutputOracle
 	var batchScanner *scc.StateCommitmentChainFilterer
 	if cfg.Bedrock {
 		portal = bridge.NewPortal(cfg.AddressManager)
+		outputOracle = bridge.NewOutputOracle(cfg.AddressManager)
 	} else {
 		batchScanner, err = bridge.StateCommitmentChainScanner(cfg.L1Client, cfg.AddressManager)
 		if err != nil {
@@ -120,6 +129,22 @@ func NewService(cfg ServiceConfig) (*Service, error) {
 
 	logger.Info("Scanning bridges for deposits", "bridges", bridges)
 
+	highest, err := cfg.DB.GetHighestL1Block()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	l1SBAddr, _ := cfg.AddressManager.L1StandardBridge()
+	backfills, err := services.RegisterBridges(cfg.DB, db.L1, l1SBAddr, cfg.Bridges, cfg.LegacyBridges, highest)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	for _, b := range backfills {
+		logger.Info("Backfilling bridge", "name", b.Name, "address", b.Address,
+			"start", b.BackfillNext, "end", b.BackfillEnd)
+	}
+
 	confirmedHeaderSelector, err := NewConfirmedHeaderSelector(HeaderSelectorConfig{
 		ConfDepth:    cfg.ConfDepth,
 		MaxBatchSize: cfg.MaxHeaderBatchSize,
@@ -135,7 +160,9 @@ func NewService(cfg ServiceConfig) (*Service, error) {
 		ctx:            ctx,
 		cancel:         cancel,
 		portal:         portal,
+		outputOracle:   outputOracle,
 		bridges:        bridges,
+		backfills:      backfills,
 		batchScanner:   batchScanner,
 		headerSelector: confirmedHeaderSelector,
 		metrics:        cfg.Metrics,
@@ -194,6 +221,9 @@ func (s *Service) loop() {
 					break
 				}
 			}
+			if err := s.backfill(); err != nil {
+				logger.Error("Unable to backfill bridge", "err", err)
+			}
 		case <-s.ctx.Done():
 			logger.Info("service stopped")
 			return
@@ -246,8 +276,9 @@ func (s *Service) Update(newHeader *types.Header) error {
 	}()
 
 	bridgeDepositsCh := make(chan bridge.DepositsMap, len(s.bridges))
+	outputProposalsCh := make(chan bridge.OutputProposalsMap, 1)
 	finalizedWithdrawalsCh := make(chan bridge.FinalizedWithdrawalsMap, 1)
-	errCh := make(chan error, len(s.bridges)+1)
+	errCh := make(chan error, len(s.bridges)+2)
 
 	for _, bridgeImpl := range s.bridges {
 		go func(b bridge.Bridge) {
@@ -259,6 +290,14 @@ func (s *Service) Update(newHeader *types.Header) error {
 			bridgeDepositsCh <- deposits
 		}(bridgeImpl)
 	}
+	go func() {
+		outputProposals, err := s.outputOracle.GetOutputProposalsByBlockRange(s.ctx, startHeight, endHeight)
+		if err != nil {
+			errCh <- err
+			return
+		}
+		outputProposalsCh <- outputProposals
+	}()
 	go func() {
 		finalizedWithdrawals, err := s.portal.GetFinalizedWithdrawalsByBlockRange(s.ctx, startHeight, endHeight)
 		if err != nil {
@@ -291,7 +330,16 @@ func (s *Service) Update(newHeader *types.Header) error {
 		}
 	}
 
-	finalizedWithdrawalsByBlockHash := <-finalizedWithdrawalsCh
+	var outputProposalsByBlockHash bridge.OutputProposalsMap
+	var finalizedWithdrawalsByBlockHash bridge.FinalizedWithdrawalsMap
+	for outputProposalsByBlockHash == nil || finalizedWithdrawalsByBlockHash == nil {
+		select {
+		case outputProposalsByBlockHash = <-outputProposalsCh:
+		case finalizedWithdrawalsByBlockHash = <-finalizedWithdrawalsCh:
+		case err := <-errCh:
+			return err
+		}
+	}
 
 	var stateBatches map[common.Hash][]db.StateBatch
 	if !s.isBedrock {
@@ -307,11 +355,12 @@ func (s *Service) Update(newHeader *types.Header) error {
 		number := header.Number.Uint64()
 		deposits := depositsByBlockHash[blockHash]
 		batches := stateBatches[blockHash]
+		outputs := outputProposalsByBlockHash[blockHash]
 		finalizedWds := finalizedWithdrawalsByBlockHash[blockHash]
 
 		// Always record block data in the last block
 		// in the list of headers
-		if len(deposits) == 0 && len(batches) == 0 && len(finalizedWds) == 0 && i != len(headers)-1 {
+		if len(deposits) == 0 && len(batches) == 0 && len(outputs) == 0 && len(finalizedWds) == 0 && i != len(headers)-1 {
 			continue
 		}
 
@@ -321,6 +370,7 @@ func (s *Service) Update(newHeader *types.Header) error {
 			Number:               number,
 			Timestamp:            header.Time,
 			Deposits:             deposits,
+			OutputProposals:      outputs,
 			FinalizedWithdrawals: finalizedWds,
 		}
 
@@ -500,6 +550,69 @@ func (s *Service) cacheToken(deposit db.Deposit) error {
 	return nil
 }
 
+// backfill indexes the next batch of blocks of the first bridge that has
+// blocks left to backfill.
+func (s *Service) backfill() error {
+	if len(s.backfills) == 0 {
+		return nil
+	}
+	indexedBridge := &s.backfills[0]
+	b := bridgeByAddress(s.bridges, indexedBridge.Address)
+	if b == nil {
+		// Only registered bridges are backfilled, so this is not expected.
+		s.backfills = s.backfills[1:]
+		return nil
+	}
+
+	start, end := indexedBridge.NextBackfill(s.cfg.MaxHeaderBatchSize)
+	deposits, err := b.GetDepositsByBlockRange(s.ctx, start, end)
+	if err != nil {
+		return err
+	}
+
+	for blockHash, blockDeposits := range deposits {
+		header, err := s.cfg.L1Client.HeaderByHash(s.ctx, blockHash)
+		if err != nil {
+			return err
+		}
+		for _, deposit := range blockDeposits {
+			if err := s.cacheToken(deposit); err != nil {
+				logger.Warn("error caching token", "err", err)
+			}
+		}
+		err = s.cfg.DB.AddBackfilledL1Block(&db.IndexedL1Block{
+			Hash:       blockHash,
+			ParentHash: header.ParentHash,
+			Number:     header.Number.Uint64(),
+			Timestamp:  header.Time,
+			Deposits:   blockDeposits,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := s.cfg.DB.UpdateBridgeBackfill(db.L1, indexedBridge.Address, end+1); err != nil {
+		return err
+	}
+	indexedBridge.BackfillNext = end + 1
+	logger.Info("backfilled bridge", "name", indexedBridge.Name, "start", start, "end", end,
+		"target", indexedBridge.BackfillEnd)
+	if indexedBridge.Backfilled() {
+		s.backfills = s.backfills[1:]
+	}
+	return nil
+}
+
+func bridgeByAddress(bridges map[string]bridge.Bridge, address common.Address) bridge.Bridge {
+	for _, b := range bridges {
+		if b.Address() == address {
+			return b
+		}
+	}
+	return nil
+}
+
 func (s *Service) Start() error {
 	if s.cfg.ChainID == nil {
 		return errNoChainID
@@ -1,11 +1,15 @@
 package bridge
 
-import "time"
+import (
+	"time"
+
+	"github.com/ethereum-optimism/optimism/indexer/services"
+)
 
 const (
 	DefaultConnectionTimeout = 60 * time.Second
 
 	L2StandardBridgeAddr = "0x4200000000000000000000000000000000000010"
 
-	StandardBridgeImpl = "StandardBridge"
+	StandardBridgeImpl = services.StandardBridgeImpl
 )
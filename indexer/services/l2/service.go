@@ -17,6 +17,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/ethereum-optimism/optimism/indexer/db"
+	"github.com/ethereum-optimism/optimism/indexer/services"
 	"github.com/ethereum-optimism/optimism/indexer/services/l2/bridge"
 
 	"github.com/ethereum/go-ethereum/rpc"
@@ -52,6 +53,14 @@ type ServiceConfig struct {
 	StartBlockNumber   uint64
 	DB                 *db.Database
 	Bedrock            bool
+	// Bridges are the custom bridges to index, in addition to the standard bridges.
+	Bridges services.BridgeRegistry
+	// LegacyBridges are the custom bridges indexed by indexers without a bridge
+	// registry, which are not backfilled when such an indexer is upgraded.
+	LegacyBridges services.BridgeRegistry
+	// FinalizationPeriod is the time in seconds after which proven Bedrock
+	// withdrawals can be finalized.
+	FinalizationPeriod uint64
 }
 
 type Service struct {
@@ -60,6 +69,7 @@ type Service struct {
 	cancel func()
 
 	bridges        map[string]bridge.Bridge
+	backfills      []db.IndexedBridge
 	latestHeader   uint64
 	headerSelector *ConfirmedHeaderSelector
 
@@ -96,7 +106,7 @@ func NewService(cfg ServiceConfig) (*Service, error) {
 		cfg.ChainID = chainID
 	}
 
-	bridges, err := bridge.BridgesByChainID(cfg.ChainID, cfg.L2Client, cfg.Bedrock)
+	bridges, err := bridge.BridgesFromRegistry(cfg.L2Client, cfg.Bedrock, cfg.Bridges)
 	if err != nil {
 		cancel()
 		return nil, err
@@ -104,6 +114,21 @@ func NewService(cfg ServiceConfig) (*Service, error) {
 
 	logger.Info("Scanning bridges for withdrawals", "bridges", bridges)
 
+	highest, err := cfg.DB.GetHighestL2Block()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	backfills, err := services.RegisterBridges(cfg.DB, db.L2, predeploys.L2StandardBridgeAddr, cfg.Bridges, cfg.LegacyBridges, highest)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	for _, b := range backfills {
+		logger.Info("Backfilling bridge", "name", b.Name, "address", b.Address,
+			"start", b.BackfillNext, "end", b.BackfillEnd)
+	}
+
 	confirmedHeaderSelector, err := NewConfirmedHeaderSelector(HeaderSelectorConfig{
 		ConfDepth:    cfg.ConfDepth,
 		MaxBatchSize: cfg.MaxHeaderBatchSize,
@@ -119,6 +144,7 @@ func NewService(cfg ServiceConfig) (*Service, error) {
 		ctx:            ctx,
 		cancel:         cancel,
 		bridges:        bridges,
+		backfills:      backfills,
 		headerSelector: confirmedHeaderSelector,
 		metrics:        cfg.Metrics,
 		tokenCache: map[common.Address]*db.Token{
@@ -170,6 +196,9 @@ func (s *Service) loop() {
 					break
 				}
 			}
+			if err := s.backfill(); err != nil {
+				logger.Error("Unable to backfill bridge", "err", err)
+			}
 		case <-s.ctx.Done():
 			logger.Info("service stopped")
 			return
@@ -370,12 +399,18 @@ func (s *Service) GetWithdrawals(w http.ResponseWriter, r *http.Request) {
 
 	finalizationState := db.ParseFinalizationState(r.URL.Query().Get("finalized"))
 
+	status, err := db.ParseWithdrawalStatus(r.URL.Query().Get("status"))
+	if err != nil {
+		server.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	page := db.PaginationParam{
 		Limit:  limit,
 		Offset: offset,
 	}
 
-	withdrawals, err := s.cfg.DB.GetWithdrawalsByAddress(common.HexToAddress(vars["address"]), page, finalizationState)
+	withdrawals, err := s.cfg.DB.GetWithdrawalsByAddress(common.HexToAddress(vars["address"]), page, finalizationState, status, s.cfg.FinalizationPeriod)
 	if err != nil {
 		server.RespondWithError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -458,6 +493,69 @@ func (s *Service) cacheToken(withdrawal db.Withdrawal) error {
 	return nil
 }
 
+// backfill indexes the next batch of blocks of the first bridge that has
+// blocks left to backfill.
+func (s *Service) backfill() error {
+	if len(s.backfills) == 0 {
+		return nil
+	}
+	indexedBridge := &s.backfills[0]
+	b := bridgeByAddress(s.bridges, indexedBridge.Address)
+	if b == nil {
+		// Only registered bridges are backfilled, so this is not expected.
+		s.backfills = s.backfills[1:]
+		return nil
+	}
+
+	start, end := indexedBridge.NextBackfill(s.cfg.MaxHeaderBatchSize)
+	withdrawals, err := b.GetWithdrawalsByBlockRange(s.ctx, start, end)
+	if err != nil {
+		return err
+	}
+
+	for blockHash, blockWithdrawals := range withdrawals {
+		header, err := s.cfg.L2Client.HeaderByHash(s.ctx, blockHash)
+		if err != nil {
+			return err
+		}
+		for _, withdrawal := range blockWithdrawals {
+			if err := s.cacheToken(withdrawal); err != nil {
+				logger.Warn("error caching token", "err", err)
+			}
+		}
+		err = s.cfg.DB.AddBackfilledL2Block(&db.IndexedL2Block{
+			Hash:        blockHash,
+			ParentHash:  header.ParentHash,
+			Number:      header.Number.Uint64(),
+			Timestamp:   header.Time,
+			Withdrawals: blockWithdrawals,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := s.cfg.DB.UpdateBridgeBackfill(db.L2, indexedBridge.Address, end+1); err != nil {
+		return err
+	}
+	indexedBridge.BackfillNext = end + 1
+	logger.Info("backfilled bridge", "name", indexedBridge.Name, "start", start, "end", end,
+		"target", indexedBridge.BackfillEnd)
+	if indexedBridge.Backfilled() {
+		s.backfills = s.backfills[1:]
+	}
+	return nil
+}
+
+func bridgeByAddress(bridges map[string]bridge.Bridge, address common.Address) bridge.Bridge {
+	for _, b := range bridges {
+		if b.Address() == address {
+			return b
+		}
+	}
+	return nil
+}
+
 func (s *Service) Start() error {
 	if s.cfg.ChainID == nil {
 		return errNoChainID
@@ -0,0 +1,62 @@
+package op_batcher
+
+import (
+	"context"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+)
+
+// BatcherStatus is a snapshot of the submission state of the batcher.
+type BatcherStatus struct {
+	// Running is false if batch submission was stopped through the admin API.
+	Running bool `json:"running"`
+	// PendingBlocks is the number of L2 blocks that are not yet part of a channel.
+	PendingBlocks int `json:"pending_blocks"`
+	// PendingFrames is the number of frames that wait to be submitted.
+	PendingFrames int `json:"pending_frames"`
+	// InflightTxs is the number of batch txs that are submitted, but not yet confirmed.
+	InflightTxs uint64 `json:"inflight_txs"`
+	// Channels is the number of channels that are not yet safe on L2.
+	Channels int `json:"channels"`
+	// LastStoredBlock is the last L2 block that was loaded into the batcher.
+	LastStoredBlock eth.BlockID `json:"last_stored_block"`
+	// LastConfirmedL1 is the L1 block that included the last confirmed batch tx.
+	LastConfirmedL1 eth.BlockID `json:"last_confirmed_l1"`
+}
+
+type batcherClient interface {
+	StartBatchSubmission(ctx context.Context) error
+	StopBatchSubmission(ctx context.Context) error
+	ForceCloseChannel(ctx context.Context) error
+	Status(ctx context.Context) (*BatcherStatus, error)
+}
+
+type adminAPI struct {
+	b batcherClient
+}
+
+func NewAdminAPI(b batcherClient) *adminAPI {
+	return &adminAPI{
+		b: b,
+	}
+}
+
+// StartBatcher resumes the submission of batches after it was stopped.
+func (a *adminAPI) StartBatcher(ctx context.Context) error {
+	return a.b.StartBatchSubmission(ctx)
+}
+
+// StopBatcher pauses the submission of new batch txs. Txs that are already in flight are still confirmed.
+func (a *adminAPI) StopBatcher(ctx context.Context) error {
+	return a.b.StopBatchSubmission(ctx)
+}
+
+// ForceCloseChannel closes the channel that is currently being filled, such that its remaining frames are submitted.
+func (a *adminAPI) ForceCloseChannel(ctx context.Context) error {
+	return a.b.ForceCloseChannel(ctx)
+}
+
+// BatcherStatus returns the submission state of the batcher.
+func (a *adminAPI) BatcherStatus(ctx context.Context) (*BatcherStatus, error) {
+	return a.b.Status(ctx)
+}
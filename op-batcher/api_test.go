@@ -0,0 +1,76 @@
+package op_batcher
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+)
+
+type mockBatcherClient struct {
+	calls  []string
+	err    error
+	status BatcherStatus
+}
+
+func (m *mockBatcherClient) StartBatchSubmission(ctx context.Context) error {
+	m.calls = append(m.calls, "start")
+	return m.err
+}
+
+func (m *mockBatcherClient) StopBatchSubmission(ctx context.Context) error {
+	m.calls = append(m.calls, "stop")
+	return m.err
+}
+
+func (m *mockBatcherClient) ForceCloseChannel(ctx context.Context) error {
+	m.calls = append(m.calls, "forceClose")
+	return m.err
+}
+
+func (m *mockBatcherClient) Status(ctx context.Context) (*BatcherStatus, error) {
+	m.calls = append(m.calls, "status")
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &m.status, nil
+}
+
+func newTestAdminClient(t *testing.T, b batcherClient) *rpc.Client {
+	srv := rpc.NewServer()
+	require.NoError(t, srv.RegisterName("admin", NewAdminAPI(b)))
+	t.Cleanup(srv.Stop)
+	client := rpc.DialInProc(srv)
+	t.Cleanup(client.Close)
+	return client
+}
+
+func TestAdminAPI(t *testing.T) {
+	ctx := context.Background()
+	b := &mockBatcherClient{status: BatcherStatus{
+		Running:         true,
+		PendingBlocks:   3,
+		PendingFrames:   2,
+		InflightTxs:     1,
+		Channels:        1,
+		LastStoredBlock: eth.BlockID{Number: 10},
+		LastConfirmedL1: eth.BlockID{Number: 5},
+	}}
+	client := newTestAdminClient(t, b)
+
+	require.NoError(t, client.CallContext(ctx, nil, "admin_stopBatcher"))
+	require.NoError(t, client.CallContext(ctx, nil, "admin_startBatcher"))
+	require.NoError(t, client.CallContext(ctx, nil, "admin_forceCloseChannel"))
+	var status BatcherStatus
+	require.NoError(t, client.CallContext(ctx, &status, "admin_batcherStatus"))
+	require.Equal(t, b.status, status)
+	require.Equal(t, []string{"stop", "start", "forceClose", "status"}, b.calls)
+
+	b.err = errors.New("boom")
+	require.ErrorContains(t, client.CallContext(ctx, nil, "admin_stopBatcher"), "boom")
+	require.ErrorContains(t, client.CallContext(ctx, &status, "admin_batcherStatus"), "boom")
+}
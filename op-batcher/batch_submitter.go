@@ -13,6 +13,7 @@ import (
 	opmetrics "github.com/ethereum-optimism/optimism/op-service/metrics"
 	oppprof "github.com/ethereum-optimism/optimism/op-service/pprof"
 	oprpc "github.com/ethereum-optimism/optimism/op-service/rpc"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/urfave/cli"
 )
 
@@ -63,7 +64,7 @@ func Main(version string) func(cliCtx *cli.Context) error {
 			}()
 		}
 
-		registry := opmetrics.NewRegistry()
+		registry := batchSubmitter.metr.Registry()
 		metricsCfg := cfg.MetricsConfig
 		if metricsCfg.Enabled {
 			l.Info("starting metrics server", "addr", metricsCfg.ListenAddr, "port", metricsCfg.ListenPort)
@@ -76,10 +77,19 @@ func Main(version string) func(cliCtx *cli.Context) error {
 		}
 
 		rpcCfg := cfg.RPCConfig
+		var apis []rpc.API
+		if cfg.RPCEnableAdmin {
+			apis = append(apis, rpc.API{
+				Namespace: "admin",
+				Service:   NewAdminAPI(batchSubmitter),
+			})
+		}
 		server := oprpc.NewServer(
 			rpcCfg.ListenAddr,
 			rpcCfg.ListenPort,
 			version,
+			oprpc.WithAPIs(apis),
+			oprpc.WithLogger(l),
 		)
 		if err := server.Start(); err != nil {
 			cancel()
@@ -0,0 +1,432 @@
+package op_batcher
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// frameTxOverhead is the number of bytes of a batcher tx that are not frame data:
+// the derivation version byte and the frame header, as accounted for by derive.ChannelOut.OutputFrame.
+const frameTxOverhead = 47 + 1
+
+// frameEncodingSize is the encoded size of the version byte and the frame header of a batcher tx,
+// which is smaller than the overhead that derive.ChannelOut.OutputFrame reserves.
+const frameEncodingSize = 1 + derive.ChannelIDLength + 2 + 4 + 1
+
+type ChannelConfig struct {
+	// ChannelTimeout is the number of L1 blocks within which all frames of a channel must be included,
+	// counted from the inclusion of the first frame.
+	ChannelTimeout uint64
+	// MaxChannelDuration is the maximum number of L1 blocks that a channel is kept open for.
+	// A channel is never kept open for more than half of the channel timeout.
+	// If 0, the channel is kept open until it is full, or half of the channel timeout passed.
+	MaxChannelDuration uint64
+	// MinFrameSize is the minimum size of a batcher tx. Only the last frame of a channel may be smaller,
+	// if the channel data does not fill a frame of the minimum size. It must be at most half of MaxFrameSize.
+	MinFrameSize uint64
+	// MaxFrameSize is the maximum size of a batcher tx, including the version byte and frame overhead.
+	MaxFrameSize uint64
+	// TargetNumFrames is the number of full frames that a channel is filled up to.
+	TargetNumFrames int
+	// ApproxComprRatio is the initial estimate of the compression ratio of channel data.
+	// The estimate is updated with the compression ratio of every closed channel.
+	ApproxComprRatio float64
+	// Codec is the compression codec of channel data.
+	// Channels are compressed with zlib until the rollup config accepts zstd channels.
+	Codec derive.CompressionCodec
+	// Rollup is the rollup config, which activates the compression codecs. It may be nil if Codec is zlib.
+	Rollup *rollup.Config
+}
+
+// Check validates the channel configuration.
+func (cc ChannelConfig) Check() error {
+	if cc.MaxFrameSize <= frameTxOverhead {
+		return fmt.Errorf("max frame size %d must be larger than the frame overhead %d", cc.MaxFrameSize, frameTxOverhead)
+	}
+	if cc.MinFrameSize > cc.MaxFrameSize/2 {
+		return fmt.Errorf("min frame size %d must be at most half of the max frame size %d", cc.MinFrameSize, cc.MaxFrameSize)
+	}
+	if cc.TargetNumFrames < 1 {
+		return fmt.Errorf("target number of frames must be at least 1, got %d", cc.TargetNumFrames)
+	}
+	if cc.ApproxComprRatio <= 0 || cc.ApproxComprRatio > 1 {
+		return fmt.Errorf("approximate compression ratio must be in (0, 1], got %f", cc.ApproxComprRatio)
+	}
+	switch cc.Codec {
+	case derive.CodecZlib:
+	case derive.CodecZstd:
+		// The rollup config is not known before the batcher connects to the rollup node.
+		if cc.Rollup != nil && cc.Rollup.ZstdTime == nil {
+			return fmt.Errorf("%w: %s is not scheduled by the rollup config", derive.ErrCodecNotActive, cc.Codec)
+		}
+	default:
+		return fmt.Errorf("%w: %s", derive.ErrUnknownCompressionCodec, cc.Codec)
+	}
+	return nil
+}
+
+// codecAt returns the compression codec of a channel that is opened at the given L1 head.
+// zstd is rejected until the rollup config accepts zstd channels, and zlib is used instead.
+func (cc *ChannelConfig) codecAt(l1Head eth.L1BlockRef) derive.CompressionCodec {
+	if cc.Codec == derive.CodecZstd && (cc.Rollup == nil || !cc.Rollup.IsZstd(l1Head.Time)) {
+		return derive.CodecZlib
+	}
+	return cc.Codec
+}
+
+// maxFrameDataSize is the maximum amount of channel data that fits in a single frame.
+func (cc *ChannelConfig) maxFrameDataSize() uint64 {
+	return cc.MaxFrameSize - frameTxOverhead
+}
+
+// minFrameDataSize is the minimum amount of channel data in a frame, such that the frame is not smaller
+// than the minimum frame size. It is at least 1, a frame never carries no data.
+func (cc *ChannelConfig) minFrameDataSize() uint64 {
+	if cc.MinFrameSize <= frameEncodingSize {
+		return 1
+	}
+	return cc.MinFrameSize - frameEncodingSize
+}
+
+// closedFrameDataSize returns the amount of channel data to output in the next frame of a closed channel,
+// given the remaining channel data. The remaining data is output in full frames, except for the last two frames:
+// if the last frame would be smaller than the minimum frame size, data is moved from the frame before it.
+// The minimum frame size is at most half of the max frame size, so both frames are at least the minimum size.
+func (cc *ChannelConfig) closedFrameDataSize(remaining uint64) uint64 {
+	maxData, minData := cc.maxFrameDataSize(), cc.minFrameDataSize()
+	if remaining < maxData || remaining-maxData >= minData {
+		return maxData
+	}
+	return remaining - minData
+}
+
+// targetDataSize is the amount of compressed channel data that a channel is filled up to.
+func (cc *ChannelConfig) targetDataSize() uint64 {
+	return uint64(cc.TargetNumFrames) * cc.maxFrameDataSize()
+}
+
+type frameStatus uint8
+
+const (
+	// framePending frames are ready to be submitted, or have to be submitted again.
+	framePending frameStatus = iota
+	// frameSubmitted frames have been handed to the tx manager, and wait for confirmation or failure.
+	frameSubmitted
+	// frameConfirmed frames have been included in a L1 block.
+	frameConfirmed
+)
+
+type frameData struct {
+	data   []byte
+	status frameStatus
+	// inclusion is the L1 block that the frame was included in, if the frame is confirmed.
+	inclusion eth.BlockID
+}
+
+// channel tracks the lifecycle of a single channel: the L2 blocks it contains,
+// and the submission status of each of its frames.
+//
+// A channel is built while it is open: blocks are added until the estimated compressed size
+// of the channel reaches the target size, and full frames are output as the compressed data becomes available.
+// The remaining data is output once the channel is closed.
+//
+// A channel is opened on L1 by the inclusion of its first frame. All of its frames must be included
+// within ChannelTimeout L1 blocks of that first inclusion, or else the channel is dropped by the
+// derivation pipeline, and the blocks have to be submitted again in a new channel.
+type channel struct {
+	id  derive.ChannelID
+	cfg *ChannelConfig
+	// co is the channel builder. It is nil once the channel is closed and all frames are output.
+	co *derive.ChannelOut
+	// openL1 is the number of the L1 head when the channel was opened.
+	openL1 uint64
+	// full is true if no more blocks fit in the channel.
+	full bool
+	// outputBytes is the amount of compressed channel data that was output into frames.
+	outputBytes int
+	// inputBytes is the amount of uncompressed channel data.
+	inputBytes int
+
+	// blocks contained in the channel, in order.
+	blocks []*types.Block
+	// frames of the channel, indexed by frame number.
+	frames []frameData
+	// next is the lowest frame number that may still be pending.
+	next int
+}
+
+// newChannel opens a new channel, to be filled with blocks.
+func newChannel(cfg *ChannelConfig, l1Head eth.L1BlockRef) (*channel, error) {
+	co, err := derive.NewChannelOutWithCodec(cfg.codecAt(l1Head))
+	if err != nil {
+		return nil, err
+	}
+	return &channel{
+		id:     co.ID(),
+		cfg:    cfg,
+		co:     co,
+		openL1: l1Head.Number,
+	}, nil
+}
+
+// IsOpen returns true if more blocks may be added to the channel.
+func (c *channel) IsOpen() bool {
+	return c.co != nil && !c.co.IsClosed()
+}
+
+// EstimatedSize returns the estimated size of the channel data after compression,
+// given the approximate compression ratio.
+func (c *channel) EstimatedSize(approxComprRatio float64) uint64 {
+	known := uint64(c.outputBytes)
+	if c.co != nil {
+		known += uint64(c.co.ReadyBytes())
+		estimate := uint64(float64(c.co.InputBytes()) * approxComprRatio)
+		if estimate > known {
+			return estimate
+		}
+	}
+	return known
+}
+
+// AddBlocks adds blocks to the open channel, until the estimated compressed size reaches the target size,
+// or the channel is full. It returns the number of blocks that were added.
+func (c *channel) AddBlocks(blocks []*types.Block, approxComprRatio float64) (int, error) {
+	if !c.IsOpen() {
+		return 0, errors.New("channel is not open")
+	}
+	i := 0
+	for ; i < len(blocks) && !c.full; i++ {
+		if c.EstimatedSize(approxComprRatio) >= c.cfg.targetDataSize() {
+			c.full = true
+			break
+		}
+		if err := c.co.AddBlock(blocks[i]); errors.Is(err, derive.ErrTooManyRLPBytes) {
+			c.full = true
+			break
+		} else if err != nil {
+			return i, err
+		}
+		c.blocks = append(c.blocks, blocks[i])
+	}
+	if c.EstimatedSize(approxComprRatio) >= c.cfg.targetDataSize() {
+		c.full = true
+	}
+	return i, nil
+}
+
+// ShouldClose returns true if the channel is full, or if it has been open for the max channel duration.
+// A channel is closed after at most half of the channel timeout passed since it was opened,
+// to leave enough time to submit the remaining frames before the channel times out.
+func (c *channel) ShouldClose(l1Head eth.L1BlockRef) bool {
+	duration := c.cfg.ChannelTimeout / 2
+	if c.cfg.MaxChannelDuration != 0 && c.cfg.MaxChannelDuration < duration {
+		duration = c.cfg.MaxChannelDuration
+	}
+	return c.full || l1Head.Number >= c.openL1+duration
+}
+
+// Close closes the channel. No more blocks can be added after the channel is closed.
+func (c *channel) Close() error {
+	if !c.IsOpen() {
+		return nil
+	}
+	c.inputBytes = c.co.InputBytes()
+	return c.co.Close()
+}
+
+// ComprRatio returns the compression ratio of the channel data.
+// It is only accurate once the channel is closed.
+func (c *channel) ComprRatio() float64 {
+	if c.inputBytes == 0 {
+		return 0
+	}
+	return float64(c.EstimatedSize(0)) / float64(c.inputBytes)
+}
+
+// OutputFrames outputs all full frames of the open channel, or all remaining frames of the closed channel.
+// Only the last frame of a channel may be smaller than the minimum frame size, if the channel data is smaller:
+// the open channel retains at least the minimum frame data, such that it can be output with the last frame.
+// It returns the fill ratio (frame size over max frame size) of every output frame.
+func (c *channel) OutputFrames() ([]float64, error) {
+	if c.co == nil {
+		return nil, nil
+	}
+	closed := c.co.IsClosed()
+	maxData, minData := c.cfg.maxFrameDataSize(), c.cfg.minFrameDataSize()
+	var fillRatios []float64
+	for closed || uint64(c.co.ReadyBytes()) >= maxData+minData {
+		var buf bytes.Buffer
+		buf.WriteByte(derive.DerivationVersion0)
+		readyBytes := c.co.ReadyBytes()
+		frameSize := c.cfg.MaxFrameSize
+		if closed {
+			frameSize = c.cfg.closedFrameDataSize(uint64(readyBytes)) + frameTxOverhead
+		}
+		err := c.co.OutputFrame(&buf, frameSize)
+		if err != io.EOF && err != nil {
+			return fillRatios, err
+		}
+		c.outputBytes += readyBytes - c.co.ReadyBytes()
+		c.frames = append(c.frames, frameData{data: buf.Bytes()})
+		fillRatios = append(fillRatios, float64(buf.Len())/float64(c.cfg.MaxFrameSize))
+		if err == io.EOF {
+			c.co = nil
+			break
+		}
+	}
+	return fillRatios, nil
+}
+
+// NextTxData returns the next frame that is pending submission, and marks it as submitted.
+// It returns io.EOF if there are no pending frames.
+func (c *channel) NextTxData() ([]byte, txID, error) {
+	for ; c.next < len(c.frames); c.next++ {
+		f := &c.frames[c.next]
+		if f.status == framePending {
+			f.status = frameSubmitted
+			id := txID{chID: c.id, frameNumber: uint16(c.next)}
+			c.next++
+			return f.data, id, nil
+		}
+	}
+	return nil, txID{}, io.EOF
+}
+
+// HasPendingFrames returns true if there are frames that still have to be submitted.
+func (c *channel) HasPendingFrames() bool {
+	for i := c.next; i < len(c.frames); i++ {
+		if c.frames[i].status == framePending {
+			return true
+		}
+	}
+	return false
+}
+
+// PendingFrames returns the number of frames that still have to be submitted.
+func (c *channel) PendingFrames() int {
+	n := 0
+	for i := c.next; i < len(c.frames); i++ {
+		if c.frames[i].status == framePending {
+			n++
+		}
+	}
+	return n
+}
+
+// HasSubmittedFrames returns true if any frame of the channel was handed to the tx manager,
+// and may thus be included on L1.
+func (c *channel) HasSubmittedFrames() bool {
+	for _, f := range c.frames {
+		if f.status != framePending {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFullyConfirmed returns true if the channel is closed, and all its frames are included on L1.
+func (c *channel) IsFullyConfirmed() bool {
+	if c.co != nil {
+		return false
+	}
+	for _, f := range c.frames {
+		if f.status != frameConfirmed {
+			return false
+		}
+	}
+	return true
+}
+
+// setPending marks a frame as pending again, so it is returned again by NextTxData.
+func (c *channel) setPending(frameNumber uint16) {
+	c.frames[frameNumber] = frameData{data: c.frames[frameNumber].data}
+	if int(frameNumber) < c.next {
+		c.next = int(frameNumber)
+	}
+}
+
+// TxFailed marks the frame as pending again, to resubmit it.
+func (c *channel) TxFailed(frameNumber uint16) {
+	c.setPending(frameNumber)
+}
+
+// TxConfirmed records the L1 inclusion block of the frame.
+func (c *channel) TxConfirmed(frameNumber uint16, inclusion eth.BlockID) {
+	f := &c.frames[frameNumber]
+	f.status = frameConfirmed
+	f.inclusion = inclusion
+}
+
+// TxReorged marks a frame, which was previously confirmed in the given inclusion block, as pending again.
+// It returns false if the frame was not confirmed in that block.
+func (c *channel) TxReorged(frameNumber uint16, inclusion eth.BlockID) bool {
+	f := c.frames[frameNumber]
+	if f.status != frameConfirmed || f.inclusion != inclusion {
+		return false
+	}
+	c.setPending(frameNumber)
+	return true
+}
+
+// inclusionRange returns the lowest and highest L1 inclusion block numbers of the confirmed frames.
+// ok is false if no frames are confirmed.
+func (c *channel) inclusionRange() (min uint64, max uint64, ok bool) {
+	for _, f := range c.frames {
+		if f.status != frameConfirmed {
+			continue
+		}
+		if !ok || f.inclusion.Number < min {
+			min = f.inclusion.Number
+		}
+		if !ok || f.inclusion.Number > max {
+			max = f.inclusion.Number
+		}
+		ok = true
+	}
+	return min, max, ok
+}
+
+// timedOutAt returns true if a frame included in the given L1 block is dropped by the derivation pipeline,
+// for a channel that was opened on L1 by the inclusion of its first frame in the given open block.
+// This matches the channel timeout rule of the channel bank.
+func (cc *ChannelConfig) timedOutAt(openBlock, inclusionBlock uint64) bool {
+	return openBlock+cc.ChannelTimeout < inclusionBlock
+}
+
+// TimedOut returns true if the channel cannot be completed anymore within the channel timeout:
+// either frames were confirmed too far apart, or there are still unconfirmed frames
+// that can only be included after the timeout. The earliest block that a frame can still
+// be included in is the block after the L1 head.
+func (c *channel) TimedOut(l1Head eth.L1BlockRef) bool {
+	min, max, ok := c.inclusionRange()
+	if !ok {
+		return false
+	}
+	if c.cfg.timedOutAt(min, max) {
+		return true
+	}
+	return !c.IsFullyConfirmed() && c.cfg.timedOutAt(min, l1Head.Number+1)
+}
+
+// LastBlock returns the ID of the last L2 block in the channel.
+func (c *channel) LastBlock() eth.BlockID {
+	return eth.ToBlockID(c.blocks[len(c.blocks)-1])
+}
+
+// ConfirmedTxs returns the inclusion blocks of all confirmed frames.
+func (c *channel) ConfirmedTxs() map[txID]eth.BlockID {
+	out := make(map[txID]eth.BlockID)
+	for i, f := range c.frames {
+		if f.status == frameConfirmed {
+			out[txID{chID: c.id, frameNumber: uint16(i)}] = f.inclusion
+		}
+	}
+	return out
+}
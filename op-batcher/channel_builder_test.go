@@ -0,0 +1,155 @@
+package op_batcher
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChannelConfigCheck(t *testing.T) {
+	cfg := testChannelConfig()
+	require.NoError(t, cfg.Check())
+
+	cfg.MinFrameSize = cfg.MaxFrameSize / 2
+	require.NoError(t, cfg.Check())
+	cfg.MinFrameSize = cfg.MaxFrameSize/2 + 1
+	require.Error(t, cfg.Check(), "min frame size larger than half of the max frame size")
+
+	cfg = testChannelConfig()
+	cfg.MaxFrameSize = frameTxOverhead
+	require.Error(t, cfg.Check(), "max frame size does not fit any data")
+
+	cfg = testChannelConfig()
+	cfg.TargetNumFrames = 0
+	require.Error(t, cfg.Check())
+
+	cfg = testChannelConfig()
+	cfg.ApproxComprRatio = 1.1
+	require.Error(t, cfg.Check())
+
+	cfg = testChannelConfig()
+	cfg.Codec = derive.CompressionCodec(2)
+	require.ErrorIs(t, cfg.Check(), derive.ErrUnknownCompressionCodec)
+
+	cfg = testChannelConfig()
+	cfg.Codec = derive.CodecZstd
+	require.NoError(t, cfg.Check(), "rollup config is not known yet")
+	cfg.Rollup = &rollup.Config{}
+	require.ErrorIs(t, cfg.Check(), derive.ErrCodecNotActive, "zstd is never activated")
+	zstdTime := uint64(10)
+	cfg.Rollup.ZstdTime = &zstdTime
+	require.NoError(t, cfg.Check())
+}
+
+func TestChannelCodec(t *testing.T) {
+	cfg := testChannelConfig()
+	ch, err := newChannel(&cfg, eth.L1BlockRef{Time: 20})
+	require.NoError(t, err)
+	require.Equal(t, derive.CodecZlib, ch.co.Codec())
+
+	zstdTime := uint64(10)
+	cfg.Codec = derive.CodecZstd
+	cfg.Rollup = &rollup.Config{ZstdTime: &zstdTime}
+	ch, err = newChannel(&cfg, eth.L1BlockRef{Time: 9})
+	require.NoError(t, err)
+	require.Equal(t, derive.CodecZlib, ch.co.Codec(), "zstd is not used before it is active")
+	ch, err = newChannel(&cfg, eth.L1BlockRef{Time: 10})
+	require.NoError(t, err)
+	require.Equal(t, derive.CodecZstd, ch.co.Codec())
+}
+
+func TestClosedFrameDataSize(t *testing.T) {
+	cfg := ChannelConfig{MinFrameSize: 100 + frameEncodingSize, MaxFrameSize: 1000 + frameTxOverhead}
+	maxData, minData := uint64(1000), uint64(100)
+	require.Equal(t, maxData, cfg.closedFrameDataSize(50), "last frame takes all remaining data")
+	require.Equal(t, maxData, cfg.closedFrameDataSize(maxData-1), "last frame takes all remaining data")
+	require.Equal(t, maxData-minData, cfg.closedFrameDataSize(maxData), "last frame is never empty")
+	require.Equal(t, maxData+10-minData, cfg.closedFrameDataSize(maxData+10))
+	require.Equal(t, maxData, cfg.closedFrameDataSize(maxData+minData))
+	require.Equal(t, maxData, cfg.closedFrameDataSize(3*maxData))
+
+	cfg.MinFrameSize = 0
+	require.Equal(t, maxData-1, cfg.closedFrameDataSize(maxData), "last frame carries at least one byte")
+}
+
+// outputAllFrames adds the blocks to a new channel, outputs frames while the channel is open,
+// and outputs the remaining frames after closing it. It returns the frames as submitted in batcher txs.
+func outputAllFrames(t *testing.T, cfg *ChannelConfig, blocks []*types.Block) ([][]byte, *channel) {
+	ch, err := newChannel(cfg, eth.L1BlockRef{})
+	require.NoError(t, err)
+	for len(blocks) > 0 && ch.IsOpen() && !ch.full {
+		added, err := ch.AddBlocks(blocks[:1], 1)
+		require.NoError(t, err)
+		blocks = blocks[added:]
+		_, err = ch.OutputFrames()
+		require.NoError(t, err)
+	}
+	require.NoError(t, ch.Close())
+	_, err = ch.OutputFrames()
+	require.NoError(t, err)
+	var frames [][]byte
+	for _, f := range ch.frames {
+		frames = append(frames, f.data)
+	}
+	return frames, ch
+}
+
+func TestChannelOutputFramesMinSize(t *testing.T) {
+	for _, minSize := range []uint64{0, 100, 300, 500} {
+		for _, blockCount := range []int{1, 2, 5, 13} {
+			rng := rand.New(rand.NewSource(int64(minSize) + int64(blockCount)))
+			cfg := testChannelConfig()
+			cfg.MinFrameSize = minSize
+			cfg.MaxFrameSize = 1000
+			cfg.TargetNumFrames = 100
+			require.NoError(t, cfg.Check())
+
+			frames, _ := outputAllFrames(t, &cfg, makeBlocksWithData(t, rng, eth.BlockID{}, blockCount, 400))
+			var total int
+			for i, data := range frames {
+				require.LessOrEqual(t, uint64(len(data)), cfg.MaxFrameSize)
+				if i < len(frames)-1 {
+					require.GreaterOrEqual(t, uint64(len(data)), minSize, "only the last frame may be smaller")
+				}
+				var f derive.Frame
+				require.NoError(t, f.UnmarshalBinary(bytes.NewReader(data[1:])))
+				require.Equal(t, uint16(i), f.FrameNumber)
+				require.Equal(t, i == len(frames)-1, f.IsLast)
+				require.NotEmpty(t, f.Data)
+				total += len(f.Data)
+			}
+			if uint64(total)+frameEncodingSize >= minSize {
+				require.GreaterOrEqual(t, uint64(len(frames[len(frames)-1])), minSize,
+					"the last frame is only smaller if the channel data is smaller")
+			}
+		}
+	}
+}
+
+func TestChannelAddBlocksTargetFrames(t *testing.T) {
+	for _, target := range []int{1, 3, 6} {
+		rng := rand.New(rand.NewSource(int64(target)))
+		cfg := testChannelConfig()
+		cfg.MaxFrameSize = 2000
+		cfg.TargetNumFrames = target
+		blocks := makeBlocksWithData(t, rng, eth.BlockID{}, 100, 300)
+
+		frames, ch := outputAllFrames(t, &cfg, blocks)
+		require.True(t, ch.full, "channel is filled up to the target")
+		require.Less(t, len(ch.blocks), len(blocks), "blocks beyond the target are not added")
+		// the estimate may overshoot the target by less than a block, which spills into one more frame
+		require.GreaterOrEqual(t, len(frames), target)
+		require.LessOrEqual(t, len(frames), target+1)
+		// all but the last two frames are full, the last two may be balanced to respect the min frame size
+		for i := 0; i < len(frames)-2; i++ {
+			data := frames[i]
+			require.Equal(t, cfg.maxFrameDataSize()+frameEncodingSize, uint64(len(data)), "frames are full")
+		}
+	}
+}
@@ -1,13 +1,14 @@
 package op_batcher
 
 import (
-	"bytes"
 	"errors"
 	"io"
 
 	"github.com/ethereum-optimism/optimism/op-node/eth"
 	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
 )
 
 var ErrReorg = errors.New("block does not extend existing chain")
@@ -17,114 +18,348 @@ type txID struct {
 	frameNumber uint16
 }
 
-type taggedData struct {
-	data []byte
-	id   txID
-}
-
+// channelManager stores a contiguous set of L2 blocks, and turns them into channels.
+// It tracks the submission status of the frames of every channel, until the blocks of the channel are safe:
+//   - Frames of failed transactions are resubmitted.
+//   - Frames that were confirmed, but then reorged out of L1, are resubmitted.
+//   - Channels that cannot be completed before the channel timeout are dropped,
+//     and their blocks are submitted again in a new channel.
 type channelManager struct {
-	// All blocks since the last request for new tx data
+	log  log.Logger
+	cfg  ChannelConfig
+	metr *Metrics
+
+	// approxComprRatio is the current estimate of the compression ratio,
+	// used to estimate the compressed size of the channel that is being filled.
+	approxComprRatio float64
+
+	// All blocks since the last request for new tx data, that are not yet part of a channel
 	blocks []*types.Block
-	datas  []taggedData
+	// tip is the hash of the last block added to the state. The next block must build on it.
+	// It is zeroed if the next block does not have to build on any block.
+	tip common.Hash
+
+	// channels that have frames to be submitted or confirmed, or that are not yet safe on L2.
+	// Ordered by their blocks: the blocks of all channels and the pending blocks form a contiguous chain.
+	channels []*channel
+}
+
+func NewChannelManager(log log.Logger, cfg ChannelConfig, metr *Metrics) *channelManager {
+	metr.RecordApproxComprRatio(cfg.ApproxComprRatio)
+	return &channelManager{
+		log:              log,
+		cfg:              cfg,
+		metr:             metr,
+		approxComprRatio: cfg.ApproxComprRatio,
+	}
 }
 
 // Clear clears the entire state of the channel manager.
-// It is intended to be used after an L2 reorg.
 func (s *channelManager) Clear() {
 	s.blocks = s.blocks[:0]
-	s.datas = s.datas[:0]
+	s.channels = s.channels[:0]
+	s.tip = common.Hash{}
 }
 
-// func (s *channelManager) TxConfirmed(id txID, inclusionBlock eth.BlockID) {
-// 	// todo: implement
-// }
+// ClearUnsubmitted is intended to be used after an L2 reorg, with the last block that is still canonical.
+// It clears the pending blocks and the channels that are not submitted to L1 yet, after the fork point.
+// Blocks and channels up to the fork point are not affected by the reorg, and are retained.
+// An unsubmitted channel that contains the fork point is dropped, and its blocks up to the fork point are
+// added back to the pending blocks. It returns the last block that is still retained, the next block to add
+// must build on that block.
+// Channels that are already (partially) submitted cannot be retracted, and are retained.
+// If such a channel contains reorged blocks, it is kept to complete its submission, but the next block
+// does not have to build on it anymore. The last block of the last channel wholly before the fork point
+// is returned then, or an empty block ID if there is no such channel.
+func (s *channelManager) ClearUnsubmitted(fork eth.BlockID) eth.BlockID {
+	for len(s.blocks) > 0 && s.blocks[len(s.blocks)-1].NumberU64() > fork.Number {
+		s.blocks = s.blocks[:len(s.blocks)-1]
+	}
+	for len(s.channels) > 0 {
+		i := len(s.channels) - 1
+		ch := s.channels[i]
+		if ch.LastBlock().Number <= fork.Number || ch.HasSubmittedFrames() {
+			break
+		}
+		s.log.Info("dropping unsubmitted channel after L2 reorg", "channel", ch.id, "fork", fork)
+		s.requeue(i)
+		for len(s.blocks) > 0 && s.blocks[len(s.blocks)-1].NumberU64() > fork.Number {
+			s.blocks = s.blocks[:len(s.blocks)-1]
+		}
+	}
+	if len(s.blocks) > 0 {
+		s.tip = s.blocks[len(s.blocks)-1].Hash()
+		return eth.ToBlockID(s.blocks[len(s.blocks)-1])
+	}
+	if len(s.channels) == 0 {
+		s.tip = common.Hash{}
+		return eth.BlockID{}
+	}
+	last := s.channels[len(s.channels)-1]
+	if last.LastBlock().Number > fork.Number {
+		// The retained channel may still be open, but the next blocks may not extend its blocks anymore.
+		if err := s.closeChannel(last); err != nil {
+			s.log.Error("failed to close channel after reorg", "err", err)
+		}
+		s.tip = common.Hash{}
+		for i := len(s.channels) - 2; i >= 0; i-- {
+			if s.channels[i].LastBlock().Number <= fork.Number {
+				return s.channels[i].LastBlock()
+			}
+		}
+		return eth.BlockID{}
+	}
+	s.tip = last.LastBlock().Hash
+	return last.LastBlock()
+}
 
-// TxData returns the next tx.data that should be submitted to L1.
-// It is very simple & currently ignores the l1Head provided (this will change).
-// It may buffer very large channels as well.
-func (s *channelManager) TxData(l1Head eth.L1BlockRef) ([]byte, txID, error) {
-	// Note: l1Head is not actually used in this function.
+// StoredBlocks returns the IDs of all blocks of the channels and pending blocks, newest first.
+func (s *channelManager) StoredBlocks() []eth.BlockID {
+	var out []eth.BlockID
+	for i := len(s.blocks) - 1; i >= 0; i-- {
+		out = append(out, eth.ToBlockID(s.blocks[i]))
+	}
+	for i := len(s.channels) - 1; i >= 0; i-- {
+		blocks := s.channels[i].blocks
+		for j := len(blocks) - 1; j >= 0; j-- {
+			out = append(out, eth.ToBlockID(blocks[j]))
+		}
+	}
+	return out
+}
 
-	// Return a pre-existing frame if we have it.
-	if len(s.datas) != 0 {
-		r := s.datas[0]
-		s.datas = s.datas[1:]
-		return r.data, r.id, nil
+// requeue drops the channel at index i, and all channels after it, and adds their blocks
+// in front of the pending blocks, to be submitted again in new channels.
+func (s *channelManager) requeue(i int) {
+	var blocks []*types.Block
+	for _, ch := range s.channels[i:] {
+		blocks = append(blocks, ch.blocks...)
 	}
+	s.blocks = append(blocks, s.blocks...)
+	s.channels = s.channels[:i]
+}
 
-	// Also return io.EOF if we cannot create a channel
-	if len(s.blocks) == 0 {
-		return nil, txID{}, io.EOF
+// channelByID returns the index of the channel with the given ID, or -1 if the channel is not known.
+func (s *channelManager) channelByID(id derive.ChannelID) int {
+	for i, ch := range s.channels {
+		if ch.id == id {
+			return i
+		}
 	}
+	return -1
+}
 
-	// Add all pending blocks to a channel
-	ch, err := derive.NewChannelOut()
-	if err != nil {
-		return nil, txID{}, err
+// TxConfirmed marks a transaction as included in the given L1 block.
+// If the inclusion of the frame makes the channel time out, the blocks of the channel are requeued.
+func (s *channelManager) TxConfirmed(id txID, inclusionBlock eth.BlockID) {
+	i := s.channelByID(id.chID)
+	if i < 0 {
+		s.log.Warn("confirmed tx of unknown channel", "channel", id.chID, "frame", id.frameNumber, "block", inclusionBlock)
+		return
 	}
-	// TODO: use peek/pop paradigm here instead of manually slicing
-	i := 0
-	// Cap length at 100 blocks
-	l := len(s.blocks)
-	if l > 100 {
-		l = 100
+	ch := s.channels[i]
+	ch.TxConfirmed(id.frameNumber, inclusionBlock)
+	s.log.Info("frame confirmed", "channel", id.chID, "frame", id.frameNumber, "block", inclusionBlock)
+	if min, max, _ := ch.inclusionRange(); s.cfg.timedOutAt(min, max) {
+		s.log.Warn("channel frames were included too far apart, resubmitting its blocks in a new channel",
+			"channel", ch.id, "first_inclusion", min, "last_inclusion", max)
+		s.requeue(i)
 	}
-	for ; i < l; i++ {
-		if err := ch.AddBlock(s.blocks[i]); err == derive.ErrTooManyRLPBytes {
+}
+
+// TxFailed marks a transaction as failed, to resubmit its frame.
+func (s *channelManager) TxFailed(id txID) {
+	i := s.channelByID(id.chID)
+	if i < 0 {
+		s.log.Warn("failed tx of unknown channel", "channel", id.chID, "frame", id.frameNumber)
+		return
+	}
+	s.channels[i].TxFailed(id.frameNumber)
+	s.log.Info("frame submission failed, resubmitting it", "channel", id.chID, "frame", id.frameNumber)
+}
+
+// TxReorged marks a transaction, that was confirmed in the given L1 block, as reorged out of L1.
+// The frame will be resubmitted.
+func (s *channelManager) TxReorged(id txID, inclusionBlock eth.BlockID) {
+	i := s.channelByID(id.chID)
+	if i < 0 {
+		return
+	}
+	if s.channels[i].TxReorged(id.frameNumber, inclusionBlock) {
+		s.log.Warn("frame was reorged out of L1, resubmitting it", "channel", id.chID, "frame", id.frameNumber, "block", inclusionBlock)
+	}
+}
+
+// ConfirmedTxs returns the inclusion blocks of all confirmed transactions of channels that are not safe yet.
+// These are the transactions that may still be affected by L1 reorgs.
+func (s *channelManager) ConfirmedTxs() map[txID]eth.BlockID {
+	out := make(map[txID]eth.BlockID)
+	for _, ch := range s.channels {
+		for id, inclusion := range ch.ConfirmedTxs() {
+			out[id] = inclusion
+		}
+	}
+	return out
+}
+
+// SafeHeadUpdated prunes the channels and blocks that are safe on L2, as derived by the rollup node.
+func (s *channelManager) SafeHeadUpdated(safe eth.L2BlockRef) {
+	for len(s.channels) > 0 {
+		ch := s.channels[0]
+		if !ch.IsFullyConfirmed() || ch.LastBlock().Number > safe.Number {
 			break
-		} else if err != nil {
-			return nil, txID{}, err
 		}
-		// TODO: limit the RLP size of the channel to be lower than the limit to enable
-		// channels to be fully submitted on time.
+		s.log.Debug("channel is safe, pruning it", "channel", ch.id, "last_block", ch.LastBlock(), "safe", safe)
+		s.channels = s.channels[1:]
+	}
+	if len(s.channels) == 0 {
+		for len(s.blocks) > 0 && s.blocks[0].NumberU64() <= safe.Number {
+			s.blocks = s.blocks[1:]
+		}
+		if len(s.blocks) == 0 {
+			s.tip = safe.Hash
+		}
+	}
+}
+
+// ForceCloseChannel closes the channel that is currently being filled, if any,
+// such that all its remaining frames are output for submission.
+func (s *channelManager) ForceCloseChannel() error {
+	if len(s.channels) == 0 {
+		return nil
+	}
+	ch := s.channels[len(s.channels)-1]
+	if !ch.IsOpen() {
+		return nil
+	}
+	s.log.Info("force closing channel", "channel", ch.id)
+	return s.closeChannel(ch)
+}
+
+// PendingBlocks returns the number of blocks that are not yet added to a channel.
+func (s *channelManager) PendingBlocks() int {
+	return len(s.blocks)
+}
+
+// PendingFrames returns the number of frames, of all channels, that still have to be submitted.
+func (s *channelManager) PendingFrames() int {
+	n := 0
+	for _, ch := range s.channels {
+		n += ch.PendingFrames()
+	}
+	return n
+}
+
+// NumChannels returns the number of channels that are not yet safe on L2.
+func (s *channelManager) NumChannels() int {
+	return len(s.channels)
+}
+
+// closeChannel closes the channel if it is open, outputs the remaining frames,
+// and updates the compression ratio estimate with the compression ratio of the channel.
+func (s *channelManager) closeChannel(ch *channel) error {
+	if !ch.IsOpen() {
+		return nil
 	}
 	if err := ch.Close(); err != nil {
-		return nil, txID{}, err
+		return err
+	}
+	if err := s.outputFrames(ch); err != nil {
+		return err
+	}
+	comprRatio := ch.ComprRatio()
+	s.metr.RecordChannelClosed(ch.inputBytes, ch.EstimatedSize(0), comprRatio)
+	if comprRatio > 0 {
+		// Smooth the estimate, the compression ratio of a single channel may be an outlier.
+		s.approxComprRatio = 0.8*s.approxComprRatio + 0.2*comprRatio
+		s.metr.RecordApproxComprRatio(s.approxComprRatio)
+	}
+	s.log.Info("closed channel", "channel", ch.id, "blocks", len(ch.blocks), "frames", len(ch.frames),
+		"input_bytes", ch.inputBytes, "compr_ratio", comprRatio, "approx_compr_ratio", s.approxComprRatio)
+	return nil
+}
+
+// outputFrames outputs the frames that the channel can output, and records their fill ratio.
+func (s *channelManager) outputFrames(ch *channel) error {
+	fillRatios, err := ch.OutputFrames()
+	for _, r := range fillRatios {
+		s.metr.RecordFrameFillRatio(r)
 	}
+	return err
+}
 
-	var t []taggedData
-	frameNumber := uint16(0)
-	for {
-		var buf bytes.Buffer
-		buf.WriteByte(derive.DerivationVersion0)
-		err := ch.OutputFrame(&buf, 120_000)
-		if err != io.EOF && err != nil {
-			return nil, txID{}, err
-		}
+// fillChannel adds pending blocks to the open channel, and closes it once it is full
+// or when it has been open for too long, as seen from the given L1 head.
+func (s *channelManager) fillChannel(ch *channel, l1Head eth.L1BlockRef) error {
+	added, err := ch.AddBlocks(s.blocks, s.approxComprRatio)
+	s.blocks = s.blocks[added:]
+	if err != nil {
+		return err
+	}
+	if len(ch.blocks) == 0 {
+		// drop the empty channel, it is the last channel
+		s.channels = s.channels[:len(s.channels)-1]
+		return errors.New("block does not fit in a channel")
+	}
+	if ch.ShouldClose(l1Head) {
+		return s.closeChannel(ch)
+	}
+	return s.outputFrames(ch)
+}
 
-		t = append(t, taggedData{
-			data: buf.Bytes(),
-			id:   txID{ch.ID(), frameNumber},
-		})
-		frameNumber += 1
-		if err == io.EOF {
+// TxData returns the next tx.data that should be submitted to L1.
+// Frames of channels that have to be resubmitted take priority over frames of new channels.
+// Channels that can no longer be completed before the channel timeout, as seen from the given L1 head,
+// are dropped, and their blocks are submitted again in a new channel.
+//
+// Pending blocks are added to the open channel until its estimated compressed size reaches
+// the target number of frames. Only full frames are output until the channel is closed.
+// It returns io.EOF if there is no data to submit.
+func (s *channelManager) TxData(l1Head eth.L1BlockRef) ([]byte, txID, error) {
+	for i, ch := range s.channels {
+		if ch.TimedOut(l1Head) {
+			s.log.Warn("channel timed out, resubmitting its blocks in a new channel", "channel", ch.id, "l1_head", l1Head)
+			s.requeue(i)
 			break
 		}
 	}
 
-	s.datas = append(s.datas, t...)
-	// Say i = 0, 1 are added to the channel, but i = 2 returns ErrTooManyRLPBytes. i remains 2 & is inclusive, so this works.
-	// Say all blocks are added, i will be len(blocks) after exiting the loop (but never inside the loop).
-	s.blocks = s.blocks[i:]
-
-	if len(s.datas) == 0 {
-		return nil, txID{}, io.EOF // TODO: not enough data error instead
+	// Return a pre-existing frame if we have it.
+	for _, ch := range s.channels {
+		if ch.HasPendingFrames() {
+			return ch.NextTxData()
+		}
 	}
 
-	r := s.datas[0]
-	s.datas = s.datas[1:]
-	return r.data, r.id, nil
+	// Continue to fill the open channel, or open a new channel if there are blocks to submit.
+	var ch *channel
+	if len(s.channels) > 0 && s.channels[len(s.channels)-1].IsOpen() {
+		ch = s.channels[len(s.channels)-1]
+	} else if len(s.blocks) > 0 {
+		var err error
+		ch, err = newChannel(&s.cfg, l1Head)
+		if err != nil {
+			return nil, txID{}, err
+		}
+		s.log.Info("opened channel", "channel", ch.id, "l1_head", l1Head)
+		s.channels = append(s.channels, ch)
+	} else {
+		return nil, txID{}, io.EOF
+	}
+	if err := s.fillChannel(ch, l1Head); err != nil {
+		return nil, txID{}, err
+	}
+	return ch.NextTxData()
 }
 
 // AddL2Block saves an L2 block to the internal state. It returns ErrReorg
 // if the block does not extend the last block loaded into the state.
-// If no block is already in the channel, the the parent hash check is skipped.
+// If there is no last block to build on, the the parent hash check is skipped.
 func (s *channelManager) AddL2Block(block *types.Block) error {
-	if len(s.blocks) > 0 {
-		if s.blocks[len(s.blocks)-1].Hash() != block.ParentHash() {
-			return ErrReorg
-		}
+	if s.tip != (common.Hash{}) && s.tip != block.ParentHash() {
+		return ErrReorg
 	}
 	s.blocks = append(s.blocks, block)
+	s.tip = block.Hash()
 	return nil
 }
@@ -0,0 +1,299 @@
+package op_batcher
+
+import (
+	"io"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	"github.com/ethereum-optimism/optimism/op-node/testutils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+)
+
+func testChannelConfig() ChannelConfig {
+	return ChannelConfig{
+		ChannelTimeout:   10,
+		MaxFrameSize:     100_000,
+		TargetNumFrames:  1,
+		ApproxComprRatio: 0.4,
+	}
+}
+
+func newTestChannelManager(t *testing.T, cfg ChannelConfig) *channelManager {
+	return NewChannelManager(testlog.Logger(t, log.LvlError), cfg, NewMetrics())
+}
+
+// makeBlocks creates a chain of n L2 blocks after the given parent, each with only an L1 info deposit.
+func makeBlocks(t *testing.T, rng *rand.Rand, parent eth.BlockID, n int) []*types.Block {
+	return makeBlocksWithData(t, rng, parent, n, 0)
+}
+
+// makeBlocksWithData creates a chain of n L2 blocks after the given parent, each with an L1 info deposit
+// and, if dataSize is not 0, a transaction with dataSize bytes of random, incompressible, calldata.
+func makeBlocksWithData(t *testing.T, rng *rand.Rand, parent eth.BlockID, n int, dataSize int) []*types.Block {
+	var blocks []*types.Block
+	for i := 0; i < n; i++ {
+		l1InfoTx, err := derive.L1InfoDeposit(0, testutils.RandomBlockInfo(rng))
+		require.NoError(t, err)
+		txs := []*types.Transaction{types.NewTx(l1InfoTx)}
+		if dataSize > 0 {
+			txs = append(txs, types.NewTx(&types.LegacyTx{Data: testutils.RandomData(rng, dataSize)}))
+		}
+		header := &types.Header{
+			ParentHash: parent.Hash,
+			Number:     new(big.Int).SetUint64(parent.Number + 1),
+			Time:       parent.Number + 1,
+			Difficulty: common.Big0,
+			Extra:      testutils.RandomData(rng, 8), // distinguish blocks of reorged chains
+		}
+		block := types.NewBlockWithHeader(header).WithBody(txs, nil)
+		blocks = append(blocks, block)
+		parent = eth.ToBlockID(block)
+	}
+	return blocks
+}
+
+func addBlocks(t *testing.T, m *channelManager, blocks []*types.Block) {
+	for _, b := range blocks {
+		require.NoError(t, m.AddL2Block(b))
+	}
+}
+
+// submitChannel puts the pending blocks into a new channel, closes it and submits its single frame.
+func submitChannel(t *testing.T, m *channelManager, l1Head eth.L1BlockRef) txID {
+	_, _, err := m.TxData(l1Head)
+	require.ErrorIs(t, err, io.EOF, "channel is not full yet")
+	require.NoError(t, m.ForceCloseChannel())
+	_, id, err := m.TxData(l1Head)
+	require.NoError(t, err)
+	return id
+}
+
+func l1Ref(n uint64) eth.L1BlockRef {
+	return eth.L1BlockRef{Number: n, Hash: common.Hash{byte(n)}}
+}
+
+func TestChannelManagerLifecycle(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	m := newTestChannelManager(t, testChannelConfig())
+	blocks := makeBlocks(t, rng, eth.BlockID{}, 3)
+	addBlocks(t, m, blocks)
+	require.Equal(t, 3, m.PendingBlocks())
+
+	// pending: the channel is filled with the pending blocks, and its frame output once it is closed
+	_, _, err := m.TxData(l1Ref(1))
+	require.ErrorIs(t, err, io.EOF)
+	require.Equal(t, 0, m.PendingBlocks())
+	require.Equal(t, 1, m.NumChannels())
+	require.NoError(t, m.ForceCloseChannel())
+	require.Equal(t, 1, m.PendingFrames())
+
+	// submitted: the frame is not returned again while it is in flight
+	data, id, err := m.TxData(l1Ref(1))
+	require.NoError(t, err)
+	require.NotEmpty(t, data)
+	require.Equal(t, 0, m.PendingFrames())
+	_, _, err = m.TxData(l1Ref(1))
+	require.ErrorIs(t, err, io.EOF)
+
+	// confirmed: the channel is retained until its blocks are safe
+	m.TxConfirmed(id, l1Ref(2).ID())
+	require.Equal(t, map[txID]eth.BlockID{id: l1Ref(2).ID()}, m.ConfirmedTxs())
+	m.SafeHeadUpdated(eth.L2BlockRef{Hash: blocks[1].Hash(), Number: 2})
+	require.Equal(t, 1, m.NumChannels(), "not all blocks of the channel are safe")
+	m.SafeHeadUpdated(eth.L2BlockRef{Hash: blocks[2].Hash(), Number: 3})
+	require.Equal(t, 0, m.NumChannels())
+	require.Empty(t, m.ConfirmedTxs())
+
+	// the next block builds on the last pruned block
+	addBlocks(t, m, makeBlocks(t, rng, eth.ToBlockID(blocks[2]), 1))
+}
+
+func TestChannelManagerTxFailed(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	m := newTestChannelManager(t, testChannelConfig())
+	addBlocks(t, m, makeBlocks(t, rng, eth.BlockID{}, 3))
+	id := submitChannel(t, m, l1Ref(1))
+	data := m.channels[0].frames[id.frameNumber].data
+
+	m.TxFailed(id)
+	require.Equal(t, 1, m.PendingFrames())
+	resubmitted, resubmittedID, err := m.TxData(l1Ref(1))
+	require.NoError(t, err)
+	require.Equal(t, id, resubmittedID)
+	require.Equal(t, data, resubmitted)
+}
+
+func TestChannelManagerTxReorged(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	m := newTestChannelManager(t, testChannelConfig())
+	addBlocks(t, m, makeBlocks(t, rng, eth.BlockID{}, 3))
+	id := submitChannel(t, m, l1Ref(1))
+	m.TxConfirmed(id, l1Ref(2).ID())
+
+	// a reorg of a different block does not affect the frame
+	m.TxReorged(id, eth.BlockID{Number: 2, Hash: common.Hash{0xff}})
+	require.Equal(t, 0, m.PendingFrames())
+
+	m.TxReorged(id, l1Ref(2).ID())
+	require.Equal(t, 1, m.PendingFrames())
+	require.Empty(t, m.ConfirmedTxs())
+	_, resubmittedID, err := m.TxData(l1Ref(3))
+	require.NoError(t, err)
+	require.Equal(t, id, resubmittedID)
+}
+
+func TestChannelTimedOut(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	cfg := testChannelConfig()
+	cfg.MaxFrameSize = 200 // split the channel into multiple frames
+	newSubmitted := func() (*channelManager, []txID) {
+		m := newTestChannelManager(t, cfg)
+		addBlocks(t, m, makeBlocks(t, rng, eth.BlockID{}, 3))
+		var ids []txID
+		for closed := false; ; {
+			_, id, err := m.TxData(l1Ref(1))
+			if err == io.EOF && !closed {
+				require.NoError(t, m.ForceCloseChannel())
+				closed = true
+				continue
+			} else if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			ids = append(ids, id)
+		}
+		require.Greater(t, len(ids), 1)
+		return m, ids
+	}
+
+	t.Run("unconfirmed frames", func(t *testing.T) {
+		m, ids := newSubmitted()
+		ch := m.channels[0]
+		m.TxConfirmed(ids[0], l1Ref(5).ID())
+		// the remaining frames can still be included in block 5+timeout
+		require.False(t, ch.TimedOut(l1Ref(5+cfg.ChannelTimeout-1)))
+		// the next block is past the timeout
+		require.True(t, ch.TimedOut(l1Ref(5+cfg.ChannelTimeout)))
+
+		// the blocks are submitted again in a new channel
+		_, _, _ = m.TxData(l1Ref(5 + cfg.ChannelTimeout))
+		require.Equal(t, 0, m.PendingBlocks())
+		require.Equal(t, 1, m.NumChannels())
+		require.NotEqual(t, ids[0].chID, m.channels[0].id)
+	})
+
+	t.Run("last frame included at the timeout", func(t *testing.T) {
+		m, ids := newSubmitted()
+		for _, id := range ids {
+			m.TxConfirmed(id, l1Ref(5+cfg.ChannelTimeout).ID())
+		}
+		m.TxConfirmed(ids[0], l1Ref(5).ID())
+		require.Equal(t, 1, m.NumChannels())
+		require.True(t, m.channels[0].IsFullyConfirmed())
+		require.False(t, m.channels[0].TimedOut(l1Ref(100)))
+	})
+
+	t.Run("last frame included after the timeout", func(t *testing.T) {
+		m, ids := newSubmitted()
+		m.TxConfirmed(ids[0], l1Ref(5).ID())
+		m.TxConfirmed(ids[1], l1Ref(5+cfg.ChannelTimeout+1).ID())
+		require.Equal(t, 0, m.NumChannels(), "channel is dropped")
+		require.Equal(t, 3, m.PendingBlocks(), "blocks are requeued")
+	})
+}
+
+func TestChannelManagerClearUnsubmitted(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	// setup creates a submitted channel with blocks 1-3, an unsubmitted open channel with blocks 4-6,
+	// and pending blocks 7-8.
+	setup := func() (*channelManager, []*types.Block) {
+		m := newTestChannelManager(t, testChannelConfig())
+		blocks := makeBlocks(t, rng, eth.BlockID{}, 8)
+		addBlocks(t, m, blocks[:3])
+		submitChannel(t, m, l1Ref(1))
+		addBlocks(t, m, blocks[3:6])
+		_, _, err := m.TxData(l1Ref(1))
+		require.ErrorIs(t, err, io.EOF)
+		addBlocks(t, m, blocks[6:])
+		require.Equal(t, 2, m.NumChannels())
+		require.Equal(t, 2, m.PendingBlocks())
+		return m, blocks
+	}
+
+	t.Run("reorg of pending blocks", func(t *testing.T) {
+		m, blocks := setup()
+		last := m.ClearUnsubmitted(eth.ToBlockID(blocks[6]))
+		require.Equal(t, eth.ToBlockID(blocks[6]), last)
+		require.Equal(t, 2, m.NumChannels(), "channels are not affected")
+		require.True(t, m.channels[1].IsOpen())
+		require.Equal(t, 1, m.PendingBlocks())
+		addBlocks(t, m, makeBlocks(t, rng, last, 1))
+	})
+
+	t.Run("reorg of unsubmitted channel", func(t *testing.T) {
+		m, blocks := setup()
+		last := m.ClearUnsubmitted(eth.ToBlockID(blocks[4]))
+		require.Equal(t, eth.ToBlockID(blocks[4]), last)
+		require.Equal(t, 1, m.NumChannels(), "submitted channel is retained")
+		require.Equal(t, 2, m.PendingBlocks(), "blocks up to the fork point are requeued")
+		addBlocks(t, m, makeBlocks(t, rng, last, 1))
+		require.ErrorIs(t, m.AddL2Block(makeBlocks(t, rng, eth.ToBlockID(blocks[5]), 1)[0]), ErrReorg)
+	})
+
+	t.Run("reorg at the end of the submitted channel", func(t *testing.T) {
+		m, blocks := setup()
+		last := m.ClearUnsubmitted(eth.ToBlockID(blocks[2]))
+		require.Equal(t, eth.ToBlockID(blocks[2]), last)
+		require.Equal(t, 1, m.NumChannels())
+		require.Equal(t, 0, m.PendingBlocks())
+		addBlocks(t, m, makeBlocks(t, rng, last, 1))
+	})
+
+	t.Run("reorg of submitted channel", func(t *testing.T) {
+		m, blocks := setup()
+		last := m.ClearUnsubmitted(eth.ToBlockID(blocks[1]))
+		require.Equal(t, eth.BlockID{}, last, "the next block does not build on the retained channel")
+		require.Equal(t, 1, m.NumChannels(), "submitted channel cannot be retracted")
+		require.False(t, m.channels[0].IsOpen())
+		require.Equal(t, 0, m.PendingBlocks())
+	})
+
+	t.Run("reorg of later submitted channel", func(t *testing.T) {
+		m, blocks := setup()
+		submitChannel(t, m, l1Ref(2))
+		last := m.ClearUnsubmitted(eth.ToBlockID(blocks[4]))
+		require.Equal(t, eth.ToBlockID(blocks[2]), last, "loading continues after the last channel before the fork")
+		require.Equal(t, 2, m.NumChannels(), "submitted channels cannot be retracted")
+		require.Equal(t, 0, m.PendingBlocks())
+		addBlocks(t, m, makeBlocks(t, rng, last, 1))
+	})
+
+	t.Run("no canonical blocks", func(t *testing.T) {
+		m, _ := setup()
+		require.Equal(t, eth.BlockID{}, m.ClearUnsubmitted(eth.BlockID{}))
+		require.Equal(t, 1, m.NumChannels())
+		require.Equal(t, 0, m.PendingBlocks())
+	})
+}
+
+func TestChannelManagerStoredBlocks(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	m := newTestChannelManager(t, testChannelConfig())
+	blocks := makeBlocks(t, rng, eth.BlockID{}, 5)
+	addBlocks(t, m, blocks[:3])
+	submitChannel(t, m, l1Ref(1))
+	addBlocks(t, m, blocks[3:])
+
+	stored := m.StoredBlocks()
+	require.Len(t, stored, 5)
+	for i, id := range stored {
+		require.Equal(t, eth.ToBlockID(blocks[4-i]), id)
+	}
+}
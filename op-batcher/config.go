@@ -6,10 +6,12 @@ import (
 	"github.com/urfave/cli"
 
 	"github.com/ethereum-optimism/optimism/op-batcher/flags"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
 	oplog "github.com/ethereum-optimism/optimism/op-service/log"
 	opmetrics "github.com/ethereum-optimism/optimism/op-service/metrics"
 	oppprof "github.com/ethereum-optimism/optimism/op-service/pprof"
 	oprpc "github.com/ethereum-optimism/optimism/op-service/rpc"
+	"github.com/ethereum-optimism/optimism/op-service/txmgr"
 )
 
 type Config struct {
@@ -30,6 +32,18 @@ type Config struct {
 	// MaxL1TxSize is the maximum size of a batch tx submitted to L1.
 	MaxL1TxSize uint64
 
+	// MaxChannelDuration is the maximum number of L1 blocks that a channel is kept open for.
+	MaxChannelDuration uint64
+
+	// TargetNumFrames is the number of max-size frames that a channel is filled up to.
+	TargetNumFrames int
+
+	// ApproxComprRatio is the initial estimate of the compression ratio of channel data.
+	ApproxComprRatio float64
+
+	// CompressionCodec is the name of the compression codec of channel data.
+	CompressionCodec string
+
 	// ChannelTimeout is the maximum amount of time to attempt completing an opened channel,
 	// as opposed to submitting missing blocks in new channels
 	ChannelTimeout uint64
@@ -42,6 +56,9 @@ type Config struct {
 	// appending new batches.
 	NumConfirmations uint64
 
+	// MaxPendingTx is the maximum number of batch txs that are in flight at the same time.
+	MaxPendingTx uint64
+
 	// SafeAbortNonceTooLowCount is the number of ErrNonceTooLowObservations
 	// required to give up on a tx at a particular nonce without receiving
 	// confirmation.
@@ -69,6 +86,9 @@ type Config struct {
 
 	RPCConfig oprpc.CLIConfig
 
+	// RPCEnableAdmin enables the admin API on the RPC server.
+	RPCEnableAdmin bool
+
 	/* Optional Params */
 
 	LogConfig oplog.CLIConfig
@@ -76,9 +96,18 @@ type Config struct {
 	MetricsConfig opmetrics.CLIConfig
 
 	PprofConfig oppprof.CLIConfig
+
+	TxMgrConfig txmgr.CLIConfig
 }
 
 func (c Config) Check() error {
+	channelCfg, err := c.channelConfig()
+	if err != nil {
+		return err
+	}
+	if err := channelCfg.Check(); err != nil {
+		return err
+	}
 	if err := c.RPCConfig.Check(); err != nil {
 		return err
 	}
@@ -91,6 +120,9 @@ func (c Config) Check() error {
 	if err := c.PprofConfig.Check(); err != nil {
 		return err
 	}
+	if err := c.TxMgrConfig.Check(); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -103,9 +135,14 @@ func NewConfig(ctx *cli.Context) Config {
 		RollupRpc:                  ctx.GlobalString(flags.RollupRpcFlag.Name),
 		MinL1TxSize:                ctx.GlobalUint64(flags.MinL1TxSizeBytesFlag.Name),
 		MaxL1TxSize:                ctx.GlobalUint64(flags.MaxL1TxSizeBytesFlag.Name),
+		MaxChannelDuration:         ctx.GlobalUint64(flags.MaxChannelDurationFlag.Name),
+		TargetNumFrames:            ctx.GlobalInt(flags.TargetNumFramesFlag.Name),
+		ApproxComprRatio:           ctx.GlobalFloat64(flags.ApproxComprRatioFlag.Name),
+		CompressionCodec:           ctx.GlobalString(flags.CompressionCodecFlag.Name),
 		ChannelTimeout:             ctx.GlobalUint64(flags.ChannelTimeoutFlag.Name),
 		PollInterval:               ctx.GlobalDuration(flags.PollIntervalFlag.Name),
 		NumConfirmations:           ctx.GlobalUint64(flags.NumConfirmationsFlag.Name),
+		MaxPendingTx:               ctx.GlobalUint64(flags.MaxPendingTxFlag.Name),
 		SafeAbortNonceTooLowCount:  ctx.GlobalUint64(flags.SafeAbortNonceTooLowCountFlag.Name),
 		ResubmissionTimeout:        ctx.GlobalDuration(flags.ResubmissionTimeoutFlag.Name),
 		Mnemonic:                   ctx.GlobalString(flags.MnemonicFlag.Name),
@@ -113,8 +150,28 @@ func NewConfig(ctx *cli.Context) Config {
 		PrivateKey:                 ctx.GlobalString(flags.PrivateKeyFlag.Name),
 		SequencerBatchInboxAddress: ctx.GlobalString(flags.SequencerBatchInboxAddressFlag.Name),
 		RPCConfig:                  oprpc.ReadCLIConfig(ctx),
+		RPCEnableAdmin:             ctx.GlobalBool(flags.RPCEnableAdminFlag.Name),
 		LogConfig:                  oplog.ReadCLIConfig(ctx),
 		MetricsConfig:              opmetrics.ReadCLIConfig(ctx),
 		PprofConfig:                oppprof.ReadCLIConfig(ctx),
+		TxMgrConfig:                txmgr.ReadCLIConfig(ctx),
+	}
+}
+
+// channelConfig returns the configuration of the channels created by the batcher.
+// The rollup config is not known yet, it is set once it is fetched from the rollup node.
+func (c Config) channelConfig() (ChannelConfig, error) {
+	codec, err := derive.ParseCompressionCodec(c.CompressionCodec)
+	if err != nil {
+		return ChannelConfig{}, err
 	}
+	return ChannelConfig{
+		ChannelTimeout:     c.ChannelTimeout,
+		MaxChannelDuration: c.MaxChannelDuration,
+		MinFrameSize:       c.MinL1TxSize,
+		MaxFrameSize:       c.MaxL1TxSize,
+		TargetNumFrames:    c.TargetNumFrames,
+		ApproxComprRatio:   c.ApproxComprRatio,
+		Codec:              codec,
+	}, nil
 }
@@ -13,9 +13,11 @@ import (
 
 	"github.com/ethereum-optimism/optimism/op-batcher/sequencer"
 	"github.com/ethereum-optimism/optimism/op-node/eth"
-	"github.com/ethereum-optimism/optimism/op-proposer/txmgr"
+	"github.com/ethereum-optimism/optimism/op-service/txmgr"
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
 	hdwallet "github.com/miguelmota/go-ethereum-hdwallet"
@@ -38,6 +40,19 @@ type BatchSubmitter struct {
 	lastStoredBlock eth.BlockID
 
 	state *channelManager
+	metr  *Metrics
+
+	// Upon receiving a channel in this channel, the event loop is paused
+	// until the caller is done inspecting or modifying the submission state.
+	stateReq chan chan struct{}
+
+	// stopped is true if batch submission was stopped through the admin API.
+	// No new blocks are loaded and no new batch txs are submitted, but in-flight txs are still confirmed.
+	stopped bool
+	// inflight is the number of batch txs that are submitted, but not yet confirmed.
+	inflight uint64
+	// lastConfirmedL1 is the L1 block that included the last confirmed batch tx.
+	lastConfirmedL1 eth.BlockID
 }
 
 // NewBatchSubmitter initializes the BatchSubmitter, gathering any resources
@@ -118,6 +133,8 @@ func NewBatchSubmitter(cfg Config, l log.Logger) (*BatchSubmitter, error) {
 
 	log.Info("starting batch submitter", "submitter_addr", addr, "submitter_bal", sequencerBalance)
 
+	metr := NewMetrics()
+
 	txManagerConfig := txmgr.Config{
 		Log:                       l,
 		Name:                      "Batch Submitter",
@@ -125,6 +142,8 @@ func NewBatchSubmitter(cfg Config, l log.Logger) (*BatchSubmitter, error) {
 		ReceiptQueryInterval:      time.Second,
 		NumConfirmations:          cfg.NumConfirmations,
 		SafeAbortNonceTooLowCount: cfg.SafeAbortNonceTooLowCount,
+		FeeLimits:                 cfg.TxMgrConfig.FeeLimits(),
+		Metrics:                   txmgr.NewMetrics(Namespace, metr.Registry()),
 	}
 
 	batcherCfg := sequencer.Config{
@@ -140,17 +159,37 @@ func NewBatchSubmitter(cfg Config, l log.Logger) (*BatchSubmitter, error) {
 		ChainID:           chainID,
 		PrivKey:           sequencerPrivKey,
 		PollInterval:      cfg.PollInterval,
+		MaxPendingTx:      cfg.MaxPendingTx,
+		CancelPendingTxs:  cfg.TxMgrConfig.CancelPendingTxs,
+	}
+
+	if cfg.MaxPendingTx == 0 {
+		return nil, errors.New("max pending txs must be at least 1")
+	}
+
+	channelCfg, err := cfg.channelConfig()
+	if err != nil {
+		return nil, fmt.Errorf("invalid channel config: %w", err)
+	}
+	channelCfg.Rollup, err = rollupClient.RollupConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch rollup config: %w", err)
+	}
+	if err := channelCfg.Check(); err != nil {
+		return nil, fmt.Errorf("invalid channel config: %w", err)
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &BatchSubmitter{
-		cfg:   batcherCfg,
-		addr:  addr,
-		txMgr: NewTransactionManger(l, txManagerConfig, batchInboxAddress, chainID, sequencerPrivKey, l1Client),
-		done:  make(chan struct{}),
-		log:   l,
-		state: new(channelManager),
+		cfg:      batcherCfg,
+		addr:     addr,
+		txMgr:    NewTransactionManger(l, txManagerConfig, batchInboxAddress, chainID, sequencerPrivKey, l1Client),
+		done:     make(chan struct{}),
+		log:      l,
+		stateReq: make(chan chan struct{}),
+		state:    NewChannelManager(l, channelCfg, metr),
+		metr:     metr,
 		// TODO: this context only exists because the even loop doesn't reach done
 		// if the tx manager is blocking forever due to e.g. insufficient balance.
 		ctx:    ctx,
@@ -187,9 +226,13 @@ func (l *BatchSubmitter) loadBlocksIntoState(ctx context.Context) {
 	for i := start.Number + 1; i < end.Number+1; i++ {
 		id, err := l.loadBlockIntoState(ctx, i)
 		if errors.Is(err, ErrReorg) {
-			l.log.Warn("Found L2 reorg", "block_number", i)
-			l.state.Clear()
-			l.lastStoredBlock = eth.BlockID{}
+			fork, err := l.findForkPoint(ctx)
+			if err != nil {
+				l.log.Warn("failed to find L2 reorg fork point", "block_number", i, "err", err)
+				return
+			}
+			l.lastStoredBlock = l.state.ClearUnsubmitted(fork)
+			l.log.Warn("Found L2 reorg, cleared unsubmitted blocks", "block_number", i, "fork", fork, "last_stored", l.lastStoredBlock)
 			return
 		} else if err != nil {
 			l.log.Warn("failed to load block into state", "err", err)
@@ -199,6 +242,25 @@ func (l *BatchSubmitter) loadBlocksIntoState(ctx context.Context) {
 	}
 }
 
+// findForkPoint returns the last block of the local state that is still canonical on L2,
+// or an empty block ID if none of the blocks in the local state are canonical anymore.
+func (l *BatchSubmitter) findForkPoint(ctx context.Context) (eth.BlockID, error) {
+	for _, id := range l.state.StoredBlocks() {
+		cctx, cancel := context.WithTimeout(ctx, networkTimeout)
+		header, err := l.cfg.L2Client.HeaderByNumber(cctx, new(big.Int).SetUint64(id.Number))
+		cancel()
+		if errors.Is(err, ethereum.NotFound) {
+			continue
+		} else if err != nil {
+			return eth.BlockID{}, fmt.Errorf("failed to fetch L2 block %d: %w", id.Number, err)
+		}
+		if header.Hash() == id.Hash {
+			return id, nil
+		}
+	}
+	return eth.BlockID{}, nil
+}
+
 // loadBlockIntoState fetches & stores a single block into `state`. It returns the block it loaded.
 func (l *BatchSubmitter) loadBlockIntoState(ctx context.Context, blockNumber uint64) (eth.BlockID, error) {
 	ctx, cancel := context.WithTimeout(ctx, networkTimeout)
@@ -229,6 +291,11 @@ func (l *BatchSubmitter) calculateL2BlockRangeToStore(ctx context.Context) (eth.
 		return eth.BlockID{}, eth.BlockID{}, errors.New("empty sync status")
 	}
 
+	l.metr.RecordL2BlocksBehind(syncStatus.SafeL2.Number, syncStatus.UnsafeL2.Number)
+
+	// Prune all channels and blocks that are safe now
+	l.state.SafeHeadUpdated(syncStatus.SafeL2)
+
 	// Check last stored to see if it needs to be set on startup OR set if is lagged behind.
 	// It lagging implies that the op-node processed some batches that where submitted prior to the current instance of the batcher being alive.
 	if l.lastStoredBlock == (eth.BlockID{}) {
@@ -258,34 +325,238 @@ func (l *BatchSubmitter) calculateL2BlockRangeToStore(ctx context.Context) (eth.
 // Submitted batch, but it is not valid
 // Missed L2 block somehow.
 
+// txResult is the outcome of the submission of a single frame.
+type txResult struct {
+	id      txID
+	receipt *types.Receipt
+	// fee is the L1 fee paid for the tx, in wei. It is nil if the fee could not be determined.
+	fee *big.Int
+	err error
+}
+
 func (l *BatchSubmitter) loop() {
 	defer l.wg.Done()
 
+	if l.cfg.CancelPendingTxs {
+		if err := l.txMgr.CancelPending(l.ctx); err != nil {
+			l.log.Error("unable to cancel pending txs", "err", err)
+		}
+	}
+
 	ticker := time.NewTicker(l.cfg.PollInterval)
 	defer ticker.Stop()
+
+	// Results of the in-flight transactions. Buffered so that the senders never block after the loop exits.
+	results := make(chan txResult, l.cfg.MaxPendingTx)
+	var l1Head eth.L1BlockRef
+
 	for {
 		select {
 		case <-ticker.C:
+			if l.stopped {
+				l.checkConfirmedTxs(l.ctx)
+				l.recordPending()
+				break
+			}
 			l.loadBlocksIntoState(l.ctx)
+			l.checkConfirmedTxs(l.ctx)
 
-			// Empty the state after loading into it on every iteration.
-			for {
-				// Collect the output frame
-				data, _, err := l.state.TxData(eth.L1BlockRef{})
-				if err == io.EOF {
-					break // local for loop
-				} else if err != nil {
-					l.log.Error("unable to get tx data", "err", err)
-					break
-				}
-				// Drop receipt + error for now
-				if _, err := l.txMgr.SendTransaction(l.ctx, data); err != nil {
-					l.log.Error("Failed to send transaction", "err", err)
-				}
+			head, err := l.l1Head(l.ctx)
+			if err != nil {
+				l.log.Error("unable to get L1 head", "err", err)
+				break
+			}
+			l1Head = head
+			l.inflight += l.publishTxs(l1Head, l.cfg.MaxPendingTx-l.inflight, results)
+			l.recordPending()
+
+		case r := <-results:
+			l.inflight--
+			if r.err != nil {
+				l.log.Error("Failed to send transaction", "channel", r.id.chID, "frame", r.id.frameNumber, "err", r.err)
+				l.state.TxFailed(r.id)
+				l.metr.RecordTxFailed()
+			} else {
+				inclusion := eth.BlockID{Hash: r.receipt.BlockHash, Number: r.receipt.BlockNumber.Uint64()}
+				l.state.TxConfirmed(r.id, inclusion)
+				l.metr.RecordTxConfirmed(r.fee)
+				l.lastConfirmedL1 = inclusion
+			}
+			// Fill the freed submission slot right away, to catch up quickly on a backlog of frames.
+			if !l.stopped && l1Head != (eth.L1BlockRef{}) {
+				l.inflight += l.publishTxs(l1Head, l.cfg.MaxPendingTx-l.inflight, results)
 			}
+			l.recordPending()
+
+		case respCh := <-l.stateReq:
+			respCh <- struct{}{}
 
 		case <-l.done:
 			return
 		}
 	}
 }
+
+func (l *BatchSubmitter) recordPending() {
+	l.metr.RecordPending(l.state.PendingBlocks(), l.state.PendingFrames(), l.inflight)
+}
+
+// withLoopPaused runs fn synchronously with the event loop, such that fn can safely
+// inspect and modify the submission state. If the event loop is too busy and the context
+// expires, a context error is returned.
+func (l *BatchSubmitter) withLoopPaused(ctx context.Context, fn func() error) error {
+	wait := make(chan struct{})
+	select {
+	case l.stateReq <- wait:
+		err := fn()
+		<-wait
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-l.done:
+		return errors.New("batch submitter is shut down")
+	}
+}
+
+// StartBatchSubmission resumes batch submission after it was stopped.
+func (l *BatchSubmitter) StartBatchSubmission(ctx context.Context) error {
+	return l.withLoopPaused(ctx, func() error {
+		if !l.stopped {
+			return errors.New("batch submission is already running")
+		}
+		l.log.Info("starting batch submission")
+		l.stopped = false
+		return nil
+	})
+}
+
+// StopBatchSubmission stops loading new blocks and submitting new batch txs.
+// The txs that are already in flight are still confirmed.
+func (l *BatchSubmitter) StopBatchSubmission(ctx context.Context) error {
+	return l.withLoopPaused(ctx, func() error {
+		if l.stopped {
+			return errors.New("batch submission is already stopped")
+		}
+		l.log.Info("stopping batch submission", "inflight", l.inflight)
+		l.stopped = true
+		return nil
+	})
+}
+
+// ForceCloseChannel closes the channel that is currently being filled,
+// such that its remaining frames are submitted with the next batch txs.
+func (l *BatchSubmitter) ForceCloseChannel(ctx context.Context) error {
+	return l.withLoopPaused(ctx, func() error {
+		return l.state.ForceCloseChannel()
+	})
+}
+
+// Status returns a snapshot of the submission state.
+func (l *BatchSubmitter) Status(ctx context.Context) (*BatcherStatus, error) {
+	var status *BatcherStatus
+	err := l.withLoopPaused(ctx, func() error {
+		status = &BatcherStatus{
+			Running:         !l.stopped,
+			PendingBlocks:   l.state.PendingBlocks(),
+			PendingFrames:   l.state.PendingFrames(),
+			InflightTxs:     l.inflight,
+			Channels:        l.state.NumChannels(),
+			LastStoredBlock: l.lastStoredBlock,
+			LastConfirmedL1: l.lastConfirmedL1,
+		}
+		return nil
+	})
+	return status, err
+}
+
+// publishTxs starts the submission of up to max frames, each in its own transaction.
+// The results are sent to the results channel. It returns the number of started submissions.
+func (l *BatchSubmitter) publishTxs(l1Head eth.L1BlockRef, max uint64, results chan<- txResult) uint64 {
+	var started uint64
+	for ; started < max; started++ {
+		// Collect the output frame
+		data, id, err := l.state.TxData(l1Head)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			l.log.Error("unable to get tx data", "err", err)
+			break
+		}
+		l.wg.Add(1)
+		go func() {
+			defer l.wg.Done()
+			receipt, err := l.txMgr.SendTransaction(l.ctx, data)
+			var fee *big.Int
+			if err == nil {
+				fee = l.txFee(l.ctx, receipt)
+			}
+			results <- txResult{id: id, receipt: receipt, fee: fee, err: err}
+		}()
+	}
+	return started
+}
+
+// txFee returns the L1 fee, in wei, that was paid for the tx of the receipt.
+// It returns nil if the fee could not be determined.
+func (l *BatchSubmitter) txFee(ctx context.Context, receipt *types.Receipt) *big.Int {
+	ctx, cancel := context.WithTimeout(ctx, networkTimeout)
+	defer cancel()
+	tx, _, err := l.cfg.L1Client.TransactionByHash(ctx, receipt.TxHash)
+	if err != nil {
+		l.log.Debug("failed to fetch batch tx to determine fee", "tx", receipt.TxHash, "err", err)
+		return nil
+	}
+	header, err := l.cfg.L1Client.HeaderByHash(ctx, receipt.BlockHash)
+	if err != nil {
+		l.log.Debug("failed to fetch inclusion block to determine fee", "block", receipt.BlockHash, "err", err)
+		return nil
+	}
+	gasPrice := tx.GasPrice()
+	if header.BaseFee != nil {
+		gasPrice = new(big.Int).Add(header.BaseFee, tx.EffectiveGasTipValue(header.BaseFee))
+	}
+	return new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(receipt.GasUsed))
+}
+
+// l1Head returns the current head of the L1 chain.
+func (l *BatchSubmitter) l1Head(ctx context.Context) (eth.L1BlockRef, error) {
+	ctx, cancel := context.WithTimeout(ctx, networkTimeout)
+	defer cancel()
+	head, err := l.cfg.L1Client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return eth.L1BlockRef{}, err
+	}
+	return eth.L1BlockRef{
+		Hash:       head.Hash(),
+		Number:     head.Number.Uint64(),
+		ParentHash: head.ParentHash,
+		Time:       head.Time,
+	}, nil
+}
+
+// checkConfirmedTxs checks if the L1 blocks that included the confirmed, but not yet safe,
+// batch transactions are still canonical. Frames that were reorged out of L1 are resubmitted.
+func (l *BatchSubmitter) checkConfirmedTxs(ctx context.Context) {
+	canonical := make(map[uint64]common.Hash)
+	for id, inclusion := range l.state.ConfirmedTxs() {
+		hash, ok := canonical[inclusion.Number]
+		if !ok {
+			childCtx, cancel := context.WithTimeout(ctx, networkTimeout)
+			header, err := l.cfg.L1Client.HeaderByNumber(childCtx, new(big.Int).SetUint64(inclusion.Number))
+			cancel()
+			if errors.Is(err, ethereum.NotFound) {
+				// the L1 chain got shorter, the block is not canonical anymore
+				hash = common.Hash{}
+			} else if err != nil {
+				l.log.Warn("failed to check inclusion block of batch tx", "block", inclusion, "err", err)
+				continue
+			} else {
+				hash = header.Hash()
+			}
+			canonical[inclusion.Number] = hash
+		}
+		if hash != inclusion.Hash {
+			l.state.TxReorged(id, inclusion)
+		}
+	}
+}
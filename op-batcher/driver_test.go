@@ -0,0 +1,107 @@
+package op_batcher
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-batcher/sequencer"
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+)
+
+// newTestBatchSubmitter creates a batch submitter without L1 and L2 clients.
+// The poll interval is long enough to never load blocks or submit txs during a test,
+// such that the event loop only serves the admin requests.
+func newTestBatchSubmitter(t *testing.T) *BatchSubmitter {
+	l := testlog.Logger(t, log.LvlError)
+	ctx, cancel := context.WithCancel(context.Background())
+	return &BatchSubmitter{
+		cfg:      sequencer.Config{PollInterval: time.Hour, MaxPendingTx: 1},
+		done:     make(chan struct{}),
+		log:      l,
+		stateReq: make(chan chan struct{}),
+		state:    NewChannelManager(l, testChannelConfig(), NewMetrics()),
+		metr:     NewMetrics(),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+func TestBatchSubmitterStartStop(t *testing.T) {
+	ctx := context.Background()
+	b := newTestBatchSubmitter(t)
+	require.NoError(t, b.Start())
+
+	status, err := b.Status(ctx)
+	require.NoError(t, err)
+	require.True(t, status.Running)
+	require.ErrorContains(t, b.StartBatchSubmission(ctx), "already running")
+
+	require.NoError(t, b.StopBatchSubmission(ctx))
+	status, err = b.Status(ctx)
+	require.NoError(t, err)
+	require.False(t, status.Running)
+	require.ErrorContains(t, b.StopBatchSubmission(ctx), "already stopped")
+
+	require.NoError(t, b.StartBatchSubmission(ctx))
+	status, err = b.Status(ctx)
+	require.NoError(t, err)
+	require.True(t, status.Running)
+
+	b.Stop()
+	require.ErrorContains(t, b.StopBatchSubmission(ctx), "shut down")
+	_, err = b.Status(ctx)
+	require.ErrorContains(t, err, "shut down")
+}
+
+func TestBatchSubmitterAdminRequestTimeout(t *testing.T) {
+	// the event loop is not running, so it cannot serve the request
+	b := newTestBatchSubmitter(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, b.StopBatchSubmission(ctx), context.DeadlineExceeded)
+}
+
+func TestBatchSubmitterForceCloseChannel(t *testing.T) {
+	ctx := context.Background()
+	rng := rand.New(rand.NewSource(1234))
+	b := newTestBatchSubmitter(t)
+
+	// without a channel there is nothing to close
+	require.NoError(t, b.Start())
+	defer b.Stop()
+	require.NoError(t, b.ForceCloseChannel(ctx))
+
+	// fill a channel, which is not full yet, such that it has no frames to submit
+	require.NoError(t, b.withLoopPaused(ctx, func() error {
+		addBlocks(t, b.state, makeBlocks(t, rng, eth.BlockID{}, 3))
+		_, _, err := b.state.TxData(l1Ref(1))
+		require.ErrorIs(t, err, io.EOF)
+		b.lastStoredBlock = b.state.StoredBlocks()[0]
+		return nil
+	}))
+	status, err := b.Status(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 0, status.PendingBlocks)
+	require.Equal(t, 0, status.PendingFrames)
+	require.Equal(t, 1, status.Channels)
+	require.Equal(t, uint64(3), status.LastStoredBlock.Number)
+
+	require.NoError(t, b.ForceCloseChannel(ctx))
+	status, err = b.Status(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, status.PendingFrames, "the remaining frame of the closed channel is pending")
+	require.Equal(t, 1, status.Channels)
+
+	// closing again is a no-op
+	require.NoError(t, b.ForceCloseChannel(ctx))
+	status, err = b.Status(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, status.PendingFrames)
+}
@@ -8,6 +8,7 @@ import (
 	opmetrics "github.com/ethereum-optimism/optimism/op-service/metrics"
 	oppprof "github.com/ethereum-optimism/optimism/op-service/pprof"
 	oprpc "github.com/ethereum-optimism/optimism/op-service/rpc"
+	"github.com/ethereum-optimism/optimism/op-service/txmgr"
 )
 
 const envVarPrefix = "OP_BATCHER"
@@ -35,7 +36,7 @@ var (
 	}
 	MinL1TxSizeBytesFlag = cli.Uint64Flag{
 		Name:     "min-l1-tx-size-bytes",
-		Usage:    "The minimum size of a batch tx submitted to L1.",
+		Usage:    "The minimum size of a batch tx submitted to L1. Only the last tx of a channel may be smaller. Must be at most half of the max size.",
 		Required: true,
 		EnvVar:   opservice.PrefixEnvVar(envVarPrefix, "MIN_L1_TX_SIZE_BYTES"),
 	}
@@ -80,6 +81,45 @@ var (
 		Required: true,
 		EnvVar:   opservice.PrefixEnvVar(envVarPrefix, "RESUBMISSION_TIMEOUT"),
 	}
+	MaxChannelDurationFlag = cli.Uint64Flag{
+		Name: "max-channel-duration",
+		Usage: "The maximum number of L1 blocks that a channel is kept open for, before it is closed. " +
+			"0 keeps channels open until they are full, or half of the channel timeout passed.",
+		Value:  0,
+		EnvVar: opservice.PrefixEnvVar(envVarPrefix, "MAX_CHANNEL_DURATION"),
+	}
+	TargetNumFramesFlag = cli.IntFlag{
+		Name:   "target-num-frames",
+		Usage:  "The number of max-size frames that a channel is filled up to, before it is closed.",
+		Value:  1,
+		EnvVar: opservice.PrefixEnvVar(envVarPrefix, "TARGET_NUM_FRAMES"),
+	}
+	ApproxComprRatioFlag = cli.Float64Flag{
+		Name: "approx-compr-ratio",
+		Usage: "The initial estimate of the compression ratio of channel data, used to fill channels " +
+			"to the target size. The estimate is updated with the compression ratio of every channel.",
+		Value:  0.5,
+		EnvVar: opservice.PrefixEnvVar(envVarPrefix, "APPROX_COMPR_RATIO"),
+	}
+	CompressionCodecFlag = cli.StringFlag{
+		Name: "compression-codec",
+		Usage: "The compression codec of channel data, zlib or zstd. zstd must be scheduled by the rollup config, " +
+			"channels are compressed with zlib until it is active.",
+		Value:  "zlib",
+		EnvVar: opservice.PrefixEnvVar(envVarPrefix, "COMPRESSION_CODEC"),
+	}
+	MaxPendingTxFlag = cli.Uint64Flag{
+		Name: "max-pending-tx",
+		Usage: "The maximum number of batch transactions that are in flight at the same time, " +
+			"using sequential nonces.",
+		Value:  1,
+		EnvVar: opservice.PrefixEnvVar(envVarPrefix, "MAX_PENDING_TX"),
+	}
+	RPCEnableAdminFlag = cli.BoolFlag{
+		Name:   "rpc.enable-admin",
+		Usage:  "Enable the admin API, to start and stop batch submission and inspect the submission state",
+		EnvVar: opservice.PrefixEnvVar(envVarPrefix, "RPC_ENABLE_ADMIN"),
+	}
 	MnemonicFlag = cli.StringFlag{
 		Name: "mnemonic",
 		Usage: "The mnemonic used to derive the wallets for either the " +
@@ -120,6 +160,12 @@ var requiredFlags = []cli.Flag{
 }
 
 var optionalFlags = []cli.Flag{
+	MaxChannelDurationFlag,
+	TargetNumFramesFlag,
+	ApproxComprRatioFlag,
+	CompressionCodecFlag,
+	MaxPendingTxFlag,
+	RPCEnableAdminFlag,
 	MnemonicFlag,
 	SequencerHDPathFlag,
 	PrivateKeyFlag,
@@ -131,6 +177,7 @@ func init() {
 	optionalFlags = append(optionalFlags, oplog.CLIFlags(envVarPrefix)...)
 	optionalFlags = append(optionalFlags, opmetrics.CLIFlags(envVarPrefix)...)
 	optionalFlags = append(optionalFlags, oppprof.CLIFlags(envVarPrefix)...)
+	optionalFlags = append(optionalFlags, txmgr.CLIFlags(envVarPrefix)...)
 
 	Flags = append(requiredFlags, optionalFlags...)
 }
@@ -0,0 +1,149 @@
+package op_batcher
+
+import (
+	"math/big"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/ethereum/go-ethereum/params"
+
+	opmetrics "github.com/ethereum-optimism/optimism/op-service/metrics"
+)
+
+const Namespace = "op_batcher"
+
+type Metrics struct {
+	FrameFillRatio     prometheus.Histogram
+	ChannelComprRatio  prometheus.Histogram
+	ApproxComprRatio   prometheus.Gauge
+	ChannelInputBytes  prometheus.Histogram
+	ChannelOutputBytes prometheus.Histogram
+	FramesSubmitted    prometheus.Counter
+	FramesFailed       prometheus.Counter
+	FeesPaid           prometheus.Counter
+	PendingBlocks      prometheus.Gauge
+	PendingFrames      prometheus.Gauge
+	InflightTxs        prometheus.Gauge
+	L2BlocksBehind     prometheus.Gauge
+
+	registry *prometheus.Registry
+}
+
+func NewMetrics() *Metrics {
+	registry := opmetrics.NewRegistry()
+	return &Metrics{
+		FrameFillRatio: promauto.With(registry).NewHistogram(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "frame_fill_ratio",
+			Help:      "Size of the submitted frames, relative to the max frame size",
+			Buckets:   []float64{0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9, 0.95, 0.99, 1},
+		}),
+		ChannelComprRatio: promauto.With(registry).NewHistogram(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "channel_compr_ratio",
+			Help:      "Compressed size of the channel data, relative to the uncompressed size",
+			Buckets:   []float64{0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9, 1},
+		}),
+		ApproxComprRatio: promauto.With(registry).NewGauge(prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "approx_compr_ratio",
+			Help:      "Current estimate of the compression ratio, used to fill channels to the target size",
+		}),
+		ChannelInputBytes: promauto.With(registry).NewHistogram(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "channel_input_bytes",
+			Help:      "Uncompressed size of the channel data",
+			Buckets:   prometheus.ExponentialBuckets(10_000, 2, 12),
+		}),
+		ChannelOutputBytes: promauto.With(registry).NewHistogram(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "channel_output_bytes",
+			Help:      "Compressed size of the channel data",
+			Buckets:   prometheus.ExponentialBuckets(10_000, 2, 12),
+		}),
+		FramesSubmitted: promauto.With(registry).NewCounter(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "frames_submitted_total",
+			Help:      "Number of frames that were confirmed on L1",
+		}),
+		FramesFailed: promauto.With(registry).NewCounter(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "frames_failed_total",
+			Help:      "Number of frame submissions that failed, and have to be resubmitted",
+		}),
+		FeesPaid: promauto.With(registry).NewCounter(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "fees_paid_eth",
+			Help:      "Total L1 fees paid for the confirmed batch transactions, in ETH",
+		}),
+		PendingBlocks: promauto.With(registry).NewGauge(prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "pending_blocks",
+			Help:      "Number of L2 blocks that are loaded, but not yet added to a channel",
+		}),
+		PendingFrames: promauto.With(registry).NewGauge(prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "pending_frames",
+			Help:      "Number of frames that wait to be submitted",
+		}),
+		InflightTxs: promauto.With(registry).NewGauge(prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "inflight_txs",
+			Help:      "Number of batch transactions that are submitted, but not yet confirmed",
+		}),
+		L2BlocksBehind: promauto.With(registry).NewGauge(prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "l2_blocks_behind",
+			Help:      "Number of L2 blocks between the safe head and the unsafe head, as reported by the rollup node",
+		}),
+		registry: registry,
+	}
+}
+
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+func (m *Metrics) RecordFrameFillRatio(ratio float64) {
+	m.FrameFillRatio.Observe(ratio)
+}
+
+func (m *Metrics) RecordChannelClosed(inputBytes int, outputBytes uint64, comprRatio float64) {
+	m.ChannelInputBytes.Observe(float64(inputBytes))
+	m.ChannelOutputBytes.Observe(float64(outputBytes))
+	m.ChannelComprRatio.Observe(comprRatio)
+}
+
+func (m *Metrics) RecordApproxComprRatio(ratio float64) {
+	m.ApproxComprRatio.Set(ratio)
+}
+
+// RecordTxConfirmed records a confirmed batch tx, and the L1 fee in wei that was paid for it.
+// The fee is nil if it could not be determined.
+func (m *Metrics) RecordTxConfirmed(fee *big.Int) {
+	m.FramesSubmitted.Inc()
+	if fee == nil {
+		return
+	}
+	feeEth, _ := new(big.Float).Quo(new(big.Float).SetInt(fee), big.NewFloat(params.Ether)).Float64()
+	m.FeesPaid.Add(feeEth)
+}
+
+func (m *Metrics) RecordTxFailed() {
+	m.FramesFailed.Inc()
+}
+
+func (m *Metrics) RecordPending(blocks, frames int, inflight uint64) {
+	m.PendingBlocks.Set(float64(blocks))
+	m.PendingFrames.Set(float64(frames))
+	m.InflightTxs.Set(float64(inflight))
+}
+
+func (m *Metrics) RecordL2BlocksBehind(safe, unsafe uint64) {
+	if unsafe < safe {
+		m.L2BlocksBehind.Set(0)
+		return
+	}
+	m.L2BlocksBehind.Set(float64(unsafe - safe))
+}
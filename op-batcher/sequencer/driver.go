@@ -43,4 +43,10 @@ type Config struct {
 	PrivKey *ecdsa.PrivateKey
 
 	PollInterval time.Duration
+
+	// MaxPendingTx is the maximum number of batch txs that are in flight at the same time.
+	MaxPendingTx uint64
+
+	// CancelPendingTxs cancels the txs of the batcher account that are pending in the tx pool on startup.
+	CancelPendingTxs bool
 }
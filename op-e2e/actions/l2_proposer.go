@@ -2,9 +2,10 @@ package actions
 
 import (
 	"crypto/ecdsa"
-	"math/big"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/log"
@@ -12,12 +13,15 @@ import (
 
 	"github.com/ethereum-optimism/optimism/op-node/sources"
 	"github.com/ethereum-optimism/optimism/op-proposer/drivers/l2output"
+	"github.com/ethereum-optimism/optimism/op-service/txmgr"
 )
 
 type ProposerCfg struct {
 	OutputOracleAddr  common.Address
 	ProposerKey       *ecdsa.PrivateKey
 	AllowNonFinalized bool
+	ConfirmationDepth uint64
+	MaxCatchUpOutputs uint64
 }
 
 type L2Proposer struct {
@@ -25,6 +29,7 @@ type L2Proposer struct {
 	l1      *ethclient.Client
 	driver  *l2output.Driver
 	address common.Address
+	signer  txmgr.SignerFn
 	lastTx  common.Hash
 }
 
@@ -37,9 +42,10 @@ func NewL2Proposer(t Testing, log log.Logger, cfg *ProposerCfg, l1 *ethclient.Cl
 		L1Client:          l1,
 		RollupClient:      rollupCl,
 		AllowNonFinalized: cfg.AllowNonFinalized,
+		ConfirmationDepth: cfg.ConfirmationDepth,
+		MaxCatchUpOutputs: cfg.MaxCatchUpOutputs,
 		L2OOAddr:          cfg.OutputOracleAddr,
-		ChainID:           chainID,
-		PrivKey:           cfg.ProposerKey,
+		From:              crypto.PubkeyToAddress(cfg.ProposerKey.PublicKey),
 	})
 	require.NoError(t, err)
 	return &L2Proposer{
@@ -47,6 +53,7 @@ func NewL2Proposer(t Testing, log log.Logger, cfg *ProposerCfg, l1 *ethclient.Cl
 		l1:      l1,
 		driver:  dr,
 		address: crypto.PubkeyToAddress(cfg.ProposerKey.PublicKey),
+		signer:  txmgr.PrivateKeySignerFn(cfg.ProposerKey, chainID),
 	}
 }
 
@@ -65,12 +72,37 @@ func (p *L2Proposer) ActMakeProposalTx(t Testing) {
 	nonce, err := p.l1.PendingNonceAt(t.Ctx(), p.address)
 	require.NoError(t, err)
 
-	tx, err := p.driver.CraftTx(t.Ctx(), start, end, new(big.Int).SetUint64(nonce))
+	candidates, err := p.driver.CraftTxs(t.Ctx(), start, end)
 	require.NoError(t, err)
 
-	err = p.driver.SendTransaction(t.Ctx(), tx)
+	gasTipCap, gasFeeCap, err := txmgr.SuggestGasPriceCaps(t.Ctx(), p.l1)
 	require.NoError(t, err)
-	p.lastTx = tx.Hash()
+	for i, candidate := range candidates {
+		gas := candidate.GasLimit
+		if gas == 0 {
+			gas, err = p.l1.EstimateGas(t.Ctx(), ethereum.CallMsg{
+				From:      p.address,
+				To:        candidate.To,
+				GasTipCap: gasTipCap,
+				GasFeeCap: gasFeeCap,
+				Data:      candidate.TxData,
+			})
+			require.NoError(t, err)
+		}
+		tx, err := p.signer(&types.DynamicFeeTx{
+			Nonce:     nonce + uint64(i),
+			To:        candidate.To,
+			GasTipCap: gasTipCap,
+			GasFeeCap: gasFeeCap,
+			Gas:       gas,
+			Data:      candidate.TxData,
+		})
+		require.NoError(t, err)
+
+		err = p.l1.SendTransaction(t.Ctx(), tx)
+		require.NoError(t, err)
+		p.lastTx = tx.Hash()
+	}
 }
 
 func (p *L2Proposer) LastProposalTx() common.Hash {
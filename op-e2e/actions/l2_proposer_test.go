@@ -80,3 +80,85 @@ func TestProposer(gt *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, eth.Bytes32(outputOnL1.OutputRoot), outputComputed.OutputRoot, "output roots must match")
 }
+
+// TestProposerCatchUp tests that a proposer that fell behind proposes all
+// pending outputs at once, with pipelined transactions, and stays the
+// confirmation depth behind the finalized L2 head.
+func TestProposerCatchUp(gt *testing.T) {
+	t := NewDefaultTesting(gt)
+	dp := e2eutils.MakeDeployParams(t, defaultRollupTestParams)
+	sd := e2eutils.Setup(t, dp, defaultAlloc)
+	log := testlog.Logger(t, log.LvlDebug)
+	miner, seqEngine, sequencer := setupSequencerTest(t, sd, log)
+
+	batcher := NewL2Batcher(log, sd.RollupCfg, &BatcherCfg{
+		MinL1TxSize: 0,
+		MaxL1TxSize: 128_000,
+		BatcherKey:  dp.Secrets.Batcher,
+	}, sequencer.RollupClient(), miner.EthClient(), seqEngine.EthClient())
+
+	const confDepth = 2
+	proposer := NewL2Proposer(t, log, &ProposerCfg{
+		OutputOracleAddr:  sd.DeploymentsL1.L2OutputOracleProxy,
+		ProposerKey:       dp.Secrets.Proposer,
+		ConfirmationDepth: confDepth,
+		MaxCatchUpOutputs: 10,
+	}, miner.EthClient(), sequencer.RollupClient())
+
+	// build a few L1 blocks worth of L2 blocks, spanning several outputs
+	for i := 0; i < 3; i++ {
+		miner.ActEmptyBlock(t)
+	}
+	sequencer.ActL1HeadSignal(t)
+	sequencer.ActL2PipelineFull(t)
+	sequencer.ActBuildToL1Head(t)
+	// submit and include in L1
+	batcher.ActSubmitAll(t)
+	miner.ActL1StartBlock(12)(t)
+	miner.ActL1IncludeTx(dp.Addresses.Batcher)(t)
+	miner.ActL1EndBlock(t)
+	// finalize all L1 blocks, including the batch
+	for i := 0; i < 4; i++ {
+		miner.ActL1SafeNext(t)
+		miner.ActL1FinalizeNext(t)
+	}
+	sequencer.ActL2PipelineFull(t)
+	sequencer.ActL1SafeSignal(t)
+	sequencer.ActL1FinalizedSignal(t)
+	finalized := sequencer.SyncStatus().FinalizedL2
+	require.Equal(t, sequencer.SyncStatus().UnsafeL2, finalized)
+
+	outputOracleContract, err := bindings.NewL2OutputOracle(sd.DeploymentsL1.L2OutputOracleProxy, miner.EthClient())
+	require.NoError(t, err)
+	interval, err := outputOracleContract.SUBMISSIONINTERVAL(nil)
+	require.NoError(t, err)
+	next, err := outputOracleContract.NextBlockNumber(nil)
+	require.NoError(t, err)
+	// all outputs up to the confirmation depth are pending
+	pending := (finalized.Number-confDepth-next.Uint64())/interval.Uint64() + 1
+	require.Greater(t, pending, uint64(1), "must have several pending outputs")
+
+	// propose all pending outputs at once, and include them in a single L1 block
+	require.True(t, proposer.CanPropose(t))
+	proposer.ActMakeProposalTx(t)
+	miner.ActL1StartBlock(12)(t)
+	for i := uint64(0); i < pending; i++ {
+		miner.ActL1IncludeTx(dp.Addresses.Proposer)(t)
+	}
+	miner.ActL1EndBlock(t)
+	receipt, err := miner.EthClient().TransactionReceipt(t.Ctx(), proposer.LastProposalTx())
+	require.NoError(t, err)
+	require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status, "proposal failed")
+
+	// nothing is left to propose within the confirmation depth
+	require.False(t, proposer.CanPropose(t))
+	latest, err := outputOracleContract.LatestBlockNumber(nil)
+	require.NoError(t, err)
+	require.Equal(t, next.Uint64()+(pending-1)*interval.Uint64(), latest.Uint64())
+	require.LessOrEqual(t, latest.Uint64(), finalized.Number-confDepth)
+	outputOnL1, err := outputOracleContract.GetL2Output(nil, latest)
+	require.NoError(t, err)
+	outputComputed, err := sequencer.RollupClient().OutputAtBlock(t.Ctx(), latest.Uint64())
+	require.NoError(t, err)
+	require.Equal(t, eth.Bytes32(outputOnL1.OutputRoot), outputComputed.OutputRoot, "output roots must match")
+}
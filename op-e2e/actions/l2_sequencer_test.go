@@ -1,6 +1,7 @@
 package actions
 
 import (
+	"context"
 	"math/big"
 	"testing"
 
@@ -12,6 +13,8 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/ethereum-optimism/optimism/op-e2e/e2eutils"
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/driver"
 	"github.com/ethereum-optimism/optimism/op-node/sources"
 	"github.com/ethereum-optimism/optimism/op-node/testlog"
 )
@@ -21,7 +24,7 @@ func setupSequencerTest(t Testing, sd *e2eutils.SetupData, log log.Logger) (*L1M
 
 	miner := NewL1Miner(t, log, sd.L1Cfg)
 
-	l1F, err := sources.NewL1Client(miner.RPCClient(), log, nil, sources.L1ClientDefaultConfig(sd.RollupCfg, false))
+	l1F, err := sources.NewL1Client(miner.RPCClient(), log, nil, sources.L1ClientDefaultConfig(sd.RollupCfg, false, sources.RPCKindBasic))
 	require.NoError(t, err)
 	engine := NewL2Engine(t, log, sd.L2Cfg, sd.RollupCfg.Genesis.L1, jwtPath)
 	l2Cl, err := sources.NewEngineClient(engine.RPCClient(), log, nil, sources.EngineClientDefaultConfig(sd.RollupCfg))
@@ -100,3 +103,81 @@ func TestL2Sequencer_SequencerDrift(gt *testing.T) {
 	sequencer.ActL2StartBlock(t)
 	require.True(t, engine.l2ForceEmpty, "engine should not be allowed to include anything after sequencer drift is surpassed")
 }
+
+// testInclusionPolicy returns the next queued inclusion decision, and the default decision once the queue is empty.
+// It records the outcomes of the blocks of its decisions.
+type testInclusionPolicy struct {
+	queue  []driver.TxInclusion
+	sealed []*eth.ExecutionPayload
+	failed []bool
+}
+
+func (p *testInclusionPolicy) BlockInclusion(ctx context.Context, l2Parent eth.L2BlockRef, l1Origin eth.L1BlockRef, timestamp uint64) (driver.TxInclusion, error) {
+	if len(p.queue) == 0 {
+		return driver.TxInclusion{}, nil
+	}
+	out := p.queue[0]
+	p.queue = p.queue[1:]
+	return out, nil
+}
+
+func (p *testInclusionPolicy) BlockSealed(payload *eth.ExecutionPayload) {
+	p.sealed = append(p.sealed, payload)
+}
+
+func (p *testInclusionPolicy) BlockFailed(invalid bool) {
+	p.failed = append(p.failed, invalid)
+}
+
+func TestL2Sequencer_TxInclusionPolicy(gt *testing.T) {
+	t := NewDefaultTesting(gt)
+	dp := e2eutils.MakeDeployParams(t, defaultRollupTestParams)
+	sd := e2eutils.Setup(t, dp, defaultAlloc)
+	log := testlog.Logger(t, log.LvlDebug)
+	miner, engine, sequencer := setupSequencerTest(t, sd, log)
+	policy := &testInclusionPolicy{}
+	sequencer.sequencer = driver.NewSequencer(log, sd.RollupCfg, sequencer.l1, sequencer.eng, policy)
+
+	miner.ActEmptyBlock(t)
+	sequencer.ActL1HeadSignal(t)
+	sequencer.ActL2PipelineFull(t)
+
+	signer := types.LatestSigner(sd.L2Cfg.Config)
+	forcedTx := types.MustSignNewTx(dp.Secrets.Alice, signer, &types.DynamicFeeTx{
+		ChainID:   sd.L2Cfg.Config.ChainID,
+		Nonce:     0,
+		GasTipCap: big.NewInt(2 * params.GWei),
+		GasFeeCap: new(big.Int).Add(miner.l1Chain.CurrentBlock().BaseFee(), big.NewInt(2*params.GWei)),
+		Gas:       params.TxGas,
+		To:        &dp.Addresses.Bob,
+		Value:     e2eutils.Ether(1),
+	})
+	forcedData, err := forcedTx.MarshalBinary()
+	require.NoError(t, err)
+
+	// the forced tx is included after the deposits, and the tx pool is excluded
+	policy.queue = []driver.TxInclusion{{Transactions: []eth.Data{forcedData}, NoTxPool: true}}
+	sequencer.ActL2StartBlock(t)
+	require.True(t, engine.l2ForceEmpty, "policy excludes the tx pool")
+	sequencer.ActL2EndBlock(t)
+	block, err := engine.EthClient().BlockByNumber(t.Ctx(), nil)
+	require.NoError(t, err)
+	require.Len(t, block.Transactions(), 2)
+	require.Equal(t, forcedTx.Hash(), block.Transactions()[1].Hash())
+	require.Len(t, policy.sealed, 1, "policy is told that the block is sealed")
+	require.Equal(t, block.Hash(), policy.sealed[0].BlockHash)
+
+	// the tx pool is used again by default
+	sequencer.ActL2StartBlock(t)
+	require.False(t, engine.l2ForceEmpty)
+	sequencer.ActL2EndBlock(t)
+
+	// deposits cannot be forced by the policy
+	deposit, err := types.NewTx(&types.DepositTx{To: &dp.Addresses.Bob, Gas: params.TxGas}).MarshalBinary()
+	require.NoError(t, err)
+	policy.queue = []driver.TxInclusion{{Transactions: []eth.Data{deposit}}}
+	head := sequencer.SyncStatus()
+	err = sequencer.sequencer.StartBuildingBlock(t.Ctx(), head.UnsafeL2, head.SafeL2.ID(), head.FinalizedL2.ID(), head.HeadL1)
+	require.ErrorContains(t, err, "must be a non-deposit transaction")
+	require.Equal(t, []bool{true}, policy.failed, "policy is told that the block is invalid")
+}
@@ -57,7 +57,7 @@ type L2API interface {
 
 func NewL2Verifier(t Testing, log log.Logger, l1 derive.L1Fetcher, eng L2API, cfg *rollup.Config) *L2Verifier {
 	metrics := &testutils.TestDerivationMetrics{}
-	pipeline := derive.NewDerivationPipeline(log, cfg, l1, eng, metrics)
+	pipeline := derive.NewDerivationPipeline(log, cfg, l1, eng, metrics, derive.PipelineHooks{})
 	pipeline.Reset()
 
 	rollupNode := &L2Verifier{
@@ -79,14 +79,14 @@ func NewL2Verifier(t Testing, log log.Logger, l1 derive.L1Fetcher, eng L2API, cf
 	apis := []rpc.API{
 		{
 			Namespace:     "optimism",
-			Service:       node.NewNodeAPI(cfg, eng, backend, log, m),
+			Service:       node.NewNodeAPI(cfg, eng, backend, nil, log, m),
 			Public:        true,
 			Authenticated: false,
 		},
 		{
 			Namespace:     "admin",
 			Version:       "",
-			Service:       node.NewAdminAPI(backend, m),
+			Service:       node.NewAdminAPI(backend, nil, m),
 			Public:        true, // TODO: this field is deprecated. Do we even need this anymore?
 			Authenticated: false,
 		},
@@ -113,6 +113,18 @@ func (s *l2VerifierBackend) ResetDerivationPipeline(ctx context.Context) error {
 	return nil
 }
 
+func (s *l2VerifierBackend) PipelineState(ctx context.Context) (*derive.PipelineState, error) {
+	return s.verifier.derivation.State(), nil
+}
+
+func (s *l2VerifierBackend) StartSequencer(ctx context.Context, blockHash common.Hash) error {
+	return errors.New("sequencing is controlled by test actions")
+}
+
+func (s *l2VerifierBackend) StopSequencer(ctx context.Context) (common.Hash, error) {
+	return common.Hash{}, errors.New("sequencing is controlled by test actions")
+}
+
 func (s *L2Verifier) L2Finalized() eth.L2BlockRef {
 	return s.derivation.Finalized()
 }
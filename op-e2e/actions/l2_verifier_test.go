@@ -91,3 +91,30 @@ func TestL2Verifier_SequenceWindow(gt *testing.T) {
 	got := miner.l1Chain.GetBlockByHash(miner.l1Chain.GetBlockByHash(verifier.SyncStatus().SafeL2.L1Origin.Hash).ParentHash())
 	require.Equal(t, reorgL1Block.Hash(), got.Hash(), "must have reorged L2 chain to the new L1 chain")
 }
+
+func TestL2Verifier_PipelineState(gt *testing.T) {
+	t := NewDefaultTesting(gt)
+	dp := e2eutils.MakeDeployParams(t, defaultRollupTestParams)
+	sd := e2eutils.Setup(t, dp, defaultAlloc)
+	log := testlog.Logger(t, log.LvlDebug)
+	miner, _, verifier := setupVerifierOnlyTest(t, sd, log)
+
+	miner.ActEmptyBlock(t)
+	miner.ActEmptyBlock(t)
+	verifier.ActL2PipelineFull(t)
+
+	state, err := verifier.RollupClient().PipelineState(t.Ctx())
+	require.NoError(t, err)
+	require.False(t, state.Resetting)
+	names := []string{"L1Traversal", "L1Retrieval", "FrameQueue", "ChannelBank", "ChannelInReader", "BatchQueue", "AttributesQueue", "EngineQueue"}
+	require.Len(t, state.Stages, len(names))
+	status := verifier.SyncStatus()
+	for i, stage := range state.Stages {
+		require.Equal(t, names[i], stage.Name)
+		require.Equal(t, status.CurrentL1, stage.Origin, "idle pipeline has the same origin in every stage")
+		require.Zero(t, stage.Buffered, "idle pipeline does not buffer data in between stages")
+	}
+	require.Equal(t, status.SafeL2, state.SafeHead)
+	require.Empty(t, state.Channels)
+	require.Empty(t, state.DroppedBatches)
+}
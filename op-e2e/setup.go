@@ -32,6 +32,7 @@ import (
 	"github.com/ethereum-optimism/optimism/op-node/p2p"
 	"github.com/ethereum-optimism/optimism/op-node/rollup"
 	"github.com/ethereum-optimism/optimism/op-node/rollup/driver"
+	"github.com/ethereum-optimism/optimism/op-node/sources"
 	"github.com/ethereum-optimism/optimism/op-node/testlog"
 	l2os "github.com/ethereum-optimism/optimism/op-proposer"
 	oplog "github.com/ethereum-optimism/optimism/op-service/log"
@@ -352,6 +353,7 @@ func (cfg SystemConfig) Start() (*System, error) {
 		rollupCfg.L1 = &rollupNode.L1EndpointConfig{
 			L1NodeAddr: l1EndpointConfig,
 			L1TrustRPC: false,
+			L1RPCKind:  sources.RPCKindBasic,
 		}
 		rollupCfg.L2 = &rollupNode.L2EndpointConfig{
 			L2EngineAddr:      l2EndpointConfig,
@@ -513,8 +515,13 @@ func (cfg SystemConfig) Start() (*System, error) {
 		MinL1TxSize:               1,
 		MaxL1TxSize:               120000,
 		ChannelTimeout:            cfg.DeployConfig.ChannelTimeout,
+		MaxChannelDuration:        1,
+		TargetNumFrames:           1,
+		ApproxComprRatio:          1.0,
+		CompressionCodec:          "zlib",
 		PollInterval:              50 * time.Millisecond,
 		NumConfirmations:          1,
+		MaxPendingTx:              1,
 		ResubmissionTimeout:       5 * time.Second,
 		SafeAbortNonceTooLowCount: 3,
 		LogConfig: oplog.CLIConfig{
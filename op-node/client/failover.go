@@ -0,0 +1,449 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ErrInconsistent is returned when two endpoints serve a different block for the same request.
+var ErrInconsistent = errors.New("inconsistent block data across L1 endpoints")
+
+// FailoverMetrics tracks the health of the endpoints of a FailoverRPC.
+type FailoverMetrics interface {
+	RecordL1EndpointHealth(endpoint string, healthy bool, head uint64)
+	RecordL1EndpointError(endpoint string)
+	RecordL1Failover(endpoint string)
+	RecordL1Inconsistency()
+}
+
+// InvalidReporter is implemented by RPC clients that can move away from an endpoint
+// when the caller finds that the endpoint served invalid data.
+type InvalidReporter interface {
+	// ReportInvalid reports that the named endpoints served invalid data, see ServedBy.
+	ReportInvalid(endpoints []string, err error)
+}
+
+// ReportInvalid reports invalid data, served by the named endpoints, to the RPC client if the client supports it.
+func ReportInvalid(c RPC, endpoints []string, err error) {
+	if r, ok := c.(InvalidReporter); ok {
+		r.ReportInvalid(endpoints, err)
+	}
+}
+
+type servedByKey struct{}
+
+// ServedBy collects the names of the endpoints that served the requests made with a context, see WithServedBy.
+// Data that is found to be invalid after combining the results of multiple requests
+// can then be reported against the endpoints that served it.
+type ServedBy struct {
+	mu        sync.Mutex
+	endpoints []string
+}
+
+// WithServedBy returns a context that records the endpoints that serve the requests made with it in s.
+func WithServedBy(ctx context.Context, s *ServedBy) context.Context {
+	return context.WithValue(ctx, servedByKey{}, s)
+}
+
+func (s *ServedBy) add(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range s.endpoints {
+		if e == name {
+			return
+		}
+	}
+	s.endpoints = append(s.endpoints, name)
+}
+
+// Endpoints returns the names of the endpoints that served requests.
+func (s *ServedBy) Endpoints() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.endpoints...)
+}
+
+// Reset forgets the endpoints that served requests.
+func (s *ServedBy) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.endpoints = nil
+}
+
+type FailoverConfig struct {
+	// MaxLag is the number of blocks an endpoint may be behind the best endpoint, before it is considered unhealthy.
+	// Lag is not checked if zero.
+	MaxLag uint64
+
+	// CrossCheck enables verification of blocks fetched by hash or by number against a second endpoint.
+	// The block hash and receipts root must match, so receipts verified against the block are consistent as well.
+	CrossCheck bool
+
+	// HealthCheckInterval is the interval at which the head of every endpoint is polled. Disabled if zero.
+	HealthCheckInterval time.Duration
+
+	// ErrorCooldown is the duration for which an endpoint is considered unhealthy after a failed request.
+	ErrorCooldown time.Duration
+}
+
+type endpoint struct {
+	name string
+	rpc  RPC
+
+	head        uint64
+	lagging     bool
+	failedUntil time.Time
+}
+
+// FailoverRPC is an RPC client that uses multiple endpoints of the same chain.
+// Requests go to the first healthy endpoint, in order of preference, and fail over to the next endpoint on errors.
+// Endpoints are unhealthy for a cooldown period after an error, or while their head lags behind the other endpoints.
+// If all endpoints are unhealthy, they are all tried in order of preference, to not stall on a false negative.
+type FailoverRPC struct {
+	log log.Logger
+	cfg FailoverConfig
+	m   FailoverMetrics
+
+	mu        sync.Mutex
+	endpoints []*endpoint
+	active    *endpoint
+
+	now func() time.Time
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+var _ RPC = (*FailoverRPC)(nil)
+
+// NewFailoverRPC creates a client that fails over between the given RPC clients, in order of preference.
+// The endpoints are named by their index in metrics and logs, to not expose any credentials in the endpoint URLs.
+// Callers are responsible for closing the client, which closes all the endpoint clients.
+func NewFailoverRPC(log log.Logger, rpcs []RPC, cfg *FailoverConfig, m FailoverMetrics) *FailoverRPC {
+	endpoints := make([]*endpoint, len(rpcs))
+	for i, c := range rpcs {
+		endpoints[i] = &endpoint{name: strconv.Itoa(i), rpc: c}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	f := &FailoverRPC{
+		log:       log,
+		cfg:       *cfg,
+		m:         m,
+		endpoints: endpoints,
+		active:    endpoints[0],
+		now:       time.Now,
+		cancel:    cancel,
+	}
+	if cfg.HealthCheckInterval > 0 {
+		f.wg.Add(1)
+		go f.healthLoop(ctx)
+	}
+	return f
+}
+
+func (f *FailoverRPC) Close() {
+	f.cancel()
+	f.wg.Wait()
+	for _, e := range f.endpoints {
+		e.rpc.Close()
+	}
+}
+
+func (f *FailoverRPC) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	if !f.cfg.CrossCheck || !crossCheckable(method, args) {
+		return f.do(ctx, func(e *endpoint) error {
+			return e.rpc.CallContext(ctx, result, method, args...)
+		})
+	}
+	var raw json.RawMessage
+	var primary *endpoint
+	err := f.do(ctx, func(e *endpoint) error {
+		primary = e
+		return e.rpc.CallContext(ctx, &raw, method, args...)
+	})
+	if err != nil {
+		return err
+	}
+	if err := f.crossCheck(ctx, primary, raw, method, args); err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, result)
+}
+
+// BatchCallContext sends the batch to the endpoints in order of preference, like CallContext.
+// Errors of individual batch elements fail over to the next endpoint as well, unless the method is not supported.
+// If every endpoint fails on some of the elements, the element errors of the last endpoint are left to the caller.
+func (f *FailoverRPC) BatchCallContext(ctx context.Context, b []rpc.BatchElem) error {
+	err := f.do(ctx, func(e *endpoint) error {
+		for i := range b {
+			b[i].Error = nil // clear any results of a previous endpoint that failed
+		}
+		if err := e.rpc.BatchCallContext(ctx, b); err != nil {
+			return err
+		}
+		for i := range b {
+			if b[i].Error != nil && !methodNotFound(b[i].Error) {
+				return &batchElemError{index: i, err: b[i].Error}
+			}
+		}
+		return nil
+	})
+	var elemErr *batchElemError
+	if errors.As(err, &elemErr) {
+		return nil
+	}
+	return err
+}
+
+// batchElemError is the first error of an element of a batch call.
+type batchElemError struct {
+	index int
+	err   error
+}
+
+func (e *batchElemError) Error() string {
+	return fmt.Sprintf("batch element %d failed: %v", e.index, e.err)
+}
+
+func (e *batchElemError) Unwrap() error {
+	return e.err
+}
+
+func (f *FailoverRPC) EthSubscribe(ctx context.Context, channel interface{}, args ...interface{}) (ethereum.Subscription, error) {
+	var sub ethereum.Subscription
+	err := f.do(ctx, func(e *endpoint) error {
+		s, err := e.rpc.EthSubscribe(ctx, channel, args...)
+		sub = s
+		return err
+	})
+	return sub, err
+}
+
+// ReportInvalid marks the named endpoints as unhealthy, after the caller found that they served invalid data,
+// e.g. receipts that do not match the receipts root of the block.
+func (f *FailoverRPC) ReportInvalid(endpoints []string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, e := range f.endpoints {
+		for _, name := range endpoints {
+			if e.name == name {
+				f.markFailed(e, err)
+			}
+		}
+	}
+}
+
+// do runs fn against the endpoints in order of preference, until it succeeds.
+// The endpoint that succeeds is recorded in the ServedBy of the context, if any.
+func (f *FailoverRPC) do(ctx context.Context, fn func(e *endpoint) error) error {
+	var err error
+	for _, e := range f.candidates(false) {
+		if err = fn(e); err == nil {
+			f.markActive(e)
+			if s, ok := ctx.Value(servedByKey{}).(*ServedBy); ok {
+				s.add(e.name)
+			}
+			return nil
+		}
+		if ctx.Err() != nil || methodNotFound(err) {
+			return err
+		}
+		f.mu.Lock()
+		f.markFailed(e, err)
+		f.mu.Unlock()
+	}
+	return err
+}
+
+// methodNotFound returns true if the endpoint does not support the requested method.
+// That is not a failure of the endpoint: the caller is expected to fall back to another method.
+func methodNotFound(err error) bool {
+	var rpcErr rpc.Error
+	return errors.As(err, &rpcErr) && rpcErr.ErrorCode() == -32601
+}
+
+// candidates returns the healthy endpoints in order of preference, followed by the unhealthy endpoints unless onlyHealthy is set.
+func (f *FailoverRPC) candidates(onlyHealthy bool) []*endpoint {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := f.now()
+	out := make([]*endpoint, 0, len(f.endpoints))
+	var unhealthy []*endpoint
+	for _, e := range f.endpoints {
+		if e.healthy(now) {
+			out = append(out, e)
+		} else {
+			unhealthy = append(unhealthy, e)
+		}
+	}
+	if onlyHealthy {
+		return out
+	}
+	return append(out, unhealthy...)
+}
+
+func (e *endpoint) healthy(now time.Time) bool {
+	return !e.lagging && !now.Before(e.failedUntil)
+}
+
+func (f *FailoverRPC) markActive(e *endpoint) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.active == e {
+		return
+	}
+	f.log.Warn("switched active L1 endpoint", "from", f.active.name, "to", e.name)
+	f.active = e
+	f.m.RecordL1Failover(e.name)
+}
+
+// markFailed marks the endpoint as unhealthy for the error cooldown. The lock must be held.
+func (f *FailoverRPC) markFailed(e *endpoint, err error) {
+	f.log.Warn("L1 endpoint failed", "endpoint", e.name, "err", err)
+	e.failedUntil = f.now().Add(f.cfg.ErrorCooldown)
+	f.m.RecordL1EndpointError(e.name)
+	f.m.RecordL1EndpointHealth(e.name, false, e.head)
+}
+
+// crossCheckable returns true for requests of a specific block, which all endpoints should agree on.
+// Requests by label, like the latest block, may legitimately differ between endpoints.
+func crossCheckable(method string, args []interface{}) bool {
+	switch method {
+	case "eth_getBlockByHash":
+		return true
+	case "eth_getBlockByNumber":
+		if len(args) == 0 {
+			return false
+		}
+		id, ok := args[0].(string)
+		return ok && strings.HasPrefix(id, "0x")
+	default:
+		return false
+	}
+}
+
+// blockCommitments are the fields of a block that endpoints are cross-checked on.
+type blockCommitments struct {
+	Hash         common.Hash `json:"hash"`
+	ReceiptsRoot common.Hash `json:"receiptsRoot"`
+}
+
+// crossCheck repeats the request against another healthy endpoint, and checks that the block matches.
+// If no other endpoint is available, or none of them has the block yet, the block is accepted unchecked.
+func (f *FailoverRPC) crossCheck(ctx context.Context, primary *endpoint, raw json.RawMessage, method string, args []interface{}) error {
+	if string(raw) == "null" {
+		return nil
+	}
+	var a blockCommitments
+	if err := json.Unmarshal(raw, &a); err != nil {
+		return fmt.Errorf("failed to decode block for cross-check: %w", err)
+	}
+	for _, e := range f.candidates(true) {
+		if e == primary {
+			continue
+		}
+		var other json.RawMessage
+		if err := e.rpc.CallContext(ctx, &other, method, args...); err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+			f.mu.Lock()
+			f.markFailed(e, err)
+			f.mu.Unlock()
+			continue
+		}
+		if string(other) == "null" { // the endpoint may not have the block yet
+			continue
+		}
+		var b blockCommitments
+		if err := json.Unmarshal(other, &b); err != nil {
+			return fmt.Errorf("failed to decode block for cross-check: %w", err)
+		}
+		if a != b {
+			f.m.RecordL1Inconsistency()
+			f.log.Error("L1 endpoints disagree on block", "method", method, "id", args[0],
+				"endpoint_a", primary.name, "hash_a", a.Hash, "receipts_a", a.ReceiptsRoot,
+				"endpoint_b", e.name, "hash_b", b.Hash, "receipts_b", b.ReceiptsRoot)
+			return fmt.Errorf("%w: endpoint %s has block %s with receipts root %s, endpoint %s has block %s with receipts root %s",
+				ErrInconsistent, primary.name, a.Hash, a.ReceiptsRoot, e.name, b.Hash, b.ReceiptsRoot)
+		}
+		return nil
+	}
+	f.log.Warn("no other L1 endpoint available to cross-check block", "method", method, "id", args[0])
+	return nil
+}
+
+func (f *FailoverRPC) healthLoop(ctx context.Context) {
+	defer f.wg.Done()
+	ticker := time.NewTicker(f.cfg.HealthCheckInterval)
+	defer ticker.Stop()
+	for {
+		f.checkHealth(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// checkHealth polls the head of every endpoint, and marks the endpoints that lag behind the best head.
+func (f *FailoverRPC) checkHealth(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	heads := make([]uint64, len(f.endpoints))
+	errs := make([]error, len(f.endpoints))
+	var wg sync.WaitGroup
+	for i, e := range f.endpoints {
+		wg.Add(1)
+		go func(i int, e *endpoint) {
+			defer wg.Done()
+			var head hexutil.Uint64
+			errs[i] = e.rpc.CallContext(ctx, &head, "eth_blockNumber")
+			heads[i] = uint64(head)
+		}(i, e)
+	}
+	wg.Wait()
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return // shutting down
+	}
+
+	var best uint64
+	for i := range f.endpoints {
+		if errs[i] == nil && heads[i] > best {
+			best = heads[i]
+		}
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := f.now()
+	for i, e := range f.endpoints {
+		if errs[i] != nil {
+			f.markFailed(e, errs[i])
+			continue
+		}
+		e.head = heads[i]
+		lagging := f.cfg.MaxLag > 0 && best-e.head > f.cfg.MaxLag
+		if lagging != e.lagging {
+			if lagging {
+				f.log.Warn("L1 endpoint is lagging behind", "endpoint", e.name, "head", e.head, "best", best)
+			} else {
+				f.log.Info("L1 endpoint caught up", "endpoint", e.name, "head", e.head, "best", best)
+			}
+			e.lagging = lagging
+		}
+		f.m.RecordL1EndpointHealth(e.name, e.healthy(now), e.head)
+	}
+}
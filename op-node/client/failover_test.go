@@ -0,0 +1,254 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/metrics"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+)
+
+// fakeL1 serves the head number, and blocks by hash or number argument.
+type fakeL1 struct {
+	mu     sync.Mutex
+	head   uint64
+	blocks map[string]*blockCommitments
+	err    error
+	calls  int
+	// elemErr is the error of every element of a batch call, if set
+	elemErr error
+}
+
+func (f *fakeL1) set(head uint64, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.head = head
+	f.err = err
+}
+
+func (f *fakeL1) callCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+func (f *fakeL1) Close() {}
+
+func (f *fakeL1) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.err != nil {
+		return f.err
+	}
+	var out interface{}
+	switch method {
+	case "eth_blockNumber":
+		out = hexutil.Uint64(f.head)
+	case "eth_getBlockByHash", "eth_getBlockByNumber":
+		out = f.blocks[args[0].(string)]
+	default:
+		return errors.New("unknown method")
+	}
+	data, err := json.Marshal(out)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, result)
+}
+
+func (f *fakeL1) BatchCallContext(ctx context.Context, b []rpc.BatchElem) error {
+	f.mu.Lock()
+	elemErr := f.elemErr
+	f.mu.Unlock()
+	for i := range b {
+		if elemErr != nil {
+			b[i].Error = elemErr
+			continue
+		}
+		if err := f.CallContext(ctx, b[i].Result, b[i].Method, b[i].Args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *fakeL1) EthSubscribe(ctx context.Context, channel interface{}, args ...interface{}) (ethereum.Subscription, error) {
+	return nil, errors.New("not supported")
+}
+
+func newTestFailover(t *testing.T, cfg *FailoverConfig, endpoints ...*fakeL1) (*FailoverRPC, *time.Time) {
+	rpcs := make([]RPC, len(endpoints))
+	for i, e := range endpoints {
+		rpcs[i] = e
+	}
+	f := NewFailoverRPC(testlog.Logger(t, log.LvlError), rpcs, cfg, metrics.NoopMetrics)
+	t.Cleanup(f.Close)
+	now := time.Unix(1000, 0)
+	f.now = func() time.Time { return now }
+	return f, &now
+}
+
+func TestFailoverOnError(t *testing.T) {
+	a, b := &fakeL1{head: 10}, &fakeL1{head: 10}
+	f, now := newTestFailover(t, &FailoverConfig{ErrorCooldown: 30 * time.Second}, a, b)
+	ctx := context.Background()
+
+	var head hexutil.Uint64
+	require.NoError(t, f.CallContext(ctx, &head, "eth_blockNumber"))
+	require.Equal(t, 1, a.callCount())
+	require.Zero(t, b.callCount())
+
+	// the failing endpoint is skipped during the cooldown
+	a.set(10, errors.New("connection refused"))
+	require.NoError(t, f.CallContext(ctx, &head, "eth_blockNumber"))
+	require.NoError(t, f.CallContext(ctx, &head, "eth_blockNumber"))
+	require.Equal(t, 2, a.callCount())
+	require.Equal(t, 2, b.callCount())
+
+	// after the cooldown the preferred endpoint is used again
+	a.set(11, nil)
+	*now = now.Add(31 * time.Second)
+	require.NoError(t, f.CallContext(ctx, &head, "eth_blockNumber"))
+	require.Equal(t, hexutil.Uint64(11), head)
+	require.Equal(t, 3, a.callCount())
+
+	// if all endpoints fail, the error is returned
+	a.set(11, errors.New("a is down"))
+	b.set(11, errors.New("b is down"))
+	require.ErrorContains(t, f.CallContext(ctx, &head, "eth_blockNumber"), "b is down")
+
+	// and unhealthy endpoints are still tried, rather than stalling
+	b.set(11, nil)
+	require.NoError(t, f.CallContext(ctx, &head, "eth_blockNumber"))
+
+	// an unsupported method is not a failure of the endpoint
+	b.set(11, methodNotFoundErr{})
+	require.ErrorIs(t, f.CallContext(ctx, &head, "eth_blockNumber"), methodNotFoundErr{})
+	b.set(12, nil)
+	require.NoError(t, f.CallContext(ctx, &head, "eth_blockNumber"))
+	require.Equal(t, hexutil.Uint64(12), head)
+}
+
+type methodNotFoundErr struct{}
+
+func (methodNotFoundErr) Error() string  { return "the method does not exist/is not available" }
+func (methodNotFoundErr) ErrorCode() int { return -32601 }
+
+func TestFailoverOnLag(t *testing.T) {
+	a, b := &fakeL1{head: 100}, &fakeL1{head: 100}
+	f, _ := newTestFailover(t, &FailoverConfig{MaxLag: 5}, a, b)
+	ctx := context.Background()
+	var head hexutil.Uint64
+
+	a.set(94, nil)
+	f.checkHealth(ctx)
+	require.NoError(t, f.CallContext(ctx, &head, "eth_blockNumber"))
+	require.Equal(t, hexutil.Uint64(100), head, "lagging endpoint is skipped")
+
+	a.set(96, nil)
+	f.checkHealth(ctx)
+	require.NoError(t, f.CallContext(ctx, &head, "eth_blockNumber"))
+	require.Equal(t, hexutil.Uint64(96), head, "endpoint within max lag is used again")
+}
+
+func TestFailoverReportInvalid(t *testing.T) {
+	a, b := &fakeL1{head: 1}, &fakeL1{head: 2}
+	f, now := newTestFailover(t, &FailoverConfig{ErrorCooldown: time.Minute}, a, b)
+	ctx := context.Background()
+	var head hexutil.Uint64
+
+	var served ServedBy
+	require.NoError(t, f.CallContext(WithServedBy(ctx, &served), &head, "eth_blockNumber"))
+	require.Equal(t, hexutil.Uint64(1), head)
+	require.Equal(t, []string{"0"}, served.Endpoints())
+
+	// the active endpoint changes before the invalid data is found
+	a.set(1, errors.New("connection refused"))
+	require.NoError(t, f.CallContext(ctx, &head, "eth_blockNumber"))
+	require.Equal(t, hexutil.Uint64(2), head)
+	a.set(1, nil)
+	*now = now.Add(2 * time.Minute)
+
+	// the endpoint that served the data is blamed, not the active endpoint,
+	// and reports pass through the client wrappers to the failover client
+	ReportInvalid(NewInstrumentedRPC(f, nil), served.Endpoints(), errors.New("bad receipts"))
+	require.NoError(t, f.CallContext(ctx, &head, "eth_blockNumber"))
+	require.Equal(t, hexutil.Uint64(2), head)
+	require.NoError(t, f.CallContext(ctx, &head, "eth_blockNumber"))
+	require.Equal(t, hexutil.Uint64(2), head)
+}
+
+func TestFailoverBatchElemErrors(t *testing.T) {
+	a, b := &fakeL1{head: 1}, &fakeL1{head: 2}
+	f, _ := newTestFailover(t, &FailoverConfig{ErrorCooldown: time.Minute}, a, b)
+	ctx := context.Background()
+	batch := func() []rpc.BatchElem {
+		return []rpc.BatchElem{
+			{Method: "eth_blockNumber", Result: new(hexutil.Uint64)},
+			{Method: "eth_blockNumber", Result: new(hexutil.Uint64)},
+		}
+	}
+
+	// element errors of the preferred endpoint fail over to the next endpoint
+	a.elemErr = errors.New("rate limited")
+	elems := batch()
+	require.NoError(t, f.BatchCallContext(ctx, elems))
+	for _, e := range elems {
+		require.NoError(t, e.Error)
+		require.Equal(t, hexutil.Uint64(2), *e.Result.(*hexutil.Uint64))
+	}
+
+	// if every endpoint fails on elements, the element errors of the last endpoint are left to the caller
+	b.elemErr = errors.New("b rate limited")
+	elems = batch()
+	require.NoError(t, f.BatchCallContext(ctx, elems))
+	for _, e := range elems {
+		require.EqualError(t, e.Error, "rate limited")
+	}
+
+	// unsupported methods do not fail over
+	c, d := &fakeL1{head: 1, elemErr: methodNotFoundErr{}}, &fakeL1{head: 2}
+	f, _ = newTestFailover(t, &FailoverConfig{ErrorCooldown: time.Minute}, c, d)
+	elems = batch()
+	require.NoError(t, f.BatchCallContext(ctx, elems))
+	require.ErrorIs(t, elems[0].Error, methodNotFoundErr{})
+	require.Equal(t, 0, d.callCount())
+}
+
+func TestFailoverCrossCheck(t *testing.T) {
+	block := &blockCommitments{Hash: common.Hash{0x01}, ReceiptsRoot: common.Hash{0x02}}
+	fork := &blockCommitments{Hash: common.Hash{0x01}, ReceiptsRoot: common.Hash{0x03}}
+	a := &fakeL1{blocks: map[string]*blockCommitments{"0xa": block, "0xb": block, "0xc": block}}
+	b := &fakeL1{blocks: map[string]*blockCommitments{"0xa": block, "0xb": fork}}
+	f, _ := newTestFailover(t, &FailoverConfig{CrossCheck: true}, a, b)
+	ctx := context.Background()
+
+	var out *blockCommitments
+	require.NoError(t, f.CallContext(ctx, &out, "eth_getBlockByNumber", "0xa", false))
+	require.Equal(t, block, out)
+	require.Equal(t, 1, b.callCount(), "block is cross-checked")
+
+	err := f.CallContext(ctx, &out, "eth_getBlockByNumber", "0xb", false)
+	require.ErrorIs(t, err, ErrInconsistent)
+
+	require.NoError(t, f.CallContext(ctx, &out, "eth_getBlockByNumber", "0xc", false), "endpoint without the block cannot cross-check")
+	require.Equal(t, block, out)
+
+	require.NoError(t, f.CallContext(ctx, &out, "eth_getBlockByNumber", "latest", false))
+	require.Equal(t, 3, b.callCount(), "blocks by label are not cross-checked")
+
+	require.NoError(t, f.CallContext(ctx, &out, "eth_getBlockByHash", "0xd", false))
+	require.Nil(t, out, "missing block is not found")
+}
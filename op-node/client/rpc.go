@@ -121,6 +121,10 @@ func (ic *InstrumentedRPCClient) EthSubscribe(ctx context.Context, channel inter
 	return ic.c.EthSubscribe(ctx, channel, args...)
 }
 
+func (ic *InstrumentedRPCClient) ReportInvalid(endpoints []string, err error) {
+	ReportInvalid(ic.c, endpoints, err)
+}
+
 // instrumentBatch handles metrics for batch calls. Request metrics are
 // increased for each batch element. Request durations are tracked for
 // the batch as a whole using a special <batch> method. Errors are tracked
@@ -0,0 +1,157 @@
+package compression
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli"
+
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+var Subcommands = cli.Commands{
+	{
+		Name:  "bench",
+		Usage: "Replays a range of L2 blocks through channels with each of the given compression codecs, and reports the compression ratios",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:     "l2",
+				Usage:    "L2 execution engine RPC endpoint to fetch the blocks from",
+				Required: true,
+			},
+			cli.Uint64Flag{
+				Name:     "start",
+				Usage:    "First L2 block number to replay",
+				Required: true,
+			},
+			cli.Uint64Flag{
+				Name:     "end",
+				Usage:    "Last L2 block number to replay (inclusive)",
+				Required: true,
+			},
+			cli.StringFlag{
+				Name:  "codecs",
+				Usage: "Comma separated list of compression codecs to compare",
+				Value: "zlib,zstd",
+			},
+			cli.Uint64Flag{
+				Name:  "max-frame-size",
+				Usage: "Maximum size of the frames output by the channels",
+				Value: 120_000,
+			},
+		},
+		Action: func(ctx *cli.Context) error {
+			var codecs []derive.CompressionCodec
+			for _, name := range strings.Split(ctx.String("codecs"), ",") {
+				codec, err := derive.ParseCompressionCodec(strings.TrimSpace(name))
+				if err != nil {
+					return err
+				}
+				codecs = append(codecs, codec)
+			}
+			start, end := ctx.Uint64("start"), ctx.Uint64("end")
+			if end < start {
+				return fmt.Errorf("end block %d is before start block %d", end, start)
+			}
+
+			client, err := ethclient.Dial(ctx.String("l2"))
+			if err != nil {
+				return fmt.Errorf("failed to dial L2 RPC: %w", err)
+			}
+			defer client.Close()
+
+			blocks := make([]*types.Block, 0, end-start+1)
+			for i := start; i <= end; i++ {
+				fetchCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				block, err := client.BlockByNumber(fetchCtx, new(big.Int).SetUint64(i))
+				cancel()
+				if err != nil {
+					return fmt.Errorf("failed to fetch block %d: %w", i, err)
+				}
+				blocks = append(blocks, block)
+			}
+
+			var results []*BenchResult
+			for _, codec := range codecs {
+				res, err := Bench(codec, blocks, ctx.Uint64("max-frame-size"))
+				if err != nil {
+					return fmt.Errorf("failed to bench %s: %w", codec, err)
+				}
+				results = append(results, res)
+			}
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(results)
+		},
+	},
+}
+
+// BenchResult summarizes the channels created when replaying blocks with a single codec.
+type BenchResult struct {
+	Codec     string        `json:"codec"`
+	Blocks    int           `json:"blocks"`
+	Channels  int           `json:"channels"`
+	Frames    int           `json:"frames"`
+	InputSize int           `json:"inputSize"`
+	FrameSize int           `json:"frameSize"`
+	Ratio     float64       `json:"ratio"`
+	Duration  time.Duration `json:"duration"`
+}
+
+// Bench adds the blocks to channels with the given codec, starting a new channel whenever a channel is full,
+// and outputs all frames of the channels.
+func Bench(codec derive.CompressionCodec, blocks []*types.Block, maxFrameSize uint64) (*BenchResult, error) {
+	res := &BenchResult{Codec: codec.String(), Blocks: len(blocks)}
+	startTime := time.Now()
+	for len(blocks) > 0 {
+		co, err := derive.NewChannelOutWithCodec(codec)
+		if err != nil {
+			return nil, err
+		}
+		added := 0
+		for _, block := range blocks {
+			if err := co.AddBlock(block); errors.Is(err, derive.ErrTooManyRLPBytes) {
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("failed to add block %d: %w", block.NumberU64(), err)
+			}
+			added++
+		}
+		if added == 0 {
+			return nil, fmt.Errorf("block %d does not fit in a channel", blocks[0].NumberU64())
+		}
+		blocks = blocks[added:]
+		res.InputSize += co.InputBytes()
+		if err := co.Close(); err != nil {
+			return nil, err
+		}
+		for {
+			var buf bytes.Buffer
+			err := co.OutputFrame(&buf, maxFrameSize)
+			if err != nil && err != io.EOF {
+				return nil, err
+			}
+			res.Frames += 1
+			res.FrameSize += buf.Len()
+			if err == io.EOF {
+				break
+			}
+		}
+		res.Channels += 1
+	}
+	res.Duration = time.Since(startTime)
+	if res.InputSize > 0 {
+		res.Ratio = float64(res.FrameSize) / float64(res.InputSize)
+	}
+	return res, nil
+}
@@ -12,8 +12,10 @@ import (
 	"github.com/urfave/cli"
 
 	opnode "github.com/ethereum-optimism/optimism/op-node"
+	"github.com/ethereum-optimism/optimism/op-node/cmd/compression"
 	"github.com/ethereum-optimism/optimism/op-node/cmd/genesis"
 	"github.com/ethereum-optimism/optimism/op-node/cmd/p2p"
+	"github.com/ethereum-optimism/optimism/op-node/cmd/replay"
 	"github.com/ethereum-optimism/optimism/op-node/flags"
 	"github.com/ethereum-optimism/optimism/op-node/heartbeat"
 	"github.com/ethereum-optimism/optimism/op-node/metrics"
@@ -68,6 +70,11 @@ func main() {
 			Name:        "genesis",
 			Subcommands: genesis.Subcommands,
 		},
+		{
+			Name:        "compression",
+			Subcommands: compression.Subcommands,
+		},
+		replay.Command,
 	}
 
 	err := app.Run(os.Args)
@@ -0,0 +1,269 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/urfave/cli"
+
+	"github.com/ethereum-optimism/optimism/op-node/client"
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/flags"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+	"github.com/ethereum-optimism/optimism/op-node/sources"
+)
+
+var (
+	RollupConfigFlag = cli.StringFlag{
+		Name:     "rollup.config",
+		Usage:    "Rollup chain parameters",
+		Required: true,
+	}
+	DataDirFlag = cli.StringFlag{
+		Name:     "data",
+		Usage:    "Directory with the recorded L1 and L2 blocks",
+		Required: true,
+	}
+)
+
+// Command runs the derivation pipeline against recorded L1 data, with a mock engine,
+// and outputs the derived payload attributes as JSON, one object per line.
+var Command = cli.Command{
+	Name:  "replay",
+	Usage: "Replays derivation over recorded L1 data, and outputs the derived payload attributes as JSON",
+	Flags: []cli.Flag{
+		RollupConfigFlag,
+		DataDirFlag,
+		cli.Uint64Flag{
+			Name:  "l1.start",
+			Usage: "Only output the payload attributes derived from L1 blocks at or after this block number",
+		},
+		cli.Uint64Flag{
+			Name:  "l1.end",
+			Usage: "Stop after deriving from this L1 block number. Defaults to the last recorded L1 block",
+			Value: math.MaxUint64,
+		},
+		cli.StringFlag{
+			Name:  "out",
+			Usage: "File to write the derived payload attributes to. Defaults to stdout",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		cfg, err := loadRollupConfig(ctx.String(RollupConfigFlag.Name))
+		if err != nil {
+			return err
+		}
+		var out io.Writer = os.Stdout
+		if path := ctx.String("out"); path != "" {
+			f, err := os.Create(path)
+			if err != nil {
+				return fmt.Errorf("failed to create output file: %w", err)
+			}
+			defer f.Close()
+			out = f
+		}
+		enc := json.NewEncoder(out)
+		start := ctx.Uint64("l1.start")
+		var writeErr error
+		onDerived := func(d *Derived) {
+			if d.DerivedFrom.Number < start || writeErr != nil {
+				return
+			}
+			writeErr = enc.Encode(d)
+		}
+		logger, err := newLogger(ctx)
+		if err != nil {
+			return err
+		}
+		if err := Replay(context.Background(), logger, cfg, ctx.String(DataDirFlag.Name), ctx.Uint64("l1.end"), onDerived); err != nil {
+			return err
+		}
+		if writeErr != nil {
+			return fmt.Errorf("failed to write output: %w", writeErr)
+		}
+		return nil
+	},
+	Subcommands: cli.Commands{
+		{
+			Name:  "record",
+			Usage: "Records the L1 and L2 data to replay derivation over from RPC endpoints",
+			Flags: []cli.Flag{
+				RollupConfigFlag,
+				DataDirFlag,
+				cli.StringFlag{
+					Name:     "l1",
+					Usage:    "L1 RPC endpoint to record the L1 blocks and receipts from",
+					Required: true,
+				},
+				cli.StringFlag{
+					Name:     "l2",
+					Usage:    "L2 RPC endpoint to record the L2 blocks from",
+					Required: true,
+				},
+				cli.Uint64Flag{
+					Name:  "l2.start",
+					Usage: "L2 block number to start deriving from. The L1 blocks are recorded from the channel timeout before its L1 origin",
+				},
+				cli.Uint64Flag{
+					Name:     "l1.end",
+					Usage:    "Last L1 block number to record",
+					Required: true,
+				},
+			},
+			Action: func(ctx *cli.Context) error {
+				cfg, err := loadRollupConfig(ctx.String(RollupConfigFlag.Name))
+				if err != nil {
+					return err
+				}
+				logger, err := newLogger(ctx)
+				if err != nil {
+					return err
+				}
+				return record(context.Background(), logger, cfg, ctx.String(DataDirFlag.Name),
+					ctx.String("l1"), ctx.String("l2"), ctx.Uint64("l2.start"), ctx.Uint64("l1.end"))
+			},
+		},
+	},
+}
+
+// newLogger creates a logger at the global log level, that writes to stderr to keep stdout for the output.
+func newLogger(ctx *cli.Context) (log.Logger, error) {
+	lvl, err := log.LvlFromString(ctx.GlobalString(flags.LogLevelFlag.Name))
+	if err != nil {
+		return nil, fmt.Errorf("invalid log level: %w", err)
+	}
+	logger := log.New()
+	logger.SetHandler(log.LvlFilterHandler(lvl, log.StreamHandler(os.Stderr, log.TerminalFormat(false))))
+	return logger, nil
+}
+
+func loadRollupConfig(path string) (*rollup.Config, error) {
+	var cfg rollup.Config
+	if err := readJSON(path, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to read rollup config: %w", err)
+	}
+	if err := cfg.Check(); err != nil {
+		return nil, fmt.Errorf("invalid rollup config: %w", err)
+	}
+	return &cfg, nil
+}
+
+// record fetches the L2 start block and the canonical L2 blocks derived from the recorded L1 range,
+// and the L1 blocks that the derivation pipeline reads, starting at the channel timeout before the L1 origin of the L2 start block.
+// The canonical L2 block hashes are needed for the replay: batches build on them.
+func record(ctx context.Context, logger log.Logger, cfg *rollup.Config, dir string, l1Addr string, l2Addr string, l2Start uint64, l1End uint64) error {
+	l2, err := ethclient.Dial(l2Addr)
+	if err != nil {
+		return fmt.Errorf("failed to dial L2 RPC: %w", err)
+	}
+	defer l2.Close()
+	data, err := recordL2(ctx, logger, cfg, l2, l2Start, l1End)
+	if err != nil {
+		return err
+	}
+	if err := WriteL2Data(dir, data); err != nil {
+		return fmt.Errorf("failed to write L2 data: %w", err)
+	}
+	l1Origin := data.Start.L1Origin.Number
+
+	l1RPC, err := client.NewRPC(ctx, logger, l1Addr)
+	if err != nil {
+		return fmt.Errorf("failed to dial L1 RPC: %w", err)
+	}
+	defer l1RPC.Close()
+	l1, err := sources.NewL1Client(l1RPC, logger, nil, sources.L1ClientDefaultConfig(cfg, false, sources.RPCKindBasic))
+	if err != nil {
+		return fmt.Errorf("failed to create L1 client: %w", err)
+	}
+	l1Start := uint64(0)
+	if l1Origin > cfg.ChannelTimeout {
+		l1Start = l1Origin - cfg.ChannelTimeout
+	}
+	if l1End < l1Origin {
+		return fmt.Errorf("L1 end block %d is before the L1 origin %d of the L2 start block", l1End, l1Origin)
+	}
+	for num := l1Start; num <= l1End; num++ {
+		block, err := recordL1Block(ctx, l1, num)
+		if err != nil {
+			return err
+		}
+		if err := WriteL1Block(dir, block); err != nil {
+			return fmt.Errorf("failed to write L1 block %d: %w", num, err)
+		}
+		logger.Info("Recorded L1 block", "number", num, "txs", len(block.Transactions))
+	}
+	return nil
+}
+
+// recordL1Block fetches the L1 block and its receipts, which are verified against the receipts root of the block.
+func recordL1Block(ctx context.Context, l1 *sources.L1Client, num uint64) (*L1Block, error) {
+	fetchCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	info, txs, err := l1.InfoAndTxsByNumber(fetchCtx, num)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch L1 block %d: %w", num, err)
+	}
+	_, receipts, err := l1.FetchReceipts(fetchCtx, info.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch receipts of L1 block %d: %w", num, err)
+	}
+	return NewL1Block(info, txs, receipts)
+}
+
+// recordL2 fetches the L2 start block, and the canonical L2 blocks after it, until the first block with an L1 origin after l1End.
+func recordL2(ctx context.Context, logger log.Logger, cfg *rollup.Config, l2 *ethclient.Client, start uint64, l1End uint64) (*L2Data, error) {
+	fetch := func(num uint64) (*types.Block, eth.L2BlockRef, error) {
+		fetchCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+		block, err := l2.BlockByNumber(fetchCtx, new(big.Int).SetUint64(num))
+		if err != nil {
+			return nil, eth.L2BlockRef{}, fmt.Errorf("failed to fetch L2 block %d: %w", num, err)
+		}
+		payload, err := eth.BlockAsPayload(block)
+		if err != nil {
+			return nil, eth.L2BlockRef{}, fmt.Errorf("failed to convert L2 block %d: %w", num, err)
+		}
+		ref, err := derive.PayloadToBlockRef(payload, &cfg.Genesis)
+		if err != nil {
+			return nil, eth.L2BlockRef{}, fmt.Errorf("failed to read L2 block %d: %w", num, err)
+		}
+		return block, ref, nil
+	}
+	_, startRef, err := fetch(start)
+	if err != nil {
+		return nil, err
+	}
+	data := &L2Data{Start: startRef}
+	for num := start + 1; ; num++ {
+		block, ref, err := fetch(num)
+		if errors.Is(err, ethereum.NotFound) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if ref.L1Origin.Number > l1End {
+			break
+		}
+		data.Blocks = append(data.Blocks, L2Block{
+			Hash:      block.Hash(),
+			Number:    hexutil.Uint64(block.NumberU64()),
+			Timestamp: hexutil.Uint64(block.Time()),
+			TxHash:    block.TxHash(),
+		})
+	}
+	logger.Info("Recorded L2 blocks", "start", startRef, "blocks", len(data.Blocks))
+	return data, nil
+}
@@ -0,0 +1,264 @@
+package replay
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/trie"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+)
+
+// L2Block is a recorded canonical L2 block, to compare the derived blocks against.
+type L2Block struct {
+	Hash      common.Hash    `json:"hash"`
+	Number    hexutil.Uint64 `json:"number"`
+	Timestamp hexutil.Uint64 `json:"timestamp"`
+	TxHash    common.Hash    `json:"transactionsRoot"`
+}
+
+// L2Data is the recorded L2 data: the L2 block to start deriving from, and the canonical L2 blocks after it.
+type L2Data struct {
+	Start  eth.L2BlockRef `json:"start"`
+	Blocks []L2Block      `json:"blocks"`
+}
+
+func l2DataPath(dir string) string {
+	return filepath.Join(dir, "l2.json")
+}
+
+// WriteL2Data writes the recorded L2 data to the data directory.
+func WriteL2Data(dir string, data *L2Data) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return writeJSON(l2DataPath(dir), data)
+}
+
+// ReadL2Data reads the recorded L2 data from the data directory.
+func ReadL2Data(dir string) (*L2Data, error) {
+	var data L2Data
+	if err := readJSON(l2DataPath(dir), &data); errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no L2 data recorded in %q: %w", dir, err)
+	} else if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// Derived is a block derived by the pipeline, built by the ReplayEngine.
+type Derived struct {
+	// DerivedFrom is the L1 block the pipeline was processing when the payload attributes were derived.
+	DerivedFrom eth.BlockID            `json:"derivedFrom"`
+	Parent      eth.BlockID            `json:"parent"`
+	Number      uint64                 `json:"number"`
+	Attributes  *eth.PayloadAttributes `json:"attributes"`
+	// Recorded is the canonical L2 block with the same number, if it was recorded,
+	// and Match is true if the derived block has the same timestamp and transactions.
+	Recorded *L2Block `json:"recorded,omitempty"`
+	Match    *bool    `json:"match,omitempty"`
+}
+
+// ReplayEngine is a mock execution engine that builds blocks from payload attributes without executing them,
+// and implements derive.Engine.
+//
+// Blocks are not executed, so the block hash of a derived block is not known from its contents.
+// Derived blocks that match the recorded canonical block are assigned the recorded block hash,
+// such that the batches that build on the canonical chain apply to the derived chain.
+// Diverging blocks, and blocks after the recorded range, are assigned a mock hash:
+// batches that build on the canonical block are dropped after a divergence.
+type ReplayEngine struct {
+	cfg *rollup.Config
+
+	refs     map[common.Hash]eth.L2BlockRef
+	payloads map[common.Hash]*eth.ExecutionPayload
+	recorded map[uint64]*L2Block
+
+	unsafe, safe, finalized eth.L2BlockRef
+
+	building  map[eth.PayloadID]*eth.ExecutionPayload
+	nextID    uint64
+	onDerived func(d *Derived)
+	origin    func() eth.L1BlockRef
+}
+
+var _ derive.Engine = (*ReplayEngine)(nil)
+
+// NewReplayEngine creates an engine with the start block of the recorded L2 data as the unsafe, safe and finalized head.
+// The onDerived callback is called for every built block, with the L1 origin of the pipeline as reported by origin.
+func NewReplayEngine(cfg *rollup.Config, data *L2Data, origin func() eth.L1BlockRef, onDerived func(d *Derived)) *ReplayEngine {
+	e := &ReplayEngine{
+		cfg:       cfg,
+		refs:      map[common.Hash]eth.L2BlockRef{data.Start.Hash: data.Start},
+		payloads:  make(map[common.Hash]*eth.ExecutionPayload),
+		recorded:  make(map[uint64]*L2Block, len(data.Blocks)),
+		unsafe:    data.Start,
+		safe:      data.Start,
+		finalized: data.Start,
+		building:  make(map[eth.PayloadID]*eth.ExecutionPayload),
+		onDerived: onDerived,
+		origin:    origin,
+	}
+	for i := range data.Blocks {
+		e.recorded[uint64(data.Blocks[i].Number)] = &data.Blocks[i]
+	}
+	return e
+}
+
+func (e *ReplayEngine) GetPayload(ctx context.Context, payloadId eth.PayloadID) (*eth.ExecutionPayload, error) {
+	payload, ok := e.building[payloadId]
+	if !ok {
+		return nil, eth.InputError{Inner: fmt.Errorf("unknown payload %s", payloadId), Code: eth.UnknownPayload}
+	}
+	delete(e.building, payloadId)
+	return payload, nil
+}
+
+func (e *ReplayEngine) ForkchoiceUpdate(ctx context.Context, state *eth.ForkchoiceState, attr *eth.PayloadAttributes) (*eth.ForkchoiceUpdatedResult, error) {
+	head, ok := e.refs[state.HeadBlockHash]
+	if !ok {
+		return nil, eth.InputError{Inner: fmt.Errorf("unknown head %s", state.HeadBlockHash), Code: eth.InvalidForkchoiceState}
+	}
+	safe, ok := e.refs[state.SafeBlockHash]
+	if !ok {
+		return nil, eth.InputError{Inner: fmt.Errorf("unknown safe block %s", state.SafeBlockHash), Code: eth.InvalidForkchoiceState}
+	}
+	finalized, ok := e.refs[state.FinalizedBlockHash]
+	if !ok {
+		return nil, eth.InputError{Inner: fmt.Errorf("unknown finalized block %s", state.FinalizedBlockHash), Code: eth.InvalidForkchoiceState}
+	}
+	e.unsafe, e.safe, e.finalized = head, safe, finalized
+	res := &eth.ForkchoiceUpdatedResult{PayloadStatus: eth.PayloadStatusV1{Status: eth.ExecutionValid, LatestValidHash: &head.Hash}}
+	if attr == nil {
+		return res, nil
+	}
+	payload, err := e.build(head, attr)
+	if err != nil {
+		return nil, eth.InputError{Inner: err, Code: eth.InvalidPayloadAttributes}
+	}
+	var id eth.PayloadID
+	binary.BigEndian.PutUint64(id[:], e.nextID)
+	e.nextID++
+	e.building[id] = payload
+	res.PayloadID = &id
+	return res, nil
+}
+
+// build creates the payload of the block with the given attributes on top of the parent block,
+// and reports it as derived block.
+func (e *ReplayEngine) build(parent eth.L2BlockRef, attr *eth.PayloadAttributes) (*eth.ExecutionPayload, error) {
+	txs := make(types.Transactions, 0, len(attr.Transactions))
+	for i, data := range attr.Transactions {
+		var tx types.Transaction
+		if err := tx.UnmarshalBinary(data); err != nil {
+			return nil, fmt.Errorf("failed to decode tx %d: %w", i, err)
+		}
+		txs = append(txs, &tx)
+	}
+	txHash := types.DeriveSha(txs, trie.NewStackTrie(nil))
+
+	num := parent.Number + 1
+	derived := &Derived{
+		Parent:     parent.ID(),
+		Number:     num,
+		Attributes: attr,
+	}
+	if e.origin != nil {
+		derived.DerivedFrom = e.origin().ID()
+	}
+	// Use a mock block hash that commits to the parent and the transactions, unless the block matches the canonical block.
+	var timestamp [8]byte
+	binary.BigEndian.PutUint64(timestamp[:], uint64(attr.Timestamp))
+	hash := crypto.Keccak256Hash(parent.Hash[:], txHash[:], timestamp[:])
+	if recorded, ok := e.recorded[num]; ok {
+		match := uint64(recorded.Timestamp) == uint64(attr.Timestamp) && recorded.TxHash == txHash
+		derived.Recorded = recorded
+		derived.Match = &match
+		if match {
+			hash = recorded.Hash
+		}
+	}
+
+	payload := &eth.ExecutionPayload{
+		ParentHash:   parent.Hash,
+		FeeRecipient: attr.SuggestedFeeRecipient,
+		PrevRandao:   attr.PrevRandao,
+		BlockNumber:  eth.Uint64Quantity(num),
+		Timestamp:    attr.Timestamp,
+		BlockHash:    hash,
+		Transactions: attr.Transactions,
+	}
+	if e.onDerived != nil {
+		e.onDerived(derived)
+	}
+	return payload, nil
+}
+
+func (e *ReplayEngine) NewPayload(ctx context.Context, payload *eth.ExecutionPayload) (*eth.PayloadStatusV1, error) {
+	if _, ok := e.refs[payload.ParentHash]; !ok {
+		return &eth.PayloadStatusV1{Status: eth.ExecutionSyncing}, nil
+	}
+	ref, err := derive.PayloadToBlockRef(payload, &e.cfg.Genesis)
+	if err != nil {
+		return &eth.PayloadStatusV1{Status: eth.ExecutionInvalid}, nil
+	}
+	e.refs[ref.Hash] = ref
+	e.payloads[ref.Hash] = payload
+	return &eth.PayloadStatusV1{Status: eth.ExecutionValid, LatestValidHash: &ref.Hash}, nil
+}
+
+func (e *ReplayEngine) PayloadByHash(ctx context.Context, hash common.Hash) (*eth.ExecutionPayload, error) {
+	payload, ok := e.payloads[hash]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	return payload, nil
+}
+
+func (e *ReplayEngine) PayloadByNumber(ctx context.Context, num uint64) (*eth.ExecutionPayload, error) {
+	// walk back the canonical chain from the unsafe head
+	ref := e.unsafe
+	for ref.Number > num {
+		parent, ok := e.refs[ref.ParentHash]
+		if !ok {
+			return nil, ethereum.NotFound
+		}
+		ref = parent
+	}
+	if ref.Number != num {
+		return nil, ethereum.NotFound
+	}
+	return e.PayloadByHash(ctx, ref.Hash)
+}
+
+func (e *ReplayEngine) L2BlockRefByLabel(ctx context.Context, label eth.BlockLabel) (eth.L2BlockRef, error) {
+	switch label {
+	case eth.Unsafe:
+		return e.unsafe, nil
+	case eth.Safe:
+		return e.safe, nil
+	case eth.Finalized:
+		return e.finalized, nil
+	default:
+		return eth.L2BlockRef{}, fmt.Errorf("unknown label %q", label)
+	}
+}
+
+func (e *ReplayEngine) L2BlockRefByHash(ctx context.Context, l2Hash common.Hash) (eth.L2BlockRef, error) {
+	ref, ok := e.refs[l2Hash]
+	if !ok {
+		return eth.L2BlockRef{}, ethereum.NotFound
+	}
+	return ref, nil
+}
@@ -0,0 +1,237 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+)
+
+// L1Block is the recorded data of an L1 block, as read by the derivation pipeline:
+// the header fields, the transactions that may contain batches, and the receipts that may contain deposits.
+type L1Block struct {
+	BlockHash     common.Hash      `json:"hash"`
+	Parent        common.Hash      `json:"parentHash"`
+	Miner         common.Address   `json:"miner"`
+	StateRoot     common.Hash      `json:"stateRoot"`
+	Number        hexutil.Uint64   `json:"number"`
+	Timestamp     hexutil.Uint64   `json:"timestamp"`
+	MixHash       common.Hash      `json:"mixHash"`
+	BaseFeePerGas *hexutil.Big     `json:"baseFeePerGas"`
+	ReceiptsRoot  common.Hash      `json:"receiptsRoot"`
+	Transactions  []hexutil.Bytes  `json:"transactions"`
+	Receipts      []*types.Receipt `json:"receipts"`
+}
+
+var _ eth.BlockInfo = (*L1Block)(nil)
+
+func (b *L1Block) Hash() common.Hash        { return b.BlockHash }
+func (b *L1Block) ParentHash() common.Hash  { return b.Parent }
+func (b *L1Block) Coinbase() common.Address { return b.Miner }
+func (b *L1Block) Root() common.Hash        { return b.StateRoot }
+func (b *L1Block) NumberU64() uint64        { return uint64(b.Number) }
+func (b *L1Block) Time() uint64             { return uint64(b.Timestamp) }
+func (b *L1Block) MixDigest() common.Hash   { return b.MixHash }
+func (b *L1Block) ReceiptHash() common.Hash { return b.ReceiptsRoot }
+
+func (b *L1Block) BaseFee() *big.Int {
+	if b.BaseFeePerGas == nil {
+		return nil
+	}
+	return (*big.Int)(b.BaseFeePerGas)
+}
+
+// NewL1Block converts a block, its transactions and its receipts into the recorded format.
+func NewL1Block(info eth.BlockInfo, transactions types.Transactions, receipts types.Receipts) (*L1Block, error) {
+	txs := make([]hexutil.Bytes, 0, len(transactions))
+	for i, tx := range transactions {
+		data, err := tx.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode tx %d of block %d: %w", i, info.NumberU64(), err)
+		}
+		txs = append(txs, data)
+	}
+	return &L1Block{
+		BlockHash:     info.Hash(),
+		Parent:        info.ParentHash(),
+		Miner:         info.Coinbase(),
+		StateRoot:     info.Root(),
+		Number:        hexutil.Uint64(info.NumberU64()),
+		Timestamp:     hexutil.Uint64(info.Time()),
+		MixHash:       info.MixDigest(),
+		BaseFeePerGas: (*hexutil.Big)(info.BaseFee()),
+		ReceiptsRoot:  info.ReceiptHash(),
+		Transactions:  txs,
+		Receipts:      receipts,
+	}, nil
+}
+
+func l1BlockPath(dir string, num uint64) string {
+	return filepath.Join(dir, "l1", fmt.Sprintf("%d.json", num))
+}
+
+// WriteL1Block writes the recorded L1 block to the data directory.
+func WriteL1Block(dir string, block *L1Block) error {
+	if err := os.MkdirAll(filepath.Join(dir, "l1"), 0755); err != nil {
+		return err
+	}
+	return writeJSON(l1BlockPath(dir, uint64(block.Number)), block)
+}
+
+// FileL1Fetcher serves the recorded L1 blocks of a data directory, and implements derive.L1Fetcher.
+// Blocks after the configured end block are hidden, and appear not to exist yet.
+type FileL1Fetcher struct {
+	byNumber map[uint64]*L1Block
+	byHash   map[common.Hash]*L1Block
+	end      uint64
+}
+
+var _ derive.L1Fetcher = (*FileL1Fetcher)(nil)
+
+// NewFileL1Fetcher loads the recorded L1 blocks from the data directory, up to and including the end block.
+// The recorded blocks must form a single contiguous chain.
+func NewFileL1Fetcher(dir string, end uint64) (*FileL1Fetcher, error) {
+	entries, err := os.ReadDir(filepath.Join(dir, "l1"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recorded L1 blocks: %w", err)
+	}
+	var nums []uint64
+	for _, entry := range entries {
+		num, err := strconv.ParseUint(strings.TrimSuffix(entry.Name(), ".json"), 10, 64)
+		if err != nil || entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		if num <= end {
+			nums = append(nums, num)
+		}
+	}
+	if len(nums) == 0 {
+		return nil, fmt.Errorf("no recorded L1 blocks up to block %d in %q", end, dir)
+	}
+	sort.Slice(nums, func(i, j int) bool { return nums[i] < nums[j] })
+
+	f := &FileL1Fetcher{
+		byNumber: make(map[uint64]*L1Block, len(nums)),
+		byHash:   make(map[common.Hash]*L1Block, len(nums)),
+		end:      nums[len(nums)-1],
+	}
+	for i, num := range nums {
+		var block L1Block
+		if err := readJSON(l1BlockPath(dir, num), &block); err != nil {
+			return nil, err
+		}
+		if uint64(block.Number) != num {
+			return nil, fmt.Errorf("recorded L1 block file %d contains block %d", num, block.Number)
+		}
+		if i > 0 {
+			if num != nums[i-1]+1 {
+				return nil, fmt.Errorf("recorded L1 blocks are not contiguous, %d is followed by %d", nums[i-1], num)
+			}
+			if parent := f.byNumber[nums[i-1]]; parent.BlockHash != block.Parent {
+				return nil, fmt.Errorf("recorded L1 block %d does not build on %s", num, parent.BlockHash)
+			}
+		}
+		f.byNumber[num] = &block
+		f.byHash[block.BlockHash] = &block
+	}
+	return f, nil
+}
+
+// Range returns the first and last recorded L1 block numbers.
+func (f *FileL1Fetcher) Range() (first uint64, last uint64) {
+	return f.end + 1 - uint64(len(f.byNumber)), f.end
+}
+
+func (f *FileL1Fetcher) L1BlockRefByLabel(ctx context.Context, label eth.BlockLabel) (eth.L1BlockRef, error) {
+	switch label {
+	case eth.Unsafe, eth.Safe, eth.Finalized:
+		// all recorded data is considered final
+		return eth.InfoToL1BlockRef(f.byNumber[f.end]), nil
+	default:
+		return eth.L1BlockRef{}, fmt.Errorf("unknown label %q", label)
+	}
+}
+
+func (f *FileL1Fetcher) L1BlockRefByNumber(ctx context.Context, num uint64) (eth.L1BlockRef, error) {
+	block, ok := f.byNumber[num]
+	if !ok {
+		return eth.L1BlockRef{}, fmt.Errorf("L1 block %d is not recorded: %w", num, ethereum.NotFound)
+	}
+	return eth.InfoToL1BlockRef(block), nil
+}
+
+func (f *FileL1Fetcher) block(hash common.Hash) (*L1Block, error) {
+	block, ok := f.byHash[hash]
+	if !ok {
+		return nil, fmt.Errorf("L1 block %s is not recorded: %w", hash, ethereum.NotFound)
+	}
+	return block, nil
+}
+
+func (f *FileL1Fetcher) L1BlockRefByHash(ctx context.Context, hash common.Hash) (eth.L1BlockRef, error) {
+	block, err := f.block(hash)
+	if err != nil {
+		return eth.L1BlockRef{}, err
+	}
+	return eth.InfoToL1BlockRef(block), nil
+}
+
+func (f *FileL1Fetcher) InfoByHash(ctx context.Context, hash common.Hash) (eth.BlockInfo, error) {
+	return f.block(hash)
+}
+
+func (f *FileL1Fetcher) InfoAndTxsByHash(ctx context.Context, hash common.Hash) (eth.BlockInfo, types.Transactions, error) {
+	block, err := f.block(hash)
+	if err != nil {
+		return nil, nil, err
+	}
+	txs := make(types.Transactions, 0, len(block.Transactions))
+	for i, data := range block.Transactions {
+		var tx types.Transaction
+		if err := tx.UnmarshalBinary(data); err != nil {
+			return nil, nil, fmt.Errorf("failed to decode tx %d of L1 block %s: %w", i, hash, err)
+		}
+		txs = append(txs, &tx)
+	}
+	return block, txs, nil
+}
+
+func (f *FileL1Fetcher) FetchReceipts(ctx context.Context, blockHash common.Hash) (eth.BlockInfo, types.Receipts, error) {
+	block, err := f.block(blockHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	return block, block.Receipts, nil
+}
+
+func readJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to decode %q: %w", path, err)
+	}
+	return nil
+}
+
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
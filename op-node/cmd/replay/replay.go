@@ -0,0 +1,61 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/metrics"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+)
+
+// maxResets is the number of pipeline resets after which the replay gives up:
+// the recorded data is static, so repeated resets will not resolve.
+const maxResets = 10
+
+// Replay runs the derivation pipeline over the recorded L1 data up to and including the end L1 block,
+// starting from the recorded L2 start block, and calls onDerived for every derived block.
+func Replay(ctx context.Context, logger log.Logger, cfg *rollup.Config, dir string, end uint64, onDerived func(d *Derived)) error {
+	l1, err := NewFileL1Fetcher(dir, end)
+	if err != nil {
+		return err
+	}
+	l2Data, err := ReadL2Data(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read recorded L2 data: %w", err)
+	}
+	first, last := l1.Range()
+	logger.Info("Replaying derivation", "l1_first", first, "l1_last", last, "l2_start", l2Data.Start, "l2_recorded", len(l2Data.Blocks))
+
+	var pipeline *derive.DerivationPipeline
+	engine := NewReplayEngine(cfg, l2Data, func() eth.L1BlockRef { return pipeline.Origin() }, onDerived)
+	pipeline = derive.NewDerivationPipeline(logger, cfg, l1, engine, metrics.NoopMetrics, derive.PipelineHooks{})
+	pipeline.Reset()
+
+	resets := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		err := pipeline.Step(ctx)
+		if err == io.EOF {
+			// the pipeline is idle, and there is no next L1 block to traverse to
+			logger.Info("Replay complete", "origin", pipeline.Origin(), "safe", pipeline.SafeL2Head())
+			return nil
+		} else if err == nil || errors.Is(err, derive.NotEnoughData) {
+			continue
+		} else if errors.Is(err, derive.ErrReset) && resets < maxResets {
+			logger.Warn("Derivation pipeline is reset", "err", err)
+			resets++
+			pipeline.Reset()
+		} else {
+			// with recorded data, temporary errors are caused by missing data, and will not resolve
+			return fmt.Errorf("derivation failed at L1 block %s: %w", pipeline.Origin(), err)
+		}
+	}
+}
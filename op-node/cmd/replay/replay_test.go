@@ -0,0 +1,244 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"io"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+)
+
+// recordL1Chain writes a chain of L1 blocks, with the given transactions by block number and without deposits,
+// to the data directory.
+func recordL1Chain(t *testing.T, dir string, count uint64, txs map[uint64]types.Transactions) []*types.Block {
+	var blocks []*types.Block
+	parent := common.Hash{}
+	for num := uint64(0); num < count; num++ {
+		header := &types.Header{
+			ParentHash: parent,
+			Number:     new(big.Int).SetUint64(num),
+			Time:       1000 + num*12,
+			BaseFee:    big.NewInt(7),
+			Difficulty: common.Big0,
+		}
+		block := types.NewBlock(header, txs[num], nil, nil, trie.NewStackTrie(nil))
+		l1Block, err := NewL1Block(block, block.Transactions(), nil)
+		require.NoError(t, err)
+		require.NoError(t, WriteL1Block(dir, l1Block))
+		blocks = append(blocks, block)
+		parent = block.Hash()
+	}
+	return blocks
+}
+
+// genesisL2Data is the recorded L2 data of a chain that is derived from the L2 genesis block.
+func genesisL2Data(cfg *rollup.Config) *L2Data {
+	return &L2Data{Start: eth.L2BlockRef{
+		Hash:     cfg.Genesis.L2.Hash,
+		Number:   cfg.Genesis.L2.Number,
+		Time:     cfg.Genesis.L2Time,
+		L1Origin: cfg.Genesis.L1,
+	}}
+}
+
+func testRollupConfig(l1Genesis *types.Block) *rollup.Config {
+	return &rollup.Config{
+		Genesis: rollup.Genesis{
+			L1:     eth.BlockID{Hash: l1Genesis.Hash(), Number: 0},
+			L2:     eth.BlockID{Hash: common.Hash{0xaa}, Number: 0},
+			L2Time: l1Genesis.Time(),
+		},
+		BlockTime:              2,
+		MaxSequencerDrift:      10,
+		SeqWindowSize:          4,
+		ChannelTimeout:         3,
+		L1ChainID:              big.NewInt(900),
+		L2ChainID:              big.NewInt(901),
+		P2PSequencerAddress:    common.Address{0x01},
+		BatchInboxAddress:      common.Address{0x02},
+		BatchSenderAddress:     common.Address{0x03},
+		DepositContractAddress: common.Address{0x04},
+	}
+}
+
+func replay(t *testing.T, cfg *rollup.Config, dir string, end uint64) []*Derived {
+	var derived []*Derived
+	err := Replay(context.Background(), testlog.Logger(t, log.LvlError), cfg, dir, end, func(d *Derived) {
+		derived = append(derived, d)
+	})
+	require.NoError(t, err)
+	return derived
+}
+
+func TestReplayEmptyL1(t *testing.T) {
+	dir := t.TempDir()
+	l1Blocks := recordL1Chain(t, dir, 10, nil)
+	cfg := testRollupConfig(l1Blocks[0])
+	require.NoError(t, WriteL2Data(dir, genesisL2Data(cfg)))
+
+	derived := replay(t, cfg, dir, 9)
+	// Without batches, the sequencing window of every epoch has to expire before deposit-only blocks are derived:
+	// epochs 0 up to and including 5 are covered by L1 blocks up to 9, with 6 L2 blocks per 12 second L1 block.
+	require.Len(t, derived, 6*6)
+	for i, d := range derived {
+		require.Equal(t, uint64(i+1), d.Number)
+		require.Equal(t, cfg.Genesis.L2Time+uint64(i+1)*cfg.BlockTime, uint64(d.Attributes.Timestamp))
+		require.Len(t, d.Attributes.Transactions, 1, "only the L1 info deposit")
+		require.True(t, d.Attributes.NoTxPool)
+		require.GreaterOrEqual(t, d.DerivedFrom.Number, uint64(i/6)+cfg.SeqWindowSize, "derived after the sequencing window expired")
+		require.Nil(t, d.Match)
+	}
+
+	// a lower end block hides the later L1 blocks
+	require.Len(t, replay(t, cfg, dir, 5), 2*6)
+}
+
+func TestReplayCompareRecordedL2(t *testing.T) {
+	dir := t.TempDir()
+	l1Blocks := recordL1Chain(t, dir, 10, nil)
+	cfg := testRollupConfig(l1Blocks[0])
+	require.NoError(t, WriteL2Data(dir, genesisL2Data(cfg)))
+	derived := replay(t, cfg, dir, 9)
+
+	// record the derived blocks as canonical chain, with one diverging block
+	data := genesisL2Data(cfg)
+	for _, d := range derived {
+		txs := make(types.Transactions, len(d.Attributes.Transactions))
+		for i, data := range d.Attributes.Transactions {
+			txs[i] = new(types.Transaction)
+			require.NoError(t, txs[i].UnmarshalBinary(data))
+		}
+		data.Blocks = append(data.Blocks, L2Block{
+			Hash:      common.BigToHash(new(big.Int).SetUint64(d.Number)),
+			Number:    hexutil.Uint64(d.Number),
+			Timestamp: hexutil.Uint64(d.Attributes.Timestamp),
+			TxHash:    types.DeriveSha(txs, trie.NewStackTrie(nil)),
+		})
+	}
+	data.Blocks[10].TxHash = common.Hash{0xff}
+	require.NoError(t, WriteL2Data(dir, data))
+
+	derived = replay(t, cfg, dir, 9)
+	require.Len(t, derived, len(data.Blocks))
+	for i, d := range derived {
+		require.NotNil(t, d.Match)
+		require.Equal(t, i != 10, *d.Match, "block %d", d.Number)
+		require.Equal(t, data.Blocks[i], *d.Recorded)
+		if i > 0 && i != 11 {
+			require.Equal(t, data.Blocks[i-1].Hash, d.Parent.Hash, "matching blocks take the recorded hash")
+		}
+	}
+	require.NotEqual(t, data.Blocks[10].Hash, derived[11].Parent.Hash, "diverging blocks get a mock hash")
+}
+
+func TestReplayWithoutL2Data(t *testing.T) {
+	dir := t.TempDir()
+	l1Blocks := recordL1Chain(t, dir, 10, nil)
+	cfg := testRollupConfig(l1Blocks[0])
+	err := Replay(context.Background(), testlog.Logger(t, log.LvlError), cfg, dir, 9, func(d *Derived) {})
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+// testL2Block creates an L2 block with the L1 info deposit and a user transaction, as produced by the sequencer.
+func testL2Block(t *testing.T, cfg *rollup.Config, parent common.Hash, num uint64, seqNumber uint64, l1Origin *types.Block, key *ecdsa.PrivateKey) *types.Block {
+	l1Info, err := derive.L1InfoDepositBytes(seqNumber, l1Origin)
+	require.NoError(t, err)
+	var deposit types.Transaction
+	require.NoError(t, deposit.UnmarshalBinary(l1Info))
+	userTx := types.MustSignNewTx(key, types.LatestSignerForChainID(cfg.L2ChainID), &types.DynamicFeeTx{
+		ChainID:   cfg.L2ChainID,
+		Nonce:     num,
+		To:        &common.Address{0xee},
+		Gas:       21000,
+		GasFeeCap: big.NewInt(10),
+		GasTipCap: big.NewInt(1),
+		Value:     big.NewInt(1),
+	})
+	header := &types.Header{
+		ParentHash: parent,
+		Number:     new(big.Int).SetUint64(num),
+		Time:       cfg.Genesis.L2Time + num*cfg.BlockTime,
+		BaseFee:    big.NewInt(7),
+		Difficulty: common.Big0,
+	}
+	return types.NewBlock(header, types.Transactions{&deposit, userTx}, nil, nil, trie.NewStackTrie(nil))
+}
+
+func TestReplayBatches(t *testing.T) {
+	batcherKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	userKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	// The L1 genesis block, as recorded by recordL1Chain, is the L1 origin of the batched blocks
+	l1Genesis := types.NewBlock(&types.Header{Number: common.Big0, Time: 1000, BaseFee: big.NewInt(7), Difficulty: common.Big0}, nil, nil, nil, trie.NewStackTrie(nil))
+	cfg := testRollupConfig(l1Genesis)
+	cfg.BatchSenderAddress = crypto.PubkeyToAddress(batcherKey.PublicKey)
+
+	// Two L2 blocks of epoch 0, batched in a single channel, the second block builds on the real hash of the first
+	block1 := testL2Block(t, cfg, cfg.Genesis.L2.Hash, 1, 1, l1Genesis, userKey)
+	block2 := testL2Block(t, cfg, block1.Hash(), 2, 2, l1Genesis, userKey)
+	co, err := derive.NewChannelOut()
+	require.NoError(t, err)
+	require.NoError(t, co.AddBlock(block1))
+	require.NoError(t, co.AddBlock(block2))
+	require.NoError(t, co.Close())
+	var frames bytes.Buffer
+	frames.WriteByte(derive.DerivationVersion0)
+	require.ErrorIs(t, co.OutputFrame(&frames, 100_000), io.EOF)
+	batcherTx := types.MustSignNewTx(batcherKey, types.LatestSignerForChainID(cfg.L1ChainID), &types.DynamicFeeTx{
+		ChainID:   cfg.L1ChainID,
+		To:        &cfg.BatchInboxAddress,
+		Gas:       1_000_000,
+		GasFeeCap: big.NewInt(10),
+		GasTipCap: big.NewInt(1),
+		Data:      frames.Bytes(),
+	})
+
+	dir := t.TempDir()
+	l1Blocks := recordL1Chain(t, dir, 10, map[uint64]types.Transactions{1: {batcherTx}})
+	require.Equal(t, l1Genesis.Hash(), l1Blocks[0].Hash())
+	data := genesisL2Data(cfg)
+	for _, b := range []*types.Block{block1, block2} {
+		data.Blocks = append(data.Blocks, L2Block{
+			Hash:      b.Hash(),
+			Number:    hexutil.Uint64(b.NumberU64()),
+			Timestamp: hexutil.Uint64(b.Time()),
+			TxHash:    b.TxHash(),
+		})
+	}
+	require.NoError(t, WriteL2Data(dir, data))
+
+	derived := replay(t, cfg, dir, 9)
+	require.Len(t, derived, 6*6)
+	for i, b := range []*types.Block{block1, block2} {
+		d := derived[i]
+		require.Equal(t, b.NumberU64(), d.Number)
+		require.Equal(t, uint64(1), d.DerivedFrom.Number, "derived from the batcher tx")
+		require.Equal(t, b.ParentHash(), d.Parent.Hash)
+		require.Len(t, d.Attributes.Transactions, 2, "L1 info deposit and the batched user tx")
+		require.NotNil(t, d.Match)
+		require.True(t, *d.Match, "block %d", d.Number)
+	}
+	// the blocks after the batched blocks are deposit-only, once the sequencing window expired
+	require.Equal(t, block2.Hash(), derived[2].Parent.Hash)
+	for _, d := range derived[2:] {
+		require.Len(t, d.Attributes.Transactions, 1)
+		require.Nil(t, d.Match)
+	}
+}
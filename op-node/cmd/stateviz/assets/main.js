@@ -15,8 +15,23 @@ function colorCode(hash) {
     return `hsl(${h}, ${s}%, ${l}%)`;
 }
 
-async function fetchLogs() {
-    const response = await fetch("/logs");
+// query returns the query string of the current event and node filters
+function query() {
+    const params = new URLSearchParams();
+    const event = $("#filter-event").val().trim();
+    const node = $("#filter-node").val().trim();
+    if (event !== "") {
+        params.set("event", event);
+    }
+    if (node !== "") {
+        params.set("node", node);
+    }
+    const q = params.toString();
+    return q === "" ? "" : `?${q}`;
+}
+
+async function fetchJSON(path) {
+    const response = await fetch(path + query());
     return await response.json();
 }
 
@@ -25,8 +40,10 @@ function tooltipFormat(v) {
   out += `<div>`
   out += `<em>hash</em>: <code>${v["hash"]}</code><br/>`
   out += `<em>num</em>: <code>${v["number"]}</code><br/>`
-  out += `<em>parent</em>: <code>${v["parentHash"]}</code><br/>`
-  out += `<em>time</em>: <code>${v["timestamp"]}</code><br/>`
+  if(v.hasOwnProperty("parentHash")) {
+    out += `<em>parent</em>: <code>${v["parentHash"]}</code><br/>`
+    out += `<em>time</em>: <code>${v["timestamp"]}</code><br/>`
+  }
   if(v.hasOwnProperty("l1origin")) {
     out += `<em>L1 hash</em>: <code>${v["l1origin"]["hash"]}</code><br/>`
     out += `<em>L1 num</em>: <code>${v["l1origin"]["number"]}</code><br/>`
@@ -36,90 +53,233 @@ function tooltipFormat(v) {
   return out
 }
 
-async function pageTable() {
-    const logs = await fetchLogs();
-    if (logs.length === 0) {
+function blockCell(v) {
+    // outer stringify in title attribute escapes the content and adds the quotes for the html to be valid
+    return `<td title="${tooltipFormat(v)}" data-bs-html="true" data-toggle="tooltip" style="background-color:${colorCode(v.hash)};">
+        ${prettyHex(v.hash)}
+    </td>`;
+}
+
+const headColumns = `
+    <th scope="col">L1Head</th>
+    <th scope="col">L1Current</th>
+    <th scope="col">L2Head</th>
+    <th scope="col">L2Safe</th>
+    <th scope="col">L2FinalizedHead</th>`;
+
+function snapshotCells(e) {
+    return blockCell(e.l1Head) + blockCell(e.l1Current) + blockCell(e.l2Head) + blockCell(e.l2Safe) + blockCell(e.l2FinalizedHead);
+}
+
+async function pageLogs() {
+    const logs = await fetchJSON("/logs");
+    const el = $("#logs");
+    el.empty();
+    if (logs.rows === null || logs.rows.length === 0) {
+        el.text("no snapshots");
         return
     }
 
     const dataEl = $(`<div id="snapshot-tables" class="row"></div>`);
-    $("#logs").append(dataEl);
-
-    let numCols = 0
-    if (logs.length !== 0) {
-        numCols = logs[0].length
-    }
+    el.append(dataEl);
     const paginationEl = $(`<div id="pagination"></div>`)
-    $("#logs").append(paginationEl)
+    el.append(paginationEl)
+    const colWidth = Math.max(2, Math.floor(12 / logs.nodes.length));
     paginationEl.pagination({
-        dataSource: logs,
+        dataSource: logs.rows,
         pageSize: 40,
         showGoInput: true,
         showGoButton: true,
         callback: (data, pagination) => {
             let tables = []
-            for (var i = 0; i < numCols; i++) {
-                // TODO: Fix grid overflow with more than 2 rollup drivers
-                let html = '<div class="col-6">';
+            for (let i = 0; i < logs.nodes.length; i++) {
+                let html = `<div class="col-${colWidth}">`;
                 html += `<table class="table">
+                    <caption style="caption-side:top">${logs.nodes[i]}</caption>
                     <thead>
-                    <caption style="caption-side:top">${data[0][i].engine_addr}</caption>
                         <tr>
                             <th scope="col">Timestamp</th>
-                            <th scope="col">L1Head</th>
-                            <th scope="col">L1Current</th>
-                            <th scope="col">L2Head</th>
-                            <th scope="col">L2Safe</th>
-                            <th scope="col">L2FinalizedHead</th>
+                            ${headColumns}
                         </tr>
                     </thead>
                         `;
                 html += "<tbody>";
-
-                // TODO: it'll also be useful to indicate which rollup driver updated its state for the given timestamp
-                for (const record of data) {
-                    const e = record[i];
-                    if (e === undefined) {
-                        // this column has reached its end
-                        break
+                for (const row of data) {
+                    const e = row.states[i];
+                    if (e === null) {
+                        // this node did not write a snapshot yet
+                        html += `<tr><td>${row.t}</td><td colspan="5"></td></tr>`;
+                        continue
                     }
-                    // outer stringify in title attribute escapes the content and adds the quotes for the html to be valid
-                    // inner stringify in
-
-                    // TODO: click to copy full hash
-                    html += `<tr>
-                        <td title="${e.event}" data-toggle="tooltip">
-                            ${e.t}
-                        </td>
-                        <td title="${tooltipFormat(e.l1Head)}" data-bs-html="true" data-toggle="tooltip" style="background-color:${colorCode(e.l1Head.hash)};">
-                            ${prettyHex(e.l1Head.hash)}
-                        </td>
-                        <td title="${tooltipFormat(e.l1Current)}" data-bs-html="true" data-toggle="tooltip" style="background-color:${colorCode(e.l1Current.hash)};">
-                            ${prettyHex(e.l1Current.hash)}
-                        </td>
-                        <td title="${tooltipFormat(e.l2Head)}" data-bs-html="true" data-toggle="tooltip" style="background-color:${colorCode(e.l2Head.hash)};">
-                            ${prettyHex(e.l2Head.hash)}
-                        </td>
-                        <td title="${tooltipFormat(e.l2Safe)}" data-bs-html="true" data-toggle="tooltip" style="background-color:${colorCode(e.l2Safe.hash)};">
-                            ${prettyHex(e.l2Safe.hash)}
-                        </td>
-                        <td title="${tooltipFormat(e.l2FinalizedHead)}" data-bs-html="true" data-toggle="tooltip" style="background-color:${colorCode(e.l2FinalizedHead.hash)};">
-                            ${prettyHex(e.l2FinalizedHead.hash)}
-                        </td>
+                    // the node that wrote the snapshot at this time is highlighted
+                    html += `<tr class="${row.updated === i ? "updated" : ""}">
+                        <td title="${e.event}" data-toggle="tooltip">${row.t}</td>
+                        ${snapshotCells(e)}
                     </tr>`;
                 }
                 html += "</tbody>";
                 html += "</table></div>";
                 tables.push(html);
             }
+            dataEl.html(tables.join("\n"));
+            $('[data-toggle="tooltip"]').tooltip();
+        }
+    })
+}
 
-            const html = tables.join("\n");
+async function pageTimeline() {
+    const timeline = await fetchJSON("/timeline");
+    const el = $("#timeline");
+    el.empty();
+    if (timeline.rows === null || timeline.rows.length === 0) {
+        el.text("no snapshots");
+        return
+    }
+    let head = `<th scope="col">L1 block</th>`;
+    for (const node of timeline.nodes) {
+        head += `<th scope="col" colspan="3">${node}: L2Head / L2Safe / L2FinalizedHead</th>`;
+    }
+    head += `<th scope="col">Divergence</th>`;
+    const dataEl = $(`<div></div>`);
+    const paginationEl = $(`<div></div>`);
+    el.append(dataEl, paginationEl);
+    paginationEl.pagination({
+        dataSource: timeline.rows,
+        pageSize: 40,
+        showGoInput: true,
+        showGoButton: true,
+        callback: (data, pagination) => {
+            let html = `<table class="table"><thead><tr>${head}</tr></thead><tbody>`;
+            for (const row of data) {
+                const divergent = row.divergences !== undefined && row.divergences.length > 0;
+                html += `<tr class="${divergent ? "divergent" : ""}"><td>${row.l1Number}</td>`;
+                for (const n of row.nodes) {
+                    if (n === null) {
+                        html += `<td colspan="3"></td>`;
+                        continue
+                    }
+                    html += blockCell(n.unsafe) + blockCell(n.safe) + blockCell(n.finalized);
+                }
+                html += `<td>${divergent ? row.divergences.join("<br/>") : ""}</td></tr>`;
+            }
+            html += "</tbody></table>";
             dataEl.html(html);
             $('[data-toggle="tooltip"]').tooltip();
         }
     })
 }
 
+// drawDivergence plots the distance between the unsafe and safe head, and between the safe and finalized head,
+// of every node over time.
+async function drawDivergence() {
+    const points = await fetchJSON("/divergence");
+    const canvas = document.getElementById("divergence-chart");
+    canvas.width = canvas.clientWidth;
+    canvas.height = canvas.clientHeight;
+    const ctx = canvas.getContext("2d");
+    ctx.clearRect(0, 0, canvas.width, canvas.height);
+    const legend = $("#divergence-legend");
+    legend.empty();
+    if (points === null || points.length === 0) {
+        legend.text("no snapshots");
+        return
+    }
+
+    const series = {};
+    let minT = Infinity, maxT = -Infinity, maxGap = 1;
+    for (const p of points) {
+        const t = Date.parse(p.t);
+        minT = Math.min(minT, t);
+        maxT = Math.max(maxT, t);
+        maxGap = Math.max(maxGap, p.unsafeSafe, p.safeFinalized);
+        for (const [kind, gap] of [["unsafe - safe", p.unsafeSafe], ["safe - finalized", p.safeFinalized]]) {
+            const key = `${p.node}: ${kind}`;
+            if (!(key in series)) {
+                series[key] = [];
+            }
+            series[key].push([t, gap]);
+        }
+    }
+    const pad = 30;
+    const x = t => pad + (maxT === minT ? 0 : (t - minT) / (maxT - minT)) * (canvas.width - 2 * pad);
+    const y = gap => canvas.height - pad - (gap / maxGap) * (canvas.height - 2 * pad);
+
+    ctx.strokeStyle = "#888";
+    ctx.beginPath();
+    ctx.moveTo(pad, pad);
+    ctx.lineTo(pad, canvas.height - pad);
+    ctx.lineTo(canvas.width - pad, canvas.height - pad);
+    ctx.stroke();
+    ctx.fillText(`${maxGap} blocks`, 2, pad - 5);
+    ctx.fillText(new Date(minT).toISOString(), pad, canvas.height - 10);
+    const end = new Date(maxT).toISOString();
+    ctx.fillText(end, canvas.width - pad - ctx.measureText(end).width, canvas.height - 10);
+
+    let i = 0;
+    for (const [key, values] of Object.entries(series)) {
+        const color = `hsl(${(i * 67) % 360}, 70%, 45%)`;
+        i++;
+        ctx.strokeStyle = color;
+        ctx.beginPath();
+        values.sort((a, b) => a[0] - b[0]);
+        values.forEach(([t, gap], j) => j === 0 ? ctx.moveTo(x(t), y(gap)) : ctx.lineTo(x(t), y(gap)));
+        ctx.stroke();
+        legend.append($(`<span class="me-3" style="color:${color}">&#9632; ${key}</span>`));
+    }
+}
+
+let liveSource = null;
+
+// startLive streams new snapshots from the server, most recent first.
+function startLive() {
+    stopLive();
+    const el = $("#live");
+    el.html(`<table class="table"><thead><tr>
+        <th scope="col">Timestamp</th><th scope="col">Node</th><th scope="col">Event</th>${headColumns}
+        </tr></thead><tbody></tbody></table>`);
+    const body = el.find("tbody");
+    liveSource = new EventSource("/events" + query());
+    liveSource.addEventListener("snapshot", msg => {
+        const e = JSON.parse(msg.data);
+        body.prepend($(`<tr><td>${e.t}</td><td>${e.node}</td><td>${e.event}</td>${snapshotCells(e)}</tr>`));
+        // keep the page responsive on long logs
+        body.children().slice(500).remove();
+    });
+}
+
+function stopLive() {
+    if (liveSource !== null) {
+        liveSource.close();
+        liveSource = null;
+    }
+}
+
+let currentView = "logs";
+
+function showView(view) {
+    currentView = view;
+    $(".view").hide();
+    $(`#view-${view}`).show();
+    if (view !== "live") {
+        stopLive();
+    }
+    switch (view) {
+        case "logs":
+            return pageLogs();
+        case "timeline":
+            return pageTimeline();
+        case "divergence":
+            return drawDivergence();
+        case "live":
+            return startLive();
+    }
+}
+
 (async () => {
-    pageTable()
+    $("button[data-view]").on("click", ev => showView($(ev.currentTarget).data("view")));
+    $("#filters").on("submit", ev => {
+        ev.preventDefault();
+        showView(currentView);
+    });
+    showView(currentView);
 })()
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"bufio"
 	"compress/gzip"
 	"embed"
 	"encoding/json"
@@ -13,80 +12,39 @@ import (
 	"net"
 	"net/http"
 	"os"
-	"strconv"
 	"strings"
-	"sync"
 	"time"
 
-	"github.com/ethereum-optimism/optimism/op-node/eth"
 	"github.com/ethereum/go-ethereum/log"
 )
 
+// snapshotFlags are the snapshot logs to load, one per node.
+type snapshotFlags []string
+
+func (s *snapshotFlags) String() string { return strings.Join(*s, ",") }
+
+func (s *snapshotFlags) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
+
 var (
-	snapshot   = flag.String("snapshot", "", "path to snapshot log")
+	snapshots  snapshotFlags
 	listenAddr = flag.String("addr", "", "listen address of webserver")
-	refresh    = flag.Duration("refresh", 10*time.Second, "snapshot refresh rate")
+	refresh    = flag.Duration("refresh", time.Second, "interval at which new snapshots are read from the snapshot logs")
 )
 
+func init() {
+	flag.Var(&snapshots, "snapshot", "path to snapshot log, optionally prefixed with a node name as name=path. "+
+		"Repeat the flag to merge the logs of multiple nodes")
+}
+
 var (
-	entries      map[string][]SnapshotState
-	entriesMutex sync.Mutex
+	store *snapshotStore
 
 	assetFS fs.FS
 )
 
-type SnapshotState struct {
-	Timestamp       string         `json:"t"`
-	EngineAddr      string         `json:"engine_addr"`
-	Event           string         `json:"event"`           // event name
-	L1Head          eth.L1BlockRef `json:"l1Head"`          // what we see as head on L1
-	L1Current       eth.L1BlockRef `json:"l1Current"`       // l1 block that the derivation is currently using
-	L2Head          eth.L2BlockRef `json:"l2Head"`          // l2 block that was last optimistically accepted (unsafe head)
-	L2Safe          eth.L2BlockRef `json:"l2Safe"`          // l2 block that was last derived
-	L2FinalizedHead eth.BlockID    `json:"l2FinalizedHead"` // l2 block that is irreversible
-}
-
-func (e *SnapshotState) UnmarshalJSON(data []byte) error {
-	t := struct {
-		Timestamp       string          `json:"t"`
-		EngineAddr      string          `json:"engine_addr"`
-		Event           string          `json:"event"`
-		L1Head          json.RawMessage `json:"l1Head"`
-		L1Current       json.RawMessage `json:"l1Current"`
-		L2Head          json.RawMessage `json:"l2Head"`
-		L2Safe          json.RawMessage `json:"l2Safe"`
-		L2FinalizedHead json.RawMessage `json:"l2FinalizedHead"`
-	}{}
-	if err := json.Unmarshal(data, &t); err != nil {
-		return err
-	}
-	e.Timestamp = t.Timestamp
-	e.EngineAddr = t.EngineAddr
-	e.Event = t.Event
-
-	unquote := func(d json.RawMessage) []byte {
-		s, _ := strconv.Unquote(string(d))
-		return []byte(s)
-	}
-
-	if err := json.Unmarshal(unquote(t.L1Head), &e.L1Head); err != nil {
-		return err
-	}
-	if err := json.Unmarshal(unquote(t.L1Current), &e.L1Current); err != nil {
-		return err
-	}
-	if err := json.Unmarshal(unquote(t.L2Head), &e.L2Head); err != nil {
-		return err
-	}
-	if err := json.Unmarshal(unquote(t.L2Safe), &e.L2Safe); err != nil {
-		return err
-	}
-	if err := json.Unmarshal(unquote(t.L2FinalizedHead), &e.L2FinalizedHead); err != nil {
-		return err
-	}
-	return nil
-}
-
 //go:embed assets
 var embeddedAssets embed.FS
 
@@ -97,7 +55,7 @@ func main() {
 		log.LvlFilterHandler(log.LvlDebug, log.StreamHandler(os.Stdout, log.TerminalFormat(true))),
 	)
 
-	if *snapshot == "" {
+	if len(snapshots) == 0 {
 		log.Crit("missing required -snapshot flag")
 	}
 
@@ -107,49 +65,33 @@ func main() {
 	}
 	assetFS = sub
 
+	store = newSnapshotStore()
+	sources := make([]*snapshotSource, len(snapshots))
+	for i, arg := range snapshots {
+		sources[i] = parseSource(arg)
+	}
 	go func() {
 		ticker := time.NewTicker(*refresh)
 		defer ticker.Stop()
-		for range ticker.C {
-			// TODO: incremental load
-			log.Info("loading snapshot...")
-			if err := loadSnapshot(); err != nil {
-				log.Error("failed to load snapshot", "err", err)
+		for {
+			for _, src := range sources {
+				entries, err := src.poll()
+				if err != nil {
+					log.Error("failed to load snapshot", "node", src.name, "err", err)
+					continue
+				}
+				if len(entries) > 0 {
+					log.Debug("loaded snapshots", "node", src.name, "count", len(entries))
+					store.add(entries)
+				}
 			}
+			<-ticker.C
 		}
 	}()
 
 	runServer()
 }
 
-func loadSnapshot() error {
-	file, err := os.Open(*snapshot)
-	if err != nil {
-		return fmt.Errorf("%w: failed to open snapshot file", err)
-	}
-	defer file.Close()
-
-	tempEntries := make(map[string][]SnapshotState)
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		var entry SnapshotState
-		if err := json.Unmarshal([]byte(scanner.Text()), &entry); err != nil {
-			return fmt.Errorf("%w: failed to decode snapshot log", err)
-		}
-
-		tempEntries[entry.EngineAddr] = append(tempEntries[entry.EngineAddr], entry)
-	}
-	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("%w: failed to scan snapshot file", err)
-	}
-
-	entriesMutex.Lock()
-	entries = tempEntries
-	entriesMutex.Unlock()
-
-	return nil
-}
-
 func runServer() {
 	l, err := net.Listen("tcp", *listenAddr)
 	if err != nil {
@@ -159,8 +101,11 @@ func runServer() {
 	mux := http.NewServeMux()
 	mux.Handle("/", http.FileServer(http.FS(assetFS)))
 	mux.HandleFunc("/logs", makeGzipHandler(logsHandler))
+	mux.HandleFunc("/divergence", makeGzipHandler(divergenceHandler))
+	mux.HandleFunc("/timeline", makeGzipHandler(timelineHandler))
+	mux.HandleFunc("/events", eventsHandler)
 
-	log.Info("running webserver...")
+	log.Info("running webserver...", "addr", l.Addr())
 	if err := http.Serve(l, mux); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Crit("http server failed", "message", err)
 	}
@@ -189,54 +134,89 @@ func makeGzipHandler(fn http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
-func logsHandler(w http.ResponseWriter, r *http.Request) {
-	var output [][]SnapshotState
+// requestFilter reads the snapshot filter from the "event" and "node" query parameters.
+// Both may be repeated, or hold a comma separated list.
+func requestFilter(r *http.Request) snapshotFilter {
+	q := r.URL.Query()
+	return newSnapshotFilter(q["event"], q["node"])
+}
 
-	entriesMutex.Lock()
-	// shallow copy so we can update the SnapshotState slice head
-	entriesCopy := make(map[string][]SnapshotState)
-	for k, v := range entries {
-		entriesCopy[k] = v
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Warn("failed to encode response", "message", err)
 	}
-	entriesMutex.Unlock()
+}
 
-	// sort log entries and zip em up
-	// Each record/row contains SnapshotStates for each rollup driver
-	// Note that we assume each SnapshotState slice is sorted by the timestamp
-	for {
-		var min *SnapshotState
-		var minKey string
-		for k, v := range entriesCopy {
-			if len(v) == 0 {
-				continue
-			}
-			if min == nil || v[0].Timestamp < min.Timestamp {
-				min = &v[0]
-				minKey = k
-			}
-		}
+// logsHandler serves the snapshots of all nodes, zipped up by time.
+func logsHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, zipLogs(requestFilter(r).apply(store.snapshots())))
+}
 
-		if min == nil {
-			break
-		}
+// divergenceHandler serves the distance between the unsafe, safe and finalized heads of every snapshot.
+func divergenceHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, headGaps(requestFilter(r).apply(store.snapshots())))
+}
 
-		entriesCopy[minKey] = entriesCopy[minKey][1:]
+// timelineHandler serves the snapshots of all nodes merged on the L1 timeline, with the divergences between the nodes.
+func timelineHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, mergeTimeline(requestFilter(r).apply(store.snapshots())))
+}
 
-		rec := make([]SnapshotState, 0, len(entriesCopy))
-		rec = append(rec, *min)
-		for k, v := range entriesCopy {
-			if k != minKey && len(v) != 0 {
-				newEntry := v[0]
-				newEntry.Timestamp = min.Timestamp
-				rec = append(rec, newEntry)
-			}
+// eventsHandler streams the snapshots as server-sent events: first the loaded snapshots, then new snapshots as they are loaded.
+func eventsHandler(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	filter := requestFilter(r)
+	backlog, ch := store.subscribe()
+	defer store.unsubscribe(ch)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	write := func(e *SnapshotState) error {
+		if !filter.match(e) {
+			return nil
 		}
-		output = append(output, rec)
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "event: snapshot\ndata: %s\n\n", data)
+		return err
 	}
+	for i := range backlog {
+		if err := write(&backlog[i]); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
 
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Cache-Control", "public, max-age=100000")
-	if err := json.NewEncoder(w).Encode(output); err != nil {
-		log.Warn("failed to encode logs", "message", err)
+	keepAlive := time.NewTicker(15 * time.Second)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case e, ok := <-ch:
+			if !ok {
+				// fell behind, the client reconnects and receives the backlog again
+				return
+			}
+			if err := write(&e); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-keepAlive.C:
+			if _, err := io.WriteString(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
 	}
 }
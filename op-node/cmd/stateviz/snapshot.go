@@ -0,0 +1,264 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+)
+
+// snapshotMsg is the log message of the rollup state snapshots written by the driver.
+// Other messages in the snapshot log, like the derivation pipeline state, are skipped.
+const snapshotMsg = "Rollup State Snapshot"
+
+type SnapshotState struct {
+	Timestamp       string         `json:"t"`
+	EngineAddr      string         `json:"engine_addr"`
+	Node            string         `json:"node"`            // node that wrote the snapshot, set when loading the log
+	Event           string         `json:"event"`           // event name
+	L1Head          eth.L1BlockRef `json:"l1Head"`          // what we see as head on L1
+	L1Current       eth.L1BlockRef `json:"l1Current"`       // l1 block that the derivation is currently using
+	L2Head          eth.L2BlockRef `json:"l2Head"`          // l2 block that was last optimistically accepted (unsafe head)
+	L2Safe          eth.L2BlockRef `json:"l2Safe"`          // l2 block that was last derived
+	L2FinalizedHead eth.BlockID    `json:"l2FinalizedHead"` // l2 block that is irreversible
+}
+
+func (e *SnapshotState) UnmarshalJSON(data []byte) error {
+	t := struct {
+		Timestamp       string          `json:"t"`
+		EngineAddr      string          `json:"engine_addr"`
+		Node            string          `json:"node"`
+		Event           string          `json:"event"`
+		L1Head          json.RawMessage `json:"l1Head"`
+		L1Current       json.RawMessage `json:"l1Current"`
+		L2Head          json.RawMessage `json:"l2Head"`
+		L2Safe          json.RawMessage `json:"l2Safe"`
+		L2FinalizedHead json.RawMessage `json:"l2FinalizedHead"`
+	}{}
+	if err := json.Unmarshal(data, &t); err != nil {
+		return err
+	}
+	e.Timestamp = t.Timestamp
+	e.EngineAddr = t.EngineAddr
+	e.Node = t.Node
+	e.Event = t.Event
+
+	// The snapshot log encodes the block refs as JSON strings, the merged entries as plain JSON objects.
+	unquote := func(d json.RawMessage) []byte {
+		if s, err := strconv.Unquote(string(d)); err == nil {
+			return []byte(s)
+		}
+		return d
+	}
+
+	if err := json.Unmarshal(unquote(t.L1Head), &e.L1Head); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(unquote(t.L1Current), &e.L1Current); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(unquote(t.L2Head), &e.L2Head); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(unquote(t.L2Safe), &e.L2Safe); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(unquote(t.L2FinalizedHead), &e.L2FinalizedHead); err != nil {
+		return err
+	}
+	return nil
+}
+
+// parseSnapshotLine decodes a line of the snapshot log. It returns false for lines that are not state snapshots.
+func parseSnapshotLine(line []byte) (SnapshotState, bool, error) {
+	var header struct {
+		Msg string `json:"msg"`
+	}
+	if err := json.Unmarshal(line, &header); err != nil {
+		return SnapshotState{}, false, err
+	}
+	if header.Msg != snapshotMsg {
+		return SnapshotState{}, false, nil
+	}
+	var entry SnapshotState
+	if err := json.Unmarshal(line, &entry); err != nil {
+		return SnapshotState{}, false, err
+	}
+	return entry, true, nil
+}
+
+// snapshotSource tails the snapshot log of a single node.
+type snapshotSource struct {
+	name string
+	path string
+
+	offset  int64
+	partial []byte // incomplete last line, completed by the next read
+}
+
+// parseSource parses a "[name=]path" snapshot log argument. The node name defaults to the path.
+func parseSource(arg string) *snapshotSource {
+	if i := strings.Index(arg, "="); i > 0 {
+		return &snapshotSource{name: arg[:i], path: arg[i+1:]}
+	}
+	return &snapshotSource{name: arg, path: arg}
+}
+
+// poll reads the snapshots that were appended to the log since the last poll.
+// The log is read from the start again if it was truncated.
+func (s *snapshotSource) poll() ([]SnapshotState, error) {
+	file, err := os.Open(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to open snapshot file", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to stat snapshot file", err)
+	}
+	if info.Size() < s.offset {
+		log.Warn("snapshot log was truncated, reading from the start", "node", s.name, "path", s.path)
+		s.offset = 0
+		s.partial = nil
+	}
+	if _, err := file.Seek(s.offset, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("%w: failed to seek snapshot file", err)
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read snapshot file", err)
+	}
+	s.offset += int64(len(data))
+	data = append(s.partial, data...)
+
+	end := bytes.LastIndexByte(data, '\n')
+	s.partial = append([]byte(nil), data[end+1:]...)
+
+	var out []SnapshotState
+	for _, line := range bytes.Split(data[:end+1], []byte{'\n'}) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		entry, ok, err := parseSnapshotLine(line)
+		if err != nil {
+			log.Warn("skipping invalid snapshot log line", "node", s.name, "err", err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if entry.EngineAddr != "" {
+			entry.Node = entry.EngineAddr
+		} else {
+			entry.Node = s.name
+		}
+		out = append(out, entry)
+	}
+	return out, nil
+}
+
+// snapshotStore holds the snapshots of all nodes, in the order they were loaded,
+// and streams new snapshots to subscribers.
+type snapshotStore struct {
+	mu      sync.Mutex
+	entries []SnapshotState
+	subs    map[chan SnapshotState]struct{}
+}
+
+func newSnapshotStore() *snapshotStore {
+	return &snapshotStore{subs: make(map[chan SnapshotState]struct{})}
+}
+
+func (s *snapshotStore) add(entries []SnapshotState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, entries...)
+	for ch := range s.subs {
+		if !send(ch, entries) {
+			// the subscriber is too slow, drop it rather than blocking the loading of new snapshots
+			close(ch)
+			delete(s.subs, ch)
+		}
+	}
+}
+
+func send(ch chan SnapshotState, entries []SnapshotState) bool {
+	for _, e := range entries {
+		select {
+		case ch <- e:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// snapshots returns a copy of all loaded snapshots.
+func (s *snapshotStore) snapshots() []SnapshotState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]SnapshotState(nil), s.entries...)
+}
+
+// subscribe returns all loaded snapshots, and a channel with the snapshots that are loaded after.
+// The channel is closed if the subscriber falls behind.
+func (s *snapshotStore) subscribe() ([]SnapshotState, chan SnapshotState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ch := make(chan SnapshotState, 1000)
+	s.subs[ch] = struct{}{}
+	return append([]SnapshotState(nil), s.entries...), ch
+}
+
+func (s *snapshotStore) unsubscribe(ch chan SnapshotState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.subs[ch]; ok {
+		close(ch)
+		delete(s.subs, ch)
+	}
+}
+
+// snapshotFilter selects snapshots by event and by node. An empty set matches all.
+type snapshotFilter struct {
+	events map[string]bool
+	nodes  map[string]bool
+}
+
+func newSnapshotFilter(events, nodes []string) snapshotFilter {
+	set := func(values []string) map[string]bool {
+		out := make(map[string]bool)
+		for _, v := range values {
+			for _, item := range strings.Split(v, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					out[item] = true
+				}
+			}
+		}
+		return out
+	}
+	return snapshotFilter{events: set(events), nodes: set(nodes)}
+}
+
+func (f snapshotFilter) match(e *SnapshotState) bool {
+	return (len(f.events) == 0 || f.events[e.Event]) && (len(f.nodes) == 0 || f.nodes[e.Node])
+}
+
+func (f snapshotFilter) apply(entries []SnapshotState) []SnapshotState {
+	out := entries[:0:0]
+	for i := range entries {
+		if f.match(&entries[i]) {
+			out = append(out, entries[i])
+		}
+	}
+	return out
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+)
+
+// snapshotLine encodes a snapshot like the JSON snapshot logger of the driver, with the block refs as JSON strings.
+func snapshotLine(t *testing.T, ts string, event string, l1 uint64, unsafe, safe eth.L2BlockRef) string {
+	str := func(v interface{}) string {
+		data, err := json.Marshal(v)
+		require.NoError(t, err)
+		return string(data)
+	}
+	l1Ref := eth.L1BlockRef{Hash: common.Hash{0x01, byte(l1)}, Number: l1}
+	return str(map[string]string{
+		"t":               ts,
+		"lvl":             "info",
+		"msg":             snapshotMsg,
+		"event":           event,
+		"l1Head":          str(l1Ref),
+		"l1Current":       str(l1Ref),
+		"l2Head":          str(unsafe),
+		"l2Safe":          str(safe),
+		"l2FinalizedHead": str(eth.BlockID{}),
+	}) + "\n"
+}
+
+func l2Ref(num uint64, fork byte) eth.L2BlockRef {
+	return eth.L2BlockRef{Hash: common.Hash{0x02, byte(num), fork}, Number: num}
+}
+
+func TestSnapshotSourcePoll(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.log")
+	src := parseSource("alice=" + path)
+	require.Equal(t, "alice", src.name)
+
+	line1 := snapshotLine(t, "2022-10-01T00:00:01Z", "New L1 Head", 1, l2Ref(2, 0), l2Ref(1, 0))
+	line2 := snapshotLine(t, "2022-10-01T00:00:02Z", "Step", 2, l2Ref(4, 0), l2Ref(3, 0))
+	pipeline := `{"t":"2022-10-01T00:00:02Z","msg":"Derivation Pipeline State","event":"Derivation step"}` + "\n"
+	// the second snapshot is only partially written
+	require.NoError(t, os.WriteFile(path, []byte(line1+pipeline+line2[:20]), 0o600))
+	entries, err := src.poll()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "alice", entries[0].Node)
+	require.Equal(t, "New L1 Head", entries[0].Event)
+	require.Equal(t, l2Ref(2, 0), entries[0].L2Head)
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = f.WriteString(line2[20:])
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	entries, err = src.poll()
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "only the appended snapshot is read")
+	require.Equal(t, l2Ref(3, 0), entries[0].L2Safe)
+
+	// a truncated log is read from the start
+	require.NoError(t, os.WriteFile(path, []byte(line1), 0o600))
+	entries, err = src.poll()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, uint64(1), entries[0].L1Current.Number)
+
+	// the snapshots are encoded as plain JSON to clients, and decode again
+	data, err := json.Marshal(entries[0])
+	require.NoError(t, err)
+	var decoded SnapshotState
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, entries[0], decoded)
+}
+
+func TestSnapshotStore(t *testing.T) {
+	s := newSnapshotStore()
+	s.add([]SnapshotState{{Node: "a", Event: "Step"}})
+	backlog, ch := s.subscribe()
+	require.Len(t, backlog, 1)
+	s.add([]SnapshotState{{Node: "b", Event: "New L1 Head"}})
+	require.Equal(t, "b", (<-ch).Node)
+	s.unsubscribe(ch)
+	_, ok := <-ch
+	require.False(t, ok)
+
+	filter := newSnapshotFilter([]string{"Step,Sequencer action"}, nil)
+	require.Len(t, filter.apply(s.snapshots()), 1)
+	filter = newSnapshotFilter(nil, []string{"a", "b"})
+	require.Len(t, filter.apply(s.snapshots()), 2)
+}
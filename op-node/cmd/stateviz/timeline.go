@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+)
+
+// groupByNode splits the snapshots per node, sorted by timestamp, and returns the sorted node names.
+func groupByNode(entries []SnapshotState) ([]string, map[string][]SnapshotState) {
+	byNode := make(map[string][]SnapshotState)
+	for _, e := range entries {
+		byNode[e.Node] = append(byNode[e.Node], e)
+	}
+	nodes := make([]string, 0, len(byNode))
+	for node, v := range byNode {
+		sort.SliceStable(v, func(i, j int) bool { return v[i].Timestamp < v[j].Timestamp })
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	return nodes, byNode
+}
+
+// LogRow is the state of every node at the time that one of the nodes wrote a snapshot.
+type LogRow struct {
+	Timestamp string `json:"t"`
+	// Updated is the index of the node that wrote the snapshot.
+	Updated int `json:"updated"`
+	// States has the latest snapshot of every node, or nil if the node did not write a snapshot yet.
+	States []*SnapshotState `json:"states"`
+}
+
+type Logs struct {
+	Nodes []string `json:"nodes"`
+	Rows  []LogRow `json:"rows"`
+}
+
+// zipLogs sorts the snapshots of all nodes by timestamp, and zips them up into rows.
+func zipLogs(entries []SnapshotState) Logs {
+	nodes, byNode := groupByNode(entries)
+	out := Logs{Nodes: nodes}
+	latest := make([]*SnapshotState, len(nodes))
+	next := make([]int, len(nodes))
+	for {
+		minIdx := -1
+		for i, node := range nodes {
+			v := byNode[node]
+			if next[i] < len(v) && (minIdx < 0 || v[next[i]].Timestamp < byNode[nodes[minIdx]][next[minIdx]].Timestamp) {
+				minIdx = i
+			}
+		}
+		if minIdx < 0 {
+			return out
+		}
+		latest[minIdx] = &byNode[nodes[minIdx]][next[minIdx]]
+		next[minIdx]++
+		out.Rows = append(out.Rows, LogRow{
+			Timestamp: latest[minIdx].Timestamp,
+			Updated:   minIdx,
+			States:    append([]*SnapshotState(nil), latest...),
+		})
+	}
+}
+
+// HeadGaps is the divergence between the heads of a node, in L2 blocks, at the time of a snapshot.
+type HeadGaps struct {
+	Timestamp     string `json:"t"`
+	Node          string `json:"node"`
+	Event         string `json:"event"`
+	L1Current     uint64 `json:"l1Current"`
+	Unsafe        uint64 `json:"unsafe"`
+	Safe          uint64 `json:"safe"`
+	Finalized     uint64 `json:"finalized"`
+	UnsafeSafe    uint64 `json:"unsafeSafe"`
+	SafeFinalized uint64 `json:"safeFinalized"`
+}
+
+func headGaps(entries []SnapshotState) []HeadGaps {
+	gap := func(a, b uint64) uint64 {
+		if a < b {
+			return 0
+		}
+		return a - b
+	}
+	out := make([]HeadGaps, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, HeadGaps{
+			Timestamp:     e.Timestamp,
+			Node:          e.Node,
+			Event:         e.Event,
+			L1Current:     e.L1Current.Number,
+			Unsafe:        e.L2Head.Number,
+			Safe:          e.L2Safe.Number,
+			Finalized:     e.L2FinalizedHead.Number,
+			UnsafeSafe:    gap(e.L2Head.Number, e.L2Safe.Number),
+			SafeFinalized: gap(e.L2Safe.Number, e.L2FinalizedHead.Number),
+		})
+	}
+	return out
+}
+
+// NodeHeads are the heads of a node after it processed an L1 block.
+type NodeHeads struct {
+	Timestamp string      `json:"t"`
+	L1Current eth.BlockID `json:"l1Current"`
+	Unsafe    eth.BlockID `json:"unsafe"`
+	Safe      eth.BlockID `json:"safe"`
+	Finalized eth.BlockID `json:"finalized"`
+}
+
+// TimelineRow is the state of the nodes at an L1 block, on the L1 timeline shared by all nodes.
+type TimelineRow struct {
+	L1Number uint64 `json:"l1Number"`
+	// Nodes has the last snapshot of every node at the L1 block, or nil if the node did not process the L1 block.
+	Nodes []*NodeHeads `json:"nodes"`
+	// Divergences describes the heads that differ between the nodes, at the same height.
+	Divergences []string `json:"divergences,omitempty"`
+}
+
+type Timeline struct {
+	Nodes []string      `json:"nodes"`
+	Rows  []TimelineRow `json:"rows"`
+}
+
+// mergeTimeline merges the snapshots of all nodes on the L1 block that is used by the derivation,
+// and detects the L1 blocks at which the nodes diverge.
+//
+// The heads of different nodes can only be compared at the same height: a node may be further
+// in processing an L1 block than another, or receive unsafe blocks at different times.
+// A divergence is a different block hash at the same height, for the L1 block, or any of the L2 heads.
+func mergeTimeline(entries []SnapshotState) Timeline {
+	nodes, byNode := groupByNode(entries)
+	rows := make(map[uint64]*TimelineRow)
+	for i, node := range nodes {
+		for _, e := range byNode[node] {
+			num := e.L1Current.Number
+			row, ok := rows[num]
+			if !ok {
+				row = &TimelineRow{L1Number: num, Nodes: make([]*NodeHeads, len(nodes))}
+				rows[num] = row
+			}
+			// snapshots are sorted by time, the last snapshot at the L1 block is the most progressed
+			row.Nodes[i] = &NodeHeads{
+				Timestamp: e.Timestamp,
+				L1Current: e.L1Current.ID(),
+				Unsafe:    e.L2Head.ID(),
+				Safe:      e.L2Safe.ID(),
+				Finalized: e.L2FinalizedHead,
+			}
+		}
+	}
+
+	out := Timeline{Nodes: nodes, Rows: make([]TimelineRow, 0, len(rows))}
+	for _, row := range rows {
+		row.Divergences = divergences(nodes, row.Nodes)
+		out.Rows = append(out.Rows, *row)
+	}
+	sort.Slice(out.Rows, func(i, j int) bool { return out.Rows[i].L1Number < out.Rows[j].L1Number })
+	return out
+}
+
+func divergences(nodes []string, heads []*NodeHeads) []string {
+	var out []string
+	check := func(name string, id func(h *NodeHeads) eth.BlockID) {
+		seen := make(map[uint64]int) // block number to the index of the first node with a block at that height
+		for i, h := range heads {
+			if h == nil {
+				continue
+			}
+			b := id(h)
+			if b.Hash == (common.Hash{}) {
+				// the head is not known yet
+				continue
+			}
+			first, ok := seen[b.Number]
+			if !ok {
+				seen[b.Number] = i
+				continue
+			}
+			if other := id(heads[first]); other.Hash != b.Hash {
+				out = append(out, fmt.Sprintf("%s %d: %s has %s, %s has %s",
+					name, b.Number, nodes[first], other.Hash.TerminalString(), nodes[i], b.Hash.TerminalString()))
+			}
+		}
+	}
+	check("L1 block", func(h *NodeHeads) eth.BlockID { return h.L1Current })
+	check("unsafe head", func(h *NodeHeads) eth.BlockID { return h.Unsafe })
+	check("safe head", func(h *NodeHeads) eth.BlockID { return h.Safe })
+	check("finalized head", func(h *NodeHeads) eth.BlockID { return h.Finalized })
+	return out
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+)
+
+func testSnapshot(node string, ts string, l1 uint64, unsafe, safe eth.L2BlockRef) SnapshotState {
+	return SnapshotState{
+		Timestamp: ts,
+		Node:      node,
+		L1Current: eth.L1BlockRef{Hash: [32]byte{0x01, byte(l1)}, Number: l1},
+		L2Head:    unsafe,
+		L2Safe:    safe,
+	}
+}
+
+func TestZipLogs(t *testing.T) {
+	logs := zipLogs([]SnapshotState{
+		testSnapshot("b", "2022-10-01T00:00:02Z", 1, l2Ref(2, 0), l2Ref(1, 0)),
+		testSnapshot("a", "2022-10-01T00:00:01Z", 1, l2Ref(2, 0), l2Ref(1, 0)),
+		testSnapshot("a", "2022-10-01T00:00:03Z", 2, l2Ref(4, 0), l2Ref(3, 0)),
+	})
+	require.Equal(t, []string{"a", "b"}, logs.Nodes)
+	require.Len(t, logs.Rows, 3)
+	require.Equal(t, 0, logs.Rows[0].Updated)
+	require.Nil(t, logs.Rows[0].States[1], "b did not write a snapshot yet")
+	require.Equal(t, 1, logs.Rows[1].Updated)
+	require.Equal(t, "2022-10-01T00:00:01Z", logs.Rows[1].States[0].Timestamp)
+	require.Equal(t, "2022-10-01T00:00:03Z", logs.Rows[2].States[0].Timestamp)
+	require.Equal(t, "2022-10-01T00:00:02Z", logs.Rows[2].States[1].Timestamp, "latest state of b is kept")
+}
+
+func TestHeadGaps(t *testing.T) {
+	gaps := headGaps([]SnapshotState{testSnapshot("a", "2022-10-01T00:00:01Z", 1, l2Ref(10, 0), l2Ref(4, 0))})
+	require.Equal(t, uint64(6), gaps[0].UnsafeSafe)
+	require.Equal(t, uint64(4), gaps[0].SafeFinalized)
+}
+
+func TestMergeTimeline(t *testing.T) {
+	timeline := mergeTimeline([]SnapshotState{
+		testSnapshot("a", "2022-10-01T00:00:01Z", 1, l2Ref(2, 0), l2Ref(1, 0)),
+		testSnapshot("b", "2022-10-01T00:00:02Z", 1, l2Ref(3, 0), l2Ref(1, 0)),
+		testSnapshot("a", "2022-10-01T00:00:03Z", 2, l2Ref(4, 0), l2Ref(3, 0)),
+		testSnapshot("b", "2022-10-01T00:00:04Z", 2, l2Ref(4, 0), l2Ref(3, 1)),
+		testSnapshot("a", "2022-10-01T00:00:05Z", 3, l2Ref(6, 0), l2Ref(5, 0)),
+	})
+	require.Equal(t, []string{"a", "b"}, timeline.Nodes)
+	require.Len(t, timeline.Rows, 3)
+
+	require.Equal(t, uint64(1), timeline.Rows[0].L1Number)
+	require.Empty(t, timeline.Rows[0].Divergences, "heads at different heights are not compared")
+
+	require.Len(t, timeline.Rows[1].Divergences, 1)
+	require.Contains(t, timeline.Rows[1].Divergences[0], "safe head 3")
+
+	require.Nil(t, timeline.Rows[2].Nodes[1], "b did not process L1 block 3")
+	require.Empty(t, timeline.Rows[2].Divergences)
+}
@@ -12,3 +12,10 @@ type OutputResponse struct {
 	StateRoot             common.Hash `json:"stateRoot"`
 	Status                *SyncStatus `json:"syncStatus"`
 }
+
+// SafeHeadResponse is the safe L2 head after the rollup node processed the L1 data up to and including L1Block.
+// L1Block is the last L1 block at or before the requested L1 block at which the safe head was updated.
+type SafeHeadResponse struct {
+	L1Block  BlockID `json:"l1Block"`
+	SafeHead BlockID `json:"safeHead"`
+}
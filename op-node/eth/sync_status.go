@@ -1,5 +1,15 @@
 package eth
 
+// Sequencer states, as reported in the SyncStatus.
+const (
+	// SequencerDisabled is the state of a node that is not configured as sequencer.
+	SequencerDisabled = "disabled"
+	// SequencerStopped is the state of a sequencer in standby, not sequencing new blocks.
+	SequencerStopped = "stopped"
+	// SequencerActive is the state of a sequencer that is sequencing new blocks.
+	SequencerActive = "active"
+)
+
 // SyncStatus is a snapshot of the driver.
 // Values may be zeroed if not yet initialized.
 type SyncStatus struct {
@@ -32,4 +42,6 @@ type SyncStatus struct {
 	// FinalizedL2 points to the L2 block that was derived fully from
 	// finalized L1 information, thus irreversible.
 	FinalizedL2 L2BlockRef `json:"finalized_l2"`
+	// SequencerState is the state of the sequencing loop: "disabled", "stopped" or "active".
+	SequencerState string `json:"sequencer_state"`
 }
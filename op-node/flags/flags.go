@@ -2,9 +2,12 @@ package flags
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/urfave/cli"
+
+	"github.com/ethereum-optimism/optimism/op-node/sources"
 )
 
 // Flags
@@ -15,6 +18,14 @@ func prefixEnvVar(name string) string {
 	return envVarPrefix + name
 }
 
+func rpcProviderKinds() string {
+	kinds := make([]string, len(sources.RPCProviderKinds))
+	for i, k := range sources.RPCProviderKinds {
+		kinds[i] = string(k)
+	}
+	return strings.Join(kinds, ", ")
+}
+
 var (
 	/* Required Flags */
 	L1NodeAddr = cli.StringFlag{
@@ -55,6 +66,29 @@ var (
 		Usage:  "Trust the L1 RPC, sync faster at risk of malicious/buggy RPC providing bad or inconsistent L1 data",
 		EnvVar: prefixEnvVar("L1_TRUST_RPC"),
 	}
+	L1FallbackAddrs = cli.StringSliceFlag{
+		Name:   "l1.fallback",
+		Usage:  "Address of a fallback L1 User JSON-RPC endpoint, to fail over to when the L1 endpoint fails or lags behind. May be repeated, in order of preference.",
+		EnvVar: prefixEnvVar("L1_FALLBACK_RPC"),
+	}
+	L1MaxLag = cli.Uint64Flag{
+		Name:   "l1.max-lag",
+		Usage:  "Number of blocks an L1 endpoint may lag behind the other L1 endpoints, before failing over to another endpoint. Disabled if 0.",
+		Value:  5,
+		EnvVar: prefixEnvVar("L1_MAX_LAG"),
+	}
+	L1CrossCheck = cli.BoolFlag{
+		Name:   "l1.cross-check",
+		Usage:  "Cross-check the hash and receipts root of every L1 block against a fallback L1 endpoint. Requires a fallback endpoint.",
+		EnvVar: prefixEnvVar("L1_CROSS_CHECK"),
+	}
+	L1RPCProviderKind = cli.StringFlag{
+		Name: "l1.rpckind",
+		Usage: "The kind of RPC provider, used to inform optimal transactions receipts fetching, and thus reduce costs. Valid options: " +
+			rpcProviderKinds(),
+		Value:  string(sources.RPCKindBasic),
+		EnvVar: prefixEnvVar("L1_RPC_KIND"),
+	}
 	L2EngineJWTSecret = cli.StringFlag{
 		Name:        "l2.jwt-secret",
 		Usage:       "Path to JWT secret key. Keys are 32 bytes, hex encoded in a file. A new key will be generated if left empty.",
@@ -82,6 +116,48 @@ var (
 		Required: false,
 		Value:    4,
 	}
+	SequencerStoppedFlag = cli.BoolFlag{
+		Name:   "sequencer.stopped",
+		Usage:  "Initialize the sequencer in a stopped state. The sequencer can be started using the admin_startSequencer RPC",
+		EnvVar: prefixEnvVar("SEQUENCER_STOPPED"),
+	}
+	SequencerLeaseFileFlag = cli.StringFlag{
+		Name: "sequencer.lease.file",
+		Usage: "Path of a file, on a file system shared with the standby sequencers, to hold a leader lease in. " +
+			"The sequencer only sequences while it holds the lease. Disabled if empty.",
+		EnvVar: prefixEnvVar("SEQUENCER_LEASE_FILE"),
+	}
+	SequencerLeaseOwnerFlag = cli.StringFlag{
+		Name:   "sequencer.lease.owner",
+		Usage:  "Unique identity of the sequencer in the leader lease. Defaults to the hostname.",
+		EnvVar: prefixEnvVar("SEQUENCER_LEASE_OWNER"),
+	}
+	SequencerLeaseTTLFlag = cli.DurationFlag{
+		Name:   "sequencer.lease.ttl",
+		Usage:  "Duration after which the leader lease expires if it is not renewed. Must be longer than the L2 block time.",
+		EnvVar: prefixEnvVar("SEQUENCER_LEASE_TTL"),
+		Value:  30 * time.Second,
+	}
+	SequencerForcedTxsFlag = cli.StringFlag{
+		Name: "sequencer.forced-txs",
+		Usage: "Path of a file with signed transactions, hex encoded, one per line, to force-include in the next blocks. " +
+			"The file is read again when it is modified. Every transaction is included once, the included transactions " +
+			"are remembered in a file next to it with the .consumed suffix. Disabled if empty.",
+		EnvVar:    prefixEnvVar("SEQUENCER_FORCED_TXS"),
+		TakesFile: true,
+	}
+	SequencerMaxForcedTxsFlag = cli.Uint64Flag{
+		Name:   "sequencer.max-forced-txs",
+		Usage:  "Maximum number of forced transactions to include in a single block.",
+		EnvVar: prefixEnvVar("SEQUENCER_MAX_FORCED_TXS"),
+		Value:  16,
+	}
+	SequencerNoTxPoolL1LagFlag = cli.Uint64Flag{
+		Name: "sequencer.no-txpool-l1-lag",
+		Usage: "Distance in seconds between the L1 origin and the L2 block timestamp at which the sequencer stops " +
+			"including transactions from the tx pool. Disabled if 0.",
+		EnvVar: prefixEnvVar("SEQUENCER_NO_TXPOOL_L1_LAG"),
+	}
 	L1EpochPollIntervalFlag = cli.DurationFlag{
 		Name:     "l1.epoch-poll-interval",
 		Usage:    "Poll interval for retrieving new L1 epoch updates such as safe and finalized block changes. Disabled if 0 or negative.",
@@ -145,6 +221,45 @@ var (
 		Usage:  "Path to the snapshot log file",
 		EnvVar: prefixEnvVar("SNAPSHOT_LOG"),
 	}
+	SafeDBPath = cli.StringFlag{
+		Name: "safedb.path",
+		Usage: "File path used to persist the safe head of every processed L1 block, " +
+			"served by optimism_safeHeadAtL1Block. Disabled if empty.",
+		EnvVar:    prefixEnvVar("SAFEDB_PATH"),
+		TakesFile: true,
+	}
+	SafeDBRetention = cli.Uint64Flag{
+		Name:   "safedb.retention",
+		Usage:  "Number of L1 blocks to retain safe heads for. Older entries are pruned. 0 to never prune.",
+		EnvVar: prefixEnvVar("SAFEDB_RETENTION"),
+		Value:  100_800, // two weeks of 12 second L1 blocks
+	}
+	CheckpointDBPath = cli.StringFlag{
+		Name: "checkpoint.path",
+		Usage: "File path used to persist derivation pipeline checkpoints, " +
+			"to resume derivation from on restart instead of resetting the pipeline. Disabled if empty.",
+		EnvVar:    prefixEnvVar("CHECKPOINT_PATH"),
+		TakesFile: true,
+	}
+	CheckpointInterval = cli.DurationFlag{
+		Name:   "checkpoint.interval",
+		Usage:  "Interval at which derivation pipeline checkpoints are persisted.",
+		EnvVar: prefixEnvVar("CHECKPOINT_INTERVAL"),
+		Value:  time.Minute,
+	}
+	UnsafeDBPath = cli.StringFlag{
+		Name: "unsafedb.path",
+		Usage: "File path used to persist unsafe payloads that are not safe yet, " +
+			"to insert them again on restart instead of waiting for them to be gossiped or derived. Disabled if empty.",
+		EnvVar:    prefixEnvVar("UNSAFEDB_PATH"),
+		TakesFile: true,
+	}
+	UnsafeDBMaxPayloads = cli.Uint64Flag{
+		Name:   "unsafedb.max-payloads",
+		Usage:  "Maximum number of unsafe payloads to persist. The lowest payloads are dropped first.",
+		EnvVar: prefixEnvVar("UNSAFEDB_MAX_PAYLOADS"),
+		Value:  3600, // two hours of 2 second L2 blocks
+	}
 	HeartbeatEnabledFlag = cli.BoolFlag{
 		Name:   "heartbeat.enabled",
 		Usage:  "Enables or disables heartbeating",
@@ -173,9 +288,20 @@ var requiredFlags = []cli.Flag{
 
 var optionalFlags = append([]cli.Flag{
 	L1TrustRPC,
+	L1FallbackAddrs,
+	L1MaxLag,
+	L1CrossCheck,
+	L1RPCProviderKind,
 	L2EngineJWTSecret,
 	VerifierL1Confs,
 	SequencerEnabledFlag,
+	SequencerStoppedFlag,
+	SequencerLeaseFileFlag,
+	SequencerLeaseOwnerFlag,
+	SequencerLeaseTTLFlag,
+	SequencerForcedTxsFlag,
+	SequencerMaxForcedTxsFlag,
+	SequencerNoTxPoolL1LagFlag,
 	SequencerL1Confs,
 	L1EpochPollIntervalFlag,
 	LogLevelFlag,
@@ -189,6 +315,12 @@ var optionalFlags = append([]cli.Flag{
 	PprofAddrFlag,
 	PprofPortFlag,
 	SnapshotLog,
+	SafeDBPath,
+	SafeDBRetention,
+	CheckpointDBPath,
+	CheckpointInterval,
+	UnsafeDBPath,
+	UnsafeDBMaxPayloads,
 	HeartbeatEnabledFlag,
 	HeartbeatMonikerFlag,
 	HeartbeatURLFlag,
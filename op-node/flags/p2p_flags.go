@@ -199,6 +199,95 @@ var (
 		Value:    "",
 		EnvVar:   p2pEnv("SEQUENCER_KEY"),
 	}
+	SequencerSignersFlag = cli.StringFlag{
+		Name: "p2p.sequencer.signers",
+		Usage: "Path to a JSON file with the list of keys that are authorized to sign blocks as sequencer, each with an optional activation window by L2 block number and timestamp. " +
+			"Defaults to the p2p sequencer address of the rollup config.",
+		Required:  false,
+		TakesFile: true,
+		EnvVar:    p2pEnv("SEQUENCER_SIGNERS"),
+	}
+	SyncReqRespFlag = cli.BoolFlag{
+		Name:     "p2p.sync.req-resp",
+		Usage:    "Enables the p2p request/response protocol to fetch missed unsafe blocks from peers, on both the client and server side.",
+		Required: false,
+		EnvVar:   p2pEnv("SYNC_REQ_RESP"),
+	}
+	BanningFlag = cli.BoolTFlag{
+		Name:     "p2p.ban.peers",
+		Usage:    "Enables automatic banning of peers whose gossip peer score drops below the ban threshold. Enabled by default.",
+		Required: false,
+		EnvVar:   p2pEnv("PEER_BANNING"),
+	}
+	BanningThresholdFlag = cli.Float64Flag{
+		Name:     "p2p.ban.threshold",
+		Usage:    "The minimum gossip peer score a peer may have before it is banned, if peer banning is enabled.",
+		Required: false,
+		Value:    -100,
+		EnvVar:   p2pEnv("PEER_BANNING_THRESHOLD"),
+	}
+	BanningDurationFlag = cli.DurationFlag{
+		Name:     "p2p.ban.duration",
+		Usage:    "The duration that peers are banned for, if peer banning is enabled.",
+		Required: false,
+		Value:    1 * time.Hour,
+		EnvVar:   p2pEnv("PEER_BANNING_DURATION"),
+	}
+	SignerEndpointFlag = cli.StringFlag{
+		Name:     "p2p.signer.endpoint",
+		Usage:    "HTTP(S) endpoint of a remote signing service to sign off on p2p application messages as sequencer, instead of a local key.",
+		Required: false,
+		Value:    "",
+		EnvVar:   p2pEnv("SIGNER_ENDPOINT"),
+	}
+	SignerAddressFlag = cli.StringFlag{
+		Name:     "p2p.signer.address",
+		Usage:    "Address of the remote signer key. If set, signatures of the remote signer are verified to recover to this address.",
+		Required: false,
+		Value:    "",
+		EnvVar:   p2pEnv("SIGNER_ADDRESS"),
+	}
+	SignerHeaderFlag = cli.StringSliceFlag{
+		Name:     "p2p.signer.header",
+		Usage:    "HTTP header to add to remote signer requests, in the format 'Name=Value', e.g. to authenticate with a bearer token. May be repeated.",
+		Required: false,
+		EnvVar:   p2pEnv("SIGNER_HEADER"),
+	}
+	SignerTLSCAFlag = cli.StringFlag{
+		Name:      "p2p.signer.tls.ca",
+		Usage:     "Path to the CA certificate to verify the remote signer with. Defaults to the system CAs.",
+		Required:  false,
+		TakesFile: true,
+		EnvVar:    p2pEnv("SIGNER_TLS_CA"),
+	}
+	SignerTLSCertFlag = cli.StringFlag{
+		Name:      "p2p.signer.tls.cert",
+		Usage:     "Path to the client certificate to authenticate with the remote signer (mTLS).",
+		Required:  false,
+		TakesFile: true,
+		EnvVar:    p2pEnv("SIGNER_TLS_CERT"),
+	}
+	SignerTLSKeyFlag = cli.StringFlag{
+		Name:      "p2p.signer.tls.key",
+		Usage:     "Path to the client key to authenticate with the remote signer (mTLS).",
+		Required:  false,
+		TakesFile: true,
+		EnvVar:    p2pEnv("SIGNER_TLS_KEY"),
+	}
+	SignerTimeoutFlag = cli.DurationFlag{
+		Name:     "p2p.signer.timeout",
+		Usage:    "Timeout of a single remote signer request.",
+		Required: false,
+		Value:    5 * time.Second,
+		EnvVar:   p2pEnv("SIGNER_TIMEOUT"),
+	}
+	SignerRetriesFlag = cli.UintFlag{
+		Name:     "p2p.signer.retries",
+		Usage:    "Number of times a failed remote signer request is retried.",
+		Required: false,
+		Value:    3,
+		EnvVar:   p2pEnv("SIGNER_RETRIES"),
+	}
 )
 
 // None of these flags are strictly required.
@@ -229,4 +318,17 @@ var p2pFlags = []cli.Flag{
 	PeerstorePath,
 	DiscoveryPath,
 	SequencerP2PKeyFlag,
+	SequencerSignersFlag,
+	SyncReqRespFlag,
+	BanningFlag,
+	BanningThresholdFlag,
+	BanningDurationFlag,
+	SignerEndpointFlag,
+	SignerAddressFlag,
+	SignerHeaderFlag,
+	SignerTLSCAFlag,
+	SignerTLSCertFlag,
+	SignerTLSKeyFlag,
+	SignerTimeoutFlag,
+	SignerRetriesFlag,
 }
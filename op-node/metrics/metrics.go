@@ -56,6 +56,12 @@ type Metricer interface {
 	IncStreamCount()
 	DecStreamCount()
 	RecordBandwidth(ctx context.Context, bwc *libp2pmetrics.BandwidthCounter)
+	RecordPeerScores(bands map[string]float64)
+	RecordPeerBan()
+	RecordL1EndpointHealth(endpoint string, healthy bool, head uint64)
+	RecordL1EndpointError(endpoint string)
+	RecordL1Failover(endpoint string)
+	RecordL1Inconsistency()
 }
 
 type Metrics struct {
@@ -100,6 +106,15 @@ type Metrics struct {
 	StreamCount       prometheus.Gauge
 	GossipEventsTotal *prometheus.CounterVec
 	BandwidthTotal    *prometheus.GaugeVec
+	PeerScores        *prometheus.GaugeVec
+	PeerBansTotal     prometheus.Counter
+
+	// L1 endpoint metrics
+	L1EndpointHealthy      *prometheus.GaugeVec
+	L1EndpointHead         *prometheus.GaugeVec
+	L1EndpointErrorsTotal  *prometheus.CounterVec
+	L1FailoversTotal       *prometheus.CounterVec
+	L1InconsistenciesTotal prometheus.Counter
 
 	registry *prometheus.Registry
 }
@@ -279,6 +294,59 @@ func NewMetrics(procName string) *Metrics {
 		}, []string{
 			"direction",
 		}),
+		PeerScores: promauto.With(registry).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Subsystem: "p2p",
+			Name:      "peer_scores",
+			Help:      "Count of peers by gossip peer score band",
+		}, []string{
+			"band",
+		}),
+		PeerBansTotal: promauto.With(registry).NewCounter(prometheus.CounterOpts{
+			Namespace: ns,
+			Subsystem: "p2p",
+			Name:      "peer_bans_total",
+			Help:      "Count of peers banned for their low gossip peer score",
+		}),
+
+		L1EndpointHealthy: promauto.With(registry).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Subsystem: "l1",
+			Name:      "endpoint_healthy",
+			Help:      "1 if the L1 endpoint is healthy: responsive, and not lagging behind the other endpoints",
+		}, []string{
+			"endpoint",
+		}),
+		L1EndpointHead: promauto.With(registry).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Subsystem: "l1",
+			Name:      "endpoint_head",
+			Help:      "Latest block number reported by the L1 endpoint",
+		}, []string{
+			"endpoint",
+		}),
+		L1EndpointErrorsTotal: promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Subsystem: "l1",
+			Name:      "endpoint_errors_total",
+			Help:      "Count of failed requests to the L1 endpoint",
+		}, []string{
+			"endpoint",
+		}),
+		L1FailoversTotal: promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Subsystem: "l1",
+			Name:      "failovers_total",
+			Help:      "Count of fail-overs to the L1 endpoint",
+		}, []string{
+			"endpoint",
+		}),
+		L1InconsistenciesTotal: promauto.With(registry).NewCounter(prometheus.CounterOpts{
+			Namespace: ns,
+			Subsystem: "l1",
+			Name:      "inconsistencies_total",
+			Help:      "Count of L1 blocks that L1 endpoints disagree on",
+		}),
 
 		registry: registry,
 	}
@@ -447,6 +515,40 @@ func (m *Metrics) RecordBandwidth(ctx context.Context, bwc *libp2pmetrics.Bandwi
 	}
 }
 
+// RecordPeerScores sets the number of peers in each gossip peer score band.
+func (m *Metrics) RecordPeerScores(bands map[string]float64) {
+	for band, count := range bands {
+		m.PeerScores.WithLabelValues(band).Set(count)
+	}
+}
+
+func (m *Metrics) RecordPeerBan() {
+	m.PeerBansTotal.Inc()
+}
+
+// RecordL1EndpointHealth records the health and the latest head block number of an L1 endpoint.
+func (m *Metrics) RecordL1EndpointHealth(endpoint string, healthy bool, head uint64) {
+	var val float64
+	if healthy {
+		val = 1
+	}
+	m.L1EndpointHealthy.WithLabelValues(endpoint).Set(val)
+	m.L1EndpointHead.WithLabelValues(endpoint).Set(float64(head))
+}
+
+func (m *Metrics) RecordL1EndpointError(endpoint string) {
+	m.L1EndpointErrorsTotal.WithLabelValues(endpoint).Inc()
+}
+
+// RecordL1Failover records a fail-over to the given L1 endpoint.
+func (m *Metrics) RecordL1Failover(endpoint string) {
+	m.L1FailoversTotal.WithLabelValues(endpoint).Inc()
+}
+
+func (m *Metrics) RecordL1Inconsistency() {
+	m.L1InconsistenciesTotal.Inc()
+}
+
 // Serve starts the metrics server on the given hostname and port.
 // The server will be closed when the passed-in context is cancelled.
 func (m *Metrics) Serve(ctx context.Context, hostname string, port int) error {
@@ -538,3 +640,21 @@ func (n *noopMetricer) DecStreamCount() {
 
 func (n *noopMetricer) RecordBandwidth(ctx context.Context, bwc *libp2pmetrics.BandwidthCounter) {
 }
+
+func (n *noopMetricer) RecordPeerScores(bands map[string]float64) {
+}
+
+func (n *noopMetricer) RecordPeerBan() {
+}
+
+func (n *noopMetricer) RecordL1EndpointHealth(endpoint string, healthy bool, head uint64) {
+}
+
+func (n *noopMetricer) RecordL1EndpointError(endpoint string) {
+}
+
+func (n *noopMetricer) RecordL1Failover(endpoint string) {
+}
+
+func (n *noopMetricer) RecordL1Inconsistency() {
+}
@@ -2,6 +2,7 @@ package node
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/ethereum/go-ethereum"
@@ -11,7 +12,9 @@ import (
 
 	"github.com/ethereum-optimism/optimism/op-bindings/predeploys"
 	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/p2p"
 	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
 	"github.com/ethereum-optimism/optimism/op-node/version"
 )
 
@@ -25,6 +28,13 @@ type driverClient interface {
 	SyncStatus(ctx context.Context) (*eth.SyncStatus, error)
 	BlockRefWithStatus(ctx context.Context, num uint64) (eth.L2BlockRef, *eth.SyncStatus, error)
 	ResetDerivationPipeline(context.Context) error
+	StartSequencer(ctx context.Context, blockHash common.Hash) error
+	StopSequencer(context.Context) (common.Hash, error)
+	PipelineState(ctx context.Context) (*derive.PipelineState, error)
+}
+
+type safeDBReader interface {
+	SafeHeadAtL1(l1BlockNum uint64) (l1Block eth.BlockID, safeHead eth.BlockID, err error)
 }
 
 type rpcMetrics interface {
@@ -32,15 +42,22 @@ type rpcMetrics interface {
 	RecordRPCServerRequest(method string) func()
 }
 
+type signerSet interface {
+	Signers() []p2p.AuthorizedSigner
+	SetSigners(signers []p2p.AuthorizedSigner) error
+}
+
 type adminAPI struct {
-	dr driverClient
-	m  rpcMetrics
+	dr      driverClient
+	signers signerSet // nil if p2p is disabled
+	m       rpcMetrics
 }
 
-func NewAdminAPI(dr driverClient, m rpcMetrics) *adminAPI {
+func NewAdminAPI(dr driverClient, signers signerSet, m rpcMetrics) *adminAPI {
 	return &adminAPI{
-		dr: dr,
-		m:  m,
+		dr:      dr,
+		signers: signers,
+		m:       m,
 	}
 }
 
@@ -50,19 +67,62 @@ func (n *adminAPI) ResetDerivationPipeline(ctx context.Context) error {
 	return n.dr.ResetDerivationPipeline(ctx)
 }
 
+// StartSequencer starts sequencing on top of the given unsafe head, which must match the unsafe head of the node.
+func (n *adminAPI) StartSequencer(ctx context.Context, blockHash common.Hash) error {
+	recordDur := n.m.RecordRPCServerRequest("admin_startSequencer")
+	defer recordDur()
+	return n.dr.StartSequencer(ctx, blockHash)
+}
+
+// StopSequencer stops sequencing, and returns the hash of the last unsafe head, to start a standby sequencer at.
+func (n *adminAPI) StopSequencer(ctx context.Context) (common.Hash, error) {
+	recordDur := n.m.RecordRPCServerRequest("admin_stopSequencer")
+	defer recordDur()
+	return n.dr.StopSequencer(ctx)
+}
+
+func (n *adminAPI) PipelineState(ctx context.Context) (*derive.PipelineState, error) {
+	recordDur := n.m.RecordRPCServerRequest("admin_pipelineState")
+	defer recordDur()
+	return n.dr.PipelineState(ctx)
+}
+
+// AuthorizedSigners returns the keys that are authorized to sign blocks as sequencer on the p2p network.
+func (n *adminAPI) AuthorizedSigners(_ context.Context) ([]p2p.AuthorizedSigner, error) {
+	recordDur := n.m.RecordRPCServerRequest("admin_authorizedSigners")
+	defer recordDur()
+	if n.signers == nil {
+		return nil, errors.New("p2p is disabled")
+	}
+	return n.signers.Signers(), nil
+}
+
+// SetAuthorizedSigners replaces the keys that are authorized to sign blocks as sequencer on the p2p network.
+// The update is not persisted: the configured signers are authorized again after a restart.
+func (n *adminAPI) SetAuthorizedSigners(_ context.Context, signers []p2p.AuthorizedSigner) error {
+	recordDur := n.m.RecordRPCServerRequest("admin_setAuthorizedSigners")
+	defer recordDur()
+	if n.signers == nil {
+		return errors.New("p2p is disabled")
+	}
+	return n.signers.SetSigners(signers)
+}
+
 type nodeAPI struct {
 	config *rollup.Config
 	client l2EthClient
 	dr     driverClient
+	safeDB safeDBReader // may be nil, the safe head history is optional
 	log    log.Logger
 	m      rpcMetrics
 }
 
-func NewNodeAPI(config *rollup.Config, l2Client l2EthClient, dr driverClient, log log.Logger, m rpcMetrics) *nodeAPI {
+func NewNodeAPI(config *rollup.Config, l2Client l2EthClient, dr driverClient, safeDB safeDBReader, log log.Logger, m rpcMetrics) *nodeAPI {
 	return &nodeAPI{
 		config: config,
 		client: l2Client,
 		dr:     dr,
+		safeDB: safeDB,
 		log:    log,
 		m:      m,
 	}
@@ -111,6 +171,32 @@ func (n *nodeAPI) OutputAtBlock(ctx context.Context, number hexutil.Uint64) (*et
 	}, nil
 }
 
+// SafeHeadAtL1Block returns the safe L2 head at the time the given L1 block was processed by the derivation pipeline.
+func (n *nodeAPI) SafeHeadAtL1Block(ctx context.Context, number hexutil.Uint64) (*eth.SafeHeadResponse, error) {
+	recordDur := n.m.RecordRPCServerRequest("optimism_safeHeadAtL1Block")
+	defer recordDur()
+
+	if n.safeDB == nil {
+		return nil, errors.New("safe head database is disabled")
+	}
+	status, err := n.dr.SyncStatus(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sync status: %w", err)
+	}
+	// The safe head may still change while the L1 block is being processed.
+	if uint64(number) >= status.CurrentL1.Number {
+		return nil, fmt.Errorf("L1 block %d is not fully processed yet, derivation is at L1 block %d", number, status.CurrentL1.Number)
+	}
+	l1Block, safeHead, err := n.safeDB.SafeHeadAtL1(uint64(number))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get safe head at L1 block %d: %w", number, err)
+	}
+	return &eth.SafeHeadResponse{
+		L1Block:  l1Block,
+		SafeHead: safeHead,
+	}, nil
+}
+
 func (n *nodeAPI) SyncStatus(ctx context.Context) (*eth.SyncStatus, error) {
 	recordDur := n.m.RecordRPCServerRequest("optimism_syncStatus")
 	defer recordDur()
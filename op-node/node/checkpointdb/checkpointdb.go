@@ -0,0 +1,138 @@
+package checkpointdb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/ethdb/leveldb"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/driver"
+)
+
+var ErrClosed = errors.New("checkpoint database is closed")
+
+var checkpointPrefix = []byte("checkpoint")
+
+const (
+	// maxCheckpoints is the number of most recent checkpoints that are retained.
+	// Older checkpoints are a fallback for when the most recent checkpoints were reorged out while the node was down.
+	maxCheckpoints = 4
+
+	dbCache   = 16 // MB
+	dbHandles = 16
+)
+
+// CheckpointDB persists the most recent derivation pipeline checkpoints.
+//
+// Checkpoints are keyed by the inverted L1 origin number, such that iteration returns the most recent checkpoint first.
+type CheckpointDB struct {
+	log log.Logger
+
+	// mu guards db against use after close.
+	mu sync.RWMutex
+	db ethdb.KeyValueStore
+}
+
+var _ driver.CheckpointStore = (*CheckpointDB)(nil)
+
+// NewCheckpointDB opens, or creates, the checkpoint database at the given path.
+func NewCheckpointDB(log log.Logger, path string) (*CheckpointDB, error) {
+	db, err := leveldb.New(path, dbCache, dbHandles, "opnode/checkpointdb/", false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checkpoint database at %q: %w", path, err)
+	}
+	return &CheckpointDB{
+		log: log,
+		db:  db,
+	}, nil
+}
+
+func checkpointKey(l1OriginNum uint64) []byte {
+	key := make([]byte, len(checkpointPrefix)+8)
+	copy(key, checkpointPrefix)
+	binary.BigEndian.PutUint64(key[len(checkpointPrefix):], math.MaxUint64-l1OriginNum)
+	return key
+}
+
+// StoreCheckpoint persists the checkpoint, and prunes all but the most recent checkpoints.
+// A checkpoint replaces any stored checkpoint with a later L1 origin, as those are from a chain that was reset.
+func (d *CheckpointDB) StoreCheckpoint(cp *derive.Checkpoint) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.db == nil {
+		return ErrClosed
+	}
+	data, err := cp.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %w", err)
+	}
+	batch := d.db.NewBatch()
+	iter := d.db.NewIterator(checkpointPrefix, nil)
+	defer iter.Release()
+	retained := 0
+	for iter.Next() {
+		key := iter.Key()
+		num := math.MaxUint64 - binary.BigEndian.Uint64(key[len(checkpointPrefix):])
+		if num < cp.Origin.Number && retained < maxCheckpoints-1 {
+			retained++
+			continue
+		}
+		if err := batch.Delete(common.CopyBytes(key)); err != nil {
+			return err
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return fmt.Errorf("failed to iterate checkpoints: %w", err)
+	}
+	if err := batch.Put(checkpointKey(cp.Origin.Number), data); err != nil {
+		return err
+	}
+	if err := batch.Write(); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	d.log.Debug("Stored derivation checkpoint", "origin", cp.Origin, "safe", cp.SafeHead, "size", len(data))
+	return nil
+}
+
+// Checkpoints returns the stored checkpoints, most recent first.
+func (d *CheckpointDB) Checkpoints() ([]*derive.Checkpoint, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.db == nil {
+		return nil, ErrClosed
+	}
+	iter := d.db.NewIterator(checkpointPrefix, nil)
+	defer iter.Release()
+	var out []*derive.Checkpoint
+	for iter.Next() {
+		var cp derive.Checkpoint
+		if err := cp.UnmarshalBinary(iter.Value()); err != nil {
+			// skip the corrupt checkpoint, older checkpoints may still be used
+			d.log.Warn("Failed to decode derivation checkpoint", "key", common.Bytes2Hex(iter.Key()), "err", err)
+			continue
+		}
+		out = append(out, &cp)
+	}
+	if err := iter.Error(); err != nil {
+		return nil, fmt.Errorf("failed to iterate checkpoints: %w", err)
+	}
+	return out, nil
+}
+
+func (d *CheckpointDB) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.db == nil {
+		return nil
+	}
+	err := d.db.Close()
+	d.db = nil
+	return err
+}
@@ -0,0 +1,83 @@
+package checkpointdb
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+)
+
+func testCheckpoint(l1Num uint64) *derive.Checkpoint {
+	l1 := eth.L1BlockRef{Hash: common.Hash{0x01, byte(l1Num)}, Number: l1Num}
+	safe := eth.L2BlockRef{Hash: common.Hash{0x02, byte(l1Num)}, Number: l1Num * 2, L1Origin: l1.ID()}
+	return &derive.Checkpoint{
+		Origin:     l1,
+		SafeHead:   safe,
+		UnsafeHead: safe,
+		Channels: []derive.ChannelCheckpoint{{
+			ID:        derive.ChannelID{0xff},
+			OpenBlock: l1,
+			Frames:    []derive.FrameCheckpoint{{FrameNumber: 0, Data: []byte{0xaa}}},
+		}},
+		BatchOrigin:   l1,
+		BatchL1Blocks: []eth.L1BlockRef{l1},
+		Batches:       []derive.BatchCheckpoint{{L1InclusionBlock: l1, Batch: []byte{0x00, 0xbb}}},
+	}
+}
+
+func requireOrigins(t *testing.T, db *CheckpointDB, expected ...uint64) {
+	cps, err := db.Checkpoints()
+	require.NoError(t, err)
+	var origins []uint64
+	for _, cp := range cps {
+		origins = append(origins, cp.Origin.Number)
+	}
+	require.Equal(t, expected, origins)
+}
+
+func TestCheckpoints(t *testing.T) {
+	dir := t.TempDir()
+	logger := testlog.Logger(t, log.LvlInfo)
+	db, err := NewCheckpointDB(logger, dir)
+	require.NoError(t, err)
+
+	requireOrigins(t, db)
+	for i := uint64(1); i <= 10; i++ {
+		require.NoError(t, db.StoreCheckpoint(testCheckpoint(i)))
+	}
+	// Only the most recent checkpoints are retained, most recent first
+	requireOrigins(t, db, 10, 9, 8, 7)
+
+	cps, err := db.Checkpoints()
+	require.NoError(t, err)
+	require.Equal(t, testCheckpoint(10), cps[0])
+
+	require.NoError(t, db.Close())
+	_, err = db.Checkpoints()
+	require.ErrorIs(t, err, ErrClosed)
+
+	// Checkpoints are persisted
+	db, err = NewCheckpointDB(logger, dir)
+	require.NoError(t, err)
+	defer db.Close()
+	requireOrigins(t, db, 10, 9, 8, 7)
+}
+
+func TestCheckpointAfterReset(t *testing.T) {
+	db, err := NewCheckpointDB(testlog.Logger(t, log.LvlInfo), t.TempDir())
+	require.NoError(t, err)
+	defer db.Close()
+
+	for i := uint64(1); i <= 5; i++ {
+		require.NoError(t, db.StoreCheckpoint(testCheckpoint(i)))
+	}
+	// A checkpoint at an earlier origin, after a pipeline reset, replaces the later checkpoints.
+	// Checkpoint 1 was already pruned.
+	require.NoError(t, db.StoreCheckpoint(testCheckpoint(3)))
+	requireOrigins(t, db, 3, 2)
+}
@@ -4,8 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/ethereum-optimism/optimism/op-node/client"
+	"github.com/ethereum-optimism/optimism/op-node/metrics"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/sources"
 	"github.com/ethereum/go-ethereum/log"
 	gn "github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/rpc"
@@ -19,7 +23,8 @@ type L2EndpointSetup interface {
 
 type L1EndpointSetup interface {
 	// Setup a RPC client to a L1 node to pull rollup input-data from.
-	Setup(ctx context.Context, log log.Logger) (cl client.RPC, trust bool, err error)
+	// The returned L1 client config is tailored to the RPC, e.g. to the receipt fetching methods it supports.
+	Setup(ctx context.Context, log log.Logger, m metrics.Metricer, rollupCfg *rollup.Config) (cl client.RPC, rpcCfg *sources.L1ClientConfig, err error)
 }
 
 type L2EndpointConfig struct {
@@ -74,30 +79,84 @@ func (p *PreparedL2Endpoints) Setup(ctx context.Context, log log.Logger) (client
 type L1EndpointConfig struct {
 	L1NodeAddr string // Address of L1 User JSON-RPC endpoint to use (eth namespace required)
 
+	// L1FallbackAddrs are the addresses of L1 endpoints to fail over to, in order of preference,
+	// when the L1NodeAddr endpoint fails or lags behind.
+	L1FallbackAddrs []string
+
+	// L1MaxLag is the number of blocks an L1 endpoint may be behind the other endpoints before failing over.
+	L1MaxLag uint64
+
+	// L1CrossCheck: if true, L1 blocks fetched by hash or number are cross-checked against a fallback endpoint,
+	// and fail to fetch when the endpoints disagree on the block hash or receipts root.
+	L1CrossCheck bool
+
 	// L1TrustRPC: if we trust the L1 RPC we do not have to validate L1 response contents like headers
 	// against block hashes, or cached transaction sender addresses.
 	// Thus we can sync faster at the risk of the source RPC being wrong.
 	L1TrustRPC bool
+
+	// L1RPCKind identifies the RPC provider kind that serves the RPC,
+	// to fetch receipts with the most efficient methods that it supports.
+	L1RPCKind sources.RPCProviderKind
 }
 
 var _ L1EndpointSetup = (*L1EndpointConfig)(nil)
 
-func (cfg *L1EndpointConfig) Setup(ctx context.Context, log log.Logger) (cl client.RPC, trust bool, err error) {
+func (cfg *L1EndpointConfig) Check() error {
+	if cfg.L1CrossCheck && len(cfg.L1FallbackAddrs) == 0 {
+		return errors.New("cross-checking L1 blocks requires a fallback L1 endpoint")
+	}
+	if !sources.ValidRPCProviderKind(cfg.L1RPCKind) {
+		return fmt.Errorf("unknown L1 RPC kind: %q", cfg.L1RPCKind)
+	}
+	return nil
+}
+
+func (cfg *L1EndpointConfig) Setup(ctx context.Context, log log.Logger, m metrics.Metricer, rollupCfg *rollup.Config) (cl client.RPC, rpcCfg *sources.L1ClientConfig, err error) {
+	if err := cfg.Check(); err != nil {
+		return nil, nil, err
+	}
+	rpcCfg = sources.L1ClientDefaultConfig(rollupCfg, cfg.L1TrustRPC, cfg.L1RPCKind)
 	l1Node, err := client.NewRPC(ctx, log, cfg.L1NodeAddr)
 	if err != nil {
-		return nil, false, fmt.Errorf("failed to dial L1 address (%s): %w", cfg.L1NodeAddr, err)
+		return nil, nil, fmt.Errorf("failed to dial L1 address (%s): %w", cfg.L1NodeAddr, err)
 	}
-	return l1Node, cfg.L1TrustRPC, nil
+	if len(cfg.L1FallbackAddrs) == 0 {
+		return l1Node, rpcCfg, nil
+	}
+	rpcs := []client.RPC{l1Node}
+	for _, addr := range cfg.L1FallbackAddrs {
+		fallback, err := client.NewRPC(ctx, log, addr)
+		if err != nil {
+			for _, c := range rpcs {
+				c.Close()
+			}
+			return nil, nil, fmt.Errorf("failed to dial fallback L1 address (%s): %w", addr, err)
+		}
+		rpcs = append(rpcs, fallback)
+	}
+	return client.NewFailoverRPC(log, rpcs, &client.FailoverConfig{
+		MaxLag:              cfg.L1MaxLag,
+		CrossCheck:          cfg.L1CrossCheck,
+		HealthCheckInterval: 12 * time.Second,
+		ErrorCooldown:       30 * time.Second,
+	}, m), rpcCfg, nil
 }
 
 // PreparedL1Endpoint enables testing with an in-process pre-setup RPC connection to L1
 type PreparedL1Endpoint struct {
 	Client   client.RPC
 	TrustRPC bool
+	// RPCProviderKind defaults to the basic kind if empty
+	RPCProviderKind sources.RPCProviderKind
 }
 
 var _ L1EndpointSetup = (*PreparedL1Endpoint)(nil)
 
-func (p *PreparedL1Endpoint) Setup(ctx context.Context, log log.Logger) (cl client.RPC, trust bool, err error) {
-	return p.Client, p.TrustRPC, nil
+func (p *PreparedL1Endpoint) Setup(ctx context.Context, log log.Logger, m metrics.Metricer, rollupCfg *rollup.Config) (cl client.RPC, rpcCfg *sources.L1ClientConfig, err error) {
+	kind := p.RPCProviderKind
+	if kind == "" {
+		kind = sources.RPCKindBasic
+	}
+	return p.Client, sources.L1ClientDefaultConfig(rollupCfg, p.TrustRPC, kind), nil
 }
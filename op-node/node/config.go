@@ -34,6 +34,30 @@ type Config struct {
 	// Used to poll the L1 for new finalized or safe blocks
 	L1EpochPollInterval time.Duration
 
+	// SafeDBPath is the path of the database that persists the safe head of every processed L1 block.
+	// The safe head history is disabled if empty.
+	SafeDBPath string
+	// SafeDBRetention is the number of L1 blocks to retain safe heads for, 0 to never prune.
+	SafeDBRetention uint64
+
+	// CheckpointDBPath is the path of the database that persists derivation pipeline checkpoints.
+	// Checkpoints are disabled if empty.
+	CheckpointDBPath string
+
+	// UnsafeDBPath is the path of the database that persists unsafe payloads until they become safe.
+	// Unsafe payloads are not persisted if empty.
+	UnsafeDBPath string
+	// UnsafeDBMaxPayloads is the maximum number of unsafe payloads to persist.
+	UnsafeDBMaxPayloads uint64
+
+	// SequencerLock is the leader lease of the sequencer, to ensure that no two nodes sequence at the same time.
+	// No lease is used if nil.
+	SequencerLock driver.SequencerLock
+
+	// TxInclusionPolicy decides the transactions the sequencer forces into blocks, and when the tx pool is excluded.
+	// The engine picks all transactions from its tx pool if nil.
+	TxInclusionPolicy driver.TxInclusionPolicy
+
 	// Optional
 	Tracer    Tracer
 	Heartbeat HeartbeatConfig
@@ -102,5 +126,11 @@ func (cfg *Config) Check() error {
 			return fmt.Errorf("p2p config error: %w", err)
 		}
 	}
+	if cfg.SequencerLock != nil && !cfg.Driver.SequencerEnabled {
+		return errors.New("sequencer lease is configured, but the sequencer is not enabled")
+	}
+	if cfg.TxInclusionPolicy != nil && !cfg.Driver.SequencerEnabled {
+		return errors.New("tx inclusion policy is configured, but the sequencer is not enabled")
+	}
 	return nil
 }
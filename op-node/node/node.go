@@ -15,7 +15,11 @@ import (
 	"github.com/ethereum-optimism/optimism/op-node/client"
 	"github.com/ethereum-optimism/optimism/op-node/eth"
 	"github.com/ethereum-optimism/optimism/op-node/metrics"
+	"github.com/ethereum-optimism/optimism/op-node/node/checkpointdb"
+	"github.com/ethereum-optimism/optimism/op-node/node/safedb"
+	"github.com/ethereum-optimism/optimism/op-node/node/unsafedb"
 	"github.com/ethereum-optimism/optimism/op-node/p2p"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
 	"github.com/ethereum-optimism/optimism/op-node/rollup/driver"
 	"github.com/ethereum-optimism/optimism/op-node/sources"
 )
@@ -29,13 +33,16 @@ type OpNode struct {
 	l1SafeSub      ethereum.Subscription // Subscription to get L1 safe blocks, a.k.a. justified data (polling)
 	l1FinalizedSub ethereum.Subscription // Subscription to get L1 safe blocks, a.k.a. justified data (polling)
 
-	l1Source  *sources.L1Client     // L1 Client to fetch data from
-	l2Driver  *driver.Driver        // L2 Engine to Sync
-	l2Source  *sources.EngineClient // L2 Execution Engine RPC bindings
-	server    *rpcServer            // RPC server hosting the rollup-node API
-	p2pNode   *p2p.NodeP2P          // P2P node functionality
-	p2pSigner p2p.Signer            // p2p gogssip application messages will be signed with this signer
-	tracer    Tracer                // tracer to get events for testing/debugging
+	l1Source     *sources.L1Client          // L1 Client to fetch data from
+	l2Driver     *driver.Driver             // L2 Engine to Sync
+	l2Source     *sources.EngineClient      // L2 Execution Engine RPC bindings
+	safeDB       *safedb.SafeDB             // Safe head history, may be nil
+	checkpointDB *checkpointdb.CheckpointDB // Derivation checkpoints, may be nil
+	unsafeDB     *unsafedb.UnsafeDB         // Persisted unsafe payloads, may be nil
+	server       *rpcServer                 // RPC server hosting the rollup-node API
+	p2pNode      *p2p.NodeP2P               // P2P node functionality
+	p2pSigner    p2p.Signer                 // p2p gogssip application messages will be signed with this signer
+	tracer       Tracer                     // tracer to get events for testing/debugging
 
 	// some resources cannot be stopped directly, like the p2p gossipsub router (not our design),
 	// and depend on this ctx to be closed.
@@ -46,6 +53,9 @@ type OpNode struct {
 // The OpNode handles incoming gossip
 var _ p2p.GossipIn = (*OpNode)(nil)
 
+// The OpNode syncs missing unsafe blocks from p2p peers, if enabled
+var _ derive.AltSync = (*OpNode)(nil)
+
 func New(ctx context.Context, cfg *Config, log log.Logger, snapshotLog log.Logger, appVersion string, m *metrics.Metrics) (*OpNode, error) {
 	if err := cfg.Check(); err != nil {
 		return nil, err
@@ -106,14 +116,13 @@ func (n *OpNode) initTracer(ctx context.Context, cfg *Config) error {
 }
 
 func (n *OpNode) initL1(ctx context.Context, cfg *Config) error {
-	l1Node, trustRPC, err := cfg.L1.Setup(ctx, n.log)
+	l1Node, rpcCfg, err := cfg.L1.Setup(ctx, n.log, n.metrics, &cfg.Rollup)
 	if err != nil {
 		return fmt.Errorf("failed to get L1 RPC client: %w", err)
 	}
 
 	n.l1Source, err = sources.NewL1Client(
-		client.NewInstrumentedRPC(l1Node, n.metrics), n.log, n.metrics.L1SourceCache,
-		sources.L1ClientDefaultConfig(&cfg.Rollup, trustRPC))
+		client.NewInstrumentedRPC(l1Node, n.metrics), n.log, n.metrics.L1SourceCache, rpcCfg)
 	if err != nil {
 		return fmt.Errorf("failed to create L1 source: %w", err)
 	}
@@ -156,14 +165,50 @@ func (n *OpNode) initL2(ctx context.Context, cfg *Config, snapshotLog log.Logger
 		return fmt.Errorf("failed to create Engine client: %w", err)
 	}
 
-	n.l2Driver = driver.NewDriver(&cfg.Driver, &cfg.Rollup, n.l2Source, n.l1Source, n, n.log, snapshotLog, n.metrics)
+	hooks := driver.Hooks{
+		Pipeline:        derive.PipelineHooks{AltSync: n},
+		SequencerLock:   cfg.SequencerLock,
+		InclusionPolicy: cfg.TxInclusionPolicy,
+	}
+	if cfg.SafeDBPath != "" {
+		n.log.Info("Safe head history enabled", "path", cfg.SafeDBPath, "retention", cfg.SafeDBRetention)
+		n.safeDB, err = safedb.NewSafeDB(n.log.New("module", "safedb"), cfg.SafeDBPath, cfg.SafeDBRetention)
+		if err != nil {
+			return err
+		}
+		hooks.Pipeline.SafeHeadNotifs = n.safeDB
+	}
+
+	if cfg.CheckpointDBPath != "" {
+		n.log.Info("Derivation checkpoints enabled", "path", cfg.CheckpointDBPath, "interval", cfg.Driver.CheckpointInterval)
+		n.checkpointDB, err = checkpointdb.NewCheckpointDB(n.log.New("module", "checkpointdb"), cfg.CheckpointDBPath)
+		if err != nil {
+			return err
+		}
+		hooks.Checkpoints = n.checkpointDB
+	}
+
+	if cfg.UnsafeDBPath != "" {
+		n.log.Info("Unsafe payload persistence enabled", "path", cfg.UnsafeDBPath, "max_payloads", cfg.UnsafeDBMaxPayloads)
+		n.unsafeDB, err = unsafedb.NewUnsafeDB(n.log.New("module", "unsafedb"), cfg.UnsafeDBPath, cfg.UnsafeDBMaxPayloads)
+		if err != nil {
+			return err
+		}
+		hooks.Pipeline.UnsafeStore = n.unsafeDB
+	}
+
+	n.l2Driver = driver.NewDriver(&cfg.Driver, &cfg.Rollup, n.l2Source, n.l1Source, n, hooks, n.log, snapshotLog, n.metrics)
 
 	return nil
 }
 
 func (n *OpNode) initRPCServer(ctx context.Context, cfg *Config) error {
 	var err error
-	n.server, err = newRPCServer(ctx, &cfg.RPC, &cfg.Rollup, n.l2Source.L2Client, n.l2Driver, n.log, n.appVersion, n.metrics)
+	var safeDB safeDBReader
+	if n.safeDB != nil {
+		safeDB = n.safeDB
+	}
+	n.server, err = newRPCServer(ctx, &cfg.RPC, &cfg.Rollup, n.l2Source.L2Client, n.l2Driver, safeDB, n.log, n.appVersion, n.metrics)
 	if err != nil {
 		return err
 	}
@@ -171,7 +216,11 @@ func (n *OpNode) initRPCServer(ctx context.Context, cfg *Config) error {
 		n.server.EnableP2P(p2p.NewP2PAPIBackend(n.p2pNode, n.log, n.metrics))
 	}
 	if cfg.RPC.EnableAdmin {
-		n.server.EnableAdminAPI(NewAdminAPI(n.l2Driver, n.metrics))
+		var signers signerSet
+		if n.p2pNode != nil {
+			signers = n.p2pNode.Signers()
+		}
+		n.server.EnableAdminAPI(NewAdminAPI(n.l2Driver, signers, n.metrics))
 	}
 	n.log.Info("Starting JSON-RPC server")
 	if err := n.server.Start(); err != nil {
@@ -304,6 +353,15 @@ func (n *OpNode) OnUnsafeL2Payload(ctx context.Context, from peer.ID, payload *e
 	return nil
 }
 
+// RequestL2Range requests missing unsafe blocks from p2p peers, if the p2p req/resp sync is enabled.
+func (n *OpNode) RequestL2Range(start, end uint64) error {
+	if n.p2pNode != nil && n.p2pNode.AltSyncEnabled() {
+		return n.p2pNode.RequestL2Range(start, end)
+	}
+	n.log.Debug("ignoring request to sync missing L2 blocks, no sync method available", "start", start, "end", end)
+	return nil
+}
+
 func (n *OpNode) P2P() p2p.Node {
 	return n.p2pNode
 }
@@ -342,6 +400,27 @@ func (n *OpNode) Close() error {
 		}
 	}
 
+	// close the safe head history, after the driver stopped writing to it
+	if n.safeDB != nil {
+		if err := n.safeDB.Close(); err != nil {
+			result = multierror.Append(result, fmt.Errorf("failed to close safe head db: %w", err))
+		}
+	}
+
+	// close the derivation checkpoints, after the driver stopped writing to them
+	if n.checkpointDB != nil {
+		if err := n.checkpointDB.Close(); err != nil {
+			result = multierror.Append(result, fmt.Errorf("failed to close checkpoint db: %w", err))
+		}
+	}
+
+	// close the unsafe payloads, after the driver stopped writing to them
+	if n.unsafeDB != nil {
+		if err := n.unsafeDB.Close(); err != nil {
+			result = multierror.Append(result, fmt.Errorf("failed to close unsafe payload db: %w", err))
+		}
+	}
+
 	// close L2 engine RPC client
 	if n.l2Source != nil {
 		n.l2Source.Close()
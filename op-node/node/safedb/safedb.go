@@ -0,0 +1,196 @@
+package safedb
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/ethdb/leveldb"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+)
+
+var (
+	ErrNotFound = errors.New("safe head not found")
+	ErrClosed   = errors.New("safe head database is closed")
+)
+
+var safeByL1Prefix = []byte("safe_by_l1")
+
+const (
+	// valueSize is the size of an entry: L1 block hash, L2 block hash, L2 block number.
+	valueSize = 32 + 32 + 8
+
+	dbCache   = 16 // MB
+	dbHandles = 16
+)
+
+// SafeDB persists the safe L2 head after processing each L1 block,
+// such that it can be looked up what the safe head was at any (retained) L1 block.
+//
+// Entries are keyed by the inverted L1 block number, such that a forward iteration
+// from any L1 block number finds the last entry at or before that L1 block first.
+type SafeDB struct {
+	log log.Logger
+
+	// retention is the number of L1 blocks to retain entries for, 0 to never prune.
+	retention uint64
+
+	// mu guards db against use after close.
+	mu sync.RWMutex
+	db ethdb.KeyValueStore
+}
+
+var _ derive.SafeHeadListener = (*SafeDB)(nil)
+
+// NewSafeDB opens, or creates, the safe head database at the given path.
+func NewSafeDB(log log.Logger, path string, retention uint64) (*SafeDB, error) {
+	db, err := leveldb.New(path, dbCache, dbHandles, "opnode/safedb/", false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open safe head database at %q: %w", path, err)
+	}
+	return &SafeDB{
+		log:       log,
+		retention: retention,
+		db:        db,
+	}, nil
+}
+
+func safeByL1Key(l1BlockNum uint64) []byte {
+	key := make([]byte, len(safeByL1Prefix)+8)
+	copy(key, safeByL1Prefix)
+	binary.BigEndian.PutUint64(key[len(safeByL1Prefix):], math.MaxUint64-l1BlockNum)
+	return key
+}
+
+func l1NumFromKey(key []byte) uint64 {
+	return math.MaxUint64 - binary.BigEndian.Uint64(key[len(safeByL1Prefix):])
+}
+
+func encodeEntry(l1Block eth.BlockID, safeHead eth.BlockID) []byte {
+	val := make([]byte, valueSize)
+	copy(val[:32], l1Block.Hash[:])
+	copy(val[32:64], safeHead.Hash[:])
+	binary.BigEndian.PutUint64(val[64:], safeHead.Number)
+	return val
+}
+
+func decodeEntry(key []byte, val []byte) (l1Block eth.BlockID, safeHead eth.BlockID, err error) {
+	if len(key) != len(safeByL1Prefix)+8 {
+		return eth.BlockID{}, eth.BlockID{}, fmt.Errorf("invalid key length %d", len(key))
+	}
+	if len(val) != valueSize {
+		return eth.BlockID{}, eth.BlockID{}, fmt.Errorf("invalid value length %d", len(val))
+	}
+	l1Block = eth.BlockID{Hash: common.BytesToHash(val[:32]), Number: l1NumFromKey(key)}
+	safeHead = eth.BlockID{Hash: common.BytesToHash(val[32:64]), Number: binary.BigEndian.Uint64(val[64:])}
+	return l1Block, safeHead, nil
+}
+
+// SafeHeadUpdated records that the given safe head was derived from data up to and including the given L1 block,
+// and prunes the entries of L1 blocks that are older than the retention window.
+func (d *SafeDB) SafeHeadUpdated(safeHead eth.L2BlockRef, l1Block eth.BlockID) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.db == nil {
+		return ErrClosed
+	}
+	batch := d.db.NewBatch()
+	if err := batch.Put(safeByL1Key(l1Block.Number), encodeEntry(l1Block, safeHead.ID())); err != nil {
+		return err
+	}
+	if d.retention > 0 && l1Block.Number > d.retention {
+		// Iterating from the cutoff visits all older entries.
+		iter := d.db.NewIterator(safeByL1Prefix, safeByL1Key(l1Block.Number - d.retention - 1)[len(safeByL1Prefix):])
+		for iter.Next() {
+			if err := batch.Delete(common.CopyBytes(iter.Key())); err != nil {
+				iter.Release()
+				return err
+			}
+		}
+		err := iter.Error()
+		iter.Release()
+		if err != nil {
+			return fmt.Errorf("failed to prune safe head entries: %w", err)
+		}
+	}
+	return batch.Write()
+}
+
+// SafeHeadReset removes the entries that were invalidated by an L1 reorg, as detected on a reset of the
+// derivation pipeline to the given safe head. Entries are checked newest first against the canonical L1 chain,
+// and removed until the first entry of a canonical L1 block: any older entries build on that block and are retained.
+// A reset without an L1 reorg, e.g. on startup, leaves all entries in place;
+// derivation from the reset safe head overwrites them with the same data.
+func (d *SafeDB) SafeHeadReset(ctx context.Context, resetSafeHead eth.L2BlockRef, l1 derive.L1BlockRefByNumberFetcher) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.db == nil {
+		return ErrClosed
+	}
+	batch := d.db.NewBatch()
+	iter := d.db.NewIterator(safeByL1Prefix, nil)
+	defer iter.Release()
+	for iter.Next() {
+		l1Block, _, err := decodeEntry(iter.Key(), iter.Value())
+		if err != nil {
+			return err
+		}
+		ref, err := l1.L1BlockRefByNumber(ctx, l1Block.Number)
+		if err == nil && ref.Hash == l1Block.Hash {
+			break
+		}
+		if err != nil && !errors.Is(err, ethereum.NotFound) {
+			return fmt.Errorf("failed to check L1 block %d of safe head entry: %w", l1Block.Number, err)
+		}
+		if err := batch.Delete(common.CopyBytes(iter.Key())); err != nil {
+			return err
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return fmt.Errorf("failed to iterate safe head entries: %w", err)
+	}
+	if batch.ValueSize() > 0 {
+		d.log.Warn("Removed safe head entries of reorged L1 blocks", "safe_head", resetSafeHead, "l1_origin", resetSafeHead.L1Origin)
+	}
+	return batch.Write()
+}
+
+// SafeHeadAtL1 returns the last safe head that was derived from data up to and including the given L1 block number,
+// along with the L1 block that the safe head was last updated at.
+// ErrNotFound is returned if no entry at or before the L1 block is retained.
+func (d *SafeDB) SafeHeadAtL1(l1BlockNum uint64) (l1Block eth.BlockID, safeHead eth.BlockID, err error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.db == nil {
+		return eth.BlockID{}, eth.BlockID{}, ErrClosed
+	}
+	iter := d.db.NewIterator(safeByL1Prefix, safeByL1Key(l1BlockNum)[len(safeByL1Prefix):])
+	defer iter.Release()
+	if !iter.Next() {
+		if err := iter.Error(); err != nil {
+			return eth.BlockID{}, eth.BlockID{}, err
+		}
+		return eth.BlockID{}, eth.BlockID{}, ErrNotFound
+	}
+	return decodeEntry(iter.Key(), iter.Value())
+}
+
+func (d *SafeDB) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.db == nil {
+		return nil
+	}
+	err := d.db.Close()
+	d.db = nil
+	return err
+}
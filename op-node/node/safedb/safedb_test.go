@@ -0,0 +1,168 @@
+package safedb
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+)
+
+func l1ID(num uint64) eth.BlockID {
+	return eth.BlockID{Hash: common.Hash{0x01, byte(num)}, Number: num}
+}
+
+func safeRef(num uint64, l1Origin uint64) eth.L2BlockRef {
+	return eth.L2BlockRef{Hash: common.Hash{0x02, byte(num)}, Number: num, L1Origin: l1ID(l1Origin)}
+}
+
+func newTestDB(t *testing.T, retention uint64) (*SafeDB, string) {
+	dir := t.TempDir()
+	db, err := NewSafeDB(testlog.Logger(t, log.LvlInfo), dir, retention)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, db.Close()) })
+	return db, dir
+}
+
+func requireSafeHead(t *testing.T, db *SafeDB, query uint64, expectedL1 uint64, expectedSafe eth.L2BlockRef) {
+	l1, safe, err := db.SafeHeadAtL1(query)
+	require.NoError(t, err)
+	require.Equal(t, l1ID(expectedL1), l1)
+	require.Equal(t, expectedSafe.ID(), safe)
+}
+
+func TestSafeHeadAtL1(t *testing.T) {
+	db, _ := newTestDB(t, 0)
+
+	_, _, err := db.SafeHeadAtL1(10)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, db.SafeHeadUpdated(safeRef(1, 9), l1ID(10)))
+	require.NoError(t, db.SafeHeadUpdated(safeRef(2, 9), l1ID(10)))
+	require.NoError(t, db.SafeHeadUpdated(safeRef(5, 11), l1ID(13)))
+
+	_, _, err = db.SafeHeadAtL1(9)
+	require.ErrorIs(t, err, ErrNotFound)
+	requireSafeHead(t, db, 10, 10, safeRef(2, 9))
+	requireSafeHead(t, db, 12, 10, safeRef(2, 9))
+	requireSafeHead(t, db, 13, 13, safeRef(5, 11))
+	requireSafeHead(t, db, 1000, 13, safeRef(5, 11))
+}
+
+func TestSafeHeadPersisted(t *testing.T) {
+	dir := t.TempDir()
+	logger := testlog.Logger(t, log.LvlInfo)
+	db, err := NewSafeDB(logger, dir, 0)
+	require.NoError(t, err)
+	require.NoError(t, db.SafeHeadUpdated(safeRef(3, 9), l1ID(10)))
+	require.NoError(t, db.Close())
+
+	_, _, err = db.SafeHeadAtL1(10)
+	require.ErrorIs(t, err, ErrClosed)
+
+	db, err = NewSafeDB(logger, dir, 0)
+	require.NoError(t, err)
+	defer db.Close()
+	requireSafeHead(t, db, 10, 10, safeRef(3, 9))
+}
+
+func TestSafeHeadPruning(t *testing.T) {
+	db, _ := newTestDB(t, 10)
+
+	for i := uint64(1); i <= 30; i++ {
+		require.NoError(t, db.SafeHeadUpdated(safeRef(i, i), l1ID(i)))
+	}
+	// Entries of L1 blocks older than 30-10 are pruned
+	_, _, err := db.SafeHeadAtL1(19)
+	require.ErrorIs(t, err, ErrNotFound)
+	requireSafeHead(t, db, 20, 20, safeRef(20, 20))
+	requireSafeHead(t, db, 30, 30, safeRef(30, 30))
+}
+
+// testL1Chain is a canonical L1 chain of the blocks identified by l1ID, up to and including head,
+// with the blocks from reorgFrom replaced by blocks with different hashes.
+type testL1Chain struct {
+	head      uint64
+	reorgFrom uint64
+}
+
+func (c *testL1Chain) L1BlockRefByNumber(_ context.Context, num uint64) (eth.L1BlockRef, error) {
+	if num > c.head {
+		return eth.L1BlockRef{}, ethereum.NotFound
+	}
+	id := l1ID(num)
+	if c.reorgFrom != 0 && num >= c.reorgFrom {
+		id.Hash[0] = 0x03
+	}
+	return eth.L1BlockRef{Hash: id.Hash, Number: num}, nil
+}
+
+func TestSafeHeadReset(t *testing.T) {
+	db, _ := newTestDB(t, 0)
+
+	require.NoError(t, db.SafeHeadUpdated(safeRef(2, 9), l1ID(10)))
+	require.NoError(t, db.SafeHeadUpdated(safeRef(4, 10), l1ID(11)))
+	require.NoError(t, db.SafeHeadUpdated(safeRef(6, 11), l1ID(12)))
+	require.NoError(t, db.SafeHeadUpdated(safeRef(8, 12), l1ID(13)))
+
+	// L1 blocks 12 and 13 are reorged out, and the pipeline is reset to a safe head with an L1 origin of block 10
+	require.NoError(t, db.SafeHeadReset(context.Background(), safeRef(3, 10), &testL1Chain{head: 13, reorgFrom: 12}))
+
+	requireSafeHead(t, db, 10, 10, safeRef(2, 9))
+	requireSafeHead(t, db, 11, 11, safeRef(4, 10))
+	// The entries of the reorged L1 blocks are removed
+	requireSafeHead(t, db, 12, 11, safeRef(4, 10))
+	requireSafeHead(t, db, 13, 11, safeRef(4, 10))
+
+	// Derivation continues from the reset safe head
+	require.NoError(t, db.SafeHeadUpdated(safeRef(5, 10), l1ID(12)))
+	requireSafeHead(t, db, 13, 12, safeRef(5, 10))
+
+	// Entries of L1 blocks that are no longer known are removed as well
+	require.NoError(t, db.SafeHeadReset(context.Background(), safeRef(1, 10), &testL1Chain{head: 11}))
+	requireSafeHead(t, db, 13, 11, safeRef(4, 10))
+}
+
+func TestSafeHeadResetWithoutReorg(t *testing.T) {
+	dir := t.TempDir()
+	logger := testlog.Logger(t, log.LvlInfo)
+	db, err := NewSafeDB(logger, dir, 0)
+	require.NoError(t, err)
+	for i := uint64(1); i <= 20; i++ {
+		require.NoError(t, db.SafeHeadUpdated(safeRef(i*2, i), l1ID(i)))
+	}
+	require.NoError(t, db.Close())
+
+	// On restart the pipeline is reset to a safe head a sequencing window back, without any L1 reorg
+	db, err = NewSafeDB(logger, dir, 0)
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, db.SafeHeadReset(context.Background(), safeRef(10, 5), &testL1Chain{head: 20}))
+
+	for i := uint64(1); i <= 20; i++ {
+		requireSafeHead(t, db, i, i, safeRef(i*2, i))
+	}
+}
+
+func TestSafeHeadResetL1Error(t *testing.T) {
+	db, _ := newTestDB(t, 0)
+	require.NoError(t, db.SafeHeadUpdated(safeRef(2, 9), l1ID(10)))
+
+	err := db.SafeHeadReset(context.Background(), safeRef(2, 9), &failingL1{})
+	require.ErrorIs(t, err, errL1Unavailable)
+	requireSafeHead(t, db, 10, 10, safeRef(2, 9))
+}
+
+var errL1Unavailable = errors.New("L1 unavailable")
+
+type failingL1 struct{}
+
+func (failingL1) L1BlockRefByNumber(context.Context, uint64) (eth.L1BlockRef, error) {
+	return eth.L1BlockRef{}, errL1Unavailable
+}
@@ -0,0 +1,163 @@
+// Package sequencerlock implements leader leases, to ensure that no two sequencers sequence at the same time.
+package sequencerlock
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-node/rollup/driver"
+)
+
+// lease is the state of a leader lease: the owner holds the lease until it expires.
+type lease struct {
+	Owner string `json:"owner"`
+	// Expiry is the unix time in milliseconds at which the lease expires.
+	Expiry int64 `json:"expiry"`
+}
+
+// acquire returns the renewed lease if the owner can acquire it, or false if another owner holds the lease.
+func (l lease) acquire(owner string, now time.Time, ttl time.Duration) (lease, bool) {
+	if l.Owner != "" && l.Owner != owner && now.UnixMilli() < l.Expiry {
+		return l, false
+	}
+	return lease{Owner: owner, Expiry: now.Add(ttl).UnixMilli()}, true
+}
+
+// release returns the lease after the owner gives it up.
+func (l lease) release(owner string) lease {
+	if l.Owner != owner {
+		return l
+	}
+	return lease{}
+}
+
+// FileLock is a leader lease stored in a file, shared by sequencers that have access to the same file system.
+// Access to the file is serialized with an advisory file lock.
+type FileLock struct {
+	path  string
+	owner string
+	ttl   time.Duration
+
+	now func() time.Time
+}
+
+var _ driver.SequencerLock = (*FileLock)(nil)
+
+// NewFileLock creates a lock that acquires the lease in the file at the given path for the given owner.
+// The lease expires after ttl, unless it is renewed.
+func NewFileLock(path string, owner string, ttl time.Duration) *FileLock {
+	return &FileLock{path: path, owner: owner, ttl: ttl, now: time.Now}
+}
+
+func (f *FileLock) Acquire(ctx context.Context) (ok bool, err error) {
+	err = f.update(func(l lease) lease {
+		l, ok = l.acquire(f.owner, f.now(), f.ttl)
+		return l
+	})
+	return ok, err
+}
+
+func (f *FileLock) Release(ctx context.Context) error {
+	return f.update(func(l lease) lease {
+		return l.release(f.owner)
+	})
+}
+
+// update reads the lease, and writes back the result of fn if it changed, while holding the file lock.
+func (f *FileLock) update(fn func(l lease) lease) error {
+	file, err := os.OpenFile(f.path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open lease file: %w", err)
+	}
+	defer file.Close()
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX); err != nil {
+		return fmt.Errorf("failed to lock lease file: %w", err)
+	}
+	defer func() {
+		_ = syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+	}()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return fmt.Errorf("failed to read lease file: %w", err)
+	}
+	var current lease
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &current); err != nil {
+			return fmt.Errorf("failed to decode lease file: %w", err)
+		}
+	}
+	next := fn(current)
+	if next == current {
+		return nil
+	}
+	data, err = json.Marshal(next)
+	if err != nil {
+		return err
+	}
+	if err := file.Truncate(0); err != nil {
+		return fmt.Errorf("failed to truncate lease file: %w", err)
+	}
+	if _, err := file.WriteAt(data, 0); err != nil {
+		return fmt.Errorf("failed to write lease file: %w", err)
+	}
+	return file.Sync()
+}
+
+// MemoryLease is a leader lease in memory. It is a local stand-in for a shared lock backend,
+// for sequencers that run in the same process, e.g. in tests.
+type MemoryLease struct {
+	mu    sync.Mutex
+	ttl   time.Duration
+	lease lease
+
+	now func() time.Time
+}
+
+// NewMemoryLease creates a lease in memory, which expires after ttl unless it is renewed.
+func NewMemoryLease(ttl time.Duration) *MemoryLease {
+	return &MemoryLease{ttl: ttl, now: time.Now}
+}
+
+// Lock returns the lock of the given owner on the lease.
+func (m *MemoryLease) Lock(owner string) *MemoryLock {
+	return &MemoryLock{lease: m, owner: owner}
+}
+
+// Owner returns the current owner of the lease, or an empty string if the lease is not held.
+func (m *MemoryLease) Owner() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.now().UnixMilli() >= m.lease.Expiry {
+		return ""
+	}
+	return m.lease.Owner
+}
+
+// MemoryLock is the lock of a single owner on a MemoryLease.
+type MemoryLock struct {
+	lease *MemoryLease
+	owner string
+}
+
+var _ driver.SequencerLock = (*MemoryLock)(nil)
+
+func (m *MemoryLock) Acquire(ctx context.Context) (ok bool, err error) {
+	m.lease.mu.Lock()
+	defer m.lease.mu.Unlock()
+	m.lease.lease, ok = m.lease.lease.acquire(m.owner, m.lease.now(), m.lease.ttl)
+	return ok, nil
+}
+
+func (m *MemoryLock) Release(ctx context.Context) error {
+	m.lease.mu.Lock()
+	defer m.lease.mu.Unlock()
+	m.lease.lease = m.lease.lease.release(m.owner)
+	return nil
+}
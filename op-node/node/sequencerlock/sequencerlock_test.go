@@ -0,0 +1,77 @@
+package sequencerlock
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/rollup/driver"
+)
+
+// testLeader checks that only a single lock holds the lease at a time, as time moves forward.
+func testLeader(t *testing.T, a, b driver.SequencerLock, advance func(d time.Duration)) {
+	ctx := context.Background()
+
+	ok, err := a.Acquire(ctx)
+	require.NoError(t, err)
+	require.True(t, ok, "a acquires the free lease")
+	ok, err = b.Acquire(ctx)
+	require.NoError(t, err)
+	require.False(t, ok, "b cannot acquire the lease held by a")
+
+	advance(20 * time.Second)
+	ok, err = a.Acquire(ctx)
+	require.NoError(t, err)
+	require.True(t, ok, "a renews the lease")
+	advance(20 * time.Second)
+	ok, err = b.Acquire(ctx)
+	require.NoError(t, err)
+	require.False(t, ok, "the renewed lease has not expired yet")
+
+	require.NoError(t, b.Release(ctx), "b cannot release the lease of a")
+	ok, err = b.Acquire(ctx)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	require.NoError(t, a.Release(ctx))
+	ok, err = b.Acquire(ctx)
+	require.NoError(t, err)
+	require.True(t, ok, "b acquires the released lease")
+
+	advance(31 * time.Second)
+	ok, err = a.Acquire(ctx)
+	require.NoError(t, err)
+	require.True(t, ok, "a acquires the expired lease of b")
+}
+
+func TestFileLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lease.json")
+	now := time.Unix(1000, 0)
+	clock := func() time.Time { return now }
+	a := NewFileLock(path, "a", 30*time.Second)
+	a.now = clock
+	b := NewFileLock(path, "b", 30*time.Second)
+	b.now = clock
+	testLeader(t, a, b, func(d time.Duration) { now = now.Add(d) })
+
+	// a corrupt lease file is not silently taken over
+	corrupt := filepath.Join(t.TempDir(), "corrupt.json")
+	require.NoError(t, os.WriteFile(corrupt, []byte("not a lease"), 0o600))
+	_, err := NewFileLock(corrupt, "c", time.Second).Acquire(context.Background())
+	require.ErrorContains(t, err, "failed to decode lease file")
+}
+
+func TestMemoryLease(t *testing.T) {
+	now := time.Unix(1000, 0)
+	lease := NewMemoryLease(30 * time.Second)
+	lease.now = func() time.Time { return now }
+	require.Equal(t, "", lease.Owner())
+	testLeader(t, lease.Lock("a"), lease.Lock("b"), func(d time.Duration) { now = now.Add(d) })
+	require.Equal(t, "a", lease.Owner())
+	now = now.Add(time.Minute)
+	require.Equal(t, "", lease.Owner())
+}
@@ -0,0 +1,273 @@
+package txinclusion
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/driver"
+)
+
+type Config struct {
+	// ForcedTxsPath is the path of a file with signed transactions to force-include, hex encoded, one per line.
+	// Forced transactions are disabled if empty.
+	ForcedTxsPath string
+	// MaxForcedTxsPerBlock is the maximum number of forced transactions to include in a single block.
+	MaxForcedTxsPerBlock uint64
+	// NoTxPoolL1Lag is the distance, in seconds, between the L1 origin and the block timestamp at which
+	// the transactions of the tx pool are excluded from the block. Disabled if 0.
+	NoTxPoolL1Lag uint64
+}
+
+// Policy is the tx inclusion policy of the sequencer that is configured by the operator.
+//
+// Forced transactions are read from a file, which is read again whenever it is modified,
+// so new transactions can be appended while the sequencer is running.
+// Every transaction is included once, in order, and only leaves the queue once the block that includes it is sealed.
+// If the block fails to build for a temporary reason, its transactions are offered again.
+// If the engine rejects the block, its transactions are offered one at a time,
+// and a transaction that is rejected on its own is dropped, so the sequencer does not stall.
+//
+// The hashes of the included and dropped transactions are persisted next to the forced txs file,
+// in a file with the .consumed suffix, so they are not included again after a restart.
+type Policy struct {
+	log log.Logger
+	cfg Config
+
+	modTime time.Time
+	queue   []eth.Data
+	// pending is the number of transactions at the front of the queue that were offered for the block being built.
+	pending int
+	// isolate limits the next block to a single forced transaction, to find the invalid transaction of a rejected block.
+	isolate bool
+	// consumed are the hashes of the forced transactions that were included or dropped.
+	// It only keeps the transactions that are still in the file, so it is bounded by the size of the file.
+	consumed map[common.Hash]struct{}
+}
+
+var _ driver.TxInclusionPolicy = (*Policy)(nil)
+
+// NewPolicy creates the policy, with the forced transactions that were consumed before the last restart.
+func NewPolicy(log log.Logger, cfg Config) (*Policy, error) {
+	p := &Policy{
+		log:      log,
+		cfg:      cfg,
+		consumed: make(map[common.Hash]struct{}),
+	}
+	if cfg.ForcedTxsPath == "" {
+		return p, nil
+	}
+	data, err := os.ReadFile(p.consumedPath())
+	if os.IsNotExist(err) {
+		return p, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read consumed forced txs: %w", err)
+	}
+	for i, line := range bytes.Fields(data) {
+		var h common.Hash
+		if err := h.UnmarshalText(line); err != nil {
+			return nil, fmt.Errorf("consumed forced txs line %d: invalid tx hash: %w", i+1, err)
+		}
+		p.consumed[h] = struct{}{}
+	}
+	return p, nil
+}
+
+// consumedPath is the path of the file with the hashes of the consumed forced transactions, one per line.
+func (p *Policy) consumedPath() string {
+	return p.cfg.ForcedTxsPath + ".consumed"
+}
+
+// consume marks the forced transaction as consumed, and appends it to the consumed file.
+func (p *Policy) consume(h common.Hash) {
+	p.consumed[h] = struct{}{}
+	f, err := os.OpenFile(p.consumedPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		p.log.Error("Failed to persist consumed forced tx", "tx", h, "err", err)
+		return
+	}
+	defer f.Close()
+	if _, err := fmt.Fprintln(f, h.Hex()); err != nil {
+		p.log.Error("Failed to persist consumed forced tx", "tx", h, "err", err)
+	}
+}
+
+// writeConsumed replaces the consumed file with the current set of consumed transactions.
+func (p *Policy) writeConsumed() error {
+	var buf bytes.Buffer
+	for h := range p.consumed {
+		fmt.Fprintln(&buf, h.Hex())
+	}
+	tmp := p.consumedPath() + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, p.consumedPath())
+}
+
+// ReadForcedTxs reads the signed transactions, hex encoded, one per line, from the given file.
+// Empty lines and lines starting with # are ignored. Deposit transactions are not allowed.
+func ReadForcedTxs(path string) ([]eth.Data, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read forced txs file: %w", err)
+	}
+	var txs []eth.Data
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 1<<20) // allow transactions up to the maximum size of a tx in the tx pool, hex encoded
+	for line := 1; scanner.Scan(); line++ {
+		text := string(bytes.TrimSpace(scanner.Bytes()))
+		if text == "" || text[0] == '#' {
+			continue
+		}
+		raw, err := hexutil.Decode(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid hex: %w", line, err)
+		}
+		var tx types.Transaction
+		if err := tx.UnmarshalBinary(raw); err != nil {
+			return nil, fmt.Errorf("line %d: invalid transaction: %w", line, err)
+		}
+		if tx.IsDepositTx() {
+			return nil, fmt.Errorf("line %d: deposit transactions cannot be forced by the sequencer", line)
+		}
+		txs = append(txs, raw)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read forced txs file: %w", err)
+	}
+	return txs, nil
+}
+
+// reload queues the transactions of the forced txs file that have not been consumed before, if the file changed.
+func (p *Policy) reload() error {
+	info, err := os.Stat(p.cfg.ForcedTxsPath)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to check forced txs file: %w", err)
+	}
+	if info.ModTime().Equal(p.modTime) {
+		return nil
+	}
+	txs, err := ReadForcedTxs(p.cfg.ForcedTxsPath)
+	if err != nil {
+		return err
+	}
+	p.modTime = info.ModTime()
+	queued := make(map[common.Hash]struct{}, len(p.queue))
+	for _, tx := range p.queue {
+		queued[txHash(tx)] = struct{}{}
+	}
+	added := 0
+	consumed := make(map[common.Hash]struct{})
+	for _, tx := range txs {
+		h := txHash(tx)
+		if _, ok := p.consumed[h]; ok {
+			consumed[h] = struct{}{}
+			continue
+		}
+		if _, ok := queued[h]; ok {
+			continue
+		}
+		queued[h] = struct{}{}
+		p.queue = append(p.queue, tx)
+		added++
+	}
+	// forget the consumed transactions that were removed from the file
+	if len(consumed) < len(p.consumed) {
+		p.consumed = consumed
+		if err := p.writeConsumed(); err != nil {
+			p.log.Error("Failed to persist consumed forced txs", "err", err)
+		}
+	}
+	p.log.Info("Loaded forced txs", "path", p.cfg.ForcedTxsPath, "added", added, "queued", len(p.queue))
+	return nil
+}
+
+func txHash(tx eth.Data) common.Hash {
+	var out types.Transaction
+	// the transactions are validated when they are read
+	_ = out.UnmarshalBinary(tx)
+	return out.Hash()
+}
+
+// BlockInclusion offers the next forced transactions of the queue,
+// and excludes the tx pool if the L1 origin lags behind too far.
+func (p *Policy) BlockInclusion(ctx context.Context, l2Parent eth.L2BlockRef, l1Origin eth.L1BlockRef, timestamp uint64) (driver.TxInclusion, error) {
+	var out driver.TxInclusion
+	if p.cfg.NoTxPoolL1Lag > 0 && timestamp >= l1Origin.Time+p.cfg.NoTxPoolL1Lag {
+		p.log.Warn("L1 origin lags behind, excluding tx pool", "timestamp", timestamp, "l1_origin", l1Origin, "lag", timestamp-l1Origin.Time)
+		out.NoTxPool = true
+	}
+	if p.cfg.ForcedTxsPath == "" {
+		return out, nil
+	}
+	if err := p.reload(); err != nil {
+		// Keep sequencing with the transactions that were loaded before: a broken file should not stall the chain.
+		p.log.Error("Failed to load forced txs", "path", p.cfg.ForcedTxsPath, "err", err)
+	}
+	n := uint64(len(p.queue))
+	if n > p.cfg.MaxForcedTxsPerBlock {
+		n = p.cfg.MaxForcedTxsPerBlock
+	}
+	if p.isolate && n > 1 {
+		n = 1
+	}
+	p.pending = int(n)
+	out.Transactions = append([]eth.Data(nil), p.queue[:n]...)
+	return out, nil
+}
+
+// BlockSealed removes the offered transactions that the sealed block included from the queue.
+func (p *Policy) BlockSealed(payload *eth.ExecutionPayload) {
+	if p.pending == 0 {
+		return
+	}
+	included := make(map[common.Hash]struct{}, len(payload.Transactions))
+	for _, tx := range payload.Transactions {
+		included[txHash(tx)] = struct{}{}
+	}
+	var remaining []eth.Data
+	for _, tx := range p.queue[:p.pending] {
+		h := txHash(tx)
+		if _, ok := included[h]; ok {
+			p.consume(h)
+		} else {
+			p.log.Warn("Forced tx was not included in sealed block, offering it again", "tx", h, "block", payload.ID())
+			remaining = append(remaining, tx)
+		}
+	}
+	p.queue = append(remaining, p.queue[p.pending:]...)
+	p.pending = 0
+	p.isolate = false
+}
+
+// BlockFailed keeps the offered transactions in the queue.
+// If the block was rejected, the transactions are offered one at a time,
+// and a single transaction that was rejected is dropped.
+func (p *Policy) BlockFailed(invalid bool) {
+	pending := p.pending
+	p.pending = 0
+	if !invalid || pending == 0 {
+		return
+	}
+	if pending > 1 {
+		p.log.Warn("Block with forced txs was rejected, offering them one at a time", "txs", pending)
+		p.isolate = true
+		return
+	}
+	h := txHash(p.queue[0])
+	p.log.Error("Dropping rejected forced tx", "tx", h)
+	p.consume(h)
+	p.queue = p.queue[1:]
+}
@@ -0,0 +1,184 @@
+package txinclusion
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+)
+
+func signedTx(t *testing.T, nonce uint64) hexutil.Bytes {
+	key, err := crypto.HexToECDSA("ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80")
+	require.NoError(t, err)
+	to := common.Address{0x42}
+	tx := types.MustSignNewTx(key, types.LatestSignerForChainID(big.NewInt(901)), &types.DynamicFeeTx{
+		ChainID: big.NewInt(901),
+		Nonce:   nonce,
+		Gas:     21000,
+		To:      &to,
+	})
+	data, err := tx.MarshalBinary()
+	require.NoError(t, err)
+	return data
+}
+
+func writeTxs(t *testing.T, path string, modTime time.Time, lines ...string) {
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o600))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+// sealedBlock returns a payload with a deposit, followed by the given transactions.
+func sealedBlock(t *testing.T, txs ...eth.Data) *eth.ExecutionPayload {
+	deposit, err := types.NewTx(&types.DepositTx{Gas: 21000}).MarshalBinary()
+	require.NoError(t, err)
+	return &eth.ExecutionPayload{Transactions: append([]eth.Data{deposit}, txs...)}
+}
+
+func newTestPolicy(t *testing.T, maxPerBlock uint64) (p *Policy, path string, inclusion func() []eth.Data) {
+	path = filepath.Join(t.TempDir(), "forced.txt")
+	p, inclusion = restartTestPolicy(t, path, maxPerBlock)
+	return p, path, inclusion
+}
+
+// restartTestPolicy creates a policy with the forced txs file at the given path, as after a restart.
+func restartTestPolicy(t *testing.T, path string, maxPerBlock uint64) (p *Policy, inclusion func() []eth.Data) {
+	p, err := NewPolicy(testlog.Logger(t, log.LvlInfo), Config{ForcedTxsPath: path, MaxForcedTxsPerBlock: maxPerBlock})
+	require.NoError(t, err)
+	inclusion = func() []eth.Data {
+		out, err := p.BlockInclusion(context.Background(), eth.L2BlockRef{}, eth.L1BlockRef{Time: 100}, 102)
+		require.NoError(t, err)
+		require.False(t, out.NoTxPool)
+		return out.Transactions
+	}
+	return p, inclusion
+}
+
+func TestPolicyForcedTxs(t *testing.T) {
+	p, path, inclusion := newTestPolicy(t, 2)
+	seal := func(txs []eth.Data) {
+		p.BlockSealed(sealedBlock(t, txs...))
+	}
+
+	// a missing file has no forced txs
+	require.Empty(t, inclusion())
+
+	tx0, tx1, tx2, tx3 := signedTx(t, 0), signedTx(t, 1), signedTx(t, 2), signedTx(t, 3)
+	writeTxs(t, path, time.Unix(1000, 0), "# maintenance", tx0.String(), "", tx1.String(), tx2.String())
+	txs := inclusion()
+	require.Equal(t, []eth.Data{eth.Data(tx0), eth.Data(tx1)}, txs, "txs are included in order, up to the max per block")
+	seal(txs)
+
+	// appended txs are queued, txs that were included before are not included again
+	writeTxs(t, path, time.Unix(1001, 0), tx0.String(), tx1.String(), tx2.String(), tx3.String())
+	txs = inclusion()
+	require.Equal(t, []eth.Data{eth.Data(tx2), eth.Data(tx3)}, txs)
+	seal(txs)
+	require.Empty(t, inclusion())
+
+	// only the txs that are still in the file are remembered
+	require.Len(t, p.consumed, 4)
+	writeTxs(t, path, time.Unix(1002, 0), tx3.String())
+	require.Empty(t, inclusion())
+	require.Len(t, p.consumed, 1)
+
+	// a broken file does not stall sequencing
+	writeTxs(t, path, time.Unix(1003, 0), "0xzz")
+	require.Empty(t, inclusion())
+	_, err := ReadForcedTxs(path)
+	require.ErrorContains(t, err, "line 1: invalid hex")
+
+	deposit, err := types.NewTx(&types.DepositTx{Gas: 21000}).MarshalBinary()
+	require.NoError(t, err)
+	writeTxs(t, path, time.Unix(1004, 0), hexutil.Encode(deposit))
+	_, err = ReadForcedTxs(path)
+	require.ErrorContains(t, err, "deposit transactions cannot be forced")
+}
+
+func TestPolicyBlockFailed(t *testing.T) {
+	p, path, inclusion := newTestPolicy(t, 3)
+	tx0, tx1, tx2, tx3 := eth.Data(signedTx(t, 0)), eth.Data(signedTx(t, 1)), eth.Data(signedTx(t, 2)), eth.Data(signedTx(t, 3))
+	writeTxs(t, path, time.Unix(1000, 0), tx0.String(), tx1.String(), tx2.String(), tx3.String())
+
+	// txs of a block that failed for a temporary reason are offered again
+	require.Equal(t, []eth.Data{tx0, tx1, tx2}, inclusion())
+	p.BlockFailed(false)
+	require.Equal(t, []eth.Data{tx0, tx1, tx2}, inclusion())
+
+	// txs of a rejected block are offered one at a time, until one is sealed
+	p.BlockFailed(true)
+	require.Equal(t, []eth.Data{tx0}, inclusion())
+	p.BlockSealed(sealedBlock(t, tx0))
+	require.Equal(t, []eth.Data{tx1, tx2, tx3}, inclusion())
+
+	// a rejected tx is dropped once it is offered on its own
+	p.BlockFailed(true)
+	require.Equal(t, []eth.Data{tx1}, inclusion())
+	p.BlockFailed(true)
+	require.Equal(t, []eth.Data{tx2}, inclusion())
+
+	// txs that the sealed block did not include stay queued
+	p.BlockSealed(sealedBlock(t))
+	require.Equal(t, []eth.Data{tx2, tx3}, inclusion())
+	p.BlockSealed(sealedBlock(t, tx2, tx3))
+	require.Empty(t, inclusion())
+
+	// the dropped tx is not queued again when the file changes
+	writeTxs(t, path, time.Unix(1001, 0), tx0.String(), tx1.String(), tx2.String(), tx3.String())
+	require.Empty(t, inclusion())
+}
+
+func TestPolicyRestart(t *testing.T) {
+	p, path, inclusion := newTestPolicy(t, 2)
+	tx0, tx1, tx2, tx3 := eth.Data(signedTx(t, 0)), eth.Data(signedTx(t, 1)), eth.Data(signedTx(t, 2)), eth.Data(signedTx(t, 3))
+	writeTxs(t, path, time.Unix(1000, 0), tx0.String(), tx1.String(), tx2.String(), tx3.String())
+	require.Equal(t, []eth.Data{tx0, tx1}, inclusion())
+	p.BlockSealed(sealedBlock(t, tx0))
+	require.Equal(t, []eth.Data{tx1, tx2}, inclusion())
+	p.BlockFailed(true)
+	require.Equal(t, []eth.Data{tx1}, inclusion())
+	p.BlockFailed(true)
+
+	// the included and dropped txs are not offered again after a restart
+	p, inclusion = restartTestPolicy(t, path, 2)
+	require.Equal(t, []eth.Data{tx2, tx3}, inclusion())
+	p.BlockSealed(sealedBlock(t, tx2))
+
+	// txs that were removed from the file are forgotten
+	writeTxs(t, path, time.Unix(1001, 0), tx2.String(), tx3.String())
+	require.Equal(t, []eth.Data{tx3}, inclusion())
+	p, inclusion = restartTestPolicy(t, path, 2)
+	require.Equal(t, []eth.Data{tx3}, inclusion())
+	require.Len(t, p.consumed, 1)
+
+	require.NoError(t, os.WriteFile(path+".consumed", []byte("0xzz\n"), 0o600))
+	_, err := NewPolicy(testlog.Logger(t, log.LvlInfo), Config{ForcedTxsPath: path, MaxForcedTxsPerBlock: 2})
+	require.ErrorContains(t, err, "invalid tx hash")
+}
+
+func TestPolicyNoTxPoolL1Lag(t *testing.T) {
+	ctx := context.Background()
+	p, err := NewPolicy(testlog.Logger(t, log.LvlInfo), Config{NoTxPoolL1Lag: 60})
+	require.NoError(t, err)
+	origin := eth.L1BlockRef{Time: 100}
+
+	out, err := p.BlockInclusion(ctx, eth.L2BlockRef{}, origin, 158)
+	require.NoError(t, err)
+	require.False(t, out.NoTxPool)
+	out, err = p.BlockInclusion(ctx, eth.L2BlockRef{}, origin, 160)
+	require.NoError(t, err)
+	require.True(t, out.NoTxPool, "tx pool is excluded once the L1 origin lags behind")
+	require.Empty(t, out.Transactions)
+}
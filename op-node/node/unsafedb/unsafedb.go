@@ -0,0 +1,202 @@
+package unsafedb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/ethdb/leveldb"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+)
+
+var ErrClosed = errors.New("unsafe payload database is closed")
+
+var payloadPrefix = []byte("payload")
+
+const (
+	dbCache   = 16 // MB
+	dbHandles = 16
+)
+
+// UnsafeDB persists unsafe payloads that have not become safe yet,
+// such that a restarted node can insert them again without waiting for them to be gossiped or derived.
+//
+// Payloads are keyed by block number and block hash, such that iteration returns the lowest payload first.
+// The database is bounded: when full, the lowest payloads are dropped first.
+type UnsafeDB struct {
+	log log.Logger
+
+	maxPayloads uint64
+
+	// mu guards db against use after close, and keeps count consistent with the stored payloads.
+	mu    sync.RWMutex
+	db    ethdb.KeyValueStore
+	count uint64
+}
+
+var _ derive.UnsafePayloadStore = (*UnsafeDB)(nil)
+
+// NewUnsafeDB opens, or creates, the unsafe payload database at the given path.
+// At most maxPayloads payloads are retained.
+func NewUnsafeDB(log log.Logger, path string, maxPayloads uint64) (*UnsafeDB, error) {
+	if maxPayloads == 0 {
+		return nil, errors.New("unsafe payload database must retain at least one payload")
+	}
+	db, err := leveldb.New(path, dbCache, dbHandles, "opnode/unsafedb/", false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open unsafe payload database at %q: %w", path, err)
+	}
+	iter := db.NewIterator(payloadPrefix, nil)
+	defer iter.Release()
+	count := uint64(0)
+	for iter.Next() {
+		count++
+	}
+	if err := iter.Error(); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to iterate unsafe payloads: %w", err)
+	}
+	return &UnsafeDB{
+		log:         log,
+		maxPayloads: maxPayloads,
+		db:          db,
+		count:       count,
+	}, nil
+}
+
+func payloadKey(num uint64, hash common.Hash) []byte {
+	key := make([]byte, len(payloadPrefix)+8+32)
+	copy(key, payloadPrefix)
+	binary.BigEndian.PutUint64(key[len(payloadPrefix):], num)
+	copy(key[len(payloadPrefix)+8:], hash[:])
+	return key
+}
+
+func keyNumber(key []byte) uint64 {
+	return binary.BigEndian.Uint64(key[len(payloadPrefix):])
+}
+
+// StorePayload persists the payload, unless it is already stored.
+// If the database is full, the lowest payloads are pruned to make room.
+func (d *UnsafeDB) StorePayload(payload *eth.ExecutionPayload) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.db == nil {
+		return ErrClosed
+	}
+	key := payloadKey(uint64(payload.BlockNumber), payload.BlockHash)
+	if ok, err := d.db.Has(key); err != nil {
+		return fmt.Errorf("failed to check for unsafe payload: %w", err)
+	} else if ok {
+		return nil
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode unsafe payload: %w", err)
+	}
+	batch := d.db.NewBatch()
+	count := d.count + 1
+	if count > d.maxPayloads {
+		iter := d.db.NewIterator(payloadPrefix, nil)
+		defer iter.Release()
+		for count > d.maxPayloads && iter.Next() {
+			// the new payload itself is dropped if it is lower than all the stored payloads
+			if bytes.Compare(iter.Key(), key) > 0 {
+				d.log.Debug("Unsafe payload database is full, not storing payload", "id", payload.ID())
+				return nil
+			}
+			if err := batch.Delete(common.CopyBytes(iter.Key())); err != nil {
+				return err
+			}
+			count--
+		}
+		if err := iter.Error(); err != nil {
+			return fmt.Errorf("failed to iterate unsafe payloads: %w", err)
+		}
+	}
+	if err := batch.Put(key, data); err != nil {
+		return err
+	}
+	if err := batch.Write(); err != nil {
+		return fmt.Errorf("failed to write unsafe payload: %w", err)
+	}
+	d.count = count
+	d.log.Debug("Stored unsafe payload", "id", payload.ID(), "size", len(data), "count", count)
+	return nil
+}
+
+// PrunePayloads removes all payloads with a block number up to and including num,
+// as these are safe, or conflict with the safe chain.
+func (d *UnsafeDB) PrunePayloads(num uint64) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.db == nil {
+		return ErrClosed
+	}
+	batch := d.db.NewBatch()
+	iter := d.db.NewIterator(payloadPrefix, nil)
+	defer iter.Release()
+	pruned := uint64(0)
+	for iter.Next() && keyNumber(iter.Key()) <= num {
+		if err := batch.Delete(common.CopyBytes(iter.Key())); err != nil {
+			return err
+		}
+		pruned++
+	}
+	if err := iter.Error(); err != nil {
+		return fmt.Errorf("failed to iterate unsafe payloads: %w", err)
+	}
+	if pruned == 0 {
+		return nil
+	}
+	if err := batch.Write(); err != nil {
+		return fmt.Errorf("failed to prune unsafe payloads: %w", err)
+	}
+	d.count -= pruned
+	d.log.Debug("Pruned unsafe payloads", "safe", num, "pruned", pruned, "count", d.count)
+	return nil
+}
+
+// Payloads returns the stored payloads, lowest block number first.
+func (d *UnsafeDB) Payloads() ([]*eth.ExecutionPayload, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.db == nil {
+		return nil, ErrClosed
+	}
+	iter := d.db.NewIterator(payloadPrefix, nil)
+	defer iter.Release()
+	var out []*eth.ExecutionPayload
+	for iter.Next() {
+		var payload eth.ExecutionPayload
+		if err := json.Unmarshal(iter.Value(), &payload); err != nil {
+			// skip the corrupt payload, it can still be received through gossip or derived from L1
+			d.log.Warn("Failed to decode unsafe payload", "key", common.Bytes2Hex(iter.Key()), "err", err)
+			continue
+		}
+		out = append(out, &payload)
+	}
+	if err := iter.Error(); err != nil {
+		return nil, fmt.Errorf("failed to iterate unsafe payloads: %w", err)
+	}
+	return out, nil
+}
+
+func (d *UnsafeDB) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.db == nil {
+		return nil
+	}
+	err := d.db.Close()
+	d.db = nil
+	return err
+}
@@ -0,0 +1,77 @@
+package unsafedb
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+)
+
+func testPayload(num uint64, fork byte) *eth.ExecutionPayload {
+	return &eth.ExecutionPayload{
+		ParentHash:    common.Hash{0x01, byte(num - 1)},
+		BlockNumber:   eth.Uint64Quantity(num),
+		BlockHash:     common.Hash{0x01, byte(num), fork},
+		Timestamp:     eth.Uint64Quantity(num * 2),
+		ExtraData:     eth.BytesMax32{},
+		BaseFeePerGas: eth.Uint256Quantity{},
+		Transactions:  []eth.Data{hexutil.Bytes{0xaa, byte(num)}},
+	}
+}
+
+func requireNumbers(t *testing.T, db *UnsafeDB, expected ...uint64) {
+	payloads, err := db.Payloads()
+	require.NoError(t, err)
+	var nums []uint64
+	for _, p := range payloads {
+		nums = append(nums, uint64(p.BlockNumber))
+	}
+	require.Equal(t, expected, nums)
+}
+
+func TestUnsafePayloads(t *testing.T) {
+	dir := t.TempDir()
+	logger := testlog.Logger(t, log.LvlInfo)
+	db, err := NewUnsafeDB(logger, dir, 4)
+	require.NoError(t, err)
+
+	requireNumbers(t, db)
+	for _, num := range []uint64{3, 1, 2, 2} {
+		require.NoError(t, db.StorePayload(testPayload(num, 0)))
+	}
+	// payloads are returned in order, without duplicates
+	requireNumbers(t, db, 1, 2, 3)
+	payloads, err := db.Payloads()
+	require.NoError(t, err)
+	require.Equal(t, testPayload(1, 0), payloads[0])
+
+	// payloads of different blocks at the same height are all kept
+	require.NoError(t, db.StorePayload(testPayload(3, 1)))
+	requireNumbers(t, db, 1, 2, 3, 3)
+
+	// the lowest payloads are dropped when full
+	require.NoError(t, db.StorePayload(testPayload(4, 0)))
+	requireNumbers(t, db, 2, 3, 3, 4)
+	require.NoError(t, db.StorePayload(testPayload(1, 0)))
+	requireNumbers(t, db, 2, 3, 3, 4)
+
+	require.NoError(t, db.PrunePayloads(3))
+	requireNumbers(t, db, 4)
+
+	// the payloads persist across restarts
+	require.NoError(t, db.Close())
+	_, err = db.Payloads()
+	require.ErrorIs(t, err, ErrClosed)
+	db, err = NewUnsafeDB(logger, dir, 2)
+	require.NoError(t, err)
+	requireNumbers(t, db, 4)
+	require.NoError(t, db.StorePayload(testPayload(5, 0)))
+	require.NoError(t, db.StorePayload(testPayload(6, 0)))
+	requireNumbers(t, db, 5, 6)
+	require.NoError(t, db.Close())
+}
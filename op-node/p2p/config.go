@@ -45,6 +45,21 @@ type SetupP2P interface {
 	// Discovery creates a disc-v5 service. Returns nil, nil, nil if discovery is disabled.
 	Discovery(log log.Logger, rollupCfg *rollup.Config, tcpPort uint16) (*enode.LocalNode, *discover.UDPv5, error)
 	TargetPeers() uint
+	// ReqRespSyncEnabled enables the request/response protocol to sync missed unsafe blocks with peers.
+	ReqRespSyncEnabled() bool
+	// BanningConfig returns the automated peer banning settings, nil if peers are not banned automatically.
+	BanningConfig() *BanConfig
+	// AuthorizedSigners returns the keys that are authorized to sign blocks as sequencer.
+	// If empty, the P2PSequencerAddress of the rollup config is authorized.
+	AuthorizedSigners() []AuthorizedSigner
+}
+
+// BanConfig configures the automated banning of peers with a low gossip peer score.
+type BanConfig struct {
+	// Peers with a peer score below the threshold are banned.
+	Threshold float64
+	// Duration of a ban, after which the peer is unbanned again.
+	Duration time.Duration
 }
 
 // Config sets up a p2p host and discv5 service from configuration.
@@ -55,6 +70,15 @@ type Config struct {
 	DisableP2P  bool
 	NoDiscovery bool
 
+	// Enable the request/response protocol to sync missed unsafe blocks with peers.
+	EnableReqRespSync bool
+
+	// Banning of peers with a low peer score, nil if disabled.
+	Banning *BanConfig
+
+	// Keys that are authorized to sign blocks as sequencer, the rollup config sequencer address if empty.
+	Signers []AuthorizedSigner
+
 	ListenIP      net.IP
 	ListenTCPPort uint16
 
@@ -170,6 +194,22 @@ func NewConfig(ctx *cli.Context) (*Config, error) {
 	conf.ConnGater = DefaultConnGater
 	conf.ConnMngr = DefaultConnManager
 
+	conf.EnableReqRespSync = ctx.GlobalBool(flags.SyncReqRespFlag.Name)
+
+	if path := ctx.GlobalString(flags.SequencerSignersFlag.Name); path != "" {
+		conf.Signers, err = LoadAuthorizedSigners(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load authorized sequencer signers: %w", err)
+		}
+	}
+
+	if ctx.GlobalBoolT(flags.BanningFlag.Name) {
+		conf.Banning = &BanConfig{
+			Threshold: ctx.GlobalFloat64(flags.BanningThresholdFlag.Name),
+			Duration:  ctx.GlobalDuration(flags.BanningDurationFlag.Name),
+		}
+	}
+
 	return conf, nil
 }
 
@@ -177,6 +217,18 @@ func (conf *Config) TargetPeers() uint {
 	return conf.PeersLo
 }
 
+func (conf *Config) ReqRespSyncEnabled() bool {
+	return conf.EnableReqRespSync
+}
+
+func (conf *Config) BanningConfig() *BanConfig {
+	return conf.Banning
+}
+
+func (conf *Config) AuthorizedSigners() []AuthorizedSigner {
+	return conf.Signers
+}
+
 func (conf *Config) loadListenOpts(ctx *cli.Context) error {
 	listenIP := ctx.GlobalString(flags.ListenIP.Name)
 	if listenIP != "" { // optional
@@ -447,5 +499,13 @@ func (conf *Config) Check() error {
 	if conf.ConnGater == nil {
 		return errors.New("need a connection gater")
 	}
+	if conf.Banning != nil {
+		if conf.Banning.Threshold >= PeerScoreThresholds.GraylistThreshold {
+			return fmt.Errorf("peer ban threshold %f must be below the graylist threshold %f", conf.Banning.Threshold, PeerScoreThresholds.GraylistThreshold)
+		}
+		if conf.Banning.Duration <= 0 {
+			return fmt.Errorf("peer ban duration must be positive, got %s", conf.Banning.Duration)
+		}
+	}
 	return nil
 }
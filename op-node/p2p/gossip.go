@@ -119,12 +119,13 @@ func BuildGlobalGossipParams(cfg *rollup.Config) pubsub.GossipSubParams {
 	return params
 }
 
-func NewGossipSub(p2pCtx context.Context, h host.Host, cfg *rollup.Config, m GossipMetricer) (*pubsub.PubSub, error) {
+// NewGossipSub creates the gossip router. The scores book is registered as peer score inspector, if not nil.
+func NewGossipSub(p2pCtx context.Context, h host.Host, cfg *rollup.Config, m GossipMetricer, scores *PeerScores) (*pubsub.PubSub, error) {
 	denyList, err := pubsub.NewTimeCachedBlacklist(30 * time.Second)
 	if err != nil {
 		return nil, err
 	}
-	return pubsub.NewGossipSub(p2pCtx, h,
+	opts := []pubsub.Option{
 		pubsub.WithMaxMessageSize(maxGossipSize),
 		pubsub.WithMessageIdFn(BuildMsgIdFn(cfg)),
 		pubsub.WithNoAuthor(),
@@ -137,8 +138,13 @@ func NewGossipSub(p2pCtx context.Context, h host.Host, cfg *rollup.Config, m Gos
 		pubsub.WithBlacklist(denyList),
 		pubsub.WithGossipSubParams(BuildGlobalGossipParams(cfg)),
 		pubsub.WithEventTracer(&gossipTracer{m: m}),
-	)
-	// TODO: pubsub.WithPeerScoreInspect(inspect, InspectInterval) to update peerstore scores with gossip scores
+		pubsub.WithPeerScore(PeerScoreParams(cfg), &PeerScoreThresholds),
+	}
+	if scores != nil {
+		// must be passed after the WithPeerScore option
+		opts = append(opts, pubsub.WithPeerScoreInspect(pubsub.ExtendedPeerScoreInspectFn(scores.Inspect), peerScoreInspectInterval))
+	}
+	return pubsub.NewGossipSub(p2pCtx, h, opts...)
 }
 
 func validationResultString(v pubsub.ValidationResult) string {
@@ -191,7 +197,9 @@ func (sb *seenBlocks) markSeen(h common.Hash) {
 	sb.blockHashes = append(sb.blockHashes, h)
 }
 
-func BuildBlocksValidator(log log.Logger, cfg *rollup.Config) pubsub.ValidatorEx {
+// BuildBlocksValidator builds the validator of the blocks topic.
+// Blocks must be signed by one of the signers that is authorized at the height and time of the block.
+func BuildBlocksValidator(log log.Logger, cfg *rollup.Config, signers *SignerSet) pubsub.ValidatorEx {
 
 	// Seen block hashes per block height
 	// uint64 -> *seenBlocks
@@ -228,25 +236,12 @@ func BuildBlocksValidator(log log.Logger, cfg *rollup.Config) pubsub.ValidatorEx
 		// message starts with compact-encoding secp256k1 encoded signature
 		signatureBytes, payloadBytes := data[:65], data[65:]
 
-		// [REJECT] if the signature by the sequencer is not valid
-		signingHash, err := BlockSigningHash(cfg, payloadBytes)
-		if err != nil {
-			log.Warn("failed to compute block signing hash", "err", err, "peer", id)
-			return pubsub.ValidationReject
-		}
-
-		pub, err := crypto.SigToPub(signingHash[:], signatureBytes)
+		// [REJECT] if the signature is not valid
+		author, err := recoverBlockSigner(cfg, signatureBytes, payloadBytes)
 		if err != nil {
 			log.Warn("invalid block signature", "err", err, "peer", id)
 			return pubsub.ValidationReject
 		}
-		addr := crypto.PubkeyToAddress(*pub)
-
-		// TODO: in the future we can support multiple valid p2p addresses.
-		if addr != cfg.P2PSequencerAddress {
-			log.Warn("unexpected block author", "err", err, "peer", id)
-			return pubsub.ValidationReject
-		}
 
 		// [REJECT] if the block encoding is not valid
 		var payload eth.ExecutionPayload
@@ -255,6 +250,12 @@ func BuildBlocksValidator(log log.Logger, cfg *rollup.Config) pubsub.ValidatorEx
 			return pubsub.ValidationReject
 		}
 
+		// [REJECT] if the signer is not an authorized sequencer key at the block height and time
+		if err := signers.CheckAuthorized(author, &payload); err != nil {
+			log.Warn("invalid block signer", "err", err, "peer", id)
+			return pubsub.ValidationReject
+		}
+
 		// rounding down to seconds is fine here.
 		now := uint64(time.Now().Unix())
 
@@ -302,6 +303,32 @@ func BuildBlocksValidator(log log.Logger, cfg *rollup.Config) pubsub.ValidatorEx
 	}
 }
 
+// recoverBlockSigner recovers the address that signed the encoded payload.
+// The caller is responsible for checking that the signer is authorized, see SignerSet.
+func recoverBlockSigner(cfg *rollup.Config, signatureBytes []byte, payloadBytes []byte) (common.Address, error) {
+	signingHash, err := BlockSigningHash(cfg, payloadBytes)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to compute block signing hash: %w", err)
+	}
+	pub, err := crypto.SigToPub(signingHash[:], signatureBytes)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// cacheSignedBlocks wraps a blocks validator, to remember the signed messages of accepted blocks,
+// including the blocks we publish ourselves, so they can be served to peers that missed them.
+func cacheSignedBlocks(blocks *SignedBlocks, fn pubsub.ValidatorEx) pubsub.ValidatorEx {
+	return func(ctx context.Context, id peer.ID, message *pubsub.Message) pubsub.ValidationResult {
+		res := fn(ctx, id, message)
+		if res == pubsub.ValidationAccept {
+			blocks.Add(uint64(message.ValidatorData.(*eth.ExecutionPayload).BlockNumber), message.Data)
+		}
+		return res
+	}
+}
+
 type GossipIn interface {
 	OnUnsafeL2Payload(ctx context.Context, from peer.ID, msg *eth.ExecutionPayload) error
 }
@@ -359,8 +386,12 @@ func (p *publisher) Close() error {
 	return p.blocksTopic.Close()
 }
 
-func JoinGossip(p2pCtx context.Context, self peer.ID, ps *pubsub.PubSub, log log.Logger, cfg *rollup.Config, gossipIn GossipIn) (GossipOut, error) {
-	val := logValidationResult(self, "validated block", log, BuildBlocksValidator(log, cfg))
+// JoinGossip joins the blocks gossip topic. The signed messages of accepted blocks are remembered in blocks, if not nil.
+func JoinGossip(p2pCtx context.Context, self peer.ID, ps *pubsub.PubSub, log log.Logger, cfg *rollup.Config, signers *SignerSet, gossipIn GossipIn, blocks *SignedBlocks) (GossipOut, error) {
+	val := logValidationResult(self, "validated block", log, BuildBlocksValidator(log, cfg, signers))
+	if blocks != nil {
+		val = cacheSignedBlocks(blocks, val)
+	}
 	blocksTopicName := blocksTopicV1(cfg)
 	err := ps.RegisterTopicValidator(blocksTopicName,
 		val,
@@ -379,11 +410,7 @@ func JoinGossip(p2pCtx context.Context, self peer.ID, ps *pubsub.PubSub, log log
 	}
 	go LogTopicEvents(p2pCtx, log.New("topic", "blocks"), blocksTopicEvents)
 
-	// TODO: block topic scoring parameters
-	// See prysm: https://github.com/prysmaticlabs/prysm/blob/develop/beacon-chain/p2p/gossip_scoring_params.go
-	// And research from lighthouse: https://gist.github.com/blacktemplar/5c1862cb3f0e32a1a7fb0b25e79e6e2c
-	// And docs: https://github.com/libp2p/specs/blob/master/pubsub/gossipsub/gossipsub-v1.1.md#topic-parameter-calculation-and-decay
-	//err := blocksTopic.SetScoreParams(&pubsub.TopicScoreParams{......})
+	// Note: the blocks topic scoring parameters are registered with the peer score params, see BlocksTopicScoreParams.
 
 	subscription, err := blocksTopic.Subscribe()
 	if err != nil {
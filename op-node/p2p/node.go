@@ -30,6 +30,10 @@ type NodeP2P struct {
 	dv5Udp   *discover.UDPv5  // p2p discovery service
 	gs       *pubsub.PubSub   // p2p gossip router
 	gsOut    GossipOut        // p2p gossip application interface for publishing
+	signers  *SignerSet       // keys that are authorized to sign blocks as sequencer
+	scores   *PeerScores      // latest gossip peer scores, and automated banning of peers with a low score
+	blocks   *SignedBlocks    // recent signed blocks, to serve to peers that missed them
+	syncCl   *SyncClient      // p2p req/resp sync client, to fetch blocks we missed on gossip
 }
 
 func NewNodeP2P(resourcesCtx context.Context, rollupCfg *rollup.Config, log log.Logger, setup SetupP2P, gossipIn GossipIn, metrics metrics.Metricer) (*NodeP2P, error) {
@@ -73,12 +77,21 @@ func (n *NodeP2P) init(resourcesCtx context.Context, rollupCfg *rollup.Config, l
 		n.host.Network().Notify(NewNetworkNotifier(log, metrics))
 		// unregister identify-push handler. Only identifying on dial is fine, and more robust against spam
 		n.host.RemoveStreamHandler(identify.IDDelta)
-		n.gs, err = NewGossipSub(resourcesCtx, n.host, rollupCfg, metrics)
+		n.signers = NewSignerSet(rollupCfg, setup.AuthorizedSigners())
+		n.scores = NewPeerScores(log.New("p2p", "scores"), metrics, rollupCfg, n.host, n.gater, setup.BanningConfig())
+		n.gs, err = NewGossipSub(resourcesCtx, n.host, rollupCfg, metrics, n.scores)
 		if err != nil {
 			return fmt.Errorf("failed to start gossipsub router: %w", err)
 		}
 
-		n.gsOut, err = JoinGossip(resourcesCtx, n.host.ID(), n.gs, log, rollupCfg, gossipIn)
+		if setup.ReqRespSyncEnabled() {
+			n.blocks = NewSignedBlocks(signedBlocksCacheSize)
+			syncSrv := NewReqRespServer(log.New("p2p", "sync"), n.blocks)
+			n.host.SetStreamHandler(PayloadByNumberProtocolID(rollupCfg), syncSrv.HandleSyncRequest)
+			n.syncCl = NewSyncClient(log.New("p2p", "sync"), rollupCfg, n.signers, n.host, n.blocks, gossipIn.OnUnsafeL2Payload)
+			n.syncCl.Start()
+		}
+		n.gsOut, err = JoinGossip(resourcesCtx, n.host.ID(), n.gs, log, rollupCfg, n.signers, gossipIn, n.blocks)
 		if err != nil {
 			return fmt.Errorf("failed to join blocks gossip topic: %w", err)
 		}
@@ -122,10 +135,32 @@ func (n *NodeP2P) GossipOut() GossipOut {
 	return n.gsOut
 }
 
+// AltSyncEnabled returns true if missing blocks can be requested from peers with RequestL2Range.
+func (n *NodeP2P) AltSyncEnabled() bool {
+	return n.syncCl != nil
+}
+
+// RequestL2Range requests the given range of blocks, inclusive, from peers.
+func (n *NodeP2P) RequestL2Range(start, end uint64) error {
+	if n.syncCl == nil {
+		return errors.New("req/resp sync is not enabled")
+	}
+	return n.syncCl.RequestL2Range(start, end)
+}
+
 func (n *NodeP2P) ConnectionGater() ConnectionGater {
 	return n.gater
 }
 
+// Signers returns the set of keys that are authorized to sign blocks as sequencer.
+func (n *NodeP2P) Signers() *SignerSet {
+	return n.signers
+}
+
+func (n *NodeP2P) PeerScores() *PeerScores {
+	return n.scores
+}
+
 func (n *NodeP2P) ConnectionManager() connmgr.ConnManager {
 	return n.connMgr
 }
@@ -135,6 +170,11 @@ func (n *NodeP2P) Close() error {
 	if n.dv5Udp != nil {
 		n.dv5Udp.Close()
 	}
+	if n.syncCl != nil {
+		if err := n.syncCl.Close(); err != nil {
+			result = multierror.Append(result, fmt.Errorf("failed to close p2p sync client cleanly: %w", err))
+		}
+	}
 	if n.gsOut != nil {
 		if err := n.gsOut.Close(); err != nil {
 			result = multierror.Append(result, fmt.Errorf("failed to close gossip cleanly: %w", err))
@@ -0,0 +1,329 @@
+package p2p
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/host"
+	"github.com/libp2p/go-libp2p-core/peer"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+
+	"github.com/ethereum-optimism/optimism/op-node/metrics"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// peerScoreInspectInterval is the interval at which the peer scores are inspected,
+// to update the score book and metrics, and to ban or unban peers.
+const peerScoreInspectInterval = 15 * time.Second
+
+// banExpiryKey is the peerstore metadata key of the unix timestamp (int64) at which an automated ban expires.
+// The gater persists bans, this ensures automated bans are lifted, even after a restart.
+const banExpiryKey = "optimismBanExpiry"
+
+// PeerScoreThresholds are the score thresholds that restrict what peers can do in the gossip router.
+// Scores start at 0, and only go negative through invalid messages, IP colocation and protocol misbehavior.
+var PeerScoreThresholds = pubsub.PeerScoreThresholds{
+	// Below this we no longer gossip (IHAVE/IWANT) with the peer.
+	GossipThreshold: -10,
+	// Below this we no longer publish our own messages to the peer.
+	PublishThreshold: -40,
+	// Below this we ignore all RPCs from the peer.
+	GraylistThreshold: -80,
+	// Peer exchange is disabled, but the threshold has to be set.
+	AcceptPXThreshold: 20,
+	// Median mesh score below which we graft additional well-scoring peers.
+	OpportunisticGraftThreshold: 0.05,
+}
+
+// slotDuration is the unit of time the scoring is tuned to: the time between two L2 blocks.
+func slotDuration(cfg *rollup.Config) time.Duration {
+	if cfg.BlockTime == 0 {
+		return 2 * time.Second
+	}
+	return time.Duration(cfg.BlockTime) * time.Second
+}
+
+// decayToZero is the fraction of a score counter below which the counter is reset to zero.
+const decayToZero = 0.01
+
+// scoreDecay returns the decay factor to apply every slot, for a counter to decay to zero within the given duration.
+func scoreDecay(duration time.Duration, slot time.Duration) float64 {
+	return pubsub.ScoreParameterDecayWithBase(duration, slot, decayToZero)
+}
+
+// BlocksTopicScoreParams returns the score parameters of the blocks topic.
+//
+// There is a single publisher, the sequencer, with a message every slot. Rewards for first deliveries and
+// time in the mesh are kept small, so honest peers cannot build up enough score to offset invalid messages.
+// Mesh delivery rate penalties are disabled: with a single low-rate publisher they would penalize honest peers
+// that simply lost the race to deliver a block first.
+func BlocksTopicScoreParams(cfg *rollup.Config) *pubsub.TopicScoreParams {
+	slot := slotDuration(cfg)
+	epoch := 6 * slot
+	return &pubsub.TopicScoreParams{
+		TopicWeight: 0.8,
+		// P1: up to 10 points for peers that stay in our mesh for 300 slots.
+		TimeInMeshWeight:  1.0 / 30,
+		TimeInMeshQuantum: slot,
+		TimeInMeshCap:     300,
+		// P2: up to 23 points for peers that deliver new blocks first.
+		FirstMessageDeliveriesWeight: 1,
+		FirstMessageDeliveriesDecay:  scoreDecay(20*epoch, slot),
+		FirstMessageDeliveriesCap:    23,
+		// P3 and P3b: disabled, see above.
+		MeshMessageDeliveriesWeight: 0,
+		MeshFailurePenaltyWeight:    0,
+		// P4: the penalty is quadratic in the number of invalid messages:
+		// one invalid block drops the peer below the gossip threshold, two below the publish threshold,
+		// and three below the graylist and default ban threshold. The count decays over 50 epochs.
+		InvalidMessageDeliveriesWeight: -20,
+		InvalidMessageDeliveriesDecay:  scoreDecay(50*epoch, slot),
+	}
+}
+
+// PeerScoreParams returns the peer score parameters of the gossip router, including the blocks topic parameters.
+func PeerScoreParams(cfg *rollup.Config) *pubsub.PeerScoreParams {
+	slot := slotDuration(cfg)
+	epoch := 6 * slot
+	return &pubsub.PeerScoreParams{
+		Topics: map[string]*pubsub.TopicScoreParams{
+			blocksTopicV1(cfg): BlocksTopicScoreParams(cfg),
+		},
+		// Cap the positive contribution of topics, so a well-behaved history does not buy a lot of misbehavior.
+		TopicScoreCap: 34,
+		// P5: unused for now, applications can score peers here in the future.
+		AppSpecificScore:  func(p peer.ID) float64 { return 0 },
+		AppSpecificWeight: 1,
+		// P6: penalize many peers sharing the same IP, to counter sybils. The threshold is generous for NAT setups.
+		IPColocationFactorWeight:    -35,
+		IPColocationFactorThreshold: 10,
+		// P7: penalize protocol misbehavior, like GRAFT floods and broken IWANT promises.
+		BehaviourPenaltyWeight:    -16,
+		BehaviourPenaltyThreshold: 6,
+		BehaviourPenaltyDecay:     scoreDecay(10*epoch, slot),
+		DecayInterval:             slot,
+		DecayToZero:               decayToZero,
+		// Remember the score of disconnected peers, so they cannot reset it by reconnecting.
+		RetainScore: 100 * epoch,
+	}
+}
+
+// TopicScores is a snapshot of the score counters of a peer in a single topic.
+type TopicScores struct {
+	TimeInMesh               time.Duration `json:"timeInMesh"`
+	FirstMessageDeliveries   float64       `json:"firstMessageDeliveries"`
+	MeshMessageDeliveries    float64       `json:"meshMessageDeliveries"`
+	InvalidMessageDeliveries float64       `json:"invalidMessageDeliveries"`
+}
+
+// GossipScores is a snapshot of the gossip peer score of a peer, and its components.
+type GossipScores struct {
+	Total              float64     `json:"total"`
+	Blocks             TopicScores `json:"blocks"`
+	IPColocationFactor float64     `json:"IPColocationFactor"`
+	BehavioralPenalty  float64     `json:"behavioralPenalty"`
+}
+
+type ScoreMetrics interface {
+	RecordPeerScores(bands map[string]float64)
+	RecordPeerBan()
+}
+
+// scoreBand buckets a peer score by the thresholds, for metrics.
+func scoreBand(score float64) string {
+	switch {
+	case score < PeerScoreThresholds.GraylistThreshold:
+		return "graylisted"
+	case score < PeerScoreThresholds.PublishThreshold:
+		return "below_publish"
+	case score < PeerScoreThresholds.GossipThreshold:
+		return "below_gossip"
+	case score < 0:
+		return "negative"
+	default:
+		return "positive"
+	}
+}
+
+// PeerScores keeps track of the latest gossip peer scores, and bans peers with a score below the ban threshold.
+type PeerScores struct {
+	log        log.Logger
+	m          ScoreMetrics
+	h          host.Host
+	gater      ConnectionGater // may be nil, in which case peers are not banned
+	blocksName string
+	banning    *BanConfig // nil if peers are not banned automatically
+
+	// now is the clock that bans are timed with, replaced in tests
+	now func() time.Time
+
+	mu     sync.Mutex
+	scores map[peer.ID]*GossipScores
+	// bans maps automatically banned peers to the time their ban expires
+	bans map[peer.ID]time.Time
+}
+
+func NewPeerScores(log log.Logger, m ScoreMetrics, cfg *rollup.Config, h host.Host, gater ConnectionGater, banning *BanConfig) *PeerScores {
+	if m == nil {
+		m = metrics.NoopMetrics
+	}
+	if banning != nil && gater == nil {
+		log.Warn("peer banning is enabled, but there is no connection gater to ban peers with")
+		banning = nil
+	}
+	ps := &PeerScores{
+		log:        log,
+		m:          m,
+		h:          h,
+		gater:      gater,
+		blocksName: blocksTopicV1(cfg),
+		banning:    banning,
+		now:        time.Now,
+		scores:     make(map[peer.ID]*GossipScores),
+		bans:       make(map[peer.ID]time.Time),
+	}
+	ps.restoreBans()
+	return ps
+}
+
+// restoreBans picks up the expiry of automated bans from before a restart.
+func (ps *PeerScores) restoreBans() {
+	if ps.gater == nil {
+		return
+	}
+	pstore := ps.h.Peerstore()
+	for _, id := range ps.gater.ListBlockedPeers() {
+		dat, err := pstore.Get(id, banExpiryKey)
+		if err != nil {
+			continue
+		}
+		if expiry, ok := dat.(int64); ok && expiry != 0 {
+			ps.bans[id] = time.Unix(expiry, 0)
+		}
+	}
+}
+
+// Get returns the latest scores of the given peer, or nil if the peer has not been scored.
+func (ps *PeerScores) Get(id peer.ID) *GossipScores {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if s, ok := ps.scores[id]; ok {
+		cp := *s
+		return &cp
+	}
+	return nil
+}
+
+// BanExpirations returns the expiry of all automated bans.
+func (ps *PeerScores) BanExpirations() map[peer.ID]time.Time {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	out := make(map[peer.ID]time.Time, len(ps.bans))
+	for id, t := range ps.bans {
+		out[id] = t
+	}
+	return out
+}
+
+// Forget stops tracking the automated ban of a peer, if any. Used when a ban is manually changed.
+func (ps *PeerScores) Forget(id peer.ID) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if _, ok := ps.bans[id]; ok {
+		delete(ps.bans, id)
+		if err := ps.h.Peerstore().Put(id, banExpiryKey, int64(0)); err != nil {
+			ps.log.Warn("failed to clear ban expiry", "peer", id, "err", err)
+		}
+	}
+}
+
+// Inspect processes a new snapshot of peer scores. It is registered with the gossip router as score inspector.
+func (ps *PeerScores) Inspect(snapshot map[peer.ID]*pubsub.PeerScoreSnapshot) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	bands := map[string]float64{
+		"graylisted":    0,
+		"below_publish": 0,
+		"below_gossip":  0,
+		"negative":      0,
+		"positive":      0,
+	}
+	scores := make(map[peer.ID]*GossipScores, len(snapshot))
+	for id, snap := range snapshot {
+		s := &GossipScores{
+			Total:              snap.Score,
+			IPColocationFactor: snap.IPColocationFactor,
+			BehavioralPenalty:  snap.BehaviourPenalty,
+		}
+		if t, ok := snap.Topics[ps.blocksName]; ok {
+			s.Blocks = TopicScores{
+				TimeInMesh:               t.TimeInMesh,
+				FirstMessageDeliveries:   t.FirstMessageDeliveries,
+				MeshMessageDeliveries:    t.MeshMessageDeliveries,
+				InvalidMessageDeliveries: t.InvalidMessageDeliveries,
+			}
+		}
+		scores[id] = s
+		bands[scoreBand(snap.Score)] += 1
+	}
+	ps.scores = scores
+	ps.m.RecordPeerScores(bands)
+
+	if ps.banning == nil {
+		return
+	}
+	now := ps.now()
+	// ban in a deterministic order, lowest score first
+	var ids []peer.ID
+	for id, s := range scores {
+		if _, banned := ps.bans[id]; !banned && s.Total < ps.banning.Threshold {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return scores[ids[i]].Total < scores[ids[j]].Total })
+	for _, id := range ids {
+		ps.ban(id, scores[id].Total, now.Add(ps.banning.Duration))
+	}
+	for id, expiry := range ps.bans {
+		if !now.Before(expiry) {
+			ps.unban(id)
+		}
+	}
+}
+
+func (ps *PeerScores) ban(id peer.ID, score float64, expiry time.Time) {
+	if id == ps.h.ID() {
+		return
+	}
+	if err := ps.gater.BlockPeer(id); err != nil {
+		ps.log.Error("failed to ban peer", "peer", id, "score", score, "err", err)
+		return
+	}
+	ps.bans[id] = expiry
+	if err := ps.h.Peerstore().Put(id, banExpiryKey, expiry.Unix()); err != nil {
+		ps.log.Warn("failed to persist ban expiry", "peer", id, "err", err)
+	}
+	ps.m.RecordPeerBan()
+	ps.log.Warn("banned peer with low score", "peer", id, "score", score, "expiry", expiry)
+	// Closing the connections may trigger notifications into the gossip router, don't block on it.
+	go func() {
+		if err := ps.h.Network().ClosePeer(id); err != nil {
+			ps.log.Debug("failed to close connections of banned peer", "peer", id, "err", err)
+		}
+	}()
+}
+
+func (ps *PeerScores) unban(id peer.ID) {
+	if err := ps.gater.UnblockPeer(id); err != nil {
+		ps.log.Error("failed to unban peer", "peer", id, "err", err)
+		return
+	}
+	delete(ps.bans, id)
+	if err := ps.h.Peerstore().Put(id, banExpiryKey, int64(0)); err != nil {
+		ps.log.Warn("failed to clear ban expiry", "peer", id, "err", err)
+	}
+	ps.log.Info("ban of peer expired", "peer", id)
+}
@@ -0,0 +1,126 @@
+package p2p
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	ds "github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/sync"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/libp2p/go-libp2p/p2p/net/conngater"
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+func TestPeerScoreParams(t *testing.T) {
+	mn, err := mocknet.FullMeshLinked(1)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = mn.Close() })
+	for _, blockTime := range []uint64{0, 1, 2, 12} {
+		cfg := &rollup.Config{L2ChainID: big.NewInt(901), BlockTime: blockTime}
+		// the gossip router validates the score params and thresholds
+		ctx, cancel := context.WithCancel(context.Background())
+		_, err := NewGossipSub(ctx, mn.Hosts()[0], cfg, nil, nil)
+		cancel()
+		require.NoError(t, err, "block time %d", blockTime)
+	}
+}
+
+func TestPeerScoresBanning(t *testing.T) {
+	cfg := &rollup.Config{L2ChainID: big.NewInt(901), BlockTime: 2}
+	mn, err := mocknet.FullMeshLinked(3)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = mn.Close() })
+	require.NoError(t, mn.ConnectAllButSelf())
+	hosts := mn.Hosts()
+	h, bad, good := hosts[0], hosts[1].ID(), hosts[2].ID()
+
+	store := sync.MutexWrap(ds.NewMapDatastore())
+	gater, err := conngater.NewBasicConnectionGater(store)
+	require.NoError(t, err)
+
+	logger := testlog.Logger(t, log.LvlInfo)
+	banning := &BanConfig{Threshold: -100, Duration: time.Hour}
+	scores := NewPeerScores(logger, nil, cfg, h, gater, banning)
+	now := time.Unix(1000, 0)
+	scores.now = func() time.Time { return now }
+
+	snapshot := map[peer.ID]*pubsub.PeerScoreSnapshot{
+		bad: {Score: -150, Topics: map[string]*pubsub.TopicScoreSnapshot{
+			blocksTopicV1(cfg): {InvalidMessageDeliveries: 3},
+		}},
+		good: {Score: 12, Topics: map[string]*pubsub.TopicScoreSnapshot{
+			blocksTopicV1(cfg): {FirstMessageDeliveries: 12, TimeInMesh: time.Minute},
+		}},
+	}
+	scores.Inspect(snapshot)
+
+	require.Equal(t, &GossipScores{Total: -150, Blocks: TopicScores{InvalidMessageDeliveries: 3}}, scores.Get(bad))
+	require.Equal(t, &GossipScores{Total: 12, Blocks: TopicScores{FirstMessageDeliveries: 12, TimeInMesh: time.Minute}}, scores.Get(good))
+	require.Nil(t, scores.Get(h.ID()))
+
+	// the bad peer is banned and disconnected, the good peer is not
+	require.Equal(t, []peer.ID{bad}, gater.ListBlockedPeers())
+	require.Equal(t, map[peer.ID]time.Time{bad: now.Add(time.Hour)}, scores.BanExpirations())
+	require.Eventually(t, func() bool {
+		return h.Network().Connectedness(bad) != network.Connected
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, network.Connected, h.Network().Connectedness(good))
+
+	// the ban expiry survives a restart
+	restored := NewPeerScores(logger, nil, cfg, h, gater, banning)
+	require.Equal(t, map[peer.ID]time.Time{bad: now.Add(time.Hour)}, restored.BanExpirations())
+
+	// the ban is lifted when it expires, even if the peer is still scored badly
+	now = now.Add(time.Hour)
+	scores.Inspect(snapshot)
+	require.Empty(t, gater.ListBlockedPeers())
+	require.Empty(t, scores.BanExpirations())
+	restored = NewPeerScores(logger, nil, cfg, h, gater, banning)
+	require.Empty(t, restored.BanExpirations())
+}
+
+func TestPeerScoresForget(t *testing.T) {
+	cfg := &rollup.Config{L2ChainID: big.NewInt(901), BlockTime: 2}
+	mn, err := mocknet.FullMeshLinked(2)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = mn.Close() })
+	h, bad := mn.Hosts()[0], mn.Hosts()[1].ID()
+
+	gater, err := conngater.NewBasicConnectionGater(sync.MutexWrap(ds.NewMapDatastore()))
+	require.NoError(t, err)
+	scores := NewPeerScores(testlog.Logger(t, log.LvlInfo), nil, cfg, h, gater, &BanConfig{Threshold: -100, Duration: time.Hour})
+	snapshot := map[peer.ID]*pubsub.PeerScoreSnapshot{bad: {Score: -150}}
+	scores.Inspect(snapshot)
+	require.Equal(t, []peer.ID{bad}, gater.ListBlockedPeers())
+
+	// a manual ban of the same peer is not lifted automatically
+	scores.Forget(bad)
+	require.Empty(t, scores.BanExpirations())
+	scores.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	scores.Inspect(map[peer.ID]*pubsub.PeerScoreSnapshot{})
+	require.Equal(t, []peer.ID{bad}, gater.ListBlockedPeers())
+}
+
+func TestPeerScoresNoBanning(t *testing.T) {
+	cfg := &rollup.Config{L2ChainID: big.NewInt(901), BlockTime: 2}
+	mn, err := mocknet.FullMeshLinked(2)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = mn.Close() })
+	h, bad := mn.Hosts()[0], mn.Hosts()[1].ID()
+
+	gater, err := conngater.NewBasicConnectionGater(sync.MutexWrap(ds.NewMapDatastore()))
+	require.NoError(t, err)
+	scores := NewPeerScores(testlog.Logger(t, log.LvlInfo), nil, cfg, h, gater, nil)
+	scores.Inspect(map[peer.ID]*pubsub.PeerScoreSnapshot{bad: {Score: -150}})
+	require.Equal(t, -150.0, scores.Get(bad).Total)
+	require.Empty(t, gater.ListBlockedPeers())
+}
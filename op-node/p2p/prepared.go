@@ -20,6 +20,10 @@ type Prepared struct {
 	HostP2P   host.Host
 	LocalNode *enode.LocalNode
 	UDPv5     *discover.UDPv5
+
+	EnableReqRespSync bool
+	Banning           *BanConfig
+	Signers           []AuthorizedSigner
 }
 
 var _ SetupP2P = (*Prepared)(nil)
@@ -28,6 +32,18 @@ func (p *Prepared) TargetPeers() uint {
 	return 20
 }
 
+func (p *Prepared) ReqRespSyncEnabled() bool {
+	return p.EnableReqRespSync
+}
+
+func (p *Prepared) BanningConfig() *BanConfig {
+	return p.Banning
+}
+
+func (p *Prepared) AuthorizedSigners() []AuthorizedSigner {
+	return p.Signers
+}
+
 func (p *Prepared) Check() error {
 	if (p.LocalNode == nil) != (p.UDPv5 == nil) {
 		return fmt.Errorf("inconsistent discv5 setup: %v <> %v", p.LocalNode, p.UDPv5)
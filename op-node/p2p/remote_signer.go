@@ -0,0 +1,191 @@
+package p2p
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/urfave/cli"
+
+	"github.com/ethereum-optimism/optimism/op-node/flags"
+	"github.com/ethereum-optimism/optimism/op-service/backoff"
+)
+
+// SignerNamespaceRPC is the JSON-RPC namespace of the remote signing service.
+var SignerNamespaceRPC = "opsigner"
+
+// remoteSignerRetryDelay is the delay between retries of failed signing requests.
+// It is short, since blocks are signed for immediate publication.
+const remoteSignerRetryDelay = 100 * time.Millisecond
+
+// SignRequest is sent to the remote signer to sign the SigningHash of a payload.
+// The remote signer computes the SigningHash itself, so it can check the domain and chain ID it signs for.
+type SignRequest struct {
+	Domain      common.Hash  `json:"domain"`
+	ChainID     *hexutil.Big `json:"chainId"`
+	PayloadHash common.Hash  `json:"payloadHash"`
+}
+
+// RemoteSignerConfig configures a RemoteSigner, and implements SignerSetup.
+type RemoteSignerConfig struct {
+	// Endpoint is the HTTP(S) URL of the signing service.
+	Endpoint string
+	// Address, if not zero, is the address that the signatures must recover to.
+	Address common.Address
+	// Headers are added to every request, e.g. to authenticate with a bearer token.
+	Headers http.Header
+	// TLS configures the client certificate and CA for mTLS, nil to use the defaults.
+	TLS *tls.Config
+	// Timeout of a single signing request.
+	Timeout time.Duration
+	// Retries is the number of times a failed signing request is retried.
+	Retries uint
+}
+
+var _ SignerSetup = (*RemoteSignerConfig)(nil)
+
+func (cfg *RemoteSignerConfig) SetupSigner(ctx context.Context) (Signer, error) {
+	return NewRemoteSigner(cfg)
+}
+
+func loadRemoteSignerConfig(ctx *cli.Context) (*RemoteSignerConfig, error) {
+	cfg := &RemoteSignerConfig{
+		Endpoint: ctx.GlobalString(flags.SignerEndpointFlag.Name),
+		Headers:  make(http.Header),
+		Timeout:  ctx.GlobalDuration(flags.SignerTimeoutFlag.Name),
+		Retries:  ctx.GlobalUint(flags.SignerRetriesFlag.Name),
+	}
+	if addr := ctx.GlobalString(flags.SignerAddressFlag.Name); addr != "" {
+		if !common.IsHexAddress(addr) {
+			return nil, fmt.Errorf("invalid signer address: %q", addr)
+		}
+		cfg.Address = common.HexToAddress(addr)
+	}
+	for _, header := range ctx.GlobalStringSlice(flags.SignerHeaderFlag.Name) {
+		name, value, ok := strings.Cut(header, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid signer header, expected 'Name=Value': %q", header)
+		}
+		cfg.Headers.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	tlsConfig, err := LoadTLSConfig(
+		ctx.GlobalString(flags.SignerTLSCAFlag.Name),
+		ctx.GlobalString(flags.SignerTLSCertFlag.Name),
+		ctx.GlobalString(flags.SignerTLSKeyFlag.Name),
+	)
+	if err != nil {
+		return nil, err
+	}
+	cfg.TLS = tlsConfig
+	return cfg, nil
+}
+
+// LoadTLSConfig loads a TLS config that verifies the other side with the given CA certificate,
+// and authenticates with the given certificate and key, if any.
+// Nil is returned if no files are given.
+func LoadTLSConfig(caFile string, certFile string, keyFile string) (*tls.Config, error) {
+	if caFile == "" && certFile == "" && keyFile == "" {
+		return nil, nil
+	}
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile != "" {
+		caPEM, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in %q", caFile)
+		}
+		cfg.RootCAs = pool
+		cfg.ClientCAs = pool
+	}
+	if (certFile == "") != (keyFile == "") {
+		return nil, errors.New("both a certificate and a key are required")
+	}
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load certificate and key: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// RemoteSigner signs with an external signing service over JSON-RPC, so the sequencer key does not live on the node host.
+type RemoteSigner struct {
+	cfg    *RemoteSignerConfig
+	client *rpc.Client
+}
+
+var _ Signer = (*RemoteSigner)(nil)
+
+func NewRemoteSigner(cfg *RemoteSignerConfig) (*RemoteSigner, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.TLS != nil {
+		transport.TLSClientConfig = cfg.TLS
+	}
+	client, err := rpc.DialOptions(context.Background(), cfg.Endpoint,
+		rpc.WithHTTPClient(&http.Client{Transport: transport}),
+		rpc.WithHeaders(cfg.Headers))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create remote signer client: %w", err)
+	}
+	return &RemoteSigner{cfg: cfg, client: client}, nil
+}
+
+func (s *RemoteSigner) Sign(ctx context.Context, domain [32]byte, chainID *big.Int, encodedMsg []byte) (sig *[65]byte, err error) {
+	req := SignRequest{
+		Domain:      domain,
+		ChainID:     (*hexutil.Big)(chainID),
+		PayloadHash: crypto.Keccak256Hash(encodedMsg),
+	}
+	var res hexutil.Bytes
+	err = backoff.DoCtx(ctx, int(s.cfg.Retries)+1, backoff.Fixed(remoteSignerRetryDelay), func() error {
+		reqCtx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+		defer cancel()
+		return s.client.CallContext(reqCtx, &res, SignerNamespaceRPC+"_signPayload", req)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("remote signer failed: %w", err)
+	}
+	if len(res) != 65 {
+		return nil, fmt.Errorf("remote signer returned a signature of %d bytes, expected 65", len(res))
+	}
+	sig = (*[65]byte)(res)
+	// signing services commonly return the Ethereum-style recovery ID of 27 or 28
+	if sig[64] >= 27 {
+		sig[64] -= 27
+	}
+	if s.cfg.Address != (common.Address{}) {
+		signingHash, err := signingHashFromPayloadHash(domain, chainID, req.PayloadHash)
+		if err != nil {
+			return nil, err
+		}
+		pub, err := crypto.SigToPub(signingHash[:], sig[:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid remote signer signature: %w", err)
+		}
+		if addr := crypto.PubkeyToAddress(*pub); addr != s.cfg.Address {
+			return nil, fmt.Errorf("remote signer signed with %s, expected %s", addr, s.cfg.Address)
+		}
+	}
+	return sig, nil
+}
+
+func (s *RemoteSigner) Close() error {
+	s.client.Close()
+	return nil
+}
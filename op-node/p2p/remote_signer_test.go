@@ -0,0 +1,168 @@
+package p2p
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+)
+
+// writeCert creates a certificate signed by the parent, or a self-signed CA certificate if parent is nil,
+// and writes the certificate and key as PEM files to the directory.
+func writeCert(t *testing.T, dir string, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name+".crt"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name+".key"), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	return cert, key
+}
+
+func TestRemoteSigner(t *testing.T) {
+	priv, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	addr := crypto.PubkeyToAddress(priv.PublicKey)
+	chainID := big.NewInt(901)
+	msg := []byte("payload")
+
+	expected, err := NewLocalSigner(priv).Sign(context.Background(), SigningDomainBlocksV1, chainID, msg)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	ca, caKey := writeCert(t, dir, "ca", nil, nil)
+	writeCert(t, dir, "server", ca, caKey)
+	writeCert(t, dir, "client", ca, caKey)
+	path := func(name string) string { return filepath.Join(dir, name) }
+
+	serverTLS, err := LoadTLSConfig(path("ca.crt"), path("server.crt"), path("server.key"))
+	require.NoError(t, err)
+	serverTLS.ClientAuth = tls.RequireAndVerifyClientCert
+	srv, err := NewSignerServer(testlog.Logger(t, log.LvlInfo), priv, "127.0.0.1:0", "secret", serverTLS)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = srv.Close() })
+
+	clientTLS, err := LoadTLSConfig(path("ca.crt"), path("client.crt"), path("client.key"))
+	require.NoError(t, err)
+	cfg := func() *RemoteSignerConfig {
+		return &RemoteSignerConfig{
+			Endpoint: srv.Endpoint(),
+			Address:  addr,
+			Headers:  http.Header{"Authorization": []string{"Bearer secret"}},
+			TLS:      clientTLS,
+			Timeout:  time.Second,
+		}
+	}
+	sign := func(cfg *RemoteSignerConfig) (*[65]byte, error) {
+		signer, err := cfg.SetupSigner(context.Background())
+		require.NoError(t, err)
+		defer signer.Close()
+		return signer.Sign(context.Background(), SigningDomainBlocksV1, chainID, msg)
+	}
+
+	t.Run("mTLS with bearer token", func(t *testing.T) {
+		sig, err := sign(cfg())
+		require.NoError(t, err)
+		require.Equal(t, expected, sig)
+	})
+
+	t.Run("wrong address", func(t *testing.T) {
+		c := cfg()
+		c.Address[0] ^= 0xff
+		_, err := sign(c)
+		require.ErrorContains(t, err, "remote signer signed with")
+	})
+
+	t.Run("missing client certificate", func(t *testing.T) {
+		tlsConfig, err := LoadTLSConfig(path("ca.crt"), "", "")
+		require.NoError(t, err)
+		c := cfg()
+		c.TLS = tlsConfig
+		_, err = sign(c)
+		require.Error(t, err)
+	})
+
+	t.Run("missing bearer token", func(t *testing.T) {
+		c := cfg()
+		c.Headers = nil
+		_, err := sign(c)
+		require.ErrorContains(t, err, "401")
+	})
+}
+
+func TestRemoteSignerRetries(t *testing.T) {
+	priv, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	rpcServer := rpc.NewServer()
+	require.NoError(t, rpcServer.RegisterName(SignerNamespaceRPC, NewSignerAPI(priv)))
+	t.Cleanup(rpcServer.Stop)
+
+	var calls int32
+	failures := int32(2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= atomic.LoadInt32(&failures) {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		rpcServer.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	signer, err := NewRemoteSigner(&RemoteSignerConfig{
+		Endpoint: srv.URL,
+		Address:  crypto.PubkeyToAddress(priv.PublicKey),
+		Timeout:  time.Second,
+		Retries:  2,
+	})
+	require.NoError(t, err)
+	defer signer.Close()
+
+	_, err = signer.Sign(context.Background(), SigningDomainBlocksV1, big.NewInt(901), []byte("payload"))
+	require.NoError(t, err)
+	require.Equal(t, int32(3), atomic.LoadInt32(&calls))
+
+	// running out of retries
+	atomic.StoreInt32(&calls, 0)
+	atomic.StoreInt32(&failures, 3)
+	_, err = signer.Sign(context.Background(), SigningDomainBlocksV1, big.NewInt(901), []byte("payload"))
+	require.ErrorContains(t, err, "503")
+	require.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
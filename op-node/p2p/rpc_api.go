@@ -12,20 +12,19 @@ import (
 )
 
 type PeerInfo struct {
-	PeerID          peer.ID  `json:"peerID"`
-	NodeID          enode.ID `json:"nodeID"`
-	UserAgent       string   `json:"userAgent"`
-	ProtocolVersion string   `json:"protocolVersion"`
-	ENR             string   `json:"ENR"`       // might not always be known, e.g. if the peer connected us instead of us discovering them
-	Addresses       []string `json:"addresses"` // multi-addresses. may be mix of LAN / docker / external IPs. All of them are communicated.
-	Protocols       []string `json:"protocols"` // negotiated protocols list
-	//GossipScore float64
-	//PeerScore float64
-	Connectedness network.Connectedness `json:"connectedness"` // "NotConnected", "Connected", "CanConnect" (gracefully disconnected), or "CannotConnect" (tried but failed)
-	Direction     network.Direction     `json:"direction"`     // "Unknown", "Inbound" (if the peer contacted us), "Outbound" (if we connected to them)
-	Protected     bool                  `json:"protected"`     // Protected peers do not get
-	ChainID       uint64                `json:"chainID"`       // some peers might try to connect, but we figure out they are on a different chain later. This may be 0 if the peer is not an optimism node at all.
-	Latency       time.Duration         `json:"latency"`
+	PeerID          peer.ID               `json:"peerID"`
+	NodeID          enode.ID              `json:"nodeID"`
+	UserAgent       string                `json:"userAgent"`
+	ProtocolVersion string                `json:"protocolVersion"`
+	ENR             string                `json:"ENR"`           // might not always be known, e.g. if the peer connected us instead of us discovering them
+	Addresses       []string              `json:"addresses"`     // multi-addresses. may be mix of LAN / docker / external IPs. All of them are communicated.
+	Protocols       []string              `json:"protocols"`     // negotiated protocols list
+	Scores          *GossipScores         `json:"scores"`        // latest gossip peer score, nil if the peer has not been scored (yet)
+	Connectedness   network.Connectedness `json:"connectedness"` // "NotConnected", "Connected", "CanConnect" (gracefully disconnected), or "CannotConnect" (tried but failed)
+	Direction       network.Direction     `json:"direction"`     // "Unknown", "Inbound" (if the peer contacted us), "Outbound" (if we connected to them)
+	Protected       bool                  `json:"protected"`     // Protected peers do not get
+	ChainID         uint64                `json:"chainID"`       // some peers might try to connect, but we figure out they are on a different chain later. This may be 0 if the peer is not an optimism node at all.
+	Latency         time.Duration         `json:"latency"`
 
 	GossipBlocks bool `json:"gossipBlocks"` // if the peer is in our gossip topic
 }
@@ -36,6 +35,8 @@ type PeerDump struct {
 	BannedPeers    []peer.ID            `json:"bannedPeers"`
 	BannedIPS      []net.IP             `json:"bannedIPS"`
 	BannedSubnets  []*net.IPNet         `json:"bannedSubnets"`
+	// BanExpirations maps peers that were banned automatically for their low score to the time their ban expires.
+	BanExpirations map[string]time.Time `json:"banExpirations"`
 }
 
 type API interface {
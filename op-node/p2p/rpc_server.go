@@ -26,7 +26,6 @@ import (
 
 // TODO: dynamic peering
 // - req-resp protocol to ensure peers from a different chain learn they shouldn't be connected
-// - banning peers based on score
 
 var (
 	DisabledDiscovery   = errors.New("discovery disabled")
@@ -49,6 +48,8 @@ type Node interface {
 	ConnectionGater() ConnectionGater
 	// ConnectionManager returns the connection manager, to protect peers with, may be nil
 	ConnectionManager() connmgr.ConnManager
+	// PeerScores returns the latest gossip peer scores and automated bans, may be nil
+	PeerScores() *PeerScores
 }
 
 type APIBackend struct {
@@ -176,6 +177,9 @@ func (s *APIBackend) Peers(ctx context.Context, connected bool) (*PeerDump, erro
 			s.log.Debug("failed to dump peer info in RPC request", "peer", id, "err", err)
 			continue
 		}
+		if scores := s.node.PeerScores(); scores != nil {
+			peerInfo.Scores = scores.Get(id)
+		}
 		// We don't use the peer.ID type as key,
 		// since JSON decoding can't use the provided json unmarshaler (on *string type).
 		dump.Peers[id.String()] = peerInfo
@@ -193,6 +197,12 @@ func (s *APIBackend) Peers(ctx context.Context, connected bool) (*PeerDump, erro
 		dump.BannedSubnets = gater.ListBlockedSubnets()
 		dump.BannedIPS = gater.ListBlockedAddrs()
 	}
+	if scores := s.node.PeerScores(); scores != nil {
+		dump.BanExpirations = make(map[string]time.Time)
+		for id, expiry := range scores.BanExpirations() {
+			dump.BanExpirations[id.String()] = expiry
+		}
+	}
 	return dump, nil
 }
 
@@ -243,6 +253,10 @@ func (s *APIBackend) BlockPeer(_ context.Context, p peer.ID) error {
 	if gater := s.node.ConnectionGater(); gater == nil {
 		return NoConnectionGater
 	} else {
+		// a manual ban does not expire
+		if scores := s.node.PeerScores(); scores != nil {
+			scores.Forget(p)
+		}
 		return gater.BlockPeer(p)
 	}
 }
@@ -253,6 +267,9 @@ func (s *APIBackend) UnblockPeer(_ context.Context, p peer.ID) error {
 	if gater := s.node.ConnectionGater(); gater == nil {
 		return NoConnectionGater
 	} else {
+		if scores := s.node.PeerScores(); scores != nil {
+			scores.Forget(p)
+		}
 		return gater.UnblockPeer(p)
 	}
 }
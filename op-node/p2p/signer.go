@@ -24,6 +24,12 @@ type Signer interface {
 }
 
 func SigningHash(domain [32]byte, chainID *big.Int, payloadBytes []byte) (common.Hash, error) {
+	return signingHashFromPayloadHash(domain, chainID, crypto.Keccak256Hash(payloadBytes))
+}
+
+// signingHashFromPayloadHash computes the SigningHash from the hash of the encoded payload,
+// such that a remote signer does not need the full payload.
+func signingHashFromPayloadHash(domain [32]byte, chainID *big.Int, payloadHash common.Hash) (common.Hash, error) {
 	var msgInput [32 + 32 + 32]byte
 	// domain: first 32 bytes
 	copy(msgInput[:32], domain[:])
@@ -33,7 +39,7 @@ func SigningHash(domain [32]byte, chainID *big.Int, payloadBytes []byte) (common
 	}
 	chainID.FillBytes(msgInput[32:64])
 	// payload_hash: third 32 bytes, hash of encoded payload
-	copy(msgInput[32:], crypto.Keccak256(payloadBytes))
+	copy(msgInput[32:], payloadHash[:])
 
 	return crypto.Keccak256Hash(msgInput[:]), nil
 }
@@ -52,10 +58,14 @@ func NewLocalSigner(priv *ecdsa.PrivateKey) *LocalSigner {
 }
 
 func (s *LocalSigner) Sign(ctx context.Context, domain [32]byte, chainID *big.Int, encodedMsg []byte) (sig *[65]byte, err error) {
+	return s.signPayloadHash(domain, chainID, crypto.Keccak256Hash(encodedMsg))
+}
+
+func (s *LocalSigner) signPayloadHash(domain [32]byte, chainID *big.Int, payloadHash common.Hash) (sig *[65]byte, err error) {
 	if s.priv == nil {
 		return nil, errors.New("signer is closed")
 	}
-	signingHash, err := SigningHash(domain, chainID, encodedMsg)
+	signingHash, err := signingHashFromPayloadHash(domain, chainID, payloadHash)
 	if err != nil {
 		return nil, err
 	}
@@ -79,9 +89,6 @@ func (p *PreparedSigner) SetupSigner(ctx context.Context) (Signer, error) {
 	return p.Signer, nil
 }
 
-// TODO: implement remote signer setup (config to authenticated endpoint)
-// and remote signer itself (e.g. a open http client to make signing requests)
-
 type SignerSetup interface {
 	SetupSigner(ctx context.Context) (Signer, error)
 }
@@ -89,6 +96,11 @@ type SignerSetup interface {
 // LoadSignerSetup loads a configuration for a Signer to be set up later
 func LoadSignerSetup(ctx *cli.Context) (SignerSetup, error) {
 	key := ctx.GlobalString(flags.SequencerP2PKeyFlag.Name)
+	endpoint := ctx.GlobalString(flags.SignerEndpointFlag.Name)
+	if key != "" && endpoint != "" {
+		return nil, fmt.Errorf("cannot use both a local sequencer key (--%s) and a remote signer (--%s)",
+			flags.SequencerP2PKeyFlag.Name, flags.SignerEndpointFlag.Name)
+	}
 	if key != "" {
 		// Mnemonics are bad because they leak *all* keys when they leak.
 		// Unencrypted keys from file are bad because they are easy to leak (and we are not checking file permissions).
@@ -99,8 +111,13 @@ func LoadSignerSetup(ctx *cli.Context) (SignerSetup, error) {
 
 		return &PreparedSigner{Signer: NewLocalSigner(priv)}, nil
 	}
-
-	// TODO: create remote signer
+	if endpoint != "" {
+		cfg, err := loadRemoteSignerConfig(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load remote signer config: %w", err)
+		}
+		return cfg, nil
+	}
 
 	return nil, nil
 }
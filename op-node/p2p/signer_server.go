@@ -0,0 +1,97 @@
+package p2p
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// SignerAPI is the JSON-RPC API of a signing service, as used by the RemoteSigner.
+type SignerAPI struct {
+	signer *LocalSigner
+}
+
+func NewSignerAPI(priv *ecdsa.PrivateKey) *SignerAPI {
+	return &SignerAPI{signer: NewLocalSigner(priv)}
+}
+
+func (api *SignerAPI) SignPayload(ctx context.Context, req SignRequest) (hexutil.Bytes, error) {
+	if req.ChainID == nil {
+		return nil, errors.New("missing chain ID")
+	}
+	sig, err := api.signer.signPayloadHash(req.Domain, req.ChainID.ToInt(), req.PayloadHash)
+	if err != nil {
+		return nil, err
+	}
+	return sig[:], nil
+}
+
+// SignerServer is a stand-in for a remote signing service, serving the SignerAPI with a local key,
+// for testing and development. It optionally requires a bearer token, and serves over TLS if configured.
+// With a TLS config that has ClientAuth set to tls.RequireAndVerifyClientCert, clients are authenticated with mTLS.
+type SignerServer struct {
+	log       log.Logger
+	listener  net.Listener
+	srv       *http.Server
+	rpcServer *rpc.Server
+	endpoint  string
+}
+
+func NewSignerServer(log log.Logger, priv *ecdsa.PrivateKey, addr string, authToken string, tlsConfig *tls.Config) (*SignerServer, error) {
+	rpcServer := rpc.NewServer()
+	if err := rpcServer.RegisterName(SignerNamespaceRPC, NewSignerAPI(priv)); err != nil {
+		return nil, fmt.Errorf("failed to register signer API: %w", err)
+	}
+	var handler http.Handler = rpcServer
+	if authToken != "" {
+		handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer "+authToken {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			rpcServer.ServeHTTP(w, r)
+		})
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %q: %w", addr, err)
+	}
+	scheme := "http"
+	if tlsConfig != nil {
+		listener = tls.NewListener(listener, tlsConfig)
+		scheme = "https"
+	}
+	s := &SignerServer{
+		log:       log,
+		listener:  listener,
+		srv:       &http.Server{Handler: handler},
+		rpcServer: rpcServer,
+		endpoint:  fmt.Sprintf("%s://%s", scheme, listener.Addr().String()),
+	}
+	go func() {
+		if err := s.srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.log.Error("signer server failed", "err", err)
+		}
+	}()
+	s.log.Info("started signer server", "endpoint", s.endpoint)
+	return s, nil
+}
+
+// Endpoint returns the URL to configure the RemoteSigner with.
+func (s *SignerServer) Endpoint() string {
+	return s.endpoint
+}
+
+func (s *SignerServer) Close() error {
+	s.rpcServer.Stop()
+	return s.srv.Close()
+}
@@ -0,0 +1,134 @@
+package p2p
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+)
+
+// AuthorizedSigner is a key that is authorized to sign blocks as sequencer, within an activation window.
+// The window is bounded by L2 block number and by L2 block timestamp, both inclusive start and exclusive end.
+// A zero end means the window has no end. Overlapping windows of multiple signers allow a key handover.
+type AuthorizedSigner struct {
+	Address common.Address `json:"address"`
+
+	FromBlock  uint64 `json:"fromBlock,omitempty"`
+	UntilBlock uint64 `json:"untilBlock,omitempty"`
+
+	FromTime  uint64 `json:"fromTime,omitempty"`
+	UntilTime uint64 `json:"untilTime,omitempty"`
+}
+
+// Active returns true if the signer is authorized to sign a block with the given number and timestamp.
+func (s *AuthorizedSigner) Active(num uint64, timestamp uint64) bool {
+	if num < s.FromBlock || (s.UntilBlock != 0 && num >= s.UntilBlock) {
+		return false
+	}
+	if timestamp < s.FromTime || (s.UntilTime != 0 && timestamp >= s.UntilTime) {
+		return false
+	}
+	return true
+}
+
+func (s *AuthorizedSigner) Check() error {
+	if s.Address == (common.Address{}) {
+		return errors.New("missing signer address")
+	}
+	if s.UntilBlock != 0 && s.UntilBlock <= s.FromBlock {
+		return fmt.Errorf("signer %s: empty block window [%d, %d)", s.Address, s.FromBlock, s.UntilBlock)
+	}
+	if s.UntilTime != 0 && s.UntilTime <= s.FromTime {
+		return fmt.Errorf("signer %s: empty time window [%d, %d)", s.Address, s.FromTime, s.UntilTime)
+	}
+	return nil
+}
+
+// LoadAuthorizedSigners reads a JSON list of authorized signers from the given file.
+func LoadAuthorizedSigners(path string) ([]AuthorizedSigner, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read authorized signers file: %w", err)
+	}
+	var signers []AuthorizedSigner
+	if err := json.Unmarshal(data, &signers); err != nil {
+		return nil, fmt.Errorf("failed to decode authorized signers: %w", err)
+	}
+	if err := CheckAuthorizedSigners(signers); err != nil {
+		return nil, err
+	}
+	return signers, nil
+}
+
+// CheckAuthorizedSigners checks that the list of signers is not empty, and that all signers are valid.
+func CheckAuthorizedSigners(signers []AuthorizedSigner) error {
+	if len(signers) == 0 {
+		return errors.New("need at least one authorized signer")
+	}
+	for i := range signers {
+		if err := signers[i].Check(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SignerSet is the set of keys that are authorized to sign blocks as sequencer.
+// The set can be updated at runtime, e.g. to rotate the sequencer key.
+type SignerSet struct {
+	mu      sync.RWMutex
+	signers []AuthorizedSigner
+}
+
+// NewSignerSet creates a signer set with the given signers.
+// If there are no signers, the P2PSequencerAddress of the rollup config is authorized for all blocks.
+func NewSignerSet(cfg *rollup.Config, signers []AuthorizedSigner) *SignerSet {
+	if len(signers) == 0 {
+		signers = []AuthorizedSigner{{Address: cfg.P2PSequencerAddress}}
+	}
+	return &SignerSet{signers: signers}
+}
+
+// Signers returns a copy of the currently authorized signers.
+func (s *SignerSet) Signers() []AuthorizedSigner {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]AuthorizedSigner(nil), s.signers...)
+}
+
+// SetSigners replaces the set of authorized signers.
+func (s *SignerSet) SetSigners(signers []AuthorizedSigner) error {
+	if err := CheckAuthorizedSigners(signers); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.signers = append([]AuthorizedSigner(nil), signers...)
+	return nil
+}
+
+// CheckAuthorized returns an error if the address is not authorized to sign the given payload.
+func (s *SignerSet) CheckAuthorized(addr common.Address, payload *eth.ExecutionPayload) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	known := false
+	for i := range s.signers {
+		if s.signers[i].Address != addr {
+			continue
+		}
+		if s.signers[i].Active(uint64(payload.BlockNumber), uint64(payload.Timestamp)) {
+			return nil
+		}
+		known = true
+	}
+	if known {
+		return fmt.Errorf("block author %s is not authorized at block %d, time %d", addr, uint64(payload.BlockNumber), uint64(payload.Timestamp))
+	}
+	return fmt.Errorf("unexpected block author %s", addr)
+}
@@ -0,0 +1,87 @@
+package p2p
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+)
+
+func payloadAt(num uint64, timestamp uint64) *eth.ExecutionPayload {
+	return &eth.ExecutionPayload{BlockNumber: hexutil.Uint64(num), Timestamp: hexutil.Uint64(timestamp)}
+}
+
+func TestSignerSetDefault(t *testing.T) {
+	cfg := &rollup.Config{P2PSequencerAddress: common.Address{0x01}}
+	signers := NewSignerSet(cfg, nil)
+	require.Equal(t, []AuthorizedSigner{{Address: common.Address{0x01}}}, signers.Signers())
+	require.NoError(t, signers.CheckAuthorized(common.Address{0x01}, payloadAt(0, 0)))
+	require.NoError(t, signers.CheckAuthorized(common.Address{0x01}, payloadAt(1000, 2000)))
+	require.ErrorContains(t, signers.CheckAuthorized(common.Address{0x02}, payloadAt(1, 2)), "unexpected block author")
+}
+
+func TestSignerSetHandover(t *testing.T) {
+	oldKey, newKey := common.Address{0x01}, common.Address{0x02}
+	// the old key is valid until block 100, the new key from timestamp 150: both are valid in between.
+	signers := NewSignerSet(&rollup.Config{}, []AuthorizedSigner{
+		{Address: oldKey, UntilBlock: 100},
+		{Address: newKey, FromTime: 150},
+	})
+	for _, tc := range []struct {
+		num, timestamp uint64
+		oldOk, newOk   bool
+	}{
+		{num: 10, timestamp: 20, oldOk: true, newOk: false},
+		{num: 75, timestamp: 150, oldOk: true, newOk: true},
+		{num: 99, timestamp: 198, oldOk: true, newOk: true},
+		{num: 100, timestamp: 200, oldOk: false, newOk: true},
+	} {
+		err := signers.CheckAuthorized(oldKey, payloadAt(tc.num, tc.timestamp))
+		require.Equal(t, tc.oldOk, err == nil, "old key at block %d: %v", tc.num, err)
+		err = signers.CheckAuthorized(newKey, payloadAt(tc.num, tc.timestamp))
+		require.Equal(t, tc.newOk, err == nil, "new key at block %d: %v", tc.num, err)
+	}
+
+	// rotate again at runtime, the new key remains
+	third := common.Address{0x03}
+	require.NoError(t, signers.SetSigners([]AuthorizedSigner{{Address: newKey}, {Address: third, FromBlock: 500}}))
+	require.NoError(t, signers.CheckAuthorized(newKey, payloadAt(10, 20)))
+	require.ErrorContains(t, signers.CheckAuthorized(third, payloadAt(499, 998)), "not authorized")
+	require.NoError(t, signers.CheckAuthorized(third, payloadAt(500, 1000)))
+	require.ErrorContains(t, signers.CheckAuthorized(oldKey, payloadAt(10, 20)), "unexpected block author")
+
+	// invalid updates are rejected, and do not change the set
+	require.Error(t, signers.SetSigners(nil))
+	require.Error(t, signers.SetSigners([]AuthorizedSigner{{}}))
+	require.Error(t, signers.SetSigners([]AuthorizedSigner{{Address: third, FromBlock: 10, UntilBlock: 10}}))
+	require.Error(t, signers.SetSigners([]AuthorizedSigner{{Address: third, FromTime: 10, UntilTime: 5}}))
+	require.Len(t, signers.Signers(), 2)
+}
+
+func TestLoadAuthorizedSigners(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "signers.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[
+		{"address": "0x0100000000000000000000000000000000000000", "untilBlock": 100},
+		{"address": "0x0200000000000000000000000000000000000000", "fromTime": 150}
+	]`), 0644))
+	signers, err := LoadAuthorizedSigners(path)
+	require.NoError(t, err)
+	require.Equal(t, []AuthorizedSigner{
+		{Address: common.Address{0x01}, UntilBlock: 100},
+		{Address: common.Address{0x02}, FromTime: 150},
+	}, signers)
+
+	require.NoError(t, os.WriteFile(path, []byte(`[]`), 0644))
+	_, err = LoadAuthorizedSigners(path)
+	require.ErrorContains(t, err, "at least one")
+
+	_, err = LoadAuthorizedSigners(filepath.Join(dir, "missing.json"))
+	require.Error(t, err)
+}
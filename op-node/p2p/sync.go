@@ -0,0 +1,417 @@
+package p2p
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/golang/snappy"
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/libp2p/go-libp2p-core/host"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	"golang.org/x/time/rate"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// The request/response sync protocol lets a node fetch the signed blocks it missed on gossip from its peers.
+//
+// The request is the block number, encoded as big-endian uint64.
+// The response is a single result byte, followed by the signed block message if the result is success.
+// The signed block message is encoded the same as on the blocks gossip topic:
+// the snappy compressed 65 byte sequencer signature, followed by the SSZ encoded execution payload.
+// Signed blocks are verified the same as blocks received on gossip, except for the timestamp checks:
+// the signature by the sequencer and the block hash must be valid.
+
+const (
+	// signedBlocksCacheSize is the number of recent signed blocks that are remembered to serve to peers.
+	signedBlocksCacheSize = 512
+
+	// maxSyncRange is the max number of blocks that are requested at once, the remaining range is requested
+	// once the missing blocks are processed and the next unsafe payload still leaves a gap.
+	maxSyncRange = 64
+	// syncWorkers is the number of blocks that are requested in parallel
+	syncWorkers = 4
+	// maxSyncAttempts is the number of peers that are tried for a block, before giving up
+	maxSyncAttempts = 3
+	// syncRequestCooldown is the time before a block is requested again, if it is still missing.
+	syncRequestCooldown = 30 * time.Second
+	// payloadRequestTimeout is the time a peer has to respond to a request
+	payloadRequestTimeout = 5 * time.Second
+
+	// clientPeerRequestRate is the number of requests per second a client sends to a single peer
+	clientPeerRequestRate  = 5
+	clientPeerRequestBurst = 10
+
+	// serverPeerRequestRate is the number of requests per second a server answers for a single peer
+	serverPeerRequestRate  = 10
+	serverPeerRequestBurst = 20
+	// serverGlobalRequestRate is the number of requests per second a server answers for all peers together
+	serverGlobalRequestRate  = 50
+	serverGlobalRequestBurst = 100
+	// serverRateLimitTimeout is the max time a request waits for the rate limits, before it is refused
+	serverRateLimitTimeout = time.Second
+
+	// peerLimitersCacheSize is the number of peers that rate limits are tracked for
+	peerLimitersCacheSize = 1000
+)
+
+// Result codes of sync responses
+const (
+	ResultSuccess     byte = 0
+	ResultNotFound    byte = 1
+	ResultInvalid     byte = 2
+	ResultRateLimited byte = 3
+)
+
+func PayloadByNumberProtocolID(cfg *rollup.Config) protocol.ID {
+	return protocol.ID(fmt.Sprintf("/optimism/%s/0/req/payload_by_number", cfg.L2ChainID.String()))
+}
+
+// SignedBlocks remembers the signed messages of recent blocks by number, to serve to peers.
+// Only the last seen block of each number is remembered.
+type SignedBlocks struct {
+	cache *lru.Cache
+}
+
+func NewSignedBlocks(size int) *SignedBlocks {
+	cache, err := lru.New(size)
+	if err != nil {
+		panic(fmt.Errorf("failed to create signed blocks cache: %w", err))
+	}
+	return &SignedBlocks{cache: cache}
+}
+
+// Add remembers the compressed signed block message of the given block number.
+func (b *SignedBlocks) Add(num uint64, msg []byte) {
+	b.cache.Add(num, msg)
+}
+
+// Get returns the compressed signed block message of the given block number, if known.
+func (b *SignedBlocks) Get(num uint64) ([]byte, bool) {
+	msg, ok := b.cache.Get(num)
+	if !ok {
+		return nil, false
+	}
+	return msg.([]byte), true
+}
+
+// DecodeSignedBlock decodes and verifies a compressed signed block message.
+// Unlike the gossip validator, it does not check the timestamp, to allow older blocks to be synced.
+func DecodeSignedBlock(cfg *rollup.Config, signers *SignerSet, msg []byte) (*eth.ExecutionPayload, error) {
+	outLen, err := snappy.DecodedLen(msg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snappy compression length data: %w", err)
+	}
+	if outLen > maxGossipSize || outLen < minGossipSize {
+		return nil, fmt.Errorf("invalid decoded length %d", outLen)
+	}
+	data, err := snappy.Decode(nil, msg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snappy compression: %w", err)
+	}
+	signatureBytes, payloadBytes := data[:65], data[65:]
+	author, err := recoverBlockSigner(cfg, signatureBytes, payloadBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid block signature: %w", err)
+	}
+	var payload eth.ExecutionPayload
+	if err := payload.UnmarshalSSZ(uint32(len(payloadBytes)), bytes.NewReader(payloadBytes)); err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+	if err := signers.CheckAuthorized(author, &payload); err != nil {
+		return nil, fmt.Errorf("invalid block signature: %w", err)
+	}
+	if actual, ok := payload.CheckBlockHash(); !ok {
+		return nil, fmt.Errorf("payload has bad block hash %s, actual %s", payload.BlockHash, actual)
+	}
+	return &payload, nil
+}
+
+// peerLimiters tracks a rate limiter per peer.
+type peerLimiters struct {
+	mu    sync.Mutex
+	cache *lru.Cache
+	limit rate.Limit
+	burst int
+}
+
+func newPeerLimiters(limit rate.Limit, burst int) *peerLimiters {
+	cache, err := lru.New(peerLimitersCacheSize)
+	if err != nil {
+		panic(fmt.Errorf("failed to create peer rate limiters cache: %w", err))
+	}
+	return &peerLimiters{cache: cache, limit: limit, burst: burst}
+}
+
+func (pl *peerLimiters) get(id peer.ID) *rate.Limiter {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	if l, ok := pl.cache.Get(id); ok {
+		return l.(*rate.Limiter)
+	}
+	l := rate.NewLimiter(pl.limit, pl.burst)
+	pl.cache.Add(id, l)
+	return l
+}
+
+type receivePayloadFn func(ctx context.Context, from peer.ID, payload *eth.ExecutionPayload) error
+
+// SyncClient fetches missing blocks from peers that support the request/response sync protocol.
+type SyncClient struct {
+	log        log.Logger
+	cfg        *rollup.Config
+	signers    *SignerSet
+	host       host.Host
+	protocolID protocol.ID
+
+	blocks         *SignedBlocks
+	receivePayload receivePayloadFn
+
+	// requested tracks when blocks were last requested, to not request them again before syncRequestCooldown
+	requestedLock sync.Mutex
+	requested     map[uint64]time.Time
+
+	requests chan uint64
+	limiters *peerLimiters
+
+	resourcesCtx   context.Context
+	resourcesClose context.CancelFunc
+	wg             sync.WaitGroup
+}
+
+// NewSyncClient creates a sync client, which passes verified blocks to receivePayload,
+// and remembers them in blocks, to serve them to other peers.
+func NewSyncClient(log log.Logger, cfg *rollup.Config, signers *SignerSet, h host.Host, blocks *SignedBlocks, receivePayload receivePayloadFn) *SyncClient {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &SyncClient{
+		log:            log,
+		cfg:            cfg,
+		signers:        signers,
+		host:           h,
+		protocolID:     PayloadByNumberProtocolID(cfg),
+		blocks:         blocks,
+		receivePayload: receivePayload,
+		requested:      make(map[uint64]time.Time),
+		requests:       make(chan uint64, maxSyncRange),
+		limiters:       newPeerLimiters(clientPeerRequestRate, clientPeerRequestBurst),
+		resourcesCtx:   ctx,
+		resourcesClose: cancel,
+	}
+}
+
+func (s *SyncClient) Start() {
+	for i := 0; i < syncWorkers; i++ {
+		s.wg.Add(1)
+		go s.worker()
+	}
+}
+
+func (s *SyncClient) Close() error {
+	s.resourcesClose()
+	s.wg.Wait()
+	return nil
+}
+
+// RequestL2Range schedules the given range of blocks, inclusive, to be fetched from peers.
+// Blocks that were recently requested already are skipped. It does not block.
+func (s *SyncClient) RequestL2Range(start, end uint64) error {
+	if end < start {
+		return fmt.Errorf("invalid range: %d > %d", start, end)
+	}
+	if end-start >= maxSyncRange {
+		end = start + maxSyncRange - 1
+	}
+	s.requestedLock.Lock()
+	defer s.requestedLock.Unlock()
+	now := time.Now()
+	for num, at := range s.requested {
+		if now.Sub(at) >= syncRequestCooldown {
+			delete(s.requested, num)
+		}
+	}
+	for num := start; num <= end; num++ {
+		if _, ok := s.requested[num]; ok {
+			continue
+		}
+		select {
+		case s.requests <- num:
+			s.requested[num] = now
+		default:
+			return fmt.Errorf("too many pending block requests, cannot request block %d", num)
+		}
+	}
+	return nil
+}
+
+func (s *SyncClient) worker() {
+	defer s.wg.Done()
+	for {
+		select {
+		case <-s.resourcesCtx.Done():
+			return
+		case num := <-s.requests:
+			if err := s.fetch(s.resourcesCtx, num); err != nil {
+				s.log.Debug("failed to sync block from peers", "number", num, "err", err)
+			}
+		}
+	}
+}
+
+// peers returns the connected peers that support the sync protocol, in random order.
+func (s *SyncClient) peers() []peer.ID {
+	var out []peer.ID
+	for _, id := range s.host.Network().Peers() {
+		if protocols, err := s.host.Peerstore().SupportsProtocols(id, string(s.protocolID)); err == nil && len(protocols) > 0 {
+			out = append(out, id)
+		}
+	}
+	rand.Shuffle(len(out), func(i, j int) { out[i], out[j] = out[j], out[i] })
+	return out
+}
+
+// fetch requests a block from up to maxSyncAttempts peers, until a peer serves a valid block.
+func (s *SyncClient) fetch(ctx context.Context, num uint64) error {
+	peers := s.peers()
+	if len(peers) == 0 {
+		return errors.New("no peers to sync from")
+	}
+	if len(peers) > maxSyncAttempts {
+		peers = peers[:maxSyncAttempts]
+	}
+	var result error
+	for _, id := range peers {
+		if err := s.limiters.get(id).Wait(ctx); err != nil {
+			return err
+		}
+		msg, payload, err := s.requestPayload(ctx, id, num)
+		if err != nil {
+			s.log.Debug("failed to sync block from peer", "number", num, "peer", id, "err", err)
+			result = err
+			continue
+		}
+		s.log.Info("synced missing block from peer", "id", payload.ID(), "peer", id)
+		s.blocks.Add(num, msg)
+		return s.receivePayload(ctx, id, payload)
+	}
+	return result
+}
+
+// requestPayload requests a block from the peer, and returns the verified block and its compressed signed message.
+func (s *SyncClient) requestPayload(ctx context.Context, id peer.ID, num uint64) ([]byte, *eth.ExecutionPayload, error) {
+	ctx, cancel := context.WithTimeout(ctx, payloadRequestTimeout)
+	defer cancel()
+	stream, err := s.host.NewStream(ctx, id, s.protocolID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open stream: %w", err)
+	}
+	defer stream.Close()
+	_ = stream.SetDeadline(time.Now().Add(payloadRequestTimeout))
+
+	var req [8]byte
+	binary.BigEndian.PutUint64(req[:], num)
+	if _, err := stream.Write(req[:]); err != nil {
+		return nil, nil, fmt.Errorf("failed to write request: %w", err)
+	}
+	if err := stream.CloseWrite(); err != nil {
+		return nil, nil, fmt.Errorf("failed to close request: %w", err)
+	}
+
+	var result [1]byte
+	if _, err := io.ReadFull(stream, result[:]); err != nil {
+		return nil, nil, fmt.Errorf("failed to read result: %w", err)
+	}
+	if result[0] != ResultSuccess {
+		return nil, nil, fmt.Errorf("peer responded with result %d", result[0])
+	}
+	maxLen := snappy.MaxEncodedLen(maxGossipSize)
+	msg, err := io.ReadAll(io.LimitReader(stream, int64(maxLen)+1))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if len(msg) > maxLen {
+		return nil, nil, fmt.Errorf("response is too large")
+	}
+	payload, err := DecodeSignedBlock(s.cfg, s.signers, msg)
+	if err != nil {
+		return nil, nil, err
+	}
+	if uint64(payload.BlockNumber) != num {
+		return nil, nil, fmt.Errorf("peer responded with block %d, expected %d", uint64(payload.BlockNumber), num)
+	}
+	return msg, payload, nil
+}
+
+// ReqRespServer serves the signed blocks it remembers to peers that request them.
+type ReqRespServer struct {
+	log    log.Logger
+	blocks *SignedBlocks
+
+	limiters      *peerLimiters
+	globalLimiter *rate.Limiter
+}
+
+func NewReqRespServer(log log.Logger, blocks *SignedBlocks) *ReqRespServer {
+	return &ReqRespServer{
+		log:           log,
+		blocks:        blocks,
+		limiters:      newPeerLimiters(serverPeerRequestRate, serverPeerRequestBurst),
+		globalLimiter: rate.NewLimiter(serverGlobalRequestRate, serverGlobalRequestBurst),
+	}
+}
+
+// HandleSyncRequest is the stream handler of the sync protocol, it serves a single block per stream.
+func (srv *ReqRespServer) HandleSyncRequest(stream network.Stream) {
+	defer stream.Close()
+	id := stream.Conn().RemotePeer()
+
+	result, msg := srv.handleSyncRequest(stream)
+	_ = stream.SetWriteDeadline(time.Now().Add(payloadRequestTimeout))
+	if _, err := stream.Write([]byte{result}); err != nil {
+		srv.log.Debug("failed to write sync response", "peer", id, "err", err)
+		return
+	}
+	if result == ResultSuccess {
+		if _, err := stream.Write(msg); err != nil {
+			srv.log.Debug("failed to write sync response", "peer", id, "err", err)
+		}
+	}
+}
+
+func (srv *ReqRespServer) handleSyncRequest(stream network.Stream) (byte, []byte) {
+	id := stream.Conn().RemotePeer()
+	ctx, cancel := context.WithTimeout(context.Background(), serverRateLimitTimeout)
+	defer cancel()
+	if err := srv.globalLimiter.Wait(ctx); err != nil {
+		return ResultRateLimited, nil
+	}
+	if err := srv.limiters.get(id).Wait(ctx); err != nil {
+		srv.log.Debug("rate limited sync request", "peer", id)
+		return ResultRateLimited, nil
+	}
+
+	_ = stream.SetReadDeadline(time.Now().Add(payloadRequestTimeout))
+	var req [8]byte
+	if _, err := io.ReadFull(stream, req[:]); err != nil {
+		srv.log.Debug("failed to read sync request", "peer", id, "err", err)
+		return ResultInvalid, nil
+	}
+	num := binary.BigEndian.Uint64(req[:])
+	msg, ok := srv.blocks.Get(num)
+	if !ok {
+		srv.log.Debug("cannot serve unknown block", "number", num, "peer", id)
+		return ResultNotFound, nil
+	}
+	srv.log.Debug("serving block", "number", num, "peer", id)
+	return ResultSuccess, msg
+}
@@ -0,0 +1,161 @@
+package p2p
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/libp2p/go-libp2p-core/peer"
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+)
+
+// signedBlockMsg creates a compressed block message, as it is published on gossip, signed by the given key.
+func signedBlockMsg(t *testing.T, cfg *rollup.Config, priv *ecdsa.PrivateKey, num uint64) []byte {
+	block := types.NewBlockWithHeader(&types.Header{
+		Number:     new(big.Int).SetUint64(num),
+		Difficulty: common.Big0,
+		BaseFee:    big.NewInt(7),
+		Time:       num * 2,
+	})
+	payload, err := eth.BlockAsPayload(block)
+	require.NoError(t, err)
+	payload.BlockHash, _ = payload.CheckBlockHash()
+	var buf bytes.Buffer
+	buf.Write(make([]byte, 65))
+	_, err = payload.MarshalSSZ(&buf)
+	require.NoError(t, err)
+	data := buf.Bytes()
+	sig, err := NewLocalSigner(priv).Sign(context.Background(), SigningDomainBlocksV1, cfg.L2ChainID, data[65:])
+	require.NoError(t, err)
+	copy(data[:65], sig[:])
+	return snappy.Encode(nil, data)
+}
+
+func TestSyncReqResp(t *testing.T) {
+	sequencer, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	other, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	cfg := &rollup.Config{
+		L2ChainID:           big.NewInt(901),
+		P2PSequencerAddress: crypto.PubkeyToAddress(sequencer.PublicKey),
+	}
+
+	mn, err := mocknet.FullMeshLinked(2)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = mn.Close() })
+	hosts := mn.Hosts()
+	hostA, hostB := hosts[0], hosts[1]
+
+	// A serves blocks 1 and 2, and block 3 signed by the wrong key. A does not know block 4.
+	blocksA := NewSignedBlocks(signedBlocksCacheSize)
+	blocksA.Add(1, signedBlockMsg(t, cfg, sequencer, 1))
+	blocksA.Add(2, signedBlockMsg(t, cfg, sequencer, 2))
+	blocksA.Add(3, signedBlockMsg(t, cfg, other, 3))
+	srv := NewReqRespServer(testlog.Logger(t, log.LvlInfo), blocksA)
+	hostA.SetStreamHandler(PayloadByNumberProtocolID(cfg), srv.HandleSyncRequest)
+
+	received := make(chan *eth.ExecutionPayload, 10)
+	blocksB := NewSignedBlocks(signedBlocksCacheSize)
+	cl := NewSyncClient(testlog.Logger(t, log.LvlInfo), cfg, NewSignerSet(cfg, nil), hostB, blocksB, func(ctx context.Context, from peer.ID, payload *eth.ExecutionPayload) error {
+		require.Equal(t, hostA.ID(), from)
+		received <- payload
+		return nil
+	})
+	cl.Start()
+	t.Cleanup(func() { _ = cl.Close() })
+
+	require.NoError(t, mn.ConnectAllButSelf())
+	// wait for B to identify the protocols supported by A
+	require.Eventually(t, func() bool {
+		return len(cl.peers()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, cl.RequestL2Range(1, 4))
+	got := make(map[uint64]bool)
+	for i := 0; i < 2; i++ {
+		select {
+		case payload := <-received:
+			_, ok := payload.CheckBlockHash()
+			require.True(t, ok)
+			got[uint64(payload.BlockNumber)] = true
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for synced blocks")
+		}
+	}
+	require.Equal(t, map[uint64]bool{1: true, 2: true}, got)
+	select {
+	case payload := <-received:
+		t.Fatalf("unexpected block %d", uint64(payload.BlockNumber))
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// B remembers the valid blocks, to serve them to other peers
+	for num, ok := range map[uint64]bool{1: true, 2: true, 3: false, 4: false} {
+		_, has := blocksB.Get(num)
+		require.Equal(t, ok, has, "block %d", num)
+	}
+
+	// blocks are not requested again during the cooldown
+	require.NoError(t, cl.RequestL2Range(1, 2))
+	select {
+	case payload := <-received:
+		t.Fatalf("unexpected block %d", uint64(payload.BlockNumber))
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestDecodeSignedBlock(t *testing.T) {
+	sequencer, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	cfg := &rollup.Config{
+		L2ChainID:           big.NewInt(901),
+		P2PSequencerAddress: crypto.PubkeyToAddress(sequencer.PublicKey),
+	}
+	signers := NewSignerSet(cfg, nil)
+	msg := signedBlockMsg(t, cfg, sequencer, 42)
+	payload, err := DecodeSignedBlock(cfg, signers, msg)
+	require.NoError(t, err)
+	require.Equal(t, uint64(42), uint64(payload.BlockNumber))
+
+	// signed by a different key than the sequencer key
+	_, err = DecodeSignedBlock(cfg, NewSignerSet(cfg, []AuthorizedSigner{{Address: common.Address{0xaa}}}), msg)
+	require.ErrorContains(t, err, "unexpected block author")
+
+	// signed by the sequencer key, but outside of its activation window
+	expired := []AuthorizedSigner{{Address: cfg.P2PSequencerAddress, UntilBlock: 42}}
+	_, err = DecodeSignedBlock(cfg, NewSignerSet(cfg, expired), msg)
+	require.ErrorContains(t, err, "not authorized at block 42")
+
+	// tamper with the block hash, and sign it again
+	data, err := snappy.Decode(nil, msg)
+	require.NoError(t, err)
+	payload.BlockHash[0] ^= 0xff
+	var buf bytes.Buffer
+	buf.Write(data[:65])
+	_, err = payload.MarshalSSZ(&buf)
+	require.NoError(t, err)
+	tampered := buf.Bytes()
+	sig, err := NewLocalSigner(sequencer).Sign(context.Background(), SigningDomainBlocksV1, cfg.L2ChainID, tampered[65:])
+	require.NoError(t, err)
+	copy(tampered[:65], sig[:])
+	_, err = DecodeSignedBlock(cfg, signers, snappy.Encode(nil, tampered))
+	require.ErrorContains(t, err, "bad block hash")
+
+	_, err = DecodeSignedBlock(cfg, signers, []byte("not a block"))
+	require.Error(t, err)
+}
@@ -91,3 +91,9 @@ func (aq *AttributesQueue) createNextAttributes(ctx context.Context, batch *Batc
 func (aq *AttributesQueue) Reset(ctx context.Context, _ eth.L1BlockRef) error {
 	return io.EOF
 }
+
+// Restore drops the batch that attributes are being prepared for, checkpoints are taken when there is none.
+func (aq *AttributesQueue) Restore(ctx context.Context, cp *Checkpoint) error {
+	aq.batch = nil
+	return nil
+}
@@ -43,6 +43,9 @@ type BatchQueue struct {
 
 	// batches in order of when we've first seen them, grouped by L2 timestamp
 	batches map[uint64][]*BatchWithL1InclusionBlock
+
+	// dropped are the most recently dropped batches, for introspection. At most maxDroppedBatches large.
+	dropped []DroppedBatch
 }
 
 // NewBatchQueue creates a BatchQueue, which should be Reset(origin) before use.
@@ -134,13 +137,30 @@ func (bq *BatchQueue) AddBatch(batch *BatchData, l2SafeHead eth.L2BlockRef) {
 		L1InclusionBlock: bq.origin,
 		Batch:            batch,
 	}
-	validity := CheckBatch(bq.config, bq.log, bq.l1Blocks, l2SafeHead, &data)
+	validity, reason := CheckBatch(bq.config, bq.log, bq.l1Blocks, l2SafeHead, &data)
 	if validity == BatchDrop {
-		return // if we do drop the batch, CheckBatch will log the drop reason with WARN level.
+		// if we do drop the batch, CheckBatch will log the drop reason with WARN level.
+		bq.recordDrop(&data, l2SafeHead, reason)
+		return
 	}
 	bq.batches[batch.Timestamp] = append(bq.batches[batch.Timestamp], &data)
 }
 
+// recordDrop remembers why the batch was dropped, evicting the oldest record if there are too many.
+func (bq *BatchQueue) recordDrop(batch *BatchWithL1InclusionBlock, l2SafeHead eth.L2BlockRef, reason string) {
+	if len(bq.dropped) >= maxDroppedBatches {
+		bq.dropped = append(bq.dropped[:0], bq.dropped[1:]...)
+	}
+	bq.dropped = append(bq.dropped, DroppedBatch{
+		Timestamp:        batch.Batch.Timestamp,
+		ParentHash:       batch.Batch.ParentHash,
+		Epoch:            batch.Batch.Epoch(),
+		L1InclusionBlock: batch.L1InclusionBlock.ID(),
+		SafeHead:         l2SafeHead.ID(),
+		Reason:           reason,
+	})
+}
+
 // deriveNextBatch derives the next batch to apply on top of the current L2 safe head,
 // following the validity rules imposed on consecutive batches,
 // based on currently available buffered batch and L1 origin information.
@@ -167,7 +187,7 @@ func (bq *BatchQueue) deriveNextBatch(ctx context.Context, outOfData bool, l2Saf
 	candidates := bq.batches[nextTimestamp]
 batchLoop:
 	for i, batch := range candidates {
-		validity := CheckBatch(bq.config, bq.log.New("batch_index", i), bq.l1Blocks, l2SafeHead, batch)
+		validity, reason := CheckBatch(bq.config, bq.log.New("batch_index", i), bq.l1Blocks, l2SafeHead, batch)
 		switch validity {
 		case BatchFuture:
 			return nil, NewCriticalError(fmt.Errorf("found batch with timestamp %d marked as future batch, but expected timestamp %d", batch.Batch.Timestamp, nextTimestamp))
@@ -180,6 +200,7 @@ batchLoop:
 				"l2_safe_head", l2SafeHead.ID(),
 				"l2_safe_head_time", l2SafeHead.Time,
 			)
+			bq.recordDrop(batch, l2SafeHead, reason)
 			continue
 		case BatchAccept:
 			nextBatch = batch
@@ -2,11 +2,11 @@ package derive
 
 import (
 	"bytes"
-	"compress/zlib"
 	"fmt"
 	"io"
 
 	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
 	"github.com/ethereum/go-ethereum/rlp"
 )
 
@@ -144,9 +144,11 @@ func (ch *Channel) Reader() io.Reader {
 
 // BatchReader provides a function that iteratively consumes batches from the reader.
 // The L1Inclusion block is also provided at creation time.
-func BatchReader(r io.Reader, l1InclusionBlock eth.L1BlockRef) (func() (BatchWithL1InclusionBlock, error), error) {
+// The decompression codec is selected by the version byte at the start of the channel data,
+// and must be active at the time of the L1 inclusion block.
+func BatchReader(cfg *rollup.Config, r io.Reader, l1InclusionBlock eth.L1BlockRef) (func() (BatchWithL1InclusionBlock, error), error) {
 	// Setup decompressor stage + RLP reader
-	zr, err := zlib.NewReader(r)
+	zr, _, err := decompressChannel(r, cfg.IsZstd(l1InclusionBlock.Time))
 	if err != nil {
 		return nil, err
 	}
@@ -38,7 +38,7 @@ type ChannelBank struct {
 	fetcher L1Fetcher
 }
 
-var _ ResetableStage = (*ChannelBank)(nil)
+var _ RestorableStage = (*ChannelBank)(nil)
 
 // NewChannelBank creates a ChannelBank, which should be Reset(origin) before use.
 func NewChannelBank(log log.Logger, cfg *rollup.Config, prev NextFrameProvider, fetcher L1Fetcher) *ChannelBank {
@@ -6,6 +6,7 @@ import (
 	"io"
 
 	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
 	"github.com/ethereum/go-ethereum/log"
 )
 
@@ -16,18 +17,20 @@ import (
 
 type ChannelInReader struct {
 	log log.Logger
+	cfg *rollup.Config
 
 	nextBatchFn func() (BatchWithL1InclusionBlock, error)
 
 	prev *ChannelBank
 }
 
-var _ ResetableStage = (*ChannelInReader)(nil)
+var _ RestorableStage = (*ChannelInReader)(nil)
 
 // NewChannelInReader creates a ChannelInReader, which should be Reset(origin) before use.
-func NewChannelInReader(log log.Logger, prev *ChannelBank) *ChannelInReader {
+func NewChannelInReader(log log.Logger, cfg *rollup.Config, prev *ChannelBank) *ChannelInReader {
 	return &ChannelInReader{
 		log:  log,
+		cfg:  cfg,
 		prev: prev,
 	}
 }
@@ -38,7 +41,7 @@ func (cr *ChannelInReader) Origin() eth.L1BlockRef {
 
 // TODO: Take full channel for better logging
 func (cr *ChannelInReader) WriteChannel(data []byte) error {
-	if f, err := BatchReader(bytes.NewBuffer(data), cr.Origin()); err == nil {
+	if f, err := BatchReader(cr.cfg, bytes.NewBuffer(data), cr.Origin()); err == nil {
 		cr.nextBatchFn = f
 		return nil
 	} else {
@@ -87,3 +90,9 @@ func (cr *ChannelInReader) Reset(ctx context.Context, _ eth.L1BlockRef) error {
 	cr.nextBatchFn = nil
 	return io.EOF
 }
+
+// Restore drops the channel that is being read, checkpoints are taken when no channel is being read.
+func (cr *ChannelInReader) Restore(ctx context.Context, cp *Checkpoint) error {
+	cr.nextBatchFn = nil
+	return nil
+}
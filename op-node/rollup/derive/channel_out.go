@@ -2,7 +2,6 @@ package derive
 
 import (
 	"bytes"
-	"compress/zlib"
 	"crypto/rand"
 	"errors"
 	"fmt"
@@ -24,8 +23,10 @@ type ChannelOut struct {
 	// rlpLength is the uncompressed size of the channel. Must be less than MAX_RLP_BYTES_PER_CHANNEL
 	rlpLength int
 
+	// codec used by the compressor stage
+	codec CompressionCodec
 	// Compressor stage. Write input data to it
-	compress *zlib.Writer
+	compress compressor
 	// post compression buffer
 	buf bytes.Buffer
 
@@ -36,22 +37,35 @@ func (co *ChannelOut) ID() ChannelID {
 	return co.id
 }
 
+// Codec returns the compression codec used by the channel.
+func (co *ChannelOut) Codec() CompressionCodec {
+	return co.codec
+}
+
+// NewChannelOut creates a new channel that compresses its data with the default zlib codec.
 func NewChannelOut() (*ChannelOut, error) {
+	return NewChannelOutWithCodec(CodecZlib)
+}
+
+// NewChannelOutWithCodec creates a new channel that compresses its data with the given codec.
+func NewChannelOutWithCodec(codec CompressionCodec) (*ChannelOut, error) {
 	c := &ChannelOut{
 		id:        ChannelID{}, // TODO: use GUID here instead of fully random data
 		frame:     0,
 		rlpLength: 0,
+		codec:     codec,
 	}
 	_, err := rand.Read(c.id[:])
 	if err != nil {
 		return nil, err
 	}
 
-	compress, err := zlib.NewWriterLevel(&c.buf, zlib.BestCompression)
+	compress, err := codec.newCompressor(&c.buf)
 	if err != nil {
 		return nil, err
 	}
 	c.compress = compress
+	c.buf.Write(codec.versionPrefix())
 
 	return c, nil
 }
@@ -62,6 +76,7 @@ func (co *ChannelOut) Reset() error {
 	co.rlpLength = 0
 	co.buf.Reset()
 	co.compress.Reset(&co.buf)
+	co.buf.Write(co.codec.versionPrefix())
 	co.closed = false
 	_, err := rand.Read(co.id[:])
 	if err != nil {
@@ -99,6 +114,11 @@ func (co *ChannelOut) AddBlock(block *types.Block) error {
 	return err
 }
 
+// InputBytes returns the total amount of RLP-encoded input bytes, before compression.
+func (co *ChannelOut) InputBytes() int {
+	return co.rlpLength
+}
+
 // ReadyBytes returns the number of bytes that the channel out can immediately output into a frame.
 // Use `Flush` or `Close` to move data from the compression buffer into the ready buffer if more bytes
 // are needed. Add blocks may add to the ready buffer, but it is not guaranteed due to the compression stage.
@@ -112,6 +132,11 @@ func (co *ChannelOut) Flush() error {
 	return co.compress.Flush()
 }
 
+// IsClosed returns true if the channel was closed, and no more blocks can be added.
+func (co *ChannelOut) IsClosed() bool {
+	return co.closed
+}
+
 func (co *ChannelOut) Close() error {
 	if co.closed {
 		return errors.New("already closed")
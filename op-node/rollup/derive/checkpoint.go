@@ -0,0 +1,257 @@
+package derive
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+)
+
+var (
+	ErrCheckpointMismatch = errors.New("checkpoint does not match the canonical chain")
+	ErrCheckpointTooLarge = errors.New("checkpoint is too large")
+)
+
+// checkpointVersion0 is the version byte of the RLP checkpoint encoding.
+const checkpointVersion0 = 0
+
+// MaxCheckpointSize is the maximum size of an encoded checkpoint. The channel bank is pruned to MaxChannelBankSize,
+// the remainder is room for the buffered batches. Checkpoints that do not fit are not taken.
+const MaxCheckpointSize = MaxChannelBankSize + MaxRLPBytesPerChannel
+
+// Checkpoint is a snapshot of the derivation pipeline, taken after all the data of the Origin L1 block was processed.
+// Restoring a checkpoint continues derivation from the L1 block after the origin,
+// instead of resetting the pipeline and re-processing the L1 chain from the L2 safe head.
+type Checkpoint struct {
+	// Origin is the last L1 block that was fully processed by the pipeline.
+	Origin eth.L1BlockRef
+
+	SafeHead   eth.L2BlockRef
+	UnsafeHead eth.L2BlockRef
+	Finalized  eth.L2BlockRef
+
+	// Channels buffered in the channel bank, in FIFO order.
+	Channels []ChannelCheckpoint
+
+	// BatchOrigin is the origin of the batch queue, BatchL1Blocks are the L1 blocks it tracks,
+	// and Batches are the buffered batches, ordered by timestamp and then by the order they were seen in.
+	BatchOrigin   eth.L1BlockRef
+	BatchL1Blocks []eth.L1BlockRef
+	Batches       []BatchCheckpoint
+}
+
+// ChannelCheckpoint is a channel buffered in the channel bank.
+type ChannelCheckpoint struct {
+	ID                      ChannelID
+	OpenBlock               eth.L1BlockRef
+	HighestL1InclusionBlock eth.L1BlockRef
+	Frames                  []FrameCheckpoint
+}
+
+// FrameCheckpoint is a frame of a channel buffered in the channel bank.
+type FrameCheckpoint struct {
+	FrameNumber uint16
+	Data        []byte
+	IsLast      bool
+}
+
+// BatchCheckpoint is a batch buffered in the batch queue.
+type BatchCheckpoint struct {
+	L1InclusionBlock eth.L1BlockRef
+	// Batch is the binary encoding of the batch.
+	Batch []byte
+}
+
+// MarshalBinary encodes the checkpoint as a version byte followed by the RLP encoding of the checkpoint.
+// ErrCheckpointTooLarge is returned if the encoding exceeds MaxCheckpointSize.
+func (cp *Checkpoint) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte(checkpointVersion0)
+	if err := rlp.Encode(&buf, cp); err != nil {
+		return nil, err
+	}
+	if buf.Len() > MaxCheckpointSize {
+		return nil, fmt.Errorf("%w: %d bytes", ErrCheckpointTooLarge, buf.Len())
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a checkpoint encoded with MarshalBinary.
+func (cp *Checkpoint) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		return errors.New("empty checkpoint")
+	}
+	if len(data) > MaxCheckpointSize {
+		return fmt.Errorf("%w: %d bytes", ErrCheckpointTooLarge, len(data))
+	}
+	if data[0] != checkpointVersion0 {
+		return fmt.Errorf("unrecognized checkpoint version %d", data[0])
+	}
+	return rlp.DecodeBytes(data[1:], cp)
+}
+
+// checkL1Canonical checks that the origin of the checkpoint, and the L1 blocks that the buffered channels
+// and batches were derived from, are canonical.
+func (cp *Checkpoint) checkL1Canonical(ctx context.Context, l1 L1BlockRefByNumberFetcher) error {
+	refs := append([]eth.L1BlockRef{cp.Origin, cp.BatchOrigin}, cp.BatchL1Blocks...)
+	for _, ch := range cp.Channels {
+		refs = append(refs, ch.OpenBlock, ch.HighestL1InclusionBlock)
+	}
+	for _, b := range cp.Batches {
+		refs = append(refs, b.L1InclusionBlock)
+	}
+	checked := make(map[uint64]common.Hash)
+	for _, ref := range refs {
+		if ref.Number > cp.Origin.Number {
+			return fmt.Errorf("%w: L1 block %s is after the checkpoint origin %s", ErrCheckpointMismatch, ref, cp.Origin)
+		}
+		if hash, ok := checked[ref.Number]; ok {
+			if hash != ref.Hash {
+				return fmt.Errorf("%w: L1 block %s conflicts with %s", ErrCheckpointMismatch, ref, hash)
+			}
+			continue
+		}
+		canonical, err := l1.L1BlockRefByNumber(ctx, ref.Number)
+		if err != nil {
+			return fmt.Errorf("failed to fetch L1 block %d: %w", ref.Number, err)
+		}
+		if canonical.Hash != ref.Hash {
+			return fmt.Errorf("%w: L1 block %s was replaced by %s", ErrCheckpointMismatch, ref, canonical)
+		}
+		checked[ref.Number] = ref.Hash
+	}
+	return nil
+}
+
+// checkpoint returns the channels buffered in the channel bank.
+func (cb *ChannelBank) checkpoint() []ChannelCheckpoint {
+	out := make([]ChannelCheckpoint, 0, len(cb.channelQueue))
+	for _, id := range cb.channelQueue {
+		ch := cb.channels[id]
+		frames := make([]FrameCheckpoint, 0, len(ch.inputs))
+		for _, f := range ch.inputs {
+			frames = append(frames, FrameCheckpoint{FrameNumber: f.FrameNumber, Data: f.Data, IsLast: f.IsLast})
+		}
+		sort.Slice(frames, func(i, j int) bool { return frames[i].FrameNumber < frames[j].FrameNumber })
+		out = append(out, ChannelCheckpoint{
+			ID:                      id,
+			OpenBlock:               ch.openBlock,
+			HighestL1InclusionBlock: ch.highestL1InclusionBlock,
+			Frames:                  frames,
+		})
+	}
+	return out
+}
+
+// Restore replaces the contents of the channel bank with the checkpointed channels.
+func (cb *ChannelBank) Restore(ctx context.Context, cp *Checkpoint) error {
+	cb.channels = make(map[ChannelID]*Channel)
+	cb.channelQueue = make([]ChannelID, 0, len(cp.Channels))
+	for _, c := range cp.Channels {
+		if _, ok := cb.channels[c.ID]; ok {
+			return fmt.Errorf("duplicate channel %s", c.ID)
+		}
+		ch := NewChannel(c.ID, c.OpenBlock)
+		// Frames are added in order of frame number, so no frames are pruned when the last frame is added.
+		for _, f := range c.Frames {
+			frame := Frame{ID: c.ID, FrameNumber: f.FrameNumber, Data: f.Data, IsLast: f.IsLast}
+			if err := ch.AddFrame(frame, c.HighestL1InclusionBlock); err != nil {
+				return fmt.Errorf("failed to restore frame %d of channel %s: %w", f.FrameNumber, c.ID, err)
+			}
+		}
+		cb.channels[c.ID] = ch
+		cb.channelQueue = append(cb.channelQueue, c.ID)
+	}
+	return nil
+}
+
+// checkpoint returns the origin, the tracked L1 blocks and the buffered batches of the batch queue.
+func (bq *BatchQueue) checkpoint() (eth.L1BlockRef, []eth.L1BlockRef, []BatchCheckpoint, error) {
+	timestamps := make([]uint64, 0, len(bq.batches))
+	for ts := range bq.batches {
+		timestamps = append(timestamps, ts)
+	}
+	sort.Slice(timestamps, func(i, j int) bool { return timestamps[i] < timestamps[j] })
+	var batches []BatchCheckpoint
+	for _, ts := range timestamps {
+		for _, b := range bq.batches[ts] {
+			data, err := b.Batch.MarshalBinary()
+			if err != nil {
+				return eth.L1BlockRef{}, nil, nil, fmt.Errorf("failed to encode batch with timestamp %d: %w", ts, err)
+			}
+			batches = append(batches, BatchCheckpoint{L1InclusionBlock: b.L1InclusionBlock, Batch: data})
+		}
+	}
+	l1Blocks := append([]eth.L1BlockRef(nil), bq.l1Blocks...)
+	return bq.origin, l1Blocks, batches, nil
+}
+
+// Restore replaces the state of the batch queue with the checkpointed state.
+func (bq *BatchQueue) Restore(ctx context.Context, cp *Checkpoint) error {
+	bq.origin = cp.BatchOrigin
+	bq.l1Blocks = append(bq.l1Blocks[:0], cp.BatchL1Blocks...)
+	bq.batches = make(map[uint64][]*BatchWithL1InclusionBlock)
+	for i, b := range cp.Batches {
+		var batch BatchData
+		if err := batch.UnmarshalBinary(b.Batch); err != nil {
+			return fmt.Errorf("failed to decode batch %d: %w", i, err)
+		}
+		bq.batches[batch.Timestamp] = append(bq.batches[batch.Timestamp], &BatchWithL1InclusionBlock{
+			L1InclusionBlock: b.L1InclusionBlock,
+			Batch:            &batch,
+		})
+	}
+	return nil
+}
+
+// checkL2Canonical checks that the given L2 block is canonical in the engine.
+func checkL2Canonical(ctx context.Context, engine Engine, ref eth.L2BlockRef) error {
+	payload, err := engine.PayloadByNumber(ctx, ref.Number)
+	if err != nil {
+		return fmt.Errorf("failed to fetch L2 block %d: %w", ref.Number, err)
+	}
+	if payload.BlockHash != ref.Hash {
+		return fmt.Errorf("%w: L2 block %s was replaced by %s", ErrCheckpointMismatch, ref, payload.ID())
+	}
+	return nil
+}
+
+// Restore sets the safe, unsafe and finalized heads of the checkpoint, after checking that they are canonical.
+// If the engine has a more recent unsafe block that builds on the checkpoint, it is used as unsafe head.
+func (eq *EngineQueue) Restore(ctx context.Context, cp *Checkpoint) error {
+	for _, ref := range []eth.L2BlockRef{cp.Finalized, cp.SafeHead, cp.UnsafeHead} {
+		if err := checkL2Canonical(ctx, eq.engine, ref); err != nil {
+			return err
+		}
+	}
+	unsafe, err := eq.engine.L2BlockRefByLabel(ctx, eth.Unsafe)
+	if err != nil {
+		return fmt.Errorf("failed to fetch the unsafe head: %w", err)
+	}
+	if unsafe.Number < cp.UnsafeHead.Number {
+		return fmt.Errorf("%w: unsafe head %s is behind the checkpoint unsafe head %s", ErrCheckpointMismatch, unsafe, cp.UnsafeHead)
+	}
+	if eq.safeHeadNotifs != nil {
+		if err := eq.safeHeadNotifs.SafeHeadReset(ctx, cp.SafeHead, eq.l1Fetcher); err != nil {
+			return fmt.Errorf("failed to notify safe head reset: %w", err)
+		}
+	}
+	eq.unsafeHead = unsafe
+	eq.safeHead = cp.SafeHead
+	eq.finalized = cp.Finalized
+	eq.needForkchoiceUpdate = true
+	eq.finalityData = eq.finalityData[:0]
+	eq.safeAttributes = eq.safeAttributes[:0]
+	eq.origin = cp.Origin
+	eq.metrics.RecordL2Ref("l2_finalized", cp.Finalized)
+	eq.metrics.RecordL2Ref("l2_safe", cp.SafeHead)
+	eq.metrics.RecordL2Ref("l2_unsafe", unsafe)
+	eq.logSyncProgress("restored derivation checkpoint")
+	return nil
+}
@@ -0,0 +1,201 @@
+package derive
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	"github.com/ethereum-optimism/optimism/op-node/testutils"
+)
+
+func TestChannelBankCheckpoint(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	a := testutils.RandomBlockRef(rng)
+	cfg := &rollup.Config{ChannelTimeout: 10}
+
+	input := &fakeChannelBankInput{origin: a}
+	input.AddFrames("a:2:third!", "a:0:first", "b:0:other")
+	input.AddFrame(Frame{}, io.EOF)
+	cb := NewChannelBank(testlog.Logger(t, log.LvlCrit), cfg, input, nil)
+	for i := 0; i < 3; i++ {
+		_, err := cb.NextData(context.Background())
+		require.ErrorIs(t, err, NotEnoughData)
+	}
+	_, err := cb.NextData(context.Background())
+	require.ErrorIs(t, err, io.EOF)
+
+	channels := cb.checkpoint()
+	require.Len(t, channels, 2)
+	require.Equal(t, uint16(0), channels[0].Frames[0].FrameNumber, "frames are ordered by frame number")
+
+	// Restore into a fresh channel bank, and complete the first channel
+	input = &fakeChannelBankInput{origin: a}
+	input.AddFrames("a:1:second")
+	input.AddFrame(Frame{}, io.EOF)
+	restored := NewChannelBank(testlog.Logger(t, log.LvlCrit), cfg, input, nil)
+	require.NoError(t, restored.Restore(context.Background(), &Checkpoint{Channels: channels}))
+	require.Equal(t, channels, restored.checkpoint())
+
+	_, err = restored.NextData(context.Background())
+	require.ErrorIs(t, err, NotEnoughData)
+	out, err := restored.NextData(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "firstsecondthird", string(out))
+}
+
+func TestBatchQueueCheckpoint(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	l1 := testutils.RandomBlockRef(rng)
+	cfg := &rollup.Config{BlockTime: 2}
+
+	bq := NewBatchQueue(testlog.Logger(t, log.LvlCrit), cfg, nil)
+	bq.origin = l1
+	bq.l1Blocks = []eth.L1BlockRef{l1}
+	bq.batches = make(map[uint64][]*BatchWithL1InclusionBlock)
+	for _, ts := range []uint64{12, 10, 10} {
+		batch := &BatchData{BatchV1{EpochNum: rollup.Epoch(l1.Number), EpochHash: l1.Hash, Timestamp: ts, Transactions: []hexutil.Bytes{{byte(ts)}}}}
+		bq.batches[ts] = append(bq.batches[ts], &BatchWithL1InclusionBlock{L1InclusionBlock: l1, Batch: batch})
+	}
+
+	origin, l1Blocks, batches, err := bq.checkpoint()
+	require.NoError(t, err)
+	require.Len(t, batches, 3)
+
+	restored := NewBatchQueue(testlog.Logger(t, log.LvlCrit), cfg, nil)
+	require.NoError(t, restored.Restore(context.Background(), &Checkpoint{BatchOrigin: origin, BatchL1Blocks: l1Blocks, Batches: batches}))
+	require.Equal(t, bq.origin, restored.origin)
+	require.Equal(t, bq.l1Blocks, restored.l1Blocks)
+	require.Equal(t, bq.batches, restored.batches)
+}
+
+func testCheckpoint(rng *rand.Rand) *Checkpoint {
+	origin := testutils.RandomBlockRef(rng)
+	parent := eth.L1BlockRef{Hash: origin.ParentHash, Number: origin.Number - 1}
+	safe := testutils.RandomL2BlockRef(rng)
+	batch := &BatchData{BatchV1{EpochNum: rollup.Epoch(parent.Number), EpochHash: parent.Hash, Timestamp: safe.Time + 2}}
+	batchData, err := batch.MarshalBinary()
+	if err != nil {
+		panic(err)
+	}
+	return &Checkpoint{
+		Origin:     origin,
+		SafeHead:   safe,
+		UnsafeHead: safe,
+		Finalized:  safe,
+		Channels: []ChannelCheckpoint{{
+			ID:                      ChannelID{0xaa},
+			OpenBlock:               parent,
+			HighestL1InclusionBlock: origin,
+			Frames:                  []FrameCheckpoint{{FrameNumber: 0, Data: []byte("first")}, {FrameNumber: 1, Data: []byte("last"), IsLast: true}},
+		}},
+		BatchOrigin:   parent,
+		BatchL1Blocks: []eth.L1BlockRef{parent, origin},
+		Batches:       []BatchCheckpoint{{L1InclusionBlock: origin, Batch: batchData}},
+	}
+}
+
+func TestCheckpointEncoding(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	cp := testCheckpoint(rng)
+
+	data, err := cp.MarshalBinary()
+	require.NoError(t, err)
+	require.Equal(t, byte(checkpointVersion0), data[0])
+	var decoded Checkpoint
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	require.Equal(t, cp, &decoded)
+
+	// frame data is encoded as is
+	cp.Channels[0].Frames[0].Data = make([]byte, 1000)
+	large, err := cp.MarshalBinary()
+	require.NoError(t, err)
+	require.Less(t, len(large), len(data)+1000+10)
+
+	require.Error(t, decoded.UnmarshalBinary(append([]byte{1}, data[1:]...)), "unknown version")
+	require.Error(t, decoded.UnmarshalBinary(data[:len(data)-1]), "truncated")
+	require.ErrorIs(t, decoded.UnmarshalBinary(make([]byte, MaxCheckpointSize+1)), ErrCheckpointTooLarge)
+}
+
+func TestCheckpointCheckL1Canonical(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	cp := testCheckpoint(rng)
+	parent := cp.BatchOrigin
+
+	l1 := &testutils.MockL1Source{}
+	l1.ExpectL1BlockRefByNumber(parent.Number, parent, nil)
+	l1.ExpectL1BlockRefByNumber(cp.Origin.Number, cp.Origin, nil)
+	require.NoError(t, cp.checkL1Canonical(context.Background(), l1))
+	l1.AssertExpectations(t)
+
+	// a buffered batch from a reorged L1 block
+	reorged := testCheckpoint(rand.New(rand.NewSource(1234)))
+	reorged.Batches[0].L1InclusionBlock.Hash = testutils.RandomHash(rng)
+	l1 = &testutils.MockL1Source{}
+	l1.ExpectL1BlockRefByNumber(cp.Origin.Number, cp.Origin, nil)
+	l1.ExpectL1BlockRefByNumber(parent.Number, parent, nil)
+	require.ErrorIs(t, reorged.checkL1Canonical(context.Background(), l1), ErrCheckpointMismatch)
+
+	// a buffered channel opened at a reorged L1 block
+	reorged = testCheckpoint(rand.New(rand.NewSource(1234)))
+	reorged.Channels[0].OpenBlock.Hash = testutils.RandomHash(rng)
+	l1 = &testutils.MockL1Source{}
+	l1.ExpectL1BlockRefByNumber(cp.Origin.Number, cp.Origin, nil)
+	l1.ExpectL1BlockRefByNumber(parent.Number, parent, nil)
+	require.ErrorIs(t, reorged.checkL1Canonical(context.Background(), l1), ErrCheckpointMismatch)
+
+	// the origin is reorged
+	l1 = &testutils.MockL1Source{}
+	l1.ExpectL1BlockRefByNumber(cp.Origin.Number, testutils.RandomBlockRef(rng), nil)
+	require.ErrorIs(t, cp.checkL1Canonical(context.Background(), l1), ErrCheckpointMismatch)
+}
+
+func TestDerivationPipelineRestore(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	cp := testCheckpoint(rng)
+	parent := cp.BatchOrigin
+	cfg := &rollup.Config{ChannelTimeout: 10, BlockTime: 2}
+
+	l1 := &testutils.MockL1Source{}
+	engine := &testutils.MockEngine{}
+	dp := NewDerivationPipeline(testlog.Logger(t, log.LvlError), cfg, l1, engine, &testutils.TestDerivationMetrics{}, PipelineHooks{})
+
+	// A checkpoint with a buffered channel from a reorged L1 block is not restored, the pipeline is left to reset
+	reorged := testCheckpoint(rand.New(rand.NewSource(1234)))
+	reorged.Channels[0].OpenBlock.Hash = testutils.RandomHash(rng)
+	l1.ExpectL1BlockRefByNumber(cp.Origin.Number, cp.Origin, nil)
+	l1.ExpectL1BlockRefByNumber(parent.Number, parent, nil)
+	dp.resetting = len(dp.stages)
+	require.ErrorIs(t, dp.Restore(context.Background(), reorged), ErrCheckpointMismatch)
+	require.Equal(t, 0, dp.resetting)
+
+	l1.ExpectL1BlockRefByNumber(cp.Origin.Number, cp.Origin, nil)
+	l1.ExpectL1BlockRefByNumber(parent.Number, parent, nil)
+	var noErr error
+	engine.Mock.On("PayloadByNumber", cp.SafeHead.Number).Times(3).Return(&eth.ExecutionPayload{BlockHash: cp.SafeHead.Hash}, &noErr)
+	engine.ExpectL2BlockRefByLabel(eth.Unsafe, cp.UnsafeHead, nil)
+	require.NoError(t, dp.Restore(context.Background(), cp))
+	l1.AssertExpectations(t)
+	engine.AssertExpectations(t)
+
+	// Every stage is restored in place of its reset
+	require.Equal(t, len(dp.stages), dp.resetting)
+	require.Equal(t, cp.Origin, dp.Origin())
+	require.Equal(t, cp.SafeHead, dp.SafeL2Head())
+	require.Equal(t, cp.Channels, dp.bank.checkpoint())
+	batchOrigin, batchL1Blocks, batches, err := dp.batchQueue.checkpoint()
+	require.NoError(t, err)
+	require.Equal(t, cp.BatchOrigin, batchOrigin)
+	require.Equal(t, cp.BatchL1Blocks, batchL1Blocks)
+	require.Len(t, batches, 1)
+	// The data of the origin was processed, the traversal waits for the next L1 block
+	_, err = dp.traversal.NextL1Block(context.Background())
+	require.ErrorIs(t, err, io.EOF)
+}
@@ -0,0 +1,158 @@
+package derive
+
+import (
+	"bufio"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// CompressionCodec identifies the compression algorithm used for the data of a channel.
+//
+// The zlib codec is the original channel encoding and carries no prefix: a zlib stream is
+// recognized by the compression method (lower nibble of the first byte) being 8 (deflate).
+// Every other codec prefixes the compressed stream with its version byte.
+// Version bytes are chosen such that their lower nibble never equals 8,
+// so they cannot be confused with the start of a zlib stream.
+type CompressionCodec byte
+
+const (
+	// CodecZlib is the default codec: a zlib stream at the best compression level.
+	CodecZlib CompressionCodec = 0
+	// CodecZstd is a zstd stream, prefixed with the ChannelVersionZstd byte.
+	CodecZstd CompressionCodec = 1
+)
+
+const (
+	// zlibCompressionMethod is the CM value of the zlib CMF header byte for deflate.
+	zlibCompressionMethod = 8
+	// ChannelVersionZstd is the version byte that prefixes zstd compressed channel data.
+	ChannelVersionZstd = 0x01
+)
+
+var (
+	ErrUnknownCompressionCodec = errors.New("unknown compression codec")
+	// ErrCodecNotActive is returned when a channel uses a codec that is not yet activated by the rollup config.
+	ErrCodecNotActive = errors.New("compression codec not active")
+)
+
+func (c CompressionCodec) String() string {
+	switch c {
+	case CodecZlib:
+		return "zlib"
+	case CodecZstd:
+		return "zstd"
+	default:
+		return fmt.Sprintf("unknown(%d)", byte(c))
+	}
+}
+
+// ParseCompressionCodec parses the name of a codec, as returned by CompressionCodec.String.
+func ParseCompressionCodec(name string) (CompressionCodec, error) {
+	switch name {
+	case "zlib":
+		return CodecZlib, nil
+	case "zstd":
+		return CodecZstd, nil
+	default:
+		return 0, fmt.Errorf("%w: %q", ErrUnknownCompressionCodec, name)
+	}
+}
+
+// compressor is the compression stage of a ChannelOut.
+type compressor interface {
+	io.WriteCloser
+	// Flush flushes any pending data to the underlying writer.
+	Flush() error
+	// Reset discards the compressor state and makes it write to w instead.
+	Reset(w io.Writer)
+}
+
+// zstdCompressor adapts the zstd encoder to the compressor interface.
+type zstdCompressor struct {
+	*zstd.Encoder
+}
+
+func (z zstdCompressor) Reset(w io.Writer) {
+	z.Encoder.Reset(w)
+}
+
+// versionPrefix returns the bytes that must be written to the channel before the compressed stream.
+func (c CompressionCodec) versionPrefix() []byte {
+	switch c {
+	case CodecZstd:
+		return []byte{ChannelVersionZstd}
+	default:
+		return nil
+	}
+}
+
+// newCompressor creates the compressor for the codec, writing the compressed stream to w.
+// The version prefix is not written by the compressor.
+func (c CompressionCodec) newCompressor(w io.Writer) (compressor, error) {
+	switch c {
+	case CodecZlib:
+		return zlib.NewWriterLevel(w, zlib.BestCompression)
+	case CodecZstd:
+		enc, err := zstd.NewWriter(w,
+			zstd.WithEncoderLevel(zstd.SpeedBestCompression),
+			zstd.WithEncoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		return zstdCompressor{enc}, nil
+	default:
+		return nil, fmt.Errorf("%w: %d", ErrUnknownCompressionCodec, byte(c))
+	}
+}
+
+// zstdReader closes the zstd decoder once the end of the stream is reached,
+// to release the decoder resources without requiring an explicit Close from the batch reader.
+type zstdReader struct {
+	dec *zstd.Decoder
+}
+
+func (z *zstdReader) Read(p []byte) (int, error) {
+	n, err := z.dec.Read(p)
+	if err != nil {
+		z.dec.Close()
+	}
+	return n, err
+}
+
+// decompressChannel inspects the version of the channel data and returns a reader of the decompressed data.
+// Zstd channel data is rejected with ErrCodecNotActive unless zstdActive is set.
+func decompressChannel(r io.Reader, zstdActive bool) (io.Reader, CompressionCodec, error) {
+	br := bufio.NewReader(r)
+	versionByte, err := br.Peek(1)
+	if err != nil {
+		return nil, 0, err
+	}
+	switch {
+	case versionByte[0]&0x0F == zlibCompressionMethod:
+		zr, err := zlib.NewReader(br)
+		if err != nil {
+			return nil, 0, err
+		}
+		return zr, CodecZlib, nil
+	case versionByte[0] == ChannelVersionZstd:
+		if !zstdActive {
+			return nil, 0, fmt.Errorf("%w: %s", ErrCodecNotActive, CodecZstd)
+		}
+		if _, err := br.Discard(1); err != nil {
+			return nil, 0, err
+		}
+		dec, err := zstd.NewReader(br,
+			zstd.WithDecoderConcurrency(1),
+			zstd.WithDecoderMaxMemory(MaxRLPBytesPerChannel))
+		if err != nil {
+			return nil, 0, err
+		}
+		return &zstdReader{dec: dec}, CodecZstd, nil
+	default:
+		return nil, 0, fmt.Errorf("%w: channel version byte %d", ErrUnknownCompressionCodec, versionByte[0])
+	}
+}
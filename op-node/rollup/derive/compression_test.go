@@ -0,0 +1,206 @@
+package derive
+
+import (
+	"bytes"
+	"io"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/testutils"
+)
+
+// zstdActiveConfig returns a rollup config that accepts zstd channels from the given L1 timestamp.
+func zstdActiveConfig(zstdTime uint64) *rollup.Config {
+	return &rollup.Config{ZstdTime: &zstdTime}
+}
+
+// randomL2Block creates a block with an L1 info deposit and a number of random user transactions,
+// suitable for adding to a ChannelOut.
+func randomL2Block(t testing.TB, rng *rand.Rand, txCount int) *types.Block {
+	l1Info := testutils.RandomBlockInfo(rng)
+	l1InfoTx, err := L1InfoDeposit(0, l1Info)
+	require.NoError(t, err)
+	txs := []*types.Transaction{types.NewTx(l1InfoTx)}
+
+	key := testutils.RandomKey()
+	signer := types.NewLondonSigner(big.NewInt(901))
+	for i := 0; i < txCount; i++ {
+		to := testutils.RandomAddress(rng)
+		tx := types.MustSignNewTx(key, signer, &types.DynamicFeeTx{
+			ChainID:   signer.ChainID(),
+			Nonce:     uint64(i),
+			GasTipCap: big.NewInt(1_000_000_000),
+			GasFeeCap: big.NewInt(2_000_000_000),
+			Gas:       21000 + uint64(rng.Intn(100_000)),
+			To:        &to,
+			Value:     testutils.RandomETH(rng, 10),
+			// transfers and contract calls are not all random data: mix in zeroes to resemble real calldata
+			Data: append(make([]byte, rng.Intn(64)), testutils.RandomData(rng, rng.Intn(200))...),
+		})
+		txs = append(txs, tx)
+	}
+	header := &types.Header{
+		ParentHash: testutils.RandomHash(rng),
+		Number:     big.NewInt(rng.Int63n(1_000_000)),
+		Time:       rng.Uint64(),
+		Difficulty: common.Big0,
+	}
+	return types.NewBlockWithHeader(header).WithBody(txs, nil)
+}
+
+// channelFrames closes the channel and collects the data of all its frames, in order.
+func channelFrames(t testing.TB, co *ChannelOut, maxFrameSize uint64) []Frame {
+	require.NoError(t, co.Close())
+	var frames []Frame
+	for {
+		var buf bytes.Buffer
+		err := co.OutputFrame(&buf, maxFrameSize)
+		if err != io.EOF {
+			require.NoError(t, err)
+		}
+		var f Frame
+		require.NoError(t, f.UnmarshalBinary(&buf))
+		frames = append(frames, f)
+		if err == io.EOF {
+			return frames
+		}
+	}
+}
+
+func TestChannelOutCodecRoundTrip(t *testing.T) {
+	for _, codec := range []CompressionCodec{CodecZlib, CodecZstd} {
+		codec := codec
+		t.Run(codec.String(), func(t *testing.T) {
+			rng := rand.New(rand.NewSource(1234))
+			co, err := NewChannelOutWithCodec(codec)
+			require.NoError(t, err)
+			require.Equal(t, codec, co.Codec())
+
+			var blocks []*types.Block
+			for i := 0; i < 10; i++ {
+				block := randomL2Block(t, rng, rng.Intn(20))
+				require.NoError(t, co.AddBlock(block))
+				blocks = append(blocks, block)
+			}
+			frames := channelFrames(t, co, 1000)
+			require.Greater(t, len(frames), 1, "expected the channel to span multiple frames")
+
+			ch := NewChannel(co.ID(), testutils.RandomBlockRef(rng))
+			for _, f := range frames {
+				require.NoError(t, ch.AddFrame(f, testutils.RandomBlockRef(rng)))
+			}
+			require.True(t, ch.IsReady())
+
+			next, err := BatchReader(zstdActiveConfig(0), ch.Reader(), testutils.RandomBlockRef(rng))
+			require.NoError(t, err)
+			for _, block := range blocks {
+				batch, err := next()
+				require.NoError(t, err)
+				expected, err := blockToBatch(block)
+				require.NoError(t, err)
+				require.Equal(t, expected.BatchV1, batch.Batch.BatchV1)
+			}
+			_, err = next()
+			require.ErrorIs(t, err, io.EOF)
+		})
+	}
+}
+
+func TestChannelOutResetKeepsCodec(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	co, err := NewChannelOutWithCodec(CodecZstd)
+	require.NoError(t, err)
+	require.NoError(t, co.AddBlock(randomL2Block(t, rng, 3)))
+	require.NoError(t, co.Reset())
+
+	block := randomL2Block(t, rng, 3)
+	require.NoError(t, co.AddBlock(block))
+	frames := channelFrames(t, co, 100_000)
+	require.Len(t, frames, 1)
+	require.Equal(t, byte(ChannelVersionZstd), frames[0].Data[0])
+
+	next, err := BatchReader(zstdActiveConfig(0), bytes.NewReader(frames[0].Data), testutils.RandomBlockRef(rng))
+	require.NoError(t, err)
+	batch, err := next()
+	require.NoError(t, err)
+	require.Equal(t, block.Time(), batch.Batch.Timestamp)
+}
+
+func TestBatchReaderUnknownVersion(t *testing.T) {
+	_, err := BatchReader(zstdActiveConfig(0), bytes.NewReader([]byte{0x02, 0xaa, 0xbb}), testutils.RandomBlockRef(rand.New(rand.NewSource(1))))
+	require.ErrorIs(t, err, ErrUnknownCompressionCodec)
+}
+
+func TestBatchReaderZstdActivation(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	co, err := NewChannelOutWithCodec(CodecZstd)
+	require.NoError(t, err)
+	require.NoError(t, co.AddBlock(randomL2Block(t, rng, 3)))
+	data := channelFrames(t, co, 100_000)[0].Data
+
+	const zstdTime = 1000
+	inclusion := func(time uint64) eth.L1BlockRef {
+		ref := testutils.RandomBlockRef(rng)
+		ref.Time = time
+		return ref
+	}
+
+	_, err = BatchReader(&rollup.Config{}, bytes.NewReader(data), inclusion(zstdTime))
+	require.ErrorIs(t, err, ErrCodecNotActive, "zstd is disabled without activation time")
+	_, err = BatchReader(zstdActiveConfig(zstdTime), bytes.NewReader(data), inclusion(zstdTime-1))
+	require.ErrorIs(t, err, ErrCodecNotActive, "zstd is rejected before activation")
+	_, err = BatchReader(zstdActiveConfig(zstdTime), bytes.NewReader(data), inclusion(zstdTime))
+	require.NoError(t, err, "zstd is accepted from activation")
+
+	// zlib channels are always accepted
+	co, err = NewChannelOutWithCodec(CodecZlib)
+	require.NoError(t, err)
+	require.NoError(t, co.AddBlock(randomL2Block(t, rng, 3)))
+	_, err = BatchReader(&rollup.Config{}, bytes.NewReader(channelFrames(t, co, 100_000)[0].Data), inclusion(0))
+	require.NoError(t, err)
+}
+
+func TestParseCompressionCodec(t *testing.T) {
+	for _, codec := range []CompressionCodec{CodecZlib, CodecZstd} {
+		parsed, err := ParseCompressionCodec(codec.String())
+		require.NoError(t, err)
+		require.Equal(t, codec, parsed)
+	}
+	_, err := ParseCompressionCodec("lz4")
+	require.ErrorIs(t, err, ErrUnknownCompressionCodec)
+}
+
+// BenchmarkChannelOutCodecs compares the compression ratio and speed of the codecs
+// when filling a channel with L2 blocks.
+// Use op-node's `compression bench` subcommand to compare codecs on real L2 blocks.
+func BenchmarkChannelOutCodecs(b *testing.B) {
+	rng := rand.New(rand.NewSource(1234))
+	var blocks []*types.Block
+	for i := 0; i < 50; i++ {
+		blocks = append(blocks, randomL2Block(b, rng, rng.Intn(50)))
+	}
+	for _, codec := range []CompressionCodec{CodecZlib, CodecZstd} {
+		codec := codec
+		b.Run(codec.String(), func(b *testing.B) {
+			var inputSize, outputSize int
+			for i := 0; i < b.N; i++ {
+				co, err := NewChannelOutWithCodec(codec)
+				require.NoError(b, err)
+				for _, block := range blocks {
+					require.NoError(b, co.AddBlock(block))
+				}
+				require.NoError(b, co.Close())
+				inputSize = co.InputBytes()
+				outputSize = co.ReadyBytes()
+			}
+			b.ReportMetric(float64(outputSize)/float64(inputSize), "ratio")
+		})
+	}
+}
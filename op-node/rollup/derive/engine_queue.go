@@ -58,6 +58,45 @@ type FinalityData struct {
 	L1Block eth.BlockID
 }
 
+// SafeHeadListener is notified of changes to the safe head, and the L1 block it was derived from.
+type SafeHeadListener interface {
+	// SafeHeadUpdated indicates that the safe head was updated,
+	// and was fully derived from L1 data up to and including the given L1 block.
+	SafeHeadUpdated(safeHead eth.L2BlockRef, l1Block eth.BlockID) error
+	// SafeHeadReset indicates that the derivation pipeline was reset to the given safe head.
+	// Data derived from L1 blocks that are no longer canonical, as checked with the given L1 source, is invalid.
+	SafeHeadReset(ctx context.Context, resetSafeHead eth.L2BlockRef, l1 L1BlockRefByNumberFetcher) error
+}
+
+// AltSync is an alternative source of unsafe L2 blocks, e.g. p2p peers,
+// to fill gaps in the unsafe chain that were missed by the regular gossip.
+type AltSync interface {
+	// RequestL2Range signals that the given range of L2 blocks, inclusive, is missing.
+	// Retrieved blocks are added back as unsafe payloads. It must not block.
+	RequestL2Range(start, end uint64) error
+}
+
+// UnsafePayloadStore persists unsafe payloads, so that payloads that were not yet derived from L1
+// can be re-inserted after a restart, instead of waiting for them to be gossiped again.
+type UnsafePayloadStore interface {
+	// StorePayload persists the payload. The store may drop the payloads with the lowest block numbers to stay bounded.
+	StorePayload(payload *eth.ExecutionPayload) error
+	// PrunePayloads removes all payloads up to and including the given block number.
+	PrunePayloads(num uint64) error
+	// Payloads returns all stored payloads, ordered by block number.
+	Payloads() ([]*eth.ExecutionPayload, error)
+}
+
+// PipelineHooks are the optional extensions of the derivation pipeline. A nil hook is disabled.
+type PipelineHooks struct {
+	// SafeHeadNotifs is notified of changes to the safe head.
+	SafeHeadNotifs SafeHeadListener
+	// AltSync is requested to retrieve the unsafe blocks that are missing from the unsafe chain.
+	AltSync AltSync
+	// UnsafeStore persists the unsafe payloads, to insert them again after a restart.
+	UnsafeStore UnsafePayloadStore
+}
+
 // EngineQueue queues up payload attributes to consolidate or process with the provided Engine
 type EngineQueue struct {
 	log log.Logger
@@ -87,11 +126,16 @@ type EngineQueue struct {
 
 	metrics   Metrics
 	l1Fetcher L1Fetcher
+
+	safeHeadNotifs SafeHeadListener   // may be nil, notifications of safe head changes are optional
+	altSync        AltSync            // may be nil, syncing of missing unsafe blocks is optional
+	unsafeStore    UnsafePayloadStore // may be nil, persistence of unsafe payloads is optional
 }
 
 // NewEngineQueue creates a new EngineQueue, which should be Reset(origin) before use.
-func NewEngineQueue(log log.Logger, cfg *rollup.Config, engine Engine, metrics Metrics, prev NextAttributesProvider, l1Fetcher L1Fetcher) *EngineQueue {
-	return &EngineQueue{
+// The unsafe payloads in the unsafe store of the hooks, if any, are queued right away.
+func NewEngineQueue(log log.Logger, cfg *rollup.Config, engine Engine, metrics Metrics, prev NextAttributesProvider, l1Fetcher L1Fetcher, hooks PipelineHooks) *EngineQueue {
+	eq := &EngineQueue{
 		log:          log,
 		cfg:          cfg,
 		engine:       engine,
@@ -101,8 +145,36 @@ func NewEngineQueue(log log.Logger, cfg *rollup.Config, engine Engine, metrics M
 			MaxSize: maxUnsafePayloadsMemory,
 			SizeFn:  payloadMemSize,
 		},
-		prev:      prev,
-		l1Fetcher: l1Fetcher,
+		prev:           prev,
+		l1Fetcher:      l1Fetcher,
+		safeHeadNotifs: hooks.SafeHeadNotifs,
+		altSync:        hooks.AltSync,
+		unsafeStore:    hooks.UnsafeStore,
+	}
+	eq.loadUnsafePayloads()
+	return eq
+}
+
+// loadUnsafePayloads queues the unsafe payloads that were persisted before a restart.
+// Payloads that were already processed before the restart are skipped once the engine queue is reset.
+func (eq *EngineQueue) loadUnsafePayloads() {
+	if eq.unsafeStore == nil {
+		return
+	}
+	payloads, err := eq.unsafeStore.Payloads()
+	if err != nil {
+		// The stored payloads are an optimization, they will be derived from L1 eventually.
+		eq.log.Error("failed to load persisted unsafe payloads", "err", err)
+		return
+	}
+	for _, payload := range payloads {
+		if err := eq.unsafePayloads.Push(payload); err != nil {
+			eq.log.Warn("Could not add persisted unsafe payload", "id", payload.ID(), "err", err)
+		}
+	}
+	if len(payloads) > 0 {
+		eq.log.Info("Loaded persisted unsafe payloads", "count", len(payloads),
+			"first", payloads[0].ID(), "last", payloads[len(payloads)-1].ID())
 	}
 }
 
@@ -125,9 +197,24 @@ func (eq *EngineQueue) AddUnsafePayload(payload *eth.ExecutionPayload) {
 		eq.log.Warn("Could not add unsafe payload", "id", payload.ID(), "timestamp", uint64(payload.Timestamp), "err", err)
 		return
 	}
+	if eq.unsafeStore != nil {
+		if err := eq.unsafeStore.StorePayload(payload); err != nil {
+			eq.log.Error("failed to persist unsafe payload", "id", payload.ID(), "err", err)
+		}
+	}
 	p := eq.unsafePayloads.Peek()
 	eq.metrics.RecordUnsafePayloadsBuffer(uint64(eq.unsafePayloads.Len()), eq.unsafePayloads.MemSize(), p.ID())
 	eq.log.Trace("Next unsafe payload to process", "next", p.ID(), "timestamp", uint64(p.Timestamp))
+
+	// If the next payload does not connect to the unsafe head, then we missed some blocks in between.
+	// Request them, instead of waiting for the batcher to submit them to L1.
+	if eq.altSync != nil && uint64(p.BlockNumber) > eq.unsafeHead.Number+1 {
+		start, end := eq.unsafeHead.Number+1, uint64(p.BlockNumber)-1
+		eq.log.Debug("Requesting missing unsafe blocks", "start", start, "end", end)
+		if err := eq.altSync.RequestL2Range(start, end); err != nil {
+			eq.log.Warn("Failed to request missing unsafe blocks", "start", start, "end", end, "err", err)
+		}
+	}
 }
 
 func (eq *EngineQueue) AddSafeAttributes(attributes *eth.PayloadAttributes) {
@@ -246,6 +333,18 @@ func (eq *EngineQueue) postProcessSafeL2() {
 		// if it's a now L2 block that was derived from the same latest L1 block, then just update the entry
 		eq.finalityData[len(eq.finalityData)-1].L2Block = eq.safeHead
 	}
+	if eq.safeHeadNotifs != nil {
+		if err := eq.safeHeadNotifs.SafeHeadUpdated(eq.safeHead, eq.origin.ID()); err != nil {
+			// The safe head history is auxiliary data, failing to record it does not affect derivation.
+			eq.log.Error("failed to notify safe head update", "safe_head", eq.safeHead, "l1_block", eq.origin, "err", err)
+		}
+	}
+	// Safe blocks are derived from L1 after a restart, the unsafe payloads are not needed anymore.
+	if eq.unsafeStore != nil {
+		if err := eq.unsafeStore.PrunePayloads(eq.safeHead.Number); err != nil {
+			eq.log.Error("failed to prune persisted unsafe payloads", "safe_head", eq.safeHead, "err", err)
+		}
+	}
 }
 
 func (eq *EngineQueue) logSyncProgress(reason string) {
@@ -294,6 +393,14 @@ func (eq *EngineQueue) tryNextUnsafePayload(ctx context.Context) error {
 		return nil
 	}
 
+	// Payloads at or below the unsafe head were processed already, e.g. persisted payloads that are loaded after a restart,
+	// or conflict with the unsafe chain. Either way they cannot be processed, and would block the payloads after them.
+	if uint64(first.BlockNumber) <= eq.unsafeHead.Number {
+		eq.log.Debug("skipping unsafe payload, since it is not newer than the unsafe head", "unsafe", eq.unsafeHead.ID(), "payload", first.ID())
+		eq.unsafePayloads.Pop()
+		return nil
+	}
+
 	// Ensure that the unsafe payload builds upon the current unsafe head
 	// TODO: once we support snap-sync we can remove this condition, and handle the "SYNCING" status of the execution engine.
 	if first.ParentHash != eq.unsafeHead.Hash {
@@ -499,6 +606,11 @@ func (eq *EngineQueue) Reset(ctx context.Context, _ eth.L1BlockRef) error {
 	eq.finalityData = eq.finalityData[:0]
 	// note: we do not clear the unsafe payloadds queue; if the payloads are not applicable anymore the parent hash checks will clear out the old payloads.
 	eq.origin = pipelineOrigin
+	if eq.safeHeadNotifs != nil {
+		if err := eq.safeHeadNotifs.SafeHeadReset(ctx, safe, eq.l1Fetcher); err != nil {
+			return NewTemporaryError(fmt.Errorf("failed to notify safe head reset: %w", err))
+		}
+	}
 	eq.metrics.RecordL2Ref("l2_finalized", finalized)
 	eq.metrics.RecordL2Ref("l2_safe", safe)
 	eq.metrics.RecordL2Ref("l2_unsafe", unsafe)
@@ -230,7 +230,7 @@ func TestEngineQueue_Finalize(t *testing.T) {
 
 	prev := &fakeAttributesQueue{}
 
-	eq := NewEngineQueue(logger, cfg, eng, metrics, prev, l1F)
+	eq := NewEngineQueue(logger, cfg, eng, metrics, prev, l1F, PipelineHooks{})
 	require.ErrorIs(t, eq.Reset(context.Background(), eth.L1BlockRef{}), io.EOF)
 
 	require.Equal(t, refB1, eq.SafeL2Head(), "L2 reset should go back to sequence window ago: blocks with origin E and D are not safe until we reconcile, C is extra, and B1 is the end we look for")
@@ -257,3 +257,101 @@ func TestEngineQueue_Finalize(t *testing.T) {
 	l1F.AssertExpectations(t)
 	eng.AssertExpectations(t)
 }
+
+type fakeAltSync struct {
+	requests [][2]uint64
+}
+
+func (f *fakeAltSync) RequestL2Range(start, end uint64) error {
+	f.requests = append(f.requests, [2]uint64{start, end})
+	return nil
+}
+
+var _ AltSync = (*fakeAltSync)(nil)
+
+func TestEngineQueue_AltSyncGap(t *testing.T) {
+	logger := testlog.Logger(t, log.LvlInfo)
+	rng := rand.New(rand.NewSource(1234))
+	cfg := &rollup.Config{}
+	metrics := &testutils.TestDerivationMetrics{}
+	altSync := &fakeAltSync{}
+
+	eq := NewEngineQueue(logger, cfg, nil, metrics, nil, nil, PipelineHooks{AltSync: altSync})
+	eq.unsafeHead = testutils.RandomL2BlockRef(rng)
+	eq.unsafeHead.Number = 10
+
+	payload := func(num uint64) *eth.ExecutionPayload {
+		return &eth.ExecutionPayload{BlockHash: testutils.RandomHash(rng), BlockNumber: eth.Uint64Quantity(num)}
+	}
+
+	eq.AddUnsafePayload(payload(11))
+	require.Empty(t, altSync.requests, "payload connects to the unsafe head")
+
+	eq.unsafePayloads.Pop()
+	eq.AddUnsafePayload(payload(14))
+	require.Equal(t, [][2]uint64{{11, 13}}, altSync.requests, "blocks between the unsafe head and the payload are missing")
+
+	eq.AddUnsafePayload(payload(12))
+	require.Equal(t, [][2]uint64{{11, 13}, {11, 11}}, altSync.requests, "the gap shrinks to the first queued payload")
+}
+
+type fakeUnsafeStore struct {
+	payloads []*eth.ExecutionPayload
+}
+
+func (f *fakeUnsafeStore) StorePayload(payload *eth.ExecutionPayload) error {
+	f.payloads = append(f.payloads, payload)
+	return nil
+}
+
+func (f *fakeUnsafeStore) PrunePayloads(num uint64) error {
+	var out []*eth.ExecutionPayload
+	for _, p := range f.payloads {
+		if uint64(p.BlockNumber) > num {
+			out = append(out, p)
+		}
+	}
+	f.payloads = out
+	return nil
+}
+
+func (f *fakeUnsafeStore) Payloads() ([]*eth.ExecutionPayload, error) {
+	return f.payloads, nil
+}
+
+var _ UnsafePayloadStore = (*fakeUnsafeStore)(nil)
+
+func TestEngineQueue_UnsafeStore(t *testing.T) {
+	logger := testlog.Logger(t, log.LvlInfo)
+	rng := rand.New(rand.NewSource(1234))
+	cfg := &rollup.Config{}
+	metrics := &testutils.TestDerivationMetrics{}
+
+	payload := func(num uint64) *eth.ExecutionPayload {
+		return &eth.ExecutionPayload{BlockHash: testutils.RandomHash(rng), BlockNumber: eth.Uint64Quantity(num)}
+	}
+	store := &fakeUnsafeStore{payloads: []*eth.ExecutionPayload{payload(9), payload(10), payload(11)}}
+
+	// the persisted payloads are queued on startup
+	eq := NewEngineQueue(logger, cfg, nil, metrics, nil, nil, PipelineHooks{UnsafeStore: store})
+	require.Equal(t, 3, eq.unsafePayloads.Len())
+
+	// new payloads are persisted
+	eq.AddUnsafePayload(payload(12))
+	require.Len(t, store.payloads, 4)
+	require.Equal(t, 4, eq.unsafePayloads.Len())
+
+	// payloads that were processed before the restart are skipped
+	eq.safeHead = eth.L2BlockRef{Number: 8}
+	eq.unsafeHead = eth.L2BlockRef{Hash: testutils.RandomHash(rng), Number: 10}
+	require.NoError(t, eq.tryNextUnsafePayload(context.Background()))
+	require.NoError(t, eq.tryNextUnsafePayload(context.Background()))
+	require.Equal(t, 2, eq.unsafePayloads.Len())
+	require.Equal(t, uint64(11), uint64(eq.unsafePayloads.Peek().BlockNumber))
+
+	// persisted payloads are pruned once safe
+	eq.safeHead = eth.L2BlockRef{Number: 10}
+	eq.postProcessSafeL2()
+	require.Len(t, store.payloads, 2)
+	require.Equal(t, uint64(11), uint64(store.payloads[0].BlockNumber))
+}
@@ -59,3 +59,9 @@ func (fq *FrameQueue) Reset(ctx context.Context, base eth.L1BlockRef) error {
 	fq.frames = fq.frames[:0]
 	return io.EOF
 }
+
+// Restore drops any buffered frames, the frames of the checkpoint origin were already processed.
+func (fq *FrameQueue) Restore(ctx context.Context, cp *Checkpoint) error {
+	fq.frames = fq.frames[:0]
+	return nil
+}
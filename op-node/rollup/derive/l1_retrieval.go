@@ -25,7 +25,7 @@ type L1Retrieval struct {
 	datas DataIter
 }
 
-var _ ResetableStage = (*L1Retrieval)(nil)
+var _ RestorableStage = (*L1Retrieval)(nil)
 
 func NewL1Retrieval(log log.Logger, dataSrc DataAvailabilitySource, prev NextBlockProvider) *L1Retrieval {
 	return &L1Retrieval{
@@ -74,3 +74,9 @@ func (l1r *L1Retrieval) Reset(ctx context.Context, base eth.L1BlockRef) error {
 	l1r.log.Info("Reset of L1Retrieval done", "origin", base)
 	return io.EOF
 }
+
+// Restore drops any open data source, the data of the checkpoint origin was already processed.
+func (l1r *L1Retrieval) Restore(ctx context.Context, cp *Checkpoint) error {
+	l1r.datas = nil
+	return nil
+}
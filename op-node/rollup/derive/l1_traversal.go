@@ -24,7 +24,7 @@ type L1Traversal struct {
 	log      log.Logger
 }
 
-var _ ResetableStage = (*L1Traversal)(nil)
+var _ RestorableStage = (*L1Traversal)(nil)
 
 func NewL1Traversal(log log.Logger, l1Blocks L1BlockRefByNumberFetcher) *L1Traversal {
 	return &L1Traversal{
@@ -66,6 +66,14 @@ func (l1t *L1Traversal) AdvanceL1Block(ctx context.Context) error {
 	return nil
 }
 
+// Restore sets the internal L1 block to the checkpoint origin, of which the data was already processed.
+// The next call to `NextL1Block` returns io.EOF, until the traversal advances to the next block.
+func (l1t *L1Traversal) Restore(ctx context.Context, cp *Checkpoint) error {
+	l1t.block = cp.Origin
+	l1t.done = true
+	return nil
+}
+
 // Reset sets the internal L1 block to the supplied base.
 // Note that the next call to `NextL1Block` will return the block after `base`
 // TODO: Walk one back/figure this out.
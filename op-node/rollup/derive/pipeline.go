@@ -30,6 +30,14 @@ type ResetableStage interface {
 	Reset(ctx context.Context, base eth.L1BlockRef) error
 }
 
+// RestorableStage is a pipeline stage that can be restored from a checkpoint, in place of a reset.
+type RestorableStage interface {
+	ResetableStage
+	// Restore restores the stage to the state of the checkpoint. Checkpoints are taken once all the data of the
+	// checkpoint origin is processed, so stages without checkpointed state are restored with nothing buffered.
+	Restore(ctx context.Context, cp *Checkpoint) error
+}
+
 type EngineQueueStage interface {
 	FinalizedL1() eth.L1BlockRef
 	Finalized() eth.L2BlockRef
@@ -42,6 +50,10 @@ type EngineQueueStage interface {
 	AddSafeAttributes(attributes *eth.PayloadAttributes)
 	AddUnsafePayload(payload *eth.ExecutionPayload)
 	Step(context.Context) error
+	Restore(ctx context.Context, cp *Checkpoint) error
+
+	stageState() StageState
+	numUnsafePayloads() int
 }
 
 // DerivationPipeline is updated with new L1 data, and the Step() function can be iterated on to keep the L2 Engine in sync.
@@ -53,17 +65,30 @@ type DerivationPipeline struct {
 	// Index of the stage that is currently being reset.
 	// >= len(stages) if no additional resetting is required
 	resetting int
-	stages    []ResetableStage
+	stages    []RestorableStage
 
 	// Special stages to keep track of
 	traversal *L1Traversal
 	eng       EngineQueueStage
 
+	// Stages with state that is captured in checkpoints, or inspected in the pipeline state
+	l1Src      *L1Retrieval
+	frameQueue *FrameQueue
+	bank       *ChannelBank
+	chInReader *ChannelInReader
+	batchQueue *BatchQueue
+	attributes *AttributesQueue
+
+	// checkpointDue is set when a checkpoint is requested, to take it once the current L1 origin is fully processed.
+	checkpointDue bool
+	// checkpoint is the last checkpoint that was taken, until it is consumed with TakeCheckpoint.
+	checkpoint *Checkpoint
+
 	metrics Metrics
 }
 
 // NewDerivationPipeline creates a derivation pipeline, which should be reset before use.
-func NewDerivationPipeline(log log.Logger, cfg *rollup.Config, l1Fetcher L1Fetcher, engine Engine, metrics Metrics) *DerivationPipeline {
+func NewDerivationPipeline(log log.Logger, cfg *rollup.Config, l1Fetcher L1Fetcher, engine Engine, metrics Metrics, hooks PipelineHooks) *DerivationPipeline {
 
 	// Pull stages
 	l1Traversal := NewL1Traversal(log, l1Fetcher)
@@ -71,27 +96,33 @@ func NewDerivationPipeline(log log.Logger, cfg *rollup.Config, l1Fetcher L1Fetch
 	l1Src := NewL1Retrieval(log, dataSrc, l1Traversal)
 	frameQueue := NewFrameQueue(log, l1Src)
 	bank := NewChannelBank(log, cfg, frameQueue, l1Fetcher)
-	chInReader := NewChannelInReader(log, bank)
+	chInReader := NewChannelInReader(log, cfg, bank)
 	batchQueue := NewBatchQueue(log, cfg, chInReader)
 	attributesQueue := NewAttributesQueue(log, cfg, l1Fetcher, batchQueue)
 
 	// Step stages
-	eng := NewEngineQueue(log, cfg, engine, metrics, attributesQueue, l1Fetcher)
+	eng := NewEngineQueue(log, cfg, engine, metrics, attributesQueue, l1Fetcher, hooks)
 
 	// Reset from engine queue then up from L1 Traversal. The stages do not talk to each other during
 	// the reset, but after the engine queue, this is the order in which the stages could talk to each other.
 	// Note: The engine queue stage is the only reset that can fail.
-	stages := []ResetableStage{eng, l1Traversal, l1Src, bank, chInReader, batchQueue, attributesQueue}
+	stages := []RestorableStage{eng, l1Traversal, l1Src, frameQueue, bank, chInReader, batchQueue, attributesQueue}
 
 	return &DerivationPipeline{
-		log:       log,
-		cfg:       cfg,
-		l1Fetcher: l1Fetcher,
-		resetting: 0,
-		stages:    stages,
-		eng:       eng,
-		metrics:   metrics,
-		traversal: l1Traversal,
+		log:        log,
+		cfg:        cfg,
+		l1Fetcher:  l1Fetcher,
+		resetting:  0,
+		stages:     stages,
+		eng:        eng,
+		metrics:    metrics,
+		traversal:  l1Traversal,
+		l1Src:      l1Src,
+		frameQueue: frameQueue,
+		bank:       bank,
+		chInReader: chInReader,
+		batchQueue: batchQueue,
+		attributes: attributesQueue,
 	}
 }
 
@@ -161,6 +192,15 @@ func (dp *DerivationPipeline) Step(ctx context.Context) error {
 
 	// Now step the engine queue. It will pull earlier data as needed.
 	if err := dp.eng.Step(ctx); err == io.EOF {
+		// Every stage has returned io.EOF: the L1 origin is fully processed, and a checkpoint can be taken.
+		if dp.checkpointDue {
+			if cp, err := dp.snapshot(); err != nil {
+				dp.log.Error("failed to take derivation checkpoint", "err", err)
+			} else {
+				dp.checkpoint = cp
+			}
+			dp.checkpointDue = false
+		}
 		// If every stage has returned io.EOF, try to advance the L1 Origin
 		return dp.traversal.AdvanceL1Block(ctx)
 	} else if err != nil {
@@ -169,3 +209,57 @@ func (dp *DerivationPipeline) Step(ctx context.Context) error {
 		return nil
 	}
 }
+
+// RequestCheckpoint requests a checkpoint to be taken once the current L1 origin is fully processed.
+// The checkpoint can then be retrieved with TakeCheckpoint.
+func (dp *DerivationPipeline) RequestCheckpoint() {
+	dp.checkpointDue = true
+}
+
+// TakeCheckpoint returns the last checkpoint that was taken, if it was not returned before.
+// It returns nil if there is no new checkpoint.
+func (dp *DerivationPipeline) TakeCheckpoint() *Checkpoint {
+	cp := dp.checkpoint
+	dp.checkpoint = nil
+	return cp
+}
+
+// snapshot captures the state of the pipeline. It must only be called when all stages fully processed
+// the current L1 origin, such that no data is buffered in between the stages.
+func (dp *DerivationPipeline) snapshot() (*Checkpoint, error) {
+	batchOrigin, batchL1Blocks, batches, err := dp.batchQueue.checkpoint()
+	if err != nil {
+		return nil, err
+	}
+	return &Checkpoint{
+		Origin:        dp.eng.Origin(),
+		SafeHead:      dp.eng.SafeL2Head(),
+		UnsafeHead:    dp.eng.UnsafeL2Head(),
+		Finalized:     dp.eng.Finalized(),
+		Channels:      dp.bank.checkpoint(),
+		BatchOrigin:   batchOrigin,
+		BatchL1Blocks: batchL1Blocks,
+		Batches:       batches,
+	}, nil
+}
+
+// Restore restores the pipeline to the state of the checkpoint, instead of resetting it.
+// The checkpoint is only restored if the L1 blocks it was derived from and its L2 heads are still canonical,
+// ErrCheckpointMismatch is returned otherwise. If an error is returned, the pipeline is left to reset.
+func (dp *DerivationPipeline) Restore(ctx context.Context, cp *Checkpoint) error {
+	dp.resetting = 0
+	if err := cp.checkL1Canonical(ctx, dp.l1Fetcher); err != nil {
+		return err
+	}
+	// Each stage is restored in place of its reset, in the same order as the stages are reset.
+	for ; dp.resetting < len(dp.stages); dp.resetting++ {
+		if err := dp.stages[dp.resetting].Restore(ctx, cp); err != nil {
+			restored := dp.resetting
+			dp.resetting = 0
+			return fmt.Errorf("stage %d failed restoring: %w", restored, err)
+		}
+	}
+	dp.log.Info("restored derivation pipeline from checkpoint", "origin", cp.Origin, "safe", cp.SafeHead,
+		"channels", len(cp.Channels), "batches", len(cp.Batches))
+	return nil
+}
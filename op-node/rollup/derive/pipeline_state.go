@@ -0,0 +1,194 @@
+package derive
+
+import (
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+)
+
+// maxDroppedBatches is the number of most recently dropped batches that the batch queue remembers for introspection.
+const maxDroppedBatches = 64
+
+// PipelineState describes the internal state of the derivation pipeline, to debug stalls of the safe head.
+type PipelineState struct {
+	// Resetting is true while the pipeline is resetting, the stage state is not meaningful until the reset completes.
+	Resetting bool `json:"resetting"`
+
+	// Stages in the order that data flows through the pipeline, from L1Traversal to EngineQueue.
+	Stages []StageState `json:"stages"`
+
+	SafeHead       eth.L2BlockRef `json:"safeHead"`
+	UnsafeHead     eth.L2BlockRef `json:"unsafeHead"`
+	Finalized      eth.L2BlockRef `json:"finalized"`
+	UnsafePayloads int            `json:"unsafePayloads"`
+
+	// Channels pending in the channel bank, in the order they will be read.
+	Channels []ChannelState `json:"channels"`
+
+	// BatchL1Blocks are the L1 blocks that the batch queue considers as epochs for the next batches.
+	BatchL1Blocks []eth.BlockID `json:"batchL1Blocks"`
+	// DroppedBatches are the most recent batches dropped by the batch queue, oldest first.
+	DroppedBatches []DroppedBatch `json:"droppedBatches"`
+}
+
+// StageState describes the progress of a single derivation stage.
+type StageState struct {
+	Name   string         `json:"name"`
+	Origin eth.L1BlockRef `json:"origin"`
+	// Buffered is the number of items the stage holds on to for the next stage:
+	// the unread L1 block (L1Traversal), the unread L1 data (L1Retrieval), frames (FrameQueue), channels (ChannelBank),
+	// the channel being read (ChannelInReader), batches (BatchQueue), the batch being converted (AttributesQueue),
+	// or safe payload attributes (EngineQueue).
+	Buffered int `json:"buffered"`
+}
+
+// ChannelState describes the frame coverage of a channel pending in the channel bank.
+type ChannelState struct {
+	ID                      string      `json:"id"`
+	OpenBlock               eth.BlockID `json:"openBlock"`
+	HighestL1InclusionBlock eth.BlockID `json:"highestL1InclusionBlock"`
+	// TimeoutBlock is the L1 block number after which the channel times out.
+	TimeoutBlock uint64 `json:"timeoutBlock"`
+	Size         uint64 `json:"size"`
+	// Closed is true if the last frame, EndFrameNumber, was received.
+	Closed         bool   `json:"closed"`
+	EndFrameNumber uint16 `json:"endFrameNumber"`
+	Ready          bool   `json:"ready"`
+	// Frames are the received frame numbers, and MissingFrames the frame numbers that are known to be missing,
+	// up to the last frame, or up to the highest received frame if the channel is not closed yet.
+	Frames        []uint16 `json:"frames"`
+	MissingFrames []uint16 `json:"missingFrames"`
+}
+
+// DroppedBatch describes a batch that was dropped by the batch queue.
+type DroppedBatch struct {
+	Timestamp        uint64      `json:"timestamp"`
+	ParentHash       common.Hash `json:"parentHash"`
+	Epoch            eth.BlockID `json:"epoch"`
+	L1InclusionBlock eth.BlockID `json:"l1InclusionBlock"`
+	// SafeHead is the safe head the batch was checked against.
+	SafeHead eth.BlockID `json:"safeHead"`
+	Reason   string      `json:"reason"`
+}
+
+func (l1t *L1Traversal) stageState() StageState {
+	buffered := 0
+	if !l1t.done {
+		buffered = 1
+	}
+	return StageState{Name: "L1Traversal", Origin: l1t.Origin(), Buffered: buffered}
+}
+
+func (l1r *L1Retrieval) stageState() StageState {
+	buffered := 0
+	if l1r.datas != nil {
+		buffered = 1
+	}
+	return StageState{Name: "L1Retrieval", Origin: l1r.Origin(), Buffered: buffered}
+}
+
+func (fq *FrameQueue) stageState() StageState {
+	return StageState{Name: "FrameQueue", Origin: fq.Origin(), Buffered: len(fq.frames)}
+}
+
+func (cb *ChannelBank) stageState() StageState {
+	return StageState{Name: "ChannelBank", Origin: cb.Origin(), Buffered: len(cb.channelQueue)}
+}
+
+func (cr *ChannelInReader) stageState() StageState {
+	buffered := 0
+	if cr.nextBatchFn != nil {
+		buffered = 1
+	}
+	return StageState{Name: "ChannelInReader", Origin: cr.Origin(), Buffered: buffered}
+}
+
+func (bq *BatchQueue) stageState() StageState {
+	buffered := 0
+	for _, batches := range bq.batches {
+		buffered += len(batches)
+	}
+	return StageState{Name: "BatchQueue", Origin: bq.Origin(), Buffered: buffered}
+}
+
+func (aq *AttributesQueue) stageState() StageState {
+	buffered := 0
+	if aq.batch != nil {
+		buffered = 1
+	}
+	return StageState{Name: "AttributesQueue", Origin: aq.Origin(), Buffered: buffered}
+}
+
+func (eq *EngineQueue) stageState() StageState {
+	return StageState{Name: "EngineQueue", Origin: eq.Origin(), Buffered: len(eq.safeAttributes)}
+}
+
+func (eq *EngineQueue) numUnsafePayloads() int {
+	return eq.unsafePayloads.Len()
+}
+
+// channelStates returns the frame coverage of the channels pending in the channel bank.
+func (cb *ChannelBank) channelStates() []ChannelState {
+	out := make([]ChannelState, 0, len(cb.channelQueue))
+	for _, id := range cb.channelQueue {
+		ch := cb.channels[id]
+		frames := make([]uint16, 0, len(ch.inputs))
+		for num := range ch.inputs {
+			frames = append(frames, uint16(num))
+		}
+		sort.Slice(frames, func(i, j int) bool { return frames[i] < frames[j] })
+		last := ch.highestFrameNumber
+		if ch.closed {
+			last = ch.endFrameNumber
+		}
+		var missing []uint16
+		for num := uint64(0); num <= uint64(last); num++ {
+			if _, ok := ch.inputs[num]; !ok {
+				missing = append(missing, uint16(num))
+			}
+		}
+		out = append(out, ChannelState{
+			ID:                      id.String(),
+			OpenBlock:               ch.openBlock.ID(),
+			HighestL1InclusionBlock: ch.highestL1InclusionBlock.ID(),
+			TimeoutBlock:            ch.openBlock.Number + cb.cfg.ChannelTimeout,
+			Size:                    ch.size,
+			Closed:                  ch.closed,
+			EndFrameNumber:          ch.endFrameNumber,
+			Ready:                   ch.IsReady(),
+			Frames:                  frames,
+			MissingFrames:           missing,
+		})
+	}
+	return out
+}
+
+// State returns a description of the internal state of the pipeline.
+func (dp *DerivationPipeline) State() *PipelineState {
+	batchL1Blocks := make([]eth.BlockID, 0, len(dp.batchQueue.l1Blocks))
+	for _, b := range dp.batchQueue.l1Blocks {
+		batchL1Blocks = append(batchL1Blocks, b.ID())
+	}
+	return &PipelineState{
+		Resetting: dp.resetting < len(dp.stages),
+		Stages: []StageState{
+			dp.traversal.stageState(),
+			dp.l1Src.stageState(),
+			dp.frameQueue.stageState(),
+			dp.bank.stageState(),
+			dp.chInReader.stageState(),
+			dp.batchQueue.stageState(),
+			dp.attributes.stageState(),
+			dp.eng.stageState(),
+		},
+		SafeHead:       dp.eng.SafeL2Head(),
+		UnsafeHead:     dp.eng.UnsafeL2Head(),
+		Finalized:      dp.eng.Finalized(),
+		UnsafePayloads: dp.eng.numUnsafePayloads(),
+		Channels:       dp.bank.channelStates(),
+		BatchL1Blocks:  batchL1Blocks,
+		DroppedBatches: append([]DroppedBatch(nil), dp.batchQueue.dropped...),
+	}
+}
@@ -0,0 +1,92 @@
+package derive
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	"github.com/ethereum-optimism/optimism/op-node/testutils"
+)
+
+func TestChannelBankChannelStates(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	a := testutils.RandomBlockRef(rng)
+
+	input := &fakeChannelBankInput{origin: a}
+	input.AddFrames("a:0:first", "a:3:fourth!", "b:2:third")
+	input.AddFrame(Frame{}, io.EOF)
+	cfg := &rollup.Config{ChannelTimeout: 10}
+	cb := NewChannelBank(testlog.Logger(t, log.LvlCrit), cfg, input, nil)
+	for {
+		if _, err := cb.NextData(context.Background()); err == io.EOF {
+			break
+		}
+	}
+
+	states := cb.channelStates()
+	require.Len(t, states, 2)
+	require.Equal(t, testFrame("a:0:").ChannelID().String(), states[0].ID)
+	require.Equal(t, a.Number+cfg.ChannelTimeout, states[0].TimeoutBlock)
+	require.True(t, states[0].Closed)
+	require.False(t, states[0].Ready)
+	require.Equal(t, uint16(3), states[0].EndFrameNumber)
+	require.Equal(t, []uint16{0, 3}, states[0].Frames)
+	require.Equal(t, []uint16{1, 2}, states[0].MissingFrames)
+
+	require.False(t, states[1].Closed)
+	require.Equal(t, []uint16{2}, states[1].Frames)
+	require.Equal(t, []uint16{0, 1}, states[1].MissingFrames, "frames up to the highest received frame are missing")
+	require.Equal(t, StageState{Name: "ChannelBank", Origin: a, Buffered: 2}, cb.stageState())
+}
+
+func TestBatchQueueDroppedBatches(t *testing.T) {
+	l1 := L1Chain([]uint64{10, 20, 30})
+	safeHead := eth.L2BlockRef{
+		Hash:     mockHash(10, 2),
+		Time:     10,
+		L1Origin: l1[0].ID(),
+	}
+	cfg := &rollup.Config{
+		Genesis:           rollup.Genesis{L2Time: 10},
+		BlockTime:         2,
+		MaxSequencerDrift: 600,
+		SeqWindowSize:     30,
+	}
+	bq := NewBatchQueue(testlog.Logger(t, log.LvlCrit), cfg, &fakeBatchQueueInput{origin: l1[0]})
+	_ = bq.Reset(context.Background(), l1[0])
+
+	old := b(10, l1[0])
+	bq.AddBatch(old, safeHead)
+	wrongParent := b(12, l1[0])
+	wrongParent.ParentHash = common.Hash{0xff}
+	bq.AddBatch(wrongParent, safeHead)
+	bq.AddBatch(b(12, l1[0]), safeHead)
+
+	require.Len(t, bq.dropped, 2)
+	require.Equal(t, DroppedBatch{
+		Timestamp:        10,
+		ParentHash:       old.ParentHash,
+		Epoch:            l1[0].ID(),
+		L1InclusionBlock: l1[0].ID(),
+		SafeHead:         safeHead.ID(),
+		Reason:           "dropping batch with old timestamp",
+	}, bq.dropped[0])
+	require.Equal(t, "ignoring batch with mismatching parent hash", bq.dropped[1].Reason)
+	require.Equal(t, 1, bq.stageState().Buffered)
+
+	for i := 0; i < maxDroppedBatches; i++ {
+		bq.AddBatch(old, safeHead)
+	}
+	require.Len(t, bq.dropped, maxDroppedBatches, "only the most recent drops are remembered")
+	for _, d := range bq.dropped {
+		require.Equal(t, uint64(10), d.Timestamp, "the oldest drops are evicted first")
+	}
+}
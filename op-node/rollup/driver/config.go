@@ -1,5 +1,7 @@
 package driver
 
+import "time"
+
 type Config struct {
 	// VerifierConfDepth is the distance to keep from the L1 head when reading L1 data for L2 derivation.
 	VerifierConfDepth uint64 `json:"verifier_conf_depth"`
@@ -13,4 +15,11 @@ type Config struct {
 
 	// SequencerEnabled is true when the driver should sequence new blocks.
 	SequencerEnabled bool `json:"sequencer_enabled"`
+
+	// SequencerStopped is true when the driver should start in standby mode, not sequencing new blocks
+	// until the sequencer is started with StartSequencer. Only used if SequencerEnabled is true.
+	SequencerStopped bool `json:"sequencer_stopped"`
+
+	// CheckpointInterval is the interval at which derivation checkpoints are stored, if a checkpoint store is used.
+	CheckpointInterval time.Duration `json:"checkpoint_interval"`
 }
@@ -53,6 +53,17 @@ type DerivationPipeline interface {
 	SafeL2Head() eth.L2BlockRef
 	UnsafeL2Head() eth.L2BlockRef
 	Origin() eth.L1BlockRef
+	RequestCheckpoint()
+	TakeCheckpoint() *derive.Checkpoint
+	Restore(ctx context.Context, cp *derive.Checkpoint) error
+	State() *derive.PipelineState
+}
+
+// CheckpointStore persists derivation pipeline checkpoints, to resume derivation from after a restart.
+type CheckpointStore interface {
+	StoreCheckpoint(cp *derive.Checkpoint) error
+	// Checkpoints returns the stored checkpoints, most recent first.
+	Checkpoints() ([]*derive.Checkpoint, error)
 }
 
 type L1StateIface interface {
@@ -77,18 +88,38 @@ type SequencerIface interface {
 	CreateNewBlock(ctx context.Context, l2Head eth.L2BlockRef, l2SafeHead eth.BlockID, l2Finalized eth.BlockID, l1Origin eth.L1BlockRef) (eth.L2BlockRef, *eth.ExecutionPayload, error)
 }
 
+// SequencerLock is a leader lease, to ensure that no two nodes sequence at the same time.
+type SequencerLock interface {
+	// Acquire acquires or renews the lease. It returns false, without error, if another node holds the lease.
+	Acquire(ctx context.Context) (bool, error)
+	// Release gives up the lease, if it is held.
+	Release(ctx context.Context) error
+}
+
 type Network interface {
 	// PublishL2Payload is called by the driver whenever there is a new payload to publish, synchronously with the driver main loop.
 	PublishL2Payload(ctx context.Context, payload *eth.ExecutionPayload) error
 }
 
+// Hooks are the optional extensions of the driver. A nil hook is disabled.
+type Hooks struct {
+	// Pipeline are the optional extensions of the derivation pipeline.
+	Pipeline derive.PipelineHooks
+	// Checkpoints persists the checkpoints of the derivation pipeline, to restore them after a restart.
+	Checkpoints CheckpointStore
+	// SequencerLock is held while sequencing, to ensure no two nodes sequence at the same time.
+	SequencerLock SequencerLock
+	// InclusionPolicy selects the transactions of the blocks that are sequenced.
+	InclusionPolicy TxInclusionPolicy
+}
+
 // NewDriver composes an events handler that tracks L1 state, triggers L2 derivation, and optionally sequences new L2 blocks.
-func NewDriver(driverCfg *Config, cfg *rollup.Config, l2 L2Chain, l1 L1Chain, network Network, log log.Logger, snapshotLog log.Logger, metrics Metrics) *Driver {
-	sequencer := NewSequencer(log, cfg, l1, l2)
+func NewDriver(driverCfg *Config, cfg *rollup.Config, l2 L2Chain, l1 L1Chain, network Network, hooks Hooks, log log.Logger, snapshotLog log.Logger, metrics Metrics) *Driver {
+	sequencer := NewSequencer(log, cfg, l1, l2, hooks.InclusionPolicy)
 	l1State := NewL1State(log, metrics)
 	findL1Origin := NewL1OriginSelector(log, cfg, l1, driverCfg.SequencerConfDepth)
 	verifConfDepth := NewConfDepth(driverCfg.VerifierConfDepth, l1State.L1Head, l1)
-	derivationPipeline := derive.NewDerivationPipeline(log, cfg, verifConfDepth, l2, metrics)
+	derivationPipeline := derive.NewDerivationPipeline(log, cfg, verifConfDepth, l2, metrics, hooks.Pipeline)
 
 	return &Driver{
 		l1State:          l1State,
@@ -96,6 +127,9 @@ func NewDriver(driverCfg *Config, cfg *rollup.Config, l2 L2Chain, l1 L1Chain, ne
 		idleDerivation:   false,
 		stateReq:         make(chan chan struct{}),
 		forceReset:       make(chan chan struct{}, 10),
+		startSequencer:   make(chan hashAndErrorChannel, 10),
+		stopSequencer:    make(chan chan hashAndError, 10),
+		sequencerActive:  driverCfg.SequencerEnabled && !driverCfg.SequencerStopped,
 		config:           cfg,
 		driverConfig:     driverCfg,
 		done:             make(chan struct{}),
@@ -106,6 +140,8 @@ func NewDriver(driverCfg *Config, cfg *rollup.Config, l2 L2Chain, l1 L1Chain, ne
 		l1OriginSelector: findL1Origin,
 		sequencer:        sequencer,
 		network:          network,
+		checkpoints:      hooks.Checkpoints,
+		sequencerLock:    hooks.SequencerLock,
 		metrics:          metrics,
 		l1HeadSig:        make(chan eth.L1BlockRef, 10),
 		l1SafeSig:        make(chan eth.L1BlockRef, 10),
@@ -19,6 +19,28 @@ type Downloader interface {
 	FetchReceipts(ctx context.Context, blockHash common.Hash) (eth.BlockInfo, types.Receipts, error)
 }
 
+// TxInclusion is the transaction-inclusion decision of a TxInclusionPolicy for a single block.
+type TxInclusion struct {
+	// Transactions are the sequencer transactions to force-include, in order, after the deposits of the block.
+	// The block fails to build if any of these transactions is invalid.
+	Transactions []eth.Data
+	// NoTxPool disables the inclusion of transactions from the tx pool of the engine.
+	NoTxPool bool
+}
+
+// TxInclusionPolicy decides which transactions, other than the deposits, the sequencer includes in a block.
+// The policy is not consulted for blocks past the sequencer drift, as these cannot include any sequencer transactions.
+type TxInclusionPolicy interface {
+	// BlockInclusion returns the inclusion decision of the block with the given timestamp and L1 origin, on top of l2Parent.
+	BlockInclusion(ctx context.Context, l2Parent eth.L2BlockRef, l1Origin eth.L1BlockRef, timestamp uint64) (TxInclusion, error)
+	// BlockSealed is called with the payload of the block of the last inclusion decision, once the block is sealed.
+	BlockSealed(payload *eth.ExecutionPayload)
+	// BlockFailed is called if the block of the last inclusion decision is not sealed.
+	// The block is invalid if the engine rejected it, e.g. because a forced transaction is invalid.
+	// Otherwise it failed for a reason unrelated to its transactions, and may be built again.
+	BlockFailed(invalid bool)
+}
+
 // Sequencer implements the sequencing interface of the driver: it starts and completes block building jobs.
 type Sequencer struct {
 	log    log.Logger
@@ -27,16 +49,22 @@ type Sequencer struct {
 	l1 Downloader
 	l2 derive.Engine
 
+	inclusionPolicy TxInclusionPolicy // may be nil, the engine then picks all transactions from its tx pool
+	// inclusionPending is true while the block of the last inclusion decision is neither sealed nor failed
+	inclusionPending bool
+
 	buildingOnto eth.ForkchoiceState
 	buildingID   eth.PayloadID
 }
 
-func NewSequencer(log log.Logger, cfg *rollup.Config, l1 Downloader, l2 derive.Engine) *Sequencer {
+// NewSequencer creates a Sequencer. The inclusionPolicy is optional, and may be nil.
+func NewSequencer(log log.Logger, cfg *rollup.Config, l1 Downloader, l2 derive.Engine, inclusionPolicy TxInclusionPolicy) *Sequencer {
 	return &Sequencer{
-		log:    log,
-		config: cfg,
-		l1:     l1,
-		l2:     l2,
+		log:             log,
+		config:          cfg,
+		l1:              l1,
+		l2:              l2,
+		inclusionPolicy: inclusionPolicy,
 	}
 }
 
@@ -46,6 +74,7 @@ func (d *Sequencer) StartBuildingBlock(ctx context.Context, l2Head eth.L2BlockRe
 	if d.buildingID != (eth.PayloadID{}) { // This may happen when we decide to build a different block in response to a reorg. Or when previous block building failed.
 		d.log.Warn("did not finish previous block building, starting new building now", "prev_onto", d.buildingOnto.HeadBlockHash, "prev_payload_id", d.buildingID, "new_onto", l2Head)
 	}
+	d.blockFailed(derive.BlockInsertTemporaryErr)
 
 	fetchCtx, cancel := context.WithTimeout(ctx, time.Second*20)
 	defer cancel()
@@ -61,6 +90,28 @@ func (d *Sequencer) StartBuildingBlock(ctx context.Context, l2Head eth.L2BlockRe
 	// from the transaction pool.
 	attrs.NoTxPool = uint64(attrs.Timestamp) >= l1Origin.Time+d.config.MaxSequencerDrift
 
+	// Within the sequencer drift, the inclusion policy may force-include sequencer transactions,
+	// and exclude the transactions of the tx pool.
+	if !attrs.NoTxPool && d.inclusionPolicy != nil {
+		inclusion, err := d.inclusionPolicy.BlockInclusion(fetchCtx, l2Head, l1Origin, uint64(attrs.Timestamp))
+		if err != nil {
+			return fmt.Errorf("failed to apply tx inclusion policy: %w", err)
+		}
+		d.inclusionPending = true
+		for i, tx := range inclusion.Transactions {
+			// deposits can only be derived from L1, and are placed before any other transactions
+			if len(tx) == 0 || tx[0] == types.DepositTxType {
+				d.blockFailed(derive.BlockInsertPayloadErr)
+				return fmt.Errorf("tx inclusion policy forced invalid tx %d: must be a non-deposit transaction", i)
+			}
+		}
+		attrs.Transactions = append(attrs.Transactions, inclusion.Transactions...)
+		attrs.NoTxPool = inclusion.NoTxPool
+		if len(inclusion.Transactions) > 0 || inclusion.NoTxPool {
+			d.log.Info("applied tx inclusion policy", "forced_txs", len(inclusion.Transactions), "no_tx_pool", inclusion.NoTxPool)
+		}
+	}
+
 	// And construct our fork choice state. This is our current fork choice state and will be
 	// updated as a result of executing the block based on the attributes described above.
 	fc := eth.ForkchoiceState{
@@ -71,6 +122,7 @@ func (d *Sequencer) StartBuildingBlock(ctx context.Context, l2Head eth.L2BlockRe
 	// Start a payload building process.
 	id, errTyp, err := derive.StartPayload(ctx, d.l2, fc, attrs)
 	if err != nil {
+		d.blockFailed(errTyp)
 		return fmt.Errorf("failed to start building on top of L2 chain %s, error (%d): %w", l2Head, errTyp, err)
 	}
 	d.buildingOnto = fc
@@ -89,12 +141,26 @@ func (d *Sequencer) CompleteBuildingBlock(ctx context.Context) (*eth.ExecutionPa
 	// Actually execute the block and add it to the head of the chain.
 	payload, errTyp, err := derive.ConfirmPayload(ctx, d.log, d.l2, d.buildingOnto, d.buildingID, false)
 	if err != nil {
+		d.blockFailed(errTyp)
 		return nil, fmt.Errorf("failed to complete building on top of L2 chain %s, error (%d): %w", d.buildingOnto.HeadBlockHash, errTyp, err)
 	}
 	d.buildingID = eth.PayloadID{}
+	if d.inclusionPending {
+		d.inclusionPending = false
+		d.inclusionPolicy.BlockSealed(payload)
+	}
 	return payload, nil
 }
 
+// blockFailed reports the failure of the block of the last inclusion decision to the inclusion policy, if it is still pending.
+func (d *Sequencer) blockFailed(errTyp derive.BlockInsertionErrType) {
+	if !d.inclusionPending {
+		return
+	}
+	d.inclusionPending = false
+	d.inclusionPolicy.BlockFailed(errTyp == derive.BlockInsertPayloadErr)
+}
+
 // CreateNewBlock sequences a L2 block with immediate building and sealing.
 func (d *Sequencer) CreateNewBlock(ctx context.Context, l2Head eth.L2BlockRef, l2SafeHead eth.BlockID, l2Finalized eth.BlockID, l1Origin eth.L1BlockRef) (eth.L2BlockRef, *eth.ExecutionPayload, error) {
 	if err := d.StartBuildingBlock(ctx, l2Head, l2SafeHead, l2Finalized, l1Origin); err != nil {
@@ -9,6 +9,7 @@ import (
 	gosync "sync"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
 
 	"github.com/ethereum-optimism/optimism/op-node/eth"
@@ -37,6 +38,17 @@ type Driver struct {
 	// It tells the caller that the reset occurred by closing the passed in channel.
 	forceReset chan chan struct{}
 
+	// Upon receiving a hash in this channel, the sequencer is started at the given hash.
+	// It tells the caller that the sequencer started by closing the passed in channel (or returning an error).
+	startSequencer chan hashAndErrorChannel
+
+	// Upon receiving a channel in this channel, the sequencer is stopped.
+	// It tells the caller that the sequencer stopped by returning the latest sequenced L2 block hash.
+	stopSequencer chan chan hashAndError
+
+	// Whether the driver is sequencing new blocks. Only accessed by the event loop.
+	sequencerActive bool
+
 	// Rollup config: rollup chain configuration
 	config *rollup.Config
 
@@ -60,7 +72,9 @@ type Driver struct {
 	l2               L2Chain
 	l1OriginSelector L1OriginSelectorIface
 	sequencer        SequencerIface
-	network          Network // may be nil, network for is optional
+	network          Network         // may be nil, network for is optional
+	checkpoints      CheckpointStore // may be nil, checkpoints are optional
+	sequencerLock    SequencerLock   // may be nil, the leader lease is optional
 
 	metrics     Metrics
 	log         log.Logger
@@ -74,6 +88,9 @@ type Driver struct {
 // The loop will have been started iff err is not nil.
 func (s *Driver) Start() error {
 	s.derivation.Reset()
+	if s.checkpoints != nil {
+		s.restoreCheckpoint()
+	}
 
 	s.wg.Add(1)
 	go s.eventLoop()
@@ -81,6 +98,41 @@ func (s *Driver) Start() error {
 	return nil
 }
 
+// restoreCheckpoint restores the derivation pipeline from the most recent stored checkpoint that is still canonical.
+// The pipeline is left to reset if there is no such checkpoint.
+func (s *Driver) restoreCheckpoint() {
+	checkpoints, err := s.checkpoints.Checkpoints()
+	if err != nil {
+		s.log.Warn("Failed to load derivation checkpoints", "err", err)
+		return
+	}
+	for _, cp := range checkpoints {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+		err := s.derivation.Restore(ctx, cp)
+		cancel()
+		if err == nil {
+			return
+		}
+		s.log.Warn("Cannot restore derivation checkpoint", "origin", cp.Origin, "safe", cp.SafeHead, "err", err)
+		// the restore may have been partially applied
+		s.derivation.Reset()
+	}
+	if len(checkpoints) > 0 {
+		s.log.Info("No derivation checkpoint matches the canonical chain, resetting the derivation pipeline")
+	}
+}
+
+// storeCheckpoint stores the last checkpoint taken by the derivation pipeline, if any.
+func (s *Driver) storeCheckpoint() {
+	cp := s.derivation.TakeCheckpoint()
+	if cp == nil {
+		return
+	}
+	if err := s.checkpoints.StoreCheckpoint(cp); err != nil {
+		s.log.Error("Failed to store derivation checkpoint", "origin", cp.Origin, "err", err)
+	}
+}
+
 func (s *Driver) Close() error {
 	s.done <- struct{}{}
 	s.wg.Wait()
@@ -189,8 +241,15 @@ func (s *Driver) eventLoop() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Give up the leader lease on shutdown, so a standby sequencer can take over right away.
+	defer func() {
+		if s.sequencerActive {
+			s.releaseSequencerLease()
+		}
+	}()
+
 	// Start a ticker to produce L2 blocks at a constant rate. Ticker will only run if we're
-	// running in Sequencer mode.
+	// running in Sequencer mode. No blocks are produced while the sequencer is stopped.
 	var l2BlockCreationTickerCh <-chan time.Time
 	if s.driverConfig.SequencerEnabled {
 		l2BlockCreationTicker := time.NewTicker(time.Duration(s.config.BlockTime) * time.Second)
@@ -198,6 +257,14 @@ func (s *Driver) eventLoop() {
 		l2BlockCreationTickerCh = l2BlockCreationTicker.C
 	}
 
+	// Start a ticker to request derivation checkpoints, if checkpoints are stored.
+	var checkpointTickerCh <-chan time.Time
+	if s.checkpoints != nil && s.driverConfig.CheckpointInterval > 0 {
+		checkpointTicker := time.NewTicker(s.driverConfig.CheckpointInterval)
+		defer checkpointTicker.Stop()
+		checkpointTickerCh = checkpointTicker.C
+	}
+
 	// stepReqCh is used to request that the driver attempts to step forward by one L1 block.
 	stepReqCh := make(chan struct{}, 1)
 
@@ -255,17 +322,33 @@ func (s *Driver) eventLoop() {
 	for {
 		select {
 		case <-l2BlockCreationTickerCh:
+			if !s.sequencerActive {
+				break
+			}
 			s.log.Trace("L2 Creation Ticker")
 			s.snapshot("L2 Creation Ticker")
 			reqL2BlockCreation()
 
 		case <-l2BlockCreationReqCh:
+			if !s.sequencerActive {
+				break // the sequencer was stopped after the block creation was requested
+			}
 			s.snapshot("L2 Block Creation Request")
 			l1Head := s.l1State.L1Head()
 			if !s.idleDerivation {
 				s.log.Warn("not creating block, node is deriving new l2 data", "head_l1", l1Head)
 				break
 			}
+			// Renew the leader lease before every block, and stop sequencing if another node holds the lease.
+			if err := s.acquireSequencerLease(ctx); errors.Is(err, errLeaseHeld) {
+				s.log.Error("Sequencer lease is held by another node, stopping the sequencer", "err", err)
+				s.sequencerActive = false
+				break
+			} else if err != nil {
+				s.log.Error("Failed to renew sequencer lease, not creating block", "err", err)
+				s.metrics.RecordSequencingError()
+				break
+			}
 			ctx, cancel := context.WithTimeout(ctx, 20*time.Minute)
 			err := s.createNewL2Block(ctx)
 			cancel()
@@ -305,11 +388,17 @@ func (s *Driver) eventLoop() {
 		case <-delayedStepReq:
 			delayedStepReq = nil
 			step()
+		case <-checkpointTickerCh:
+			s.derivation.RequestCheckpoint()
 		case <-stepReqCh:
 			s.metrics.SetDerivationIdle(false)
 			s.idleDerivation = false
 			s.log.Debug("Derivation process step", "onto_origin", s.derivation.Origin(), "attempts", stepAttempts)
 			err := s.derivation.Step(context.Background())
+			if s.checkpoints != nil {
+				s.storeCheckpoint()
+			}
+			s.snapshotPipeline(err)
 			stepAttempts += 1 // count as attempt by default. We reset to 0 if we are making healthy progress.
 			if err == io.EOF {
 				s.log.Debug("Derivation process went idle", "progress", s.derivation.Origin())
@@ -349,6 +438,32 @@ func (s *Driver) eventLoop() {
 			s.derivation.Reset()
 			s.metrics.RecordPipelineReset()
 			close(respCh)
+		case resp := <-s.startSequencer:
+			unsafeHead := s.derivation.UnsafeL2Head().Hash
+			if !s.driverConfig.SequencerEnabled {
+				resp.err <- errors.New("node is not configured as sequencer")
+			} else if s.sequencerActive {
+				resp.err <- errors.New("sequencer already running")
+			} else if unsafeHead != resp.hash {
+				resp.err <- fmt.Errorf("block hash does not match: head %s, received %s", unsafeHead, resp.hash)
+			} else if err := s.acquireSequencerLease(ctx); err != nil {
+				resp.err <- fmt.Errorf("cannot start sequencer: %w", err)
+			} else {
+				s.log.Info("Sequencer has been started", "unsafe_head", unsafeHead)
+				s.sequencerActive = true
+				reqL2BlockCreation()
+				close(resp.err)
+			}
+		case respCh := <-s.stopSequencer:
+			if !s.sequencerActive {
+				respCh <- hashAndError{err: errors.New("sequencer not running")}
+			} else {
+				s.sequencerActive = false
+				s.releaseSequencerLease()
+				unsafeHead := s.derivation.UnsafeL2Head().Hash
+				s.log.Info("Sequencer has been stopped", "unsafe_head", unsafeHead)
+				respCh <- hashAndError{hash: unsafeHead}
+			}
 		case <-s.done:
 			return
 		}
@@ -373,6 +488,98 @@ func (s *Driver) ResetDerivationPipeline(ctx context.Context) error {
 	}
 }
 
+// errLeaseHeld is returned when the sequencer lease is held by another node.
+var errLeaseHeld = errors.New("sequencer lease is held by another node")
+
+// acquireSequencerLease acquires or renews the leader lease, if a sequencer lock is used.
+func (s *Driver) acquireSequencerLease(ctx context.Context) error {
+	if s.sequencerLock == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	ok, err := s.sequencerLock.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire sequencer lease: %w", err)
+	}
+	if !ok {
+		return errLeaseHeld
+	}
+	return nil
+}
+
+// releaseSequencerLease gives up the leader lease, if a sequencer lock is used.
+// Failing to release the lease is not critical: the lease expires eventually.
+func (s *Driver) releaseSequencerLease() {
+	if s.sequencerLock == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := s.sequencerLock.Release(ctx); err != nil {
+		s.log.Warn("Failed to release sequencer lease", "err", err)
+	}
+}
+
+type hashAndError struct {
+	hash common.Hash
+	err  error
+}
+
+type hashAndErrorChannel struct {
+	hash common.Hash
+	err  chan error
+}
+
+// StartSequencer starts sequencing new blocks on top of the given unsafe head.
+// The unsafe head must match the unsafe head of the node, to ensure a standby sequencer continues
+// exactly where the previously active sequencer stopped.
+func (s *Driver) StartSequencer(ctx context.Context, blockHash common.Hash) error {
+	h := hashAndErrorChannel{
+		hash: blockHash,
+		err:  make(chan error, 1),
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case s.startSequencer <- h:
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case e := <-h.err:
+			return e
+		}
+	}
+}
+
+// StopSequencer stops sequencing new blocks, and returns the hash of the last unsafe head.
+// The node continues to follow the chain as verifier.
+func (s *Driver) StopSequencer(ctx context.Context) (common.Hash, error) {
+	respCh := make(chan hashAndError, 1)
+	select {
+	case <-ctx.Done():
+		return common.Hash{}, ctx.Err()
+	case s.stopSequencer <- respCh:
+		select {
+		case <-ctx.Done():
+			return common.Hash{}, ctx.Err()
+		case he := <-respCh:
+			return he.hash, he.err
+		}
+	}
+}
+
+// sequencerState returns the state of the sequencing loop, as reported in the sync status.
+func (s *Driver) sequencerState() string {
+	if !s.driverConfig.SequencerEnabled {
+		return eth.SequencerDisabled
+	}
+	if s.sequencerActive {
+		return eth.SequencerActive
+	}
+	return eth.SequencerStopped
+}
+
 // syncStatus returns the current sync status, and should only be called synchronously with
 // the driver event loop to avoid retrieval of an inconsistent status.
 func (s *Driver) syncStatus() *eth.SyncStatus {
@@ -385,6 +592,7 @@ func (s *Driver) syncStatus() *eth.SyncStatus {
 		UnsafeL2:           s.derivation.UnsafeL2Head(),
 		SafeL2:             s.derivation.SafeL2Head(),
 		FinalizedL2:        s.derivation.Finalized(),
+		SequencerState:     s.sequencerState(),
 	}
 }
 
@@ -418,6 +626,20 @@ func (s *Driver) BlockRefWithStatus(ctx context.Context, num uint64) (eth.L2Bloc
 	}
 }
 
+// PipelineState blocks the driver event loop and captures the internal state of the derivation pipeline.
+// If the event loop is too busy and the context expires, a context error is returned.
+func (s *Driver) PipelineState(ctx context.Context) (*derive.PipelineState, error) {
+	wait := make(chan struct{})
+	select {
+	case s.stateReq <- wait:
+		resp := s.derivation.State()
+		<-wait
+		return resp, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
 // deferJSONString helps avoid a JSON-encoding performance hit if the snapshot logger does not run
 type deferJSONString struct {
 	x any
@@ -437,3 +659,24 @@ func (s *Driver) snapshot(event string) {
 		"l2Safe", deferJSONString{s.derivation.SafeL2Head()},
 		"l2FinalizedHead", deferJSONString{s.derivation.Finalized()})
 }
+
+// deferPipelineState helps avoid capturing the pipeline state if the snapshot logger does not run
+type deferPipelineState struct {
+	dp DerivationPipeline
+}
+
+func (v deferPipelineState) String() string {
+	return deferJSONString{v.dp.State()}.String()
+}
+
+// snapshotPipeline streams the derivation pipeline state after a derivation step to the snapshot log.
+func (s *Driver) snapshotPipeline(stepErr error) {
+	var errStr string
+	if stepErr != nil {
+		errStr = stepErr.Error()
+	}
+	s.snapshotLog.Info("Derivation Pipeline State",
+		"event", "Derivation step",
+		"stepErr", errStr,
+		"pipeline", deferPipelineState{s.derivation})
+}
@@ -0,0 +1,158 @@
+package driver
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/metrics"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+)
+
+// fakePipeline is always idle, at a fixed unsafe head.
+type fakePipeline struct {
+	DerivationPipeline
+	unsafeHead eth.L2BlockRef
+}
+
+func (f *fakePipeline) Reset()                                 {}
+func (f *fakePipeline) Step(ctx context.Context) error         { return io.EOF }
+func (f *fakePipeline) UnsafeL2Head() eth.L2BlockRef           { return f.unsafeHead }
+func (f *fakePipeline) SafeL2Head() eth.L2BlockRef             { return eth.L2BlockRef{} }
+func (f *fakePipeline) Finalized() eth.L2BlockRef              { return eth.L2BlockRef{} }
+func (f *fakePipeline) Origin() eth.L1BlockRef                 { return eth.L1BlockRef{} }
+func (f *fakePipeline) FinalizedL1() eth.L1BlockRef            { return eth.L1BlockRef{} }
+func (f *fakePipeline) AddUnsafePayload(*eth.ExecutionPayload) {}
+
+// countingOriginSelector counts block building attempts, and fails them, so no block is built.
+type countingOriginSelector struct {
+	calls atomic.Int64
+}
+
+func (c *countingOriginSelector) FindL1Origin(ctx context.Context, l1Head eth.L1BlockRef, l2Head eth.L2BlockRef) (eth.L1BlockRef, error) {
+	c.calls.Add(1)
+	return eth.L1BlockRef{}, errors.New("no origin in test")
+}
+
+type fakeLock struct {
+	mu   sync.Mutex
+	deny bool
+	held bool
+}
+
+func (f *fakeLock) Acquire(ctx context.Context) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.held = !f.deny
+	return f.held, nil
+}
+
+func (f *fakeLock) Release(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.held = false
+	return nil
+}
+
+func (f *fakeLock) setDeny(deny bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.deny = deny
+}
+
+func (f *fakeLock) isHeld() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.held
+}
+
+func TestDriverStartStopSequencer(t *testing.T) {
+	logger := testlog.Logger(t, log.LvlInfo)
+	head := eth.L2BlockRef{Hash: common.Hash{0xaa}, Number: 10}
+	origins := &countingOriginSelector{}
+	lock := &fakeLock{}
+	driverCfg := &Config{SequencerEnabled: true, SequencerStopped: true}
+	s := &Driver{
+		l1State:          NewL1State(logger, metrics.NoopMetrics),
+		derivation:       &fakePipeline{unsafeHead: head},
+		stateReq:         make(chan chan struct{}),
+		forceReset:       make(chan chan struct{}, 10),
+		startSequencer:   make(chan hashAndErrorChannel, 10),
+		stopSequencer:    make(chan chan hashAndError, 10),
+		sequencerActive:  driverCfg.SequencerEnabled && !driverCfg.SequencerStopped,
+		config:           &rollup.Config{BlockTime: 1},
+		driverConfig:     driverCfg,
+		done:             make(chan struct{}),
+		log:              logger,
+		snapshotLog:      log.New(),
+		l1OriginSelector: origins,
+		sequencerLock:    lock,
+		metrics:          metrics.NoopMetrics,
+		l1HeadSig:        make(chan eth.L1BlockRef, 10),
+		l1SafeSig:        make(chan eth.L1BlockRef, 10),
+		l1FinalizedSig:   make(chan eth.L1BlockRef, 10),
+		unsafeL2Payloads: make(chan *eth.ExecutionPayload, 10),
+	}
+	s.snapshotLog.SetHandler(log.DiscardHandler())
+	require.NoError(t, s.Start())
+	t.Cleanup(func() { _ = s.Close() })
+
+	ctx := context.Background()
+	sequencerState := func() string {
+		status, err := s.SyncStatus(ctx)
+		require.NoError(t, err)
+		return status.SequencerState
+	}
+
+	// the standby sequencer does not build blocks
+	require.Equal(t, eth.SequencerStopped, sequencerState())
+	time.Sleep(1500 * time.Millisecond)
+	require.Zero(t, origins.calls.Load())
+	_, err := s.StopSequencer(ctx)
+	require.ErrorContains(t, err, "sequencer not running")
+
+	// the sequencer only starts at the current unsafe head, and while it can hold the lease
+	require.ErrorContains(t, s.StartSequencer(ctx, common.Hash{0xbb}), "block hash does not match")
+	lock.setDeny(true)
+	require.ErrorContains(t, s.StartSequencer(ctx, head.Hash), "lease is held by another node")
+	require.Equal(t, eth.SequencerStopped, sequencerState())
+	lock.setDeny(false)
+	require.NoError(t, s.StartSequencer(ctx, head.Hash))
+	require.Equal(t, eth.SequencerActive, sequencerState())
+	require.True(t, lock.isHeld())
+	require.ErrorContains(t, s.StartSequencer(ctx, head.Hash), "sequencer already running")
+	require.Eventually(t, func() bool { return origins.calls.Load() > 0 }, 5*time.Second, 10*time.Millisecond)
+
+	// the sequencer stops when another node takes the lease
+	lock.setDeny(true)
+	require.Eventually(t, func() bool { return sequencerState() == eth.SequencerStopped }, 5*time.Second, 10*time.Millisecond)
+
+	// stopping the sequencer returns the unsafe head, and releases the lease
+	lock.setDeny(false)
+	require.NoError(t, s.StartSequencer(ctx, head.Hash))
+	stoppedAt, err := s.StopSequencer(ctx)
+	require.NoError(t, err)
+	require.Equal(t, head.Hash, stoppedAt)
+	require.False(t, lock.isHeld())
+	require.Equal(t, eth.SequencerStopped, sequencerState())
+}
+
+func TestDriverSequencerDisabled(t *testing.T) {
+	logger := testlog.Logger(t, log.LvlInfo)
+	s := &Driver{
+		derivation:   &fakePipeline{},
+		l1State:      NewL1State(logger, metrics.NoopMetrics),
+		driverConfig: &Config{},
+	}
+	require.Equal(t, eth.SequencerDisabled, s.syncStatus().SequencerState)
+}
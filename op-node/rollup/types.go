@@ -51,6 +51,11 @@ type Config struct {
 	BatchSenderAddress common.Address `json:"batch_sender_address"`
 	// L1 Deposit Contract Address
 	DepositContractAddress common.Address `json:"deposit_contract_address"`
+
+	// ZstdTime is the L1 timestamp from which channels may be compressed with zstd.
+	// Before activation, channels with the zstd version byte are invalid.
+	// A nil value disables zstd channels.
+	ZstdTime *uint64 `json:"zstd_time,omitempty"`
 }
 
 // Check verifies that the given configuration makes sense
@@ -100,6 +105,11 @@ func (cfg *Config) Check() error {
 	return nil
 }
 
+// IsZstd returns true if zstd compressed channels are accepted in L1 blocks with the given timestamp.
+func (c *Config) IsZstd(l1Timestamp uint64) bool {
+	return c.ZstdTime != nil && l1Timestamp >= *c.ZstdTime
+}
+
 func (c *Config) L1Signer() types.Signer {
 	return types.NewLondonSigner(c.L1ChainID)
 }
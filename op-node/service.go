@@ -3,18 +3,23 @@ package opnode
 import (
 	"crypto/rand"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/urfave/cli"
 
 	"github.com/ethereum-optimism/optimism/op-node/flags"
 	"github.com/ethereum-optimism/optimism/op-node/node"
+	"github.com/ethereum-optimism/optimism/op-node/node/sequencerlock"
+	"github.com/ethereum-optimism/optimism/op-node/node/txinclusion"
 	"github.com/ethereum-optimism/optimism/op-node/p2p"
 	"github.com/ethereum-optimism/optimism/op-node/rollup"
 	"github.com/ethereum-optimism/optimism/op-node/rollup/driver"
+	"github.com/ethereum-optimism/optimism/op-node/sources"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/log"
@@ -36,6 +41,16 @@ func NewConfig(ctx *cli.Context, log log.Logger) (*node.Config, error) {
 		return nil, err
 	}
 
+	sequencerLock, err := NewSequencerLock(ctx, rollupConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sequencer lease: %w", err)
+	}
+
+	inclusionPolicy, err := NewTxInclusionPolicy(ctx, log)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tx inclusion policy: %w", err)
+	}
+
 	p2pSignerSetup, err := p2p.LoadSignerSetup(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load p2p signer: %w", err)
@@ -79,6 +94,13 @@ func NewConfig(ctx *cli.Context, log log.Logger) (*node.Config, error) {
 		P2P:                 p2pConfig,
 		P2PSigner:           p2pSignerSetup,
 		L1EpochPollInterval: ctx.GlobalDuration(flags.L1EpochPollIntervalFlag.Name),
+		SafeDBPath:          ctx.GlobalString(flags.SafeDBPath.Name),
+		SafeDBRetention:     ctx.GlobalUint64(flags.SafeDBRetention.Name),
+		CheckpointDBPath:    ctx.GlobalString(flags.CheckpointDBPath.Name),
+		UnsafeDBPath:        ctx.GlobalString(flags.UnsafeDBPath.Name),
+		UnsafeDBMaxPayloads: ctx.GlobalUint64(flags.UnsafeDBMaxPayloads.Name),
+		SequencerLock:       sequencerLock,
+		TxInclusionPolicy:   inclusionPolicy,
 		Heartbeat: node.HeartbeatConfig{
 			Enabled: ctx.GlobalBool(flags.HeartbeatEnabledFlag.Name),
 			Moniker: ctx.GlobalString(flags.HeartbeatMonikerFlag.Name),
@@ -92,9 +114,17 @@ func NewConfig(ctx *cli.Context, log log.Logger) (*node.Config, error) {
 }
 
 func NewL1EndpointConfig(ctx *cli.Context) (*node.L1EndpointConfig, error) {
+	kind := sources.RPCProviderKind(strings.ToLower(ctx.GlobalString(flags.L1RPCProviderKind.Name)))
+	if !sources.ValidRPCProviderKind(kind) {
+		return nil, fmt.Errorf("unknown L1 RPC kind %q, expected one of: %v", kind, sources.RPCProviderKinds)
+	}
 	return &node.L1EndpointConfig{
-		L1NodeAddr: ctx.GlobalString(flags.L1NodeAddr.Name),
-		L1TrustRPC: ctx.GlobalBool(flags.L1TrustRPC.Name),
+		L1NodeAddr:      ctx.GlobalString(flags.L1NodeAddr.Name),
+		L1FallbackAddrs: ctx.GlobalStringSlice(flags.L1FallbackAddrs.Name),
+		L1MaxLag:        ctx.GlobalUint64(flags.L1MaxLag.Name),
+		L1CrossCheck:    ctx.GlobalBool(flags.L1CrossCheck.Name),
+		L1TrustRPC:      ctx.GlobalBool(flags.L1TrustRPC.Name),
+		L1RPCKind:       kind,
 	}, nil
 }
 
@@ -133,9 +163,54 @@ func NewDriverConfig(ctx *cli.Context) (*driver.Config, error) {
 		VerifierConfDepth:  ctx.GlobalUint64(flags.VerifierL1Confs.Name),
 		SequencerConfDepth: ctx.GlobalUint64(flags.SequencerL1Confs.Name),
 		SequencerEnabled:   ctx.GlobalBool(flags.SequencerEnabledFlag.Name),
+		SequencerStopped:   ctx.GlobalBool(flags.SequencerStoppedFlag.Name),
+		CheckpointInterval: ctx.GlobalDuration(flags.CheckpointInterval.Name),
 	}, nil
 }
 
+// NewSequencerLock creates the leader lease of the sequencer, or nil if no lease is used.
+func NewSequencerLock(ctx *cli.Context, rollupConfig *rollup.Config) (driver.SequencerLock, error) {
+	path := ctx.GlobalString(flags.SequencerLeaseFileFlag.Name)
+	if path == "" {
+		return nil, nil
+	}
+	ttl := ctx.GlobalDuration(flags.SequencerLeaseTTLFlag.Name)
+	if ttl <= time.Duration(rollupConfig.BlockTime)*time.Second {
+		return nil, fmt.Errorf("lease ttl %s must be longer than the block time of %d seconds", ttl, rollupConfig.BlockTime)
+	}
+	owner := ctx.GlobalString(flags.SequencerLeaseOwnerFlag.Name)
+	if owner == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("failed to determine lease owner from hostname: %w", err)
+		}
+		owner = hostname
+	}
+	return sequencerlock.NewFileLock(path, owner, ttl), nil
+}
+
+// NewTxInclusionPolicy creates the tx inclusion policy of the sequencer, or nil if no policy is configured.
+func NewTxInclusionPolicy(ctx *cli.Context, log log.Logger) (driver.TxInclusionPolicy, error) {
+	cfg := txinclusion.Config{
+		ForcedTxsPath:        ctx.GlobalString(flags.SequencerForcedTxsFlag.Name),
+		MaxForcedTxsPerBlock: ctx.GlobalUint64(flags.SequencerMaxForcedTxsFlag.Name),
+		NoTxPoolL1Lag:        ctx.GlobalUint64(flags.SequencerNoTxPoolL1LagFlag.Name),
+	}
+	if cfg.ForcedTxsPath == "" && cfg.NoTxPoolL1Lag == 0 {
+		return nil, nil
+	}
+	if cfg.ForcedTxsPath != "" {
+		if cfg.MaxForcedTxsPerBlock == 0 {
+			return nil, fmt.Errorf("%s must be at least 1 to include forced txs", flags.SequencerMaxForcedTxsFlag.Name)
+		}
+		// fail early on a malformed file, rather than when the first block is built
+		if _, err := txinclusion.ReadForcedTxs(cfg.ForcedTxsPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return txinclusion.NewPolicy(log.New("module", "txinclusion"), cfg)
+}
+
 func NewRollupConfig(ctx *cli.Context) (*rollup.Config, error) {
 	rollupConfigPath := ctx.GlobalString(flags.RollupConfig.Name)
 	file, err := os.Open(rollupConfigPath)
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"sync"
+	"time"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
@@ -44,6 +46,14 @@ type EthClientConfig struct {
 	// If this is not checked, disabled header fields like the nonce or difficulty
 	// may be used to get a different block-hash.
 	MustBePostMerge bool
+
+	// RPCProviderKind is a hint at what type of RPC provider we are dealing with,
+	// to select the receipt fetching methods that it supports.
+	RPCProviderKind RPCProviderKind
+
+	// MethodResetDuration is the duration after which receipt fetching methods that failed as unsupported
+	// are tried again. Never reset if zero.
+	MethodResetDuration time.Duration
 }
 
 func (c *EthClientConfig) Check() error {
@@ -65,6 +75,9 @@ func (c *EthClientConfig) Check() error {
 	if c.MaxRequestsPerBatch < 1 {
 		return fmt.Errorf("expected at least 1 request per batch, but max is: %d", c.MaxRequestsPerBatch)
 	}
+	if !ValidRPCProviderKind(c.RPCProviderKind) {
+		return fmt.Errorf("unknown rpc provider kind: %q", c.RPCProviderKind)
+	}
 	return nil
 }
 
@@ -80,6 +93,22 @@ type EthClient struct {
 
 	log log.Logger
 
+	provKind RPCProviderKind
+
+	// availableReceiptMethods tracks which receipt methods can be used for fetching receipts.
+	availableReceiptMethods ReceiptsFetchingMethod
+
+	// lastMethodsReset tracks when availableReceiptMethods was last reset.
+	// When receipt-fetching fails it falls back to available methods,
+	// but periodically it will try to reset to the preferred optimal methods.
+	lastMethodsReset time.Time
+
+	// methodResetDuration defines how long we take till we reset availableReceiptMethods
+	methodResetDuration time.Duration
+
+	// methodsLock guards availableReceiptMethods and lastMethodsReset
+	methodsLock sync.Mutex
+
 	// cache receipts in bundles per block hash
 	// We cache the receipts fetcher to not lose progress when we have to retry the `Fetch` call
 	// common.Hash -> eth.ReceiptsFetcher
@@ -106,17 +135,54 @@ func NewEthClient(client client.RPC, log log.Logger, metrics caching.Metrics, co
 	}
 	client = LimitRPC(client, config.MaxConcurrentRequests)
 	return &EthClient{
-		client:            client,
-		maxBatchSize:      config.MaxRequestsPerBatch,
-		trustRPC:          config.TrustRPC,
-		log:               log,
-		receiptsCache:     caching.NewLRUCache(metrics, "receipts", config.ReceiptsCacheSize),
-		transactionsCache: caching.NewLRUCache(metrics, "txs", config.TransactionsCacheSize),
-		headersCache:      caching.NewLRUCache(metrics, "headers", config.HeadersCacheSize),
-		payloadsCache:     caching.NewLRUCache(metrics, "payloads", config.PayloadsCacheSize),
+		client:                  client,
+		maxBatchSize:            config.MaxRequestsPerBatch,
+		trustRPC:                config.TrustRPC,
+		log:                     log,
+		provKind:                config.RPCProviderKind,
+		availableReceiptMethods: AvailableReceiptsFetchingMethods(config.RPCProviderKind),
+		lastMethodsReset:        time.Now(),
+		methodResetDuration:     config.MethodResetDuration,
+		receiptsCache:           caching.NewLRUCache(metrics, "receipts", config.ReceiptsCacheSize),
+		transactionsCache:       caching.NewLRUCache(metrics, "txs", config.TransactionsCacheSize),
+		headersCache:            caching.NewLRUCache(metrics, "headers", config.HeadersCacheSize),
+		payloadsCache:           caching.NewLRUCache(metrics, "payloads", config.PayloadsCacheSize),
 	}, nil
 }
 
+// PickReceiptsMethod picks the best available method to fetch the receipts of a block with txCount transactions.
+func (s *EthClient) PickReceiptsMethod(txCount uint64) ReceiptsFetchingMethod {
+	s.methodsLock.Lock()
+	defer s.methodsLock.Unlock()
+	if s.methodResetDuration > 0 && time.Since(s.lastMethodsReset) > s.methodResetDuration {
+		s.availableReceiptMethods = AvailableReceiptsFetchingMethods(s.provKind)
+		s.lastMethodsReset = time.Now()
+	}
+	return PickBestReceiptsFetchingMethod(s.provKind, s.availableReceiptMethods, txCount)
+}
+
+// OnReceiptsMethodErr stops using the receipts method if the error shows that the RPC does not support it.
+func (s *EthClient) OnReceiptsMethodErr(m ReceiptsFetchingMethod, err error) {
+	if !unusableMethod(err) {
+		s.log.Debug("failed to use selected RPC method for receipt fetching", "method", m, "err", err)
+		return
+	}
+	s.methodsLock.Lock()
+	defer s.methodsLock.Unlock()
+	// clear the bit of the method that errored
+	if s.availableReceiptMethods&m != 0 {
+		s.log.Warn("failed to use selected RPC method for receipt fetching, temporarily falling back to alternatives",
+			"provider_kind", s.provKind, "failed_method", m, "fallback", s.availableReceiptMethods&^m, "err", err)
+		s.availableReceiptMethods &^= m
+	}
+}
+
+// OnInvalidReceipts moves away from the endpoints that served receipts that do not match the block, if there are others.
+func (s *EthClient) OnInvalidReceipts(endpoints []string, err error) {
+	s.log.Warn("received invalid receipts", "endpoints", endpoints, "err", err)
+	client.ReportInvalid(s.client, endpoints, err)
+}
+
 // SubscribeNewHead subscribes to notifications about the current blockchain head on the given channel.
 func (s *EthClient) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
 	// Note that *types.Header does not cache the block hash unlike *HeaderInfo, it always recomputes.
@@ -238,15 +304,13 @@ func (s *EthClient) FetchReceipts(ctx context.Context, blockHash common.Hash) (e
 	// Try to reuse the receipts fetcher because is caches the results of intermediate calls. This means
 	// that if just one of many calls fail, we only retry the failed call rather than all of the calls.
 	// The underlying fetcher uses the receipts hash to verify receipt integrity.
+	// The receipts are fetched with the best method that the RPC supports, and verified against the receipts hash
+	// regardless of the method.
 	var fetcher eth.ReceiptsFetcher
 	if v, ok := s.receiptsCache.Get(blockHash); ok {
 		fetcher = v.(eth.ReceiptsFetcher)
 	} else {
-		txHashes := make([]common.Hash, len(txs))
-		for i := 0; i < len(txs); i++ {
-			txHashes[i] = txs[i].Hash()
-		}
-		fetcher = NewReceiptsFetcher(eth.ToBlockID(info), info.ReceiptHash(), txHashes, s.client.BatchCallContext, s.maxBatchSize)
+		fetcher = newReceiptsFetchingJob(s, s.client, s.maxBatchSize, eth.ToBlockID(info), info.ReceiptHash(), txs)
 		s.receiptsCache.Add(blockHash, fetcher)
 	}
 	// Fetch all receipts
@@ -45,6 +45,10 @@ func (lc *limitClient) EthSubscribe(ctx context.Context, channel interface{}, ar
 	return lc.c.EthSubscribe(ctx, channel, args...)
 }
 
+func (lc *limitClient) ReportInvalid(endpoints []string, err error) {
+	client.ReportInvalid(lc.c, endpoints, err)
+}
+
 func (lc *limitClient) Close() {
 	lc.wg.Wait()
 	close(lc.sema)
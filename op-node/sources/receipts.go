@@ -1,73 +1,91 @@
 package sources
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"sync"
 
-	"github.com/ethereum-optimism/optimism/op-node/eth"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/ethereum/go-ethereum/trie"
+
+	"github.com/ethereum-optimism/optimism/op-node/client"
+	"github.com/ethereum-optimism/optimism/op-node/eth"
 )
 
 func makeReceiptsFn(block eth.BlockID, receiptHash common.Hash) func(txHashes []common.Hash, receipts []*types.Receipt) (types.Receipts, error) {
 	return func(txHashes []common.Hash, receipts []*types.Receipt) (types.Receipts, error) {
-		if len(receipts) != len(txHashes) {
-			return nil, fmt.Errorf("got %d receipts but expected %d", len(receipts), len(txHashes))
+		if err := validateReceipts(block, receiptHash, txHashes, receipts); err != nil {
+			return nil, err
 		}
-		if len(txHashes) == 0 {
-			if receiptHash != types.EmptyRootHash {
-				return nil, fmt.Errorf("no transactions, but got non-empty receipt trie root: %s", receiptHash)
-			}
+		return receipts, nil
+	}
+}
+
+// validateReceipts checks the receipts against the block, and against the receipts root of the block.
+func validateReceipts(block eth.BlockID, receiptHash common.Hash, txHashes []common.Hash, receipts []*types.Receipt) error {
+	if len(receipts) != len(txHashes) {
+		return fmt.Errorf("got %d receipts but expected %d", len(receipts), len(txHashes))
+	}
+	if len(txHashes) == 0 {
+		if receiptHash != types.EmptyRootHash {
+			return fmt.Errorf("no transactions, but got non-empty receipt trie root: %s", receiptHash)
+		}
+	}
+	// We don't trust the RPC to provide consistent cached receipt info that we use for critical rollup derivation work.
+	// Let's check everything quickly.
+	logIndex := uint(0)
+	for i, r := range receipts {
+		if r == nil { // on reorgs or other cases the receipts may disappear before they can be retrieved.
+			return fmt.Errorf("receipt of tx %d returns nil on retrieval", i)
+		}
+		if r.TransactionIndex != uint(i) {
+			return fmt.Errorf("receipt %d has unexpected tx index %d", i, r.TransactionIndex)
+		}
+		if r.BlockNumber == nil || r.BlockNumber.Uint64() != block.Number {
+			return fmt.Errorf("receipt %d has unexpected block number %d, expected %d", i, r.BlockNumber, block.Number)
+		}
+		if r.BlockHash != block.Hash {
+			return fmt.Errorf("receipt %d has unexpected block hash %s, expected %s", i, r.BlockHash, block.Hash)
 		}
-		// We don't trust the RPC to provide consistent cached receipt info that we use for critical rollup derivation work.
-		// Let's check everything quickly.
-		logIndex := uint(0)
-		for i, r := range receipts {
-			if r == nil { // on reorgs or other cases the receipts may disappear before they can be retrieved.
-				return nil, fmt.Errorf("receipt of tx %d returns nil on retrieval", i)
+		for j, log := range r.Logs {
+			if log.Index != logIndex {
+				return fmt.Errorf("log %d (%d of tx %d) has unexpected log index %d", logIndex, j, i, log.Index)
 			}
-			if r.TransactionIndex != uint(i) {
-				return nil, fmt.Errorf("receipt %d has unexpected tx index %d", i, r.TransactionIndex)
+			if log.TxIndex != uint(i) {
+				return fmt.Errorf("log %d has unexpected tx index %d", log.Index, log.TxIndex)
 			}
-			if r.BlockNumber.Uint64() != block.Number {
-				return nil, fmt.Errorf("receipt %d has unexpected block number %d, expected %d", i, r.BlockNumber, block.Number)
+			if log.BlockHash != block.Hash {
+				return fmt.Errorf("log %d of block %s has unexpected block hash %s", log.Index, block.Hash, log.BlockHash)
 			}
-			if r.BlockHash != block.Hash {
-				return nil, fmt.Errorf("receipt %d has unexpected block hash %s, expected %s", i, r.BlockHash, block.Hash)
+			if log.BlockNumber != block.Number {
+				return fmt.Errorf("log %d of block %d has unexpected block number %d", log.Index, block.Number, log.BlockNumber)
 			}
-			for j, log := range r.Logs {
-				if log.Index != logIndex {
-					return nil, fmt.Errorf("log %d (%d of tx %d) has unexpected log index %d", logIndex, j, i, log.Index)
-				}
-				if log.TxIndex != uint(i) {
-					return nil, fmt.Errorf("log %d has unexpected tx index %d", log.Index, log.TxIndex)
-				}
-				if log.BlockHash != block.Hash {
-					return nil, fmt.Errorf("log %d of block %s has unexpected block hash %s", log.Index, block.Hash, log.BlockHash)
-				}
-				if log.BlockNumber != block.Number {
-					return nil, fmt.Errorf("log %d of block %d has unexpected block number %d", log.Index, block.Number, log.BlockNumber)
-				}
-				if log.TxHash != txHashes[i] {
-					return nil, fmt.Errorf("log %d of tx %s has unexpected tx hash %s", log.Index, txHashes[i], log.TxHash)
-				}
-				if log.Removed {
-					return nil, fmt.Errorf("canonical log (%d) must never be removed due to reorg", log.Index)
-				}
-				logIndex++
+			if log.TxHash != txHashes[i] {
+				return fmt.Errorf("log %d of tx %s has unexpected tx hash %s", log.Index, txHashes[i], log.TxHash)
 			}
+			if log.Removed {
+				return fmt.Errorf("canonical log (%d) must never be removed due to reorg", log.Index)
+			}
+			logIndex++
 		}
+	}
 
-		// Sanity-check: external L1-RPC sources are notorious for not returning all receipts,
-		// or returning them out-of-order. Verify the receipts against the expected receipt-hash.
-		hasher := trie.NewStackTrie(nil)
-		computed := types.DeriveSha(types.Receipts(receipts), hasher)
-		if receiptHash != computed {
-			return nil, fmt.Errorf("failed to fetch list of receipts: expected receipt root %s but computed %s from retrieved receipts", receiptHash, computed)
-		}
-		return receipts, nil
+	// Sanity-check: external L1-RPC sources are notorious for not returning all receipts,
+	// or returning them out-of-order. Verify the receipts against the expected receipt-hash.
+	hasher := trie.NewStackTrie(nil)
+	computed := types.DeriveSha(types.Receipts(receipts), hasher)
+	if receiptHash != computed {
+		return fmt.Errorf("failed to fetch list of receipts: expected receipt root %s but computed %s from retrieved receipts", receiptHash, computed)
 	}
+	return nil
 }
 
 func makeReceiptRequest(txHash common.Hash) (*types.Receipt, rpc.BatchElem) {
@@ -89,3 +107,386 @@ func NewReceiptsFetcher(block eth.BlockID, receiptHash common.Hash, txHashes []c
 		batchSize,
 	)
 }
+
+// ReceiptsFetchingMethod is a method to fetch the receipts of a block. The methods are a bitfield,
+// so the set of methods that are available from an RPC can be represented as a single value.
+type ReceiptsFetchingMethod uint64
+
+const (
+	// EthGetTransactionReceiptBatch fetches the receipts one transaction at a time, with batched
+	// eth_getTransactionReceipt requests. Every RPC supports this method.
+	EthGetTransactionReceiptBatch ReceiptsFetchingMethod = 1 << iota
+	// AlchemyGetTransactionReceipts fetches all receipts of a block with alchemy_getTransactionReceipts.
+	AlchemyGetTransactionReceipts
+	// DebugGetRawReceipts fetches all consensus-encoded receipts of a block with debug_getRawReceipts.
+	// The receipt fields that are not part of the consensus encoding are derived from the block.
+	DebugGetRawReceipts
+	// ParityGetBlockReceipts fetches all receipts of a block with parity_getBlockReceipts.
+	ParityGetBlockReceipts
+	// EthGetBlockReceipts fetches all receipts of a block with eth_getBlockReceipts.
+	EthGetBlockReceipts
+	// ErigonGetBlockReceiptsByBlockHash fetches all receipts of a block with erigon_getBlockReceiptsByBlockHash.
+	ErigonGetBlockReceiptsByBlockHash
+)
+
+func (m ReceiptsFetchingMethod) String() string {
+	out := ""
+	addMaybe := func(k ReceiptsFetchingMethod, v string) {
+		if m&k != 0 {
+			if out != "" {
+				out += ", "
+			}
+			out += v
+		}
+	}
+	addMaybe(EthGetTransactionReceiptBatch, "eth_getTransactionReceipt (batched)")
+	addMaybe(AlchemyGetTransactionReceipts, "alchemy_getTransactionReceipts")
+	addMaybe(DebugGetRawReceipts, "debug_getRawReceipts")
+	addMaybe(ParityGetBlockReceipts, "parity_getBlockReceipts")
+	addMaybe(EthGetBlockReceipts, "eth_getBlockReceipts")
+	addMaybe(ErigonGetBlockReceiptsByBlockHash, "erigon_getBlockReceiptsByBlockHash")
+	return out
+}
+
+// RPCProviderKind identifies the kind of RPC provider, to select the receipt fetching methods it supports.
+type RPCProviderKind string
+
+const (
+	RPCKindAlchemy    RPCProviderKind = "alchemy"
+	RPCKindQuickNode  RPCProviderKind = "quicknode"
+	RPCKindInfura     RPCProviderKind = "infura"
+	RPCKindParity     RPCProviderKind = "parity"
+	RPCKindNethermind RPCProviderKind = "nethermind"
+	RPCKindDebugGeth  RPCProviderKind = "debug_geth"
+	RPCKindErigon     RPCProviderKind = "erigon"
+	RPCKindStandard   RPCProviderKind = "standard"
+	RPCKindBasic      RPCProviderKind = "basic"
+	RPCKindAny        RPCProviderKind = "any"
+)
+
+var RPCProviderKinds = []RPCProviderKind{
+	RPCKindAlchemy,
+	RPCKindQuickNode,
+	RPCKindInfura,
+	RPCKindParity,
+	RPCKindNethermind,
+	RPCKindDebugGeth,
+	RPCKindErigon,
+	RPCKindStandard,
+	RPCKindBasic,
+	RPCKindAny,
+}
+
+// ValidRPCProviderKind returns true if the given value is a known kind of RPC provider.
+func ValidRPCProviderKind(value RPCProviderKind) bool {
+	for _, k := range RPCProviderKinds {
+		if k == value {
+			return true
+		}
+	}
+	return false
+}
+
+// AvailableReceiptsFetchingMethods returns the methods that the given kind of RPC provider supports.
+// The batched eth_getTransactionReceipt method is always available, as fallback.
+func AvailableReceiptsFetchingMethods(kind RPCProviderKind) ReceiptsFetchingMethod {
+	switch kind {
+	case RPCKindAlchemy:
+		return AlchemyGetTransactionReceipts | EthGetBlockReceipts | EthGetTransactionReceiptBatch
+	case RPCKindQuickNode:
+		return DebugGetRawReceipts | EthGetBlockReceipts | EthGetTransactionReceiptBatch
+	case RPCKindInfura:
+		// Infura is big, but sadly does not support more optimized receipts fetching methods (yet?)
+		return EthGetTransactionReceiptBatch
+	case RPCKindParity:
+		return ParityGetBlockReceipts | EthGetTransactionReceiptBatch
+	case RPCKindNethermind:
+		return ParityGetBlockReceipts | EthGetTransactionReceiptBatch
+	case RPCKindDebugGeth:
+		return DebugGetRawReceipts | EthGetTransactionReceiptBatch
+	case RPCKindErigon:
+		return ErigonGetBlockReceiptsByBlockHash | EthGetTransactionReceiptBatch
+	case RPCKindStandard:
+		return EthGetBlockReceipts | EthGetTransactionReceiptBatch
+	case RPCKindBasic:
+		return EthGetTransactionReceiptBatch
+	case RPCKindAny:
+		// if it's any kind of RPC provider, then try all methods
+		return AlchemyGetTransactionReceipts | EthGetBlockReceipts |
+			DebugGetRawReceipts | ErigonGetBlockReceiptsByBlockHash |
+			ParityGetBlockReceipts | EthGetTransactionReceiptBatch
+	default:
+		return EthGetTransactionReceiptBatch
+	}
+}
+
+// PickBestReceiptsFetchingMethod selects the cheapest available method to fetch the receipts of a block
+// with txCount transactions. Providers that bill per request weight favor the batched per-transaction method
+// for small blocks, other providers favor a single request for the whole block.
+func PickBestReceiptsFetchingMethod(kind RPCProviderKind, available ReceiptsFetchingMethod, txCount uint64) ReceiptsFetchingMethod {
+	switch kind {
+	case RPCKindAlchemy:
+		// Alchemy charges 15 compute units per eth_getTransactionReceipt,
+		// 250 per alchemy_getTransactionReceipts and 500 per eth_getBlockReceipts.
+		if available&AlchemyGetTransactionReceipts != 0 && txCount > 250/15 {
+			return AlchemyGetTransactionReceipts
+		}
+		if available&EthGetBlockReceipts != 0 && txCount > 500/15 {
+			return EthGetBlockReceipts
+		}
+		return EthGetTransactionReceiptBatch
+	case RPCKindQuickNode:
+		// QuickNode charges 2 credits per eth_getTransactionReceipt, 2 per debug_getRawReceipts
+		// and 59 per eth_getBlockReceipts.
+		if available&DebugGetRawReceipts != 0 {
+			return DebugGetRawReceipts
+		}
+		if available&EthGetBlockReceipts != 0 && txCount > 59/2 {
+			return EthGetBlockReceipts
+		}
+		return EthGetTransactionReceiptBatch
+	}
+	// otherwise prefer any bulk method that is available, in the order of the methods
+	for m := AlchemyGetTransactionReceipts; m <= ErigonGetBlockReceiptsByBlockHash; m <<= 1 {
+		if available&m != 0 {
+			return m
+		}
+	}
+	return EthGetTransactionReceiptBatch
+}
+
+// ReceiptsRequester picks the receipt fetching method to use, and learns which methods fail.
+type ReceiptsRequester interface {
+	PickReceiptsMethod(txCount uint64) ReceiptsFetchingMethod
+	OnReceiptsMethodErr(m ReceiptsFetchingMethod, err error)
+	// OnInvalidReceipts is called with the endpoints that served receipts that do not match the block.
+	OnInvalidReceipts(endpoints []string, err error)
+}
+
+// receiptsFetchingJob fetches the receipts of a block with the method that the requester picks,
+// and verifies them against the block, whichever method was used.
+type receiptsFetchingJob struct {
+	mu sync.Mutex
+
+	requester ReceiptsRequester
+
+	client       rpcClient
+	maxBatchSize int
+
+	block       eth.BlockID
+	receiptHash common.Hash
+	txs         types.Transactions
+
+	// served tracks the endpoints that served the receipts, since the last reset.
+	served client.ServedBy
+
+	// the per-transaction fetcher is kept, to not lose progress when a batch fails
+	fetcher eth.ReceiptsFetcher
+	// receipts fetched with a bulk method, to be verified
+	bulk types.Receipts
+
+	result types.Receipts
+}
+
+var _ eth.ReceiptsFetcher = (*receiptsFetchingJob)(nil)
+
+// rpcClient is the subset of the client.RPC methods used to fetch receipts.
+type rpcClient interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+	BatchCallContext(ctx context.Context, b []rpc.BatchElem) error
+}
+
+// newReceiptsFetchingJob creates a fetcher of the receipts of the given block, with the given transactions.
+func newReceiptsFetchingJob(requester ReceiptsRequester, client rpcClient, maxBatchSize int, block eth.BlockID,
+	receiptHash common.Hash, txs types.Transactions) *receiptsFetchingJob {
+	return &receiptsFetchingJob{
+		requester:    requester,
+		client:       client,
+		maxBatchSize: maxBatchSize,
+		block:        block,
+		receiptHash:  receiptHash,
+		txs:          txs,
+	}
+}
+
+func (job *receiptsFetchingJob) txHashes() []common.Hash {
+	txHashes := make([]common.Hash, len(job.txs))
+	for i := 0; i < len(job.txs); i++ {
+		txHashes[i] = job.txs[i].Hash()
+	}
+	return txHashes
+}
+
+// Fetch fetches the receipts, and returns io.EOF when all receipts have been fetched.
+func (job *receiptsFetchingJob) Fetch(ctx context.Context) error {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	if job.result != nil || job.bulk != nil {
+		return io.EOF
+	}
+	ctx = client.WithServedBy(ctx, &job.served)
+	// Continue with the per-transaction fetcher if it is in progress, to not lose its progress.
+	// Blocks without transactions do not need any request at all.
+	m := EthGetTransactionReceiptBatch
+	if job.fetcher == nil && len(job.txs) > 0 {
+		m = job.requester.PickReceiptsMethod(uint64(len(job.txs)))
+	}
+	if m == EthGetTransactionReceiptBatch {
+		if job.fetcher == nil {
+			job.fetcher = NewReceiptsFetcher(job.block, job.receiptHash, job.txHashes(), job.client.BatchCallContext, job.maxBatchSize)
+		}
+		return job.fetcher.Fetch(ctx)
+	}
+	receipts, err := job.fetchBulk(ctx, m)
+	if err != nil {
+		job.requester.OnReceiptsMethodErr(m, err)
+		return fmt.Errorf("failed to fetch receipts with %s: %w", m, err)
+	}
+	job.bulk = receipts
+	return io.EOF
+}
+
+// fetchBulk fetches all receipts of the block with a single request.
+func (job *receiptsFetchingJob) fetchBulk(ctx context.Context, m ReceiptsFetchingMethod) (types.Receipts, error) {
+	var receipts types.Receipts
+	switch m {
+	case AlchemyGetTransactionReceipts:
+		var result struct {
+			Receipts types.Receipts `json:"receipts"`
+		}
+		err := job.client.CallContext(ctx, &result, "alchemy_getTransactionReceipts", map[string]string{"blockHash": job.block.Hash.String()})
+		if err != nil {
+			return nil, err
+		}
+		receipts = result.Receipts
+	case DebugGetRawReceipts:
+		var rawReceipts []hexutil.Bytes
+		if err := job.client.CallContext(ctx, &rawReceipts, "debug_getRawReceipts", job.block.Hash); err != nil {
+			return nil, err
+		}
+		receipts = make(types.Receipts, len(rawReceipts))
+		for i, raw := range rawReceipts {
+			receipts[i] = new(types.Receipt)
+			if err := receipts[i].UnmarshalBinary(raw); err != nil {
+				return nil, fmt.Errorf("failed to decode raw receipt %d: %w", i, err)
+			}
+		}
+		if err := receipts.DeriveFields(deriveConfig(job.txs), job.block.Hash, job.block.Number, job.txs); err != nil {
+			return nil, fmt.Errorf("failed to derive receipt fields: %w", err)
+		}
+	case ParityGetBlockReceipts:
+		if err := job.client.CallContext(ctx, &receipts, "parity_getBlockReceipts", job.block.Hash); err != nil {
+			return nil, err
+		}
+	case EthGetBlockReceipts:
+		if err := job.client.CallContext(ctx, &receipts, "eth_getBlockReceipts", job.block.Hash); err != nil {
+			return nil, err
+		}
+	case ErigonGetBlockReceiptsByBlockHash:
+		if err := job.client.CallContext(ctx, &receipts, "erigon_getBlockReceiptsByBlockHash", job.block.Hash); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown receipt fetching method: %d", uint64(m))
+	}
+	// Some endpoints answer null instead of an error for blocks they cannot serve the receipts of.
+	if receipts == nil && len(job.txs) > 0 {
+		return nil, errNoReceipts
+	}
+	return receipts, nil
+}
+
+// deriveConfig returns a chain config to derive the receipt fields of the given transactions with.
+// Only the signer of the config is used, to derive the address of created contracts.
+func deriveConfig(txs types.Transactions) *params.ChainConfig {
+	chainID := new(big.Int)
+	for _, tx := range txs {
+		if tx.Protected() {
+			chainID = tx.ChainId()
+			break
+		}
+	}
+	return &params.ChainConfig{
+		ChainID:        chainID,
+		HomesteadBlock: new(big.Int),
+		EIP155Block:    new(big.Int),
+		ByzantiumBlock: new(big.Int),
+		BerlinBlock:    new(big.Int),
+		LondonBlock:    new(big.Int),
+	}
+}
+
+func (job *receiptsFetchingJob) Complete() bool {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	return job.result != nil || job.bulk != nil || (job.fetcher != nil && job.fetcher.Complete())
+}
+
+// Result returns the receipts, after verifying them. If they are invalid, the job is reset.
+func (job *receiptsFetchingJob) Result() (types.Receipts, error) {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	if job.result != nil {
+		return job.result, nil
+	}
+	if job.bulk != nil {
+		receipts := job.bulk
+		job.bulk = nil
+		if err := validateReceipts(job.block, job.receiptHash, job.txHashes(), receipts); err != nil {
+			job.invalid(err)
+			return nil, err
+		}
+		job.result = receipts
+		return receipts, nil
+	}
+	if job.fetcher != nil {
+		receipts, err := job.fetcher.Result()
+		if err != nil {
+			// the fetcher reset itself, start over with the best method
+			job.fetcher = nil
+			job.invalid(err)
+			return nil, err
+		}
+		job.result = receipts
+		return receipts, nil
+	}
+	return nil, fmt.Errorf("results not available yet, Fetch more first")
+}
+
+// invalid reports the endpoints that served the invalid receipts to the requester, and forgets them.
+// The job lock must be held.
+func (job *receiptsFetchingJob) invalid(err error) {
+	job.requester.OnInvalidReceipts(job.served.Endpoints(), err)
+	job.served.Reset()
+}
+
+func (job *receiptsFetchingJob) Reset() {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	job.fetcher = nil
+	job.bulk = nil
+	job.result = nil
+	job.served.Reset()
+}
+
+// errNoReceipts is returned when a bulk method serves no receipts for a block with transactions.
+var errNoReceipts = errors.New("no receipts returned for block with transactions")
+
+// unusableMethod returns true if the error indicates that the RPC does not support the method.
+func unusableMethod(err error) bool {
+	if errors.Is(err, errNoReceipts) {
+		return true
+	}
+	if rpcErr, ok := err.(rpc.Error); ok {
+		code := rpcErr.ErrorCode()
+		// method not found, or invalid params
+		if code == -32601 || code == -32602 {
+			return true
+		}
+	}
+	errText := strings.ToLower(err.Error())
+	return strings.Contains(errText, "unknown method") ||
+		strings.Contains(errText, "method not found") ||
+		strings.Contains(errText, "is not available") ||
+		strings.Contains(errText, "not supported")
+}
@@ -0,0 +1,281 @@
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/client"
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/metrics"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+)
+
+type methodNotFoundErr struct{}
+
+func (methodNotFoundErr) Error() string  { return "the method does not exist/is not available" }
+func (methodNotFoundErr) ErrorCode() int { return -32601 }
+
+// receiptsRPC serves the receipts of a single block, with the bulk methods that are supported.
+type receiptsRPC struct {
+	receipts  types.Receipts
+	supported map[string]bool
+	calls     map[string]int
+}
+
+func (r *receiptsRPC) serve(result interface{}, out interface{}) error {
+	data, err := json.Marshal(out)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, result)
+}
+
+func (r *receiptsRPC) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	r.calls[method]++
+	if !r.supported[method] {
+		return methodNotFoundErr{}
+	}
+	switch method {
+	case "alchemy_getTransactionReceipts":
+		return r.serve(result, map[string]interface{}{"receipts": r.receipts})
+	case "debug_getRawReceipts":
+		raw := make([]hexutil.Bytes, len(r.receipts))
+		for i, rec := range r.receipts {
+			data, err := rec.MarshalBinary()
+			if err != nil {
+				return err
+			}
+			raw[i] = data
+		}
+		return r.serve(result, raw)
+	default:
+		return r.serve(result, r.receipts)
+	}
+}
+
+func (r *receiptsRPC) EthSubscribe(ctx context.Context, channel interface{}, args ...interface{}) (ethereum.Subscription, error) {
+	return nil, errors.New("not supported")
+}
+
+func (r *receiptsRPC) Close() {}
+
+func (r *receiptsRPC) BatchCallContext(ctx context.Context, b []rpc.BatchElem) error {
+	for i := range b {
+		r.calls[b[i].Method]++
+		txHash := b[i].Args[0].(common.Hash)
+		for _, rec := range r.receipts {
+			if rec.TxHash == txHash {
+				b[i].Error = r.serve(b[i].Result, rec)
+			}
+		}
+	}
+	return nil
+}
+
+// fixedRequester always picks the same receipts fetching method.
+type fixedRequester struct {
+	method  ReceiptsFetchingMethod
+	errs    []error
+	invalid [][]string
+}
+
+func (f *fixedRequester) PickReceiptsMethod(txCount uint64) ReceiptsFetchingMethod {
+	return f.method
+}
+
+func (f *fixedRequester) OnReceiptsMethodErr(m ReceiptsFetchingMethod, err error) {
+	f.errs = append(f.errs, err)
+}
+
+func (f *fixedRequester) OnInvalidReceipts(endpoints []string, err error) {
+	f.invalid = append(f.invalid, endpoints)
+}
+
+func makeReceiptsBlock(t *testing.T, txCount int) (eth.BlockID, common.Hash, types.Transactions, types.Receipts) {
+	block := eth.BlockID{Hash: common.Hash{0xbb}, Number: 100}
+	to := common.Address{0x42}
+	txs := make(types.Transactions, txCount)
+	receipts := make(types.Receipts, txCount)
+	for i := 0; i < txCount; i++ {
+		txs[i] = types.NewTx(&types.DynamicFeeTx{ChainID: big.NewInt(1), Nonce: uint64(i), To: &to, Gas: 21000})
+		receipts[i] = &types.Receipt{
+			Type:              types.DynamicFeeTxType,
+			Status:            types.ReceiptStatusSuccessful,
+			CumulativeGasUsed: uint64(i+1) * 21000,
+			Logs:              []*types.Log{{Address: to, Topics: []common.Hash{{byte(i)}}, Data: []byte{byte(i)}}},
+		}
+		receipts[i].Bloom = types.CreateBloom(types.Receipts{receipts[i]})
+	}
+	require.NoError(t, receipts.DeriveFields(deriveConfig(txs), block.Hash, block.Number, txs))
+	receiptHash := types.DeriveSha(receipts, trie.NewStackTrie(nil))
+	return block, receiptHash, txs, receipts
+}
+
+func fetchAll(ctx context.Context, job eth.ReceiptsFetcher) (types.Receipts, error) {
+	for {
+		if err := job.Fetch(ctx); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	return job.Result()
+}
+
+func TestReceiptsFetchingMethods(t *testing.T) {
+	ctx := context.Background()
+	block, receiptHash, txs, receipts := makeReceiptsBlock(t, 5)
+	for _, m := range []ReceiptsFetchingMethod{
+		EthGetTransactionReceiptBatch,
+		AlchemyGetTransactionReceipts,
+		DebugGetRawReceipts,
+		ParityGetBlockReceipts,
+		EthGetBlockReceipts,
+		ErigonGetBlockReceiptsByBlockHash,
+	} {
+		t.Run(m.String(), func(t *testing.T) {
+			rpcMethod := map[ReceiptsFetchingMethod]string{
+				EthGetTransactionReceiptBatch:     "eth_getTransactionReceipt",
+				AlchemyGetTransactionReceipts:     "alchemy_getTransactionReceipts",
+				DebugGetRawReceipts:               "debug_getRawReceipts",
+				ParityGetBlockReceipts:            "parity_getBlockReceipts",
+				EthGetBlockReceipts:               "eth_getBlockReceipts",
+				ErigonGetBlockReceiptsByBlockHash: "erigon_getBlockReceiptsByBlockHash",
+			}[m]
+			cl := &receiptsRPC{receipts: receipts, supported: map[string]bool{rpcMethod: true}, calls: map[string]int{}}
+			job := newReceiptsFetchingJob(&fixedRequester{method: m}, cl, 2, block, receiptHash, txs)
+			result, err := fetchAll(ctx, job)
+			require.NoError(t, err)
+			require.Len(t, result, len(receipts))
+			for i := range receipts {
+				require.Equal(t, receipts[i].TxHash, result[i].TxHash)
+				require.Equal(t, receipts[i].Logs, result[i].Logs)
+				require.Equal(t, receipts[i].CumulativeGasUsed, result[i].CumulativeGasUsed)
+			}
+			if m == EthGetTransactionReceiptBatch {
+				require.Equal(t, len(receipts), cl.calls[rpcMethod])
+			} else {
+				require.Equal(t, 1, cl.calls[rpcMethod], "a single request fetches all receipts")
+			}
+		})
+	}
+}
+
+func TestReceiptsFetchingInvalid(t *testing.T) {
+	ctx := context.Background()
+	block, receiptHash, txs, receipts := makeReceiptsBlock(t, 3)
+	// the receipts are served out of order
+	shuffled := types.Receipts{receipts[1], receipts[0], receipts[2]}
+	cl := &receiptsRPC{receipts: shuffled, supported: map[string]bool{"eth_getBlockReceipts": true}, calls: map[string]int{}}
+	job := newReceiptsFetchingJob(&fixedRequester{method: EthGetBlockReceipts}, cl, 10, block, receiptHash, txs)
+	_, err := fetchAll(ctx, job)
+	require.ErrorContains(t, err, "unexpected tx index")
+
+	// the job starts over after invalid results
+	cl.receipts = receipts
+	result, err := fetchAll(ctx, job)
+	require.NoError(t, err)
+	require.Len(t, result, 3)
+	require.Equal(t, 2, cl.calls["eth_getBlockReceipts"])
+}
+
+func TestReceiptsInvalidReportsServingEndpoint(t *testing.T) {
+	ctx := context.Background()
+	block, receiptHash, txs, receipts := makeReceiptsBlock(t, 3)
+	shuffled := types.Receipts{receipts[1], receipts[0], receipts[2]}
+	a := &receiptsRPC{receipts: shuffled, supported: map[string]bool{"eth_getBlockReceipts": true}, calls: map[string]int{}}
+	b := &receiptsRPC{receipts: receipts, supported: map[string]bool{"eth_getBlockReceipts": true}, calls: map[string]int{}}
+	f := client.NewFailoverRPC(testlog.Logger(t, log.LvlError), []client.RPC{a, b}, &client.FailoverConfig{ErrorCooldown: time.Minute}, metrics.NoopMetrics)
+	defer f.Close()
+	requester := &fixedRequester{method: EthGetBlockReceipts}
+	job := newReceiptsFetchingJob(requester, f, 10, block, receiptHash, txs)
+
+	_, err := fetchAll(ctx, job)
+	require.ErrorContains(t, err, "unexpected tx index")
+	require.Equal(t, [][]string{{"0"}}, requester.invalid, "the endpoint that served the receipts is reported")
+
+	// once reported, the next endpoint serves the receipts
+	client.ReportInvalid(f, requester.invalid[0], err)
+	result, err := fetchAll(ctx, job)
+	require.NoError(t, err)
+	require.Len(t, result, 3)
+	require.Equal(t, 1, a.calls["eth_getBlockReceipts"])
+	require.Equal(t, 1, b.calls["eth_getBlockReceipts"])
+	require.Len(t, requester.invalid, 1)
+}
+
+func TestReceiptsFetchingNull(t *testing.T) {
+	ctx := context.Background()
+	block, receiptHash, txs, receipts := makeReceiptsBlock(t, 3)
+	// the endpoint answers null for the block
+	cl := &receiptsRPC{supported: map[string]bool{"eth_getBlockReceipts": true}, calls: map[string]int{}}
+	requester := &fixedRequester{method: EthGetBlockReceipts}
+	job := newReceiptsFetchingJob(requester, cl, 10, block, receiptHash, txs)
+	_, err := fetchAll(ctx, job)
+	require.ErrorIs(t, err, errNoReceipts)
+	require.Len(t, requester.errs, 1, "the requester learns that the method failed")
+	require.True(t, unusableMethod(requester.errs[0]))
+
+	cl.receipts = receipts
+	result, err := fetchAll(ctx, job)
+	require.NoError(t, err)
+	require.Len(t, result, 3)
+}
+
+func TestReceiptsMethodFallback(t *testing.T) {
+	ctx := context.Background()
+	block, receiptHash, txs, receipts := makeReceiptsBlock(t, 40)
+	cl := &receiptsRPC{receipts: receipts, supported: map[string]bool{"eth_getTransactionReceipt": true}, calls: map[string]int{}}
+	config := *testEthClientConfig
+	config.RPCProviderKind = RPCKindAny
+	s, err := NewEthClient(nil, testlog.Logger(t, log.LvlError), nil, &config)
+	require.NoError(t, err)
+
+	// all bulk methods are tried, and disabled as they are not supported, until the receipts are fetched per tx
+	job := newReceiptsFetchingJob(s, cl, 20, block, receiptHash, txs)
+	result, err := fetchAll(ctx, job)
+	for errors.Is(err, methodNotFoundErr{}) {
+		result, err = fetchAll(ctx, job)
+	}
+	require.NoError(t, err)
+	require.Len(t, result, 40)
+	for _, m := range []string{"alchemy_getTransactionReceipts", "debug_getRawReceipts", "parity_getBlockReceipts", "eth_getBlockReceipts", "erigon_getBlockReceiptsByBlockHash"} {
+		require.Equal(t, 1, cl.calls[m], "method %s is tried once", m)
+	}
+	require.Equal(t, EthGetTransactionReceiptBatch, s.PickReceiptsMethod(40))
+
+	// other errors do not disable a method
+	s, err = NewEthClient(nil, testlog.Logger(t, log.LvlError), nil, &config)
+	require.NoError(t, err)
+	s.OnReceiptsMethodErr(AlchemyGetTransactionReceipts, errors.New("timeout"))
+	require.Equal(t, AlchemyGetTransactionReceipts, s.PickReceiptsMethod(40))
+}
+
+func TestPickBestReceiptsFetchingMethod(t *testing.T) {
+	alchemy := AvailableReceiptsFetchingMethods(RPCKindAlchemy)
+	require.Equal(t, EthGetTransactionReceiptBatch, PickBestReceiptsFetchingMethod(RPCKindAlchemy, alchemy, 5))
+	require.Equal(t, AlchemyGetTransactionReceipts, PickBestReceiptsFetchingMethod(RPCKindAlchemy, alchemy, 100))
+	require.Equal(t, EthGetBlockReceipts, PickBestReceiptsFetchingMethod(RPCKindAlchemy, alchemy&^AlchemyGetTransactionReceipts, 100))
+
+	quicknode := AvailableReceiptsFetchingMethods(RPCKindQuickNode)
+	require.Equal(t, DebugGetRawReceipts, PickBestReceiptsFetchingMethod(RPCKindQuickNode, quicknode, 5))
+	require.Equal(t, EthGetTransactionReceiptBatch, PickBestReceiptsFetchingMethod(RPCKindQuickNode, quicknode&^DebugGetRawReceipts, 5))
+
+	require.Equal(t, EthGetBlockReceipts, PickBestReceiptsFetchingMethod(RPCKindStandard, AvailableReceiptsFetchingMethods(RPCKindStandard), 1))
+	require.Equal(t, DebugGetRawReceipts, PickBestReceiptsFetchingMethod(RPCKindDebugGeth, AvailableReceiptsFetchingMethods(RPCKindDebugGeth), 1))
+	require.Equal(t, EthGetTransactionReceiptBatch, PickBestReceiptsFetchingMethod(RPCKindBasic, AvailableReceiptsFetchingMethods(RPCKindBasic), 1000))
+}
@@ -3,11 +3,13 @@ package sources
 import (
 	"context"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 
 	"github.com/ethereum-optimism/optimism/op-node/client"
 	"github.com/ethereum-optimism/optimism/op-node/eth"
 	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
 )
 
 type RollupClient struct {
@@ -24,12 +26,34 @@ func (r *RollupClient) OutputAtBlock(ctx context.Context, blockNum uint64) (*eth
 	return output, err
 }
 
+func (r *RollupClient) SafeHeadAtL1Block(ctx context.Context, blockNum uint64) (*eth.SafeHeadResponse, error) {
+	var output *eth.SafeHeadResponse
+	err := r.rpc.CallContext(ctx, &output, "optimism_safeHeadAtL1Block", hexutil.Uint64(blockNum))
+	return output, err
+}
+
 func (r *RollupClient) SyncStatus(ctx context.Context) (*eth.SyncStatus, error) {
 	var output *eth.SyncStatus
 	err := r.rpc.CallContext(ctx, &output, "optimism_syncStatus")
 	return output, err
 }
 
+func (r *RollupClient) PipelineState(ctx context.Context) (*derive.PipelineState, error) {
+	var output *derive.PipelineState
+	err := r.rpc.CallContext(ctx, &output, "admin_pipelineState")
+	return output, err
+}
+
+func (r *RollupClient) StartSequencer(ctx context.Context, unsafeHead common.Hash) error {
+	return r.rpc.CallContext(ctx, nil, "admin_startSequencer", unsafeHead)
+}
+
+func (r *RollupClient) StopSequencer(ctx context.Context) (common.Hash, error) {
+	var result common.Hash
+	err := r.rpc.CallContext(ctx, &result, "admin_stopSequencer")
+	return result, err
+}
+
 func (r *RollupClient) RollupConfig(ctx context.Context) (*rollup.Config, error) {
 	var output *rollup.Config
 	err := r.rpc.CallContext(ctx, &output, "optimism_rollupConfig")
@@ -1,6 +1,7 @@
 package op_proposer
 
 import (
+	"errors"
 	"time"
 
 	"github.com/urfave/cli"
@@ -10,6 +11,7 @@ import (
 	opmetrics "github.com/ethereum-optimism/optimism/op-service/metrics"
 	oppprof "github.com/ethereum-optimism/optimism/op-service/pprof"
 	oprpc "github.com/ethereum-optimism/optimism/op-service/rpc"
+	"github.com/ethereum-optimism/optimism/op-service/txmgr"
 )
 
 type Config struct {
@@ -61,11 +63,31 @@ type Config struct {
 	// for L2 blocks derived from non-finalized L1 data.
 	AllowNonFinalized bool
 
+	// L2ConfirmationDepth is the number of L2 blocks to stay behind the safe,
+	// or finalized, L2 head of the rollup node when proposing outputs.
+	L2ConfirmationDepth uint64
+
+	// MaxCatchUpOutputs is the maximum number of pending outputs that are
+	// proposed back to back, when the proposer fell behind.
+	MaxCatchUpOutputs uint64
+
+	// VerifierEnabled enables checking the outputs on the L2OutputOracle against the rollup node.
+	// Without a private key or mnemonic, only the verifier runs.
+	VerifierEnabled bool
+
+	// VerifierStateFile is the file that records the checked outputs. Not persisted if empty.
+	VerifierStateFile string
+
+	// VerifierStartBlock is the first L2 block to check the output of.
+	VerifierStartBlock uint64
+
 	LogConfig oplog.CLIConfig
 
 	MetricsConfig opmetrics.CLIConfig
 
 	PprofConfig oppprof.CLIConfig
+
+	TxMgrConfig txmgr.CLIConfig
 }
 
 func (c Config) Check() error {
@@ -81,6 +103,15 @@ func (c Config) Check() error {
 	if err := c.PprofConfig.Check(); err != nil {
 		return err
 	}
+	if err := c.TxMgrConfig.Check(); err != nil {
+		return err
+	}
+	if c.MaxCatchUpOutputs == 0 {
+		return errors.New("max catch-up outputs must be at least 1")
+	}
+	if c.PrivateKey == "" && c.Mnemonic == "" && !c.VerifierEnabled {
+		return errors.New("a private key or mnemonic is required, unless running as verifier")
+	}
 	return nil
 }
 
@@ -99,9 +130,15 @@ func NewConfig(ctx *cli.Context) Config {
 		L2OutputHDPath:            ctx.GlobalString(flags.L2OutputHDPathFlag.Name),
 		PrivateKey:                ctx.GlobalString(flags.PrivateKeyFlag.Name),
 		AllowNonFinalized:         ctx.GlobalBool(flags.AllowNonFinalizedFlag.Name),
+		L2ConfirmationDepth:       ctx.GlobalUint64(flags.L2ConfirmationDepthFlag.Name),
+		MaxCatchUpOutputs:         ctx.GlobalUint64(flags.MaxCatchUpOutputsFlag.Name),
+		VerifierEnabled:           ctx.GlobalBool(flags.VerifierEnabledFlag.Name),
+		VerifierStateFile:         ctx.GlobalString(flags.VerifierStateFileFlag.Name),
+		VerifierStartBlock:        ctx.GlobalUint64(flags.VerifierStartBlockFlag.Name),
 		RPCConfig:                 oprpc.ReadCLIConfig(ctx),
 		LogConfig:                 oplog.ReadCLIConfig(ctx),
 		MetricsConfig:             opmetrics.ReadCLIConfig(ctx),
 		PprofConfig:               oppprof.ReadCLIConfig(ctx),
+		TxMgrConfig:               txmgr.ReadCLIConfig(ctx),
 	}
 }
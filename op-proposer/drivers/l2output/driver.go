@@ -2,33 +2,36 @@ package l2output
 
 import (
 	"context"
-	"crypto/ecdsa"
 	"fmt"
 	"math/big"
-	"strings"
 
 	"github.com/ethereum-optimism/optimism/op-node/sources"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/log"
 
 	"github.com/ethereum-optimism/optimism/op-bindings/bindings"
 	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-service/txmgr"
 )
 
 var bigOne = big.NewInt(1)
 var supportedL2OutputVersion = eth.Bytes32{}
 
+// catchUpGasMarginPercent is the margin added to the gas estimate of the first
+// output, to set the gas limit of all outputs that are proposed back to back.
+// The later outputs cannot be estimated, as they depend on the earlier outputs.
+const catchUpGasMarginPercent = 20
+
 type Config struct {
 	Log  log.Logger
 	Name string
 
-	// L1Client is used to submit transactions to
+	// L1Client is used to query the L2 Output Oracle
 	L1Client *ethclient.Client
 	// RollupClient is used to retrieve output roots from
 	RollupClient *sources.RollupClient
@@ -39,50 +42,48 @@ type Config struct {
 	// This option is not necessary when higher proposal latency is acceptable and L1 is healthy.
 	AllowNonFinalized bool
 
+	// ConfirmationDepth is the number of L2 blocks to stay behind the safe, or
+	// finalized, L2 head of the rollup node when proposing outputs.
+	ConfirmationDepth uint64
+
+	// MaxCatchUpOutputs is the maximum number of pending outputs that are
+	// proposed back to back, with pipelined transactions, when the proposer
+	// fell behind. Zero or one proposes a single output at a time.
+	MaxCatchUpOutputs uint64
+
 	// L2OOAddr is the L1 contract address of the L2 Output Oracle.
 	L2OOAddr common.Address
 
-	// ChainID is the L1 chain ID used for proposal transaction signing
-	ChainID *big.Int
-
-	// Privkey used for proposal transaction signing
-	PrivKey *ecdsa.PrivateKey
+	// From is the address of the account that signs the proposal transactions
+	From common.Address
 }
 
 type Driver struct {
-	cfg             Config
-	l2ooContract    *bindings.L2OutputOracle
-	rawL2ooContract *bind.BoundContract
-	walletAddr      common.Address
-	l               log.Logger
+	cfg          Config
+	l2ooContract *bindings.L2OutputOracleCaller
+	l2ooABI      *abi.ABI
+	l            log.Logger
 }
 
 func NewDriver(cfg Config) (*Driver, error) {
-	l2ooContract, err := bindings.NewL2OutputOracle(cfg.L2OOAddr, cfg.L1Client)
+	l2ooContract, err := bindings.NewL2OutputOracleCaller(cfg.L2OOAddr, cfg.L1Client)
 	if err != nil {
 		return nil, err
 	}
 
-	parsed, err := abi.JSON(strings.NewReader(
-		bindings.L2OutputOracleMetaData.ABI,
-	))
+	parsed, err := bindings.L2OutputOracleMetaData.GetAbi()
 	if err != nil {
 		return nil, err
 	}
 
-	rawL2ooContract := bind.NewBoundContract(
-		cfg.L2OOAddr, parsed, cfg.L1Client, cfg.L1Client, cfg.L1Client,
-	)
-
-	walletAddr := crypto.PubkeyToAddress(cfg.PrivKey.PublicKey)
-	cfg.Log.Info("Configured driver", "wallet", walletAddr, "l2-output-contract", cfg.L2OOAddr)
+	cfg.Log.Info("Configured driver", "wallet", cfg.From, "l2-output-contract", cfg.L2OOAddr,
+		"confirmation_depth", cfg.ConfirmationDepth, "max_catch_up_outputs", cfg.MaxCatchUpOutputs)
 
 	return &Driver{
-		cfg:             cfg,
-		l2ooContract:    l2ooContract,
-		rawL2ooContract: rawL2ooContract,
-		walletAddr:      walletAddr,
-		l:               cfg.Log,
+		cfg:          cfg,
+		l2ooContract: l2ooContract,
+		l2ooABI:      parsed,
+		l:            cfg.Log,
 	}, nil
 }
 
@@ -93,12 +94,15 @@ func (d *Driver) Name() string {
 
 // WalletAddr is the wallet address used to pay for transaction fees.
 func (d *Driver) WalletAddr() common.Address {
-	return d.walletAddr
+	return d.cfg.From
 }
 
 // GetBlockRange returns the start and end L2 block heights that need to be
 // processed. Note that the end value is *exclusive*, therefore if the returned
 // values are identical nothing needs to be processed.
+//
+// When the proposer fell behind, the range spans up to MaxCatchUpOutputs
+// outputs, which are all ready to be proposed.
 func (d *Driver) GetBlockRange(ctx context.Context) (*big.Int, *big.Int, error) {
 	name := d.cfg.Name
 
@@ -121,17 +125,17 @@ func (d *Driver) GetBlockRange(ctx context.Context) (*big.Int, *big.Int, error)
 		d.l.Error(name+" unable to get next block number", "err", err)
 		return nil, nil, err
 	}
+	interval, err := d.l2ooContract.SUBMISSIONINTERVAL(callOpts)
+	if err != nil {
+		d.l.Error(name+" unable to get submission interval", "err", err)
+		return nil, nil, err
+	}
 	status, err := d.cfg.RollupClient.SyncStatus(ctx)
 	if err != nil {
 		d.l.Error(name+" unable to get sync status", "err", err)
 		return nil, nil, err
 	}
-	var currentBlockNumber *big.Int
-	if d.cfg.AllowNonFinalized {
-		currentBlockNumber = new(big.Int).SetUint64(status.SafeL2.Number)
-	} else {
-		currentBlockNumber = new(big.Int).SetUint64(status.FinalizedL2.Number)
-	}
+	currentBlockNumber := new(big.Int).SetUint64(d.readyHead(status))
 
 	// If we do not have the new L2 Block number
 	if currentBlockNumber.Cmp(nextBlockNumber) < 0 {
@@ -140,76 +144,135 @@ func (d *Driver) GetBlockRange(ctx context.Context) (*big.Int, *big.Int, error)
 		return start, start, nil
 	}
 
-	d.l.Info(name+" submission interval has elapsed",
-		"currentBlockNumber", currentBlockNumber, "nextBlockNumber", nextBlockNumber)
+	// Otherwise the submission interval has elapsed. Propose as many pending
+	// outputs as are ready and allowed, and add one since end is exclusive.
+	pending := new(big.Int).Sub(currentBlockNumber, nextBlockNumber)
+	pending.Div(pending, interval)
+	pending.Add(pending, bigOne)
+	if maxOutputs := new(big.Int).SetUint64(d.maxCatchUpOutputs()); pending.Cmp(maxOutputs) > 0 {
+		pending = maxOutputs
+	}
+	end := new(big.Int).Sub(pending, bigOne)
+	end.Mul(end, interval)
+	end.Add(end, nextBlockNumber)
+	end.Add(end, bigOne)
 
-	// Otherwise the submission interval has elapsed. Transform the next
-	// expected timestamp into its L2 block number, and add one since end is
-	// exclusive.
-	end := new(big.Int).Add(nextBlockNumber, bigOne)
+	d.l.Info(name+" submission interval has elapsed",
+		"currentBlockNumber", currentBlockNumber, "nextBlockNumber", nextBlockNumber,
+		"pendingOutputs", pending)
 
 	return start, end, nil
 }
 
-// CraftTx transforms the L2 blocks between start and end into a transaction
-// using the given nonce.
+// CraftTxs transforms the L2 blocks between start and end into transaction
+// candidates, one per output in the range, in the order they must be proposed.
+// The outputs are spaced by the submission interval, and the last one is the
+// output of the last block in the range.
 //
-// NOTE: This method SHOULD NOT publish the resulting transaction.
-func (d *Driver) CraftTx(ctx context.Context, start, end, nonce *big.Int) (*types.Transaction, error) {
+// NOTE: This method SHOULD NOT publish the resulting transactions.
+func (d *Driver) CraftTxs(ctx context.Context, start, end *big.Int) ([]*txmgr.TxCandidate, error) {
 	name := d.cfg.Name
 
-	d.l.Info(name+" crafting checkpoint tx", "start", start, "end", end, "nonce", nonce)
+	d.l.Info(name+" crafting checkpoint txs", "start", start, "end", end)
+
+	callOpts := &bind.CallOpts{
+		Pending: false,
+		Context: ctx,
+	}
+	interval, err := d.l2ooContract.SUBMISSIONINTERVAL(callOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get submission interval: %w", err)
+	}
+	checkpoints := checkpointsInRange(start.Uint64(), end.Uint64(), interval.Uint64())
+	if len(checkpoints) == 0 {
+		return nil, fmt.Errorf("no output in block range [%s, %s)", start, end)
+	}
+
+	candidates := make([]*txmgr.TxCandidate, 0, len(checkpoints))
+	for _, checkpoint := range checkpoints {
+		candidate, err := d.craftTx(ctx, callOpts, checkpoint)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	// Only the first output can be estimated against the current oracle state,
+	// the next outputs are proposed on top of it, and cost about the same.
+	if len(candidates) > 1 {
+		gas, err := d.cfg.L1Client.EstimateGas(ctx, ethereum.CallMsg{
+			From: d.cfg.From,
+			To:   candidates[0].To,
+			Data: candidates[0].TxData,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to estimate gas of first output: %w", err)
+		}
+		gas = gas * (100 + catchUpGasMarginPercent) / 100
+		for _, candidate := range candidates {
+			candidate.GasLimit = gas
+		}
+	}
+
+	d.l.Info(name+" proposals constructed",
+		"start", start, "end", end,
+		"blocks_committed", new(big.Int).Sub(end, start),
+		"outputs", len(candidates))
+	return candidates, nil
+}
 
-	// Fetch the final block in the range, as this is the only L2 output we need to submit.
-	nextCheckpointBlock := new(big.Int).Sub(end, bigOne).Uint64()
+// craftTx crafts the proposal of the output of the given L2 block.
+func (d *Driver) craftTx(ctx context.Context, callOpts *bind.CallOpts, checkpoint uint64) (*txmgr.TxCandidate, error) {
+	name := d.cfg.Name
 
-	output, err := d.cfg.RollupClient.OutputAtBlock(ctx, nextCheckpointBlock)
+	output, err := d.cfg.RollupClient.OutputAtBlock(ctx, checkpoint)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch output at block %d: %w", nextCheckpointBlock, err)
+		return nil, fmt.Errorf("failed to fetch output at block %d: %w", checkpoint, err)
 	}
 	if output.Version != supportedL2OutputVersion {
 		return nil, fmt.Errorf("unsupported l2 output version: %s", output.Version)
 	}
-	if output.BlockRef.Number != nextCheckpointBlock { // sanity check, e.g. in case of bad RPC caching
-		return nil, fmt.Errorf("invalid blockNumber: next blockNumber is %v, blockNumber of block is %v", nextCheckpointBlock, output.BlockRef.Number)
+	if output.BlockRef.Number != checkpoint { // sanity check, e.g. in case of bad RPC caching
+		return nil, fmt.Errorf("invalid blockNumber: next blockNumber is %v, blockNumber of block is %v", checkpoint, output.BlockRef.Number)
 	}
 
 	// Always propose if it's part of the Finalized L2 chain. Or if allowed, if it's part of the safe L2 chain.
-	if !(output.BlockRef.Number <= output.Status.FinalizedL2.Number || (d.cfg.AllowNonFinalized && output.BlockRef.Number <= output.Status.SafeL2.Number)) {
+	// In both cases, stay the configured confirmation depth behind the head.
+	if output.BlockRef.Number > d.readyHead(output.Status) {
 		d.l.Debug("not proposing yet, L2 block is not ready for proposal",
 			"l2_proposal", output.BlockRef,
 			"l2_safe", output.Status.SafeL2,
 			"l2_finalized", output.Status.FinalizedL2,
-			"allow_non_finalized", d.cfg.AllowNonFinalized)
+			"allow_non_finalized", d.cfg.AllowNonFinalized,
+			"confirmation_depth", d.cfg.ConfirmationDepth)
 		return nil, fmt.Errorf("output for L2 block %s is still unsafe", output.BlockRef)
 	}
 
-	opts, err := bind.NewKeyedTransactorWithChainID(d.cfg.PrivKey, d.cfg.ChainID)
+	// The oracle maps every L2 block number to a timestamp. If the rollup node
+	// disagrees, e.g. because of a misconfigured oracle or rollup node, the
+	// output would be proposed for the wrong L2 block.
+	timestamp, err := d.l2ooContract.ComputeL2Timestamp(callOpts, new(big.Int).SetUint64(checkpoint))
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to compute L2 timestamp of block %d: %w", checkpoint, err)
+	}
+	if !timestamp.IsUint64() || timestamp.Uint64() != output.BlockRef.Time {
+		return nil, fmt.Errorf("L2 block %s has timestamp %d, but the oracle expects timestamp %s", output.BlockRef, output.BlockRef.Time, timestamp)
 	}
-	opts.Context = ctx
-	opts.Nonce = nonce
-	opts.NoSend = true
 
 	// Note: the CurrentL1 is up to (and incl.) what the safe chain and finalized chain have been derived from,
 	// and should be a quite recent L1 block (depends on L1 conf distance applied to rollup node).
 
-	tx, err := d.l2ooContract.ProposeL2Output(
-		opts,
+	data, err := d.l2ooABI.Pack(
+		"proposeL2Output",
 		output.OutputRoot,
 		new(big.Int).SetUint64(output.BlockRef.Number),
 		output.Status.CurrentL1.Hash,
 		new(big.Int).SetUint64(output.Status.CurrentL1.Number))
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to pack proposal: %w", err)
 	}
 
-	numElements := new(big.Int).Sub(start, end).Uint64()
 	d.l.Info(name+" proposal constructed",
-		"start", start, "end", end,
-		"nonce", nonce, "blocks_committed", numElements,
-		"tx_hash", tx.Hash(),
 		"output_version", output.Version,
 		"output_root", output.OutputRoot,
 		"output_block", output.BlockRef,
@@ -219,29 +282,44 @@ func (d *Driver) CraftTx(ctx context.Context, start, end, nonce *big.Int) (*type
 		"safe_l2", output.Status.SafeL2,
 		"finalized_l2", output.Status.FinalizedL2,
 	)
-	return tx, nil
+	return &txmgr.TxCandidate{
+		To:     &d.cfg.L2OOAddr,
+		TxData: data,
+	}, nil
 }
 
-// UpdateGasPrice signs an otherwise identical txn to the one provided but with
-// updated gas prices sampled from the existing network conditions.
-//
-// NOTE: This method SHOULD NOT publish the resulting transaction.
-func (d *Driver) UpdateGasPrice(ctx context.Context, tx *types.Transaction) (*types.Transaction, error) {
-	opts, err := bind.NewKeyedTransactorWithChainID(
-		d.cfg.PrivKey, d.cfg.ChainID,
-	)
-	if err != nil {
-		return nil, err
+// readyHead returns the highest L2 block number that may be proposed: the
+// finalized, or if allowed the safe, L2 head, minus the confirmation depth.
+func (d *Driver) readyHead(status *eth.SyncStatus) uint64 {
+	head := status.FinalizedL2.Number
+	if d.cfg.AllowNonFinalized {
+		head = status.SafeL2.Number
 	}
-	opts.Context = ctx
-	opts.Nonce = new(big.Int).SetUint64(tx.Nonce())
-	opts.NoSend = true
+	if head < d.cfg.ConfirmationDepth {
+		return 0
+	}
+	return head - d.cfg.ConfirmationDepth
+}
 
-	return d.rawL2ooContract.RawTransact(opts, tx.Data())
+func (d *Driver) maxCatchUpOutputs() uint64 {
+	if d.cfg.MaxCatchUpOutputs == 0 {
+		return 1
+	}
+	return d.cfg.MaxCatchUpOutputs
 }
 
-// SendTransaction injects a signed transaction into the pending pool for execution.
-func (d *Driver) SendTransaction(ctx context.Context, tx *types.Transaction) error {
-	d.l.Info(d.cfg.Name+" sending transaction", "tx", tx.Hash())
-	return d.cfg.L1Client.SendTransaction(ctx, tx)
+// checkpointsInRange returns the L2 block numbers of the outputs in the range
+// [start, end), in ascending order. The outputs are spaced by the submission
+// interval, the last one being at end-1.
+func checkpointsInRange(start, end, interval uint64) []uint64 {
+	if end <= start || interval == 0 {
+		return nil
+	}
+	last := end - 1
+	first := last - (last-start)/interval*interval
+	var checkpoints []uint64
+	for checkpoint := first; checkpoint <= last; checkpoint += interval {
+		checkpoints = append(checkpoints, checkpoint)
+	}
+	return checkpoints
 }
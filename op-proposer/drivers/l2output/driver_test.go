@@ -0,0 +1,31 @@
+package l2output
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+)
+
+func TestCheckpointsInRange(t *testing.T) {
+	require.Equal(t, []uint64{10}, checkpointsInRange(1, 11, 10))
+	require.Equal(t, []uint64{20, 30, 40}, checkpointsInRange(11, 41, 10))
+	require.Equal(t, []uint64{0}, checkpointsInRange(0, 1, 10))
+	require.Equal(t, []uint64{5, 6, 7}, checkpointsInRange(5, 8, 1))
+	require.Empty(t, checkpointsInRange(11, 11, 10))
+	require.Empty(t, checkpointsInRange(11, 20, 0))
+}
+
+func TestReadyHead(t *testing.T) {
+	status := &eth.SyncStatus{
+		SafeL2:      eth.L2BlockRef{Number: 100},
+		FinalizedL2: eth.L2BlockRef{Number: 80},
+	}
+	d := &Driver{cfg: Config{ConfirmationDepth: 5}}
+	require.Equal(t, uint64(75), d.readyHead(status))
+	d.cfg.AllowNonFinalized = true
+	require.Equal(t, uint64(95), d.readyHead(status))
+	d.cfg.ConfirmationDepth = 200
+	require.Equal(t, uint64(0), d.readyHead(status))
+}
@@ -8,6 +8,7 @@ import (
 	opmetrics "github.com/ethereum-optimism/optimism/op-service/metrics"
 	oppprof "github.com/ethereum-optimism/optimism/op-service/pprof"
 	oprpc "github.com/ethereum-optimism/optimism/op-service/rpc"
+	"github.com/ethereum-optimism/optimism/op-service/txmgr"
 )
 
 const envVarPrefix = "OP_PROPOSER"
@@ -87,6 +88,36 @@ var (
 		Usage:  "Allow the proposer to submit proposals for L2 blocks derived from non-finalized L1 blocks.",
 		EnvVar: opservice.PrefixEnvVar(envVarPrefix, "ALLOW_NON_FINALIZED"),
 	}
+	L2ConfirmationDepthFlag = cli.Uint64Flag{
+		Name: "l2-confirmation-depth",
+		Usage: "Number of L2 blocks to stay behind the safe (or finalized) L2 head of the rollup node " +
+			"when proposing outputs.",
+		EnvVar: opservice.PrefixEnvVar(envVarPrefix, "L2_CONFIRMATION_DEPTH"),
+	}
+	MaxCatchUpOutputsFlag = cli.Uint64Flag{
+		Name: "catch-up.max-outputs",
+		Usage: "Maximum number of pending outputs to propose back to back, with pipelined transactions, " +
+			"when the proposer fell behind. 1 proposes a single output at a time.",
+		Value:  1,
+		EnvVar: opservice.PrefixEnvVar(envVarPrefix, "CATCH_UP_MAX_OUTPUTS"),
+	}
+	VerifierEnabledFlag = cli.BoolFlag{
+		Name: "verifier.enabled",
+		Usage: "Check the outputs on the L2OutputOracle, including outputs of other proposers, against the rollup node. " +
+			"Without a private key or mnemonic, the proposer runs as a watchtower, only checking outputs.",
+		EnvVar: opservice.PrefixEnvVar(envVarPrefix, "VERIFIER_ENABLED"),
+	}
+	VerifierStateFileFlag = cli.StringFlag{
+		Name:   "verifier.state-file",
+		Usage:  "Path of the file that records the checked outputs and the mismatches, to resume from on restart. Not persisted if empty.",
+		EnvVar: opservice.PrefixEnvVar(envVarPrefix, "VERIFIER_STATE_FILE"),
+	}
+	VerifierStartBlockFlag = cli.Uint64Flag{
+		Name: "verifier.start-block",
+		Usage: "First L2 block to check the output of. Earlier outputs are not checked, " +
+			"e.g. when the rollup node does not have the state of older blocks.",
+		EnvVar: opservice.PrefixEnvVar(envVarPrefix, "VERIFIER_START_BLOCK"),
+	}
 )
 
 var requiredFlags = []cli.Flag{
@@ -104,6 +135,11 @@ var optionalFlags = []cli.Flag{
 	L2OutputHDPathFlag,
 	PrivateKeyFlag,
 	AllowNonFinalizedFlag,
+	L2ConfirmationDepthFlag,
+	MaxCatchUpOutputsFlag,
+	VerifierEnabledFlag,
+	VerifierStateFileFlag,
+	VerifierStartBlockFlag,
 }
 
 func init() {
@@ -112,6 +148,7 @@ func init() {
 	optionalFlags = append(optionalFlags, oplog.CLIFlags(envVarPrefix)...)
 	optionalFlags = append(optionalFlags, opmetrics.CLIFlags(envVarPrefix)...)
 	optionalFlags = append(optionalFlags, oppprof.CLIFlags(envVarPrefix)...)
+	optionalFlags = append(optionalFlags, txmgr.CLIFlags(envVarPrefix)...)
 
 	Flags = append(requiredFlags, optionalFlags...)
 }
@@ -19,22 +19,28 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rpc"
 	hdwallet "github.com/miguelmota/go-ethereum-hdwallet"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/urfave/cli"
 
+	"github.com/ethereum-optimism/optimism/op-bindings/bindings"
 	"github.com/ethereum-optimism/optimism/op-node/client"
 	"github.com/ethereum-optimism/optimism/op-node/sources"
 	"github.com/ethereum-optimism/optimism/op-proposer/drivers/l2output"
-	"github.com/ethereum-optimism/optimism/op-proposer/txmgr"
+	"github.com/ethereum-optimism/optimism/op-proposer/verifier"
 	oplog "github.com/ethereum-optimism/optimism/op-service/log"
 	opmetrics "github.com/ethereum-optimism/optimism/op-service/metrics"
 	oppprof "github.com/ethereum-optimism/optimism/op-service/pprof"
 	oprpc "github.com/ethereum-optimism/optimism/op-service/rpc"
+	"github.com/ethereum-optimism/optimism/op-service/txmgr"
 )
 
 const (
 	// defaultDialTimeout is default duration the service will wait on
 	// startup to make a connection to either the L1 or L2 backends.
 	defaultDialTimeout = 5 * time.Second
+
+	// metricsNamespace is the namespace of the metrics of the L2 Output Submitter.
+	metricsNamespace = "op_proposer"
 )
 
 // Main is the entrypoint into the L2 Output Submitter. This method returns a
@@ -78,7 +84,7 @@ func Main(version string) func(ctx *cli.Context) error {
 			}()
 		}
 
-		registry := opmetrics.NewRegistry()
+		registry := l2OutputSubmitter.registry
 		metricsCfg := cfg.MetricsConfig
 		if metricsCfg.Enabled {
 			l.Info("starting metrics server", "addr", metricsCfg.ListenAddr, "port", metricsCfg.ListenPort)
@@ -87,8 +93,10 @@ func Main(version string) func(ctx *cli.Context) error {
 					l.Error("error starting metrics server", err)
 				}
 			}()
-			addr := l2OutputSubmitter.l2OutputService.cfg.Driver.WalletAddr()
-			opmetrics.LaunchBalanceMetrics(ctx, l, registry, "", l2OutputSubmitter.l2OutputService.cfg.L1Client, addr)
+			if l2OutputSubmitter.l2OutputService != nil {
+				addr := l2OutputSubmitter.l2OutputService.cfg.Driver.WalletAddr()
+				opmetrics.LaunchBalanceMetrics(ctx, l, registry, "", l2OutputSubmitter.l1Client, addr)
+			}
 		}
 
 		rpcCfg := cfg.RPCConfig
@@ -120,7 +128,10 @@ func Main(version string) func(ctx *cli.Context) error {
 // L2Outputs to the L2OutputOracle contract.
 type L2OutputSubmitter struct {
 	ctx             context.Context
-	l2OutputService *Service
+	l1Client        *ethclient.Client
+	l2OutputService *Service           // nil when running as watchtower
+	verifier        *verifier.Verifier // nil if the verifier is not enabled
+	registry        *prometheus.Registry
 }
 
 // NewL2OutputSubmitter initializes the L2OutputSubmitter, gathering any resources
@@ -138,8 +149,15 @@ func NewL2OutputSubmitter(
 	if cfg.PrivateKey != "" && cfg.Mnemonic != "" {
 		return nil, errors.New("cannot specify both a private key and a mnemonic")
 	}
+	// Without a proposing key, only the verifier runs, as a watchtower.
+	watchtower := cfg.PrivateKey == "" && cfg.Mnemonic == ""
+	if watchtower && !cfg.VerifierEnabled {
+		return nil, errors.New("a private key or mnemonic is required, unless running as verifier")
+	}
 
-	if cfg.PrivateKey == "" {
+	if watchtower {
+		l.Info("No proposing key configured, running as watchtower")
+	} else if cfg.PrivateKey == "" {
 		// Parse l2output wallet private key and L2OO contract address.
 		wallet, err := hdwallet.NewFromMnemonic(cfg.Mnemonic)
 		if err != nil {
@@ -183,6 +201,39 @@ func NewL2OutputSubmitter(
 		return nil, err
 	}
 
+	registry := opmetrics.NewRegistry()
+
+	var outputVerifier *verifier.Verifier
+	if cfg.VerifierEnabled {
+		l2ooContract, err := bindings.NewL2OutputOracleCaller(l2ooAddress, l1Client)
+		if err != nil {
+			return nil, err
+		}
+		outputVerifier, err = verifier.NewVerifier(verifier.Config{
+			Log:               l,
+			Name:              "L2Output Verifier",
+			L2OO:              l2ooContract,
+			RollupClient:      rollupClient,
+			AllowNonFinalized: cfg.AllowNonFinalized,
+			PollInterval:      cfg.PollInterval,
+			StartBlock:        cfg.VerifierStartBlock,
+			StatePath:         cfg.VerifierStateFile,
+			Metrics:           verifier.NewMetrics(registry),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if watchtower {
+		return &L2OutputSubmitter{
+			ctx:      ctx,
+			l1Client: l1Client,
+			verifier: outputVerifier,
+			registry: registry,
+		}, nil
+	}
+
 	txManagerConfig := txmgr.Config{
 		Log:                       l,
 		Name:                      "L2Output Submitter",
@@ -190,6 +241,8 @@ func NewL2OutputSubmitter(
 		ReceiptQueryInterval:      time.Second,
 		NumConfirmations:          cfg.NumConfirmations,
 		SafeAbortNonceTooLowCount: cfg.SafeAbortNonceTooLowCount,
+		FeeLimits:                 cfg.TxMgrConfig.FeeLimits(),
+		Metrics:                   txmgr.NewMetrics(metricsNamespace, registry),
 	}
 
 	l2OutputDriver, err := l2output.NewDriver(l2output.Config{
@@ -198,35 +251,57 @@ func NewL2OutputSubmitter(
 		L1Client:          l1Client,
 		RollupClient:      rollupClient,
 		AllowNonFinalized: cfg.AllowNonFinalized,
+		ConfirmationDepth: cfg.L2ConfirmationDepth,
+		MaxCatchUpOutputs: cfg.MaxCatchUpOutputs,
 		L2OOAddr:          l2ooAddress,
-		ChainID:           chainID,
-		PrivKey:           l2OutputPrivKey,
+		From:              crypto.PubkeyToAddress(l2OutputPrivKey.PublicKey),
 	})
 	if err != nil {
 		return nil, err
 	}
 
 	l2OutputService := NewService(ServiceConfig{
-		Log:             l,
-		Context:         ctx,
-		Driver:          l2OutputDriver,
-		PollInterval:    cfg.PollInterval,
-		L1Client:        l1Client,
-		TxManagerConfig: txManagerConfig,
+		Log:              l,
+		Context:          ctx,
+		Driver:           l2OutputDriver,
+		PollInterval:     cfg.PollInterval,
+		L1Client:         l1Client,
+		TxManagerConfig:  txManagerConfig,
+		ChainID:          chainID,
+		SignerFn:         txmgr.PrivateKeySignerFn(l2OutputPrivKey, chainID),
+		CancelPendingTxs: cfg.TxMgrConfig.CancelPendingTxs,
 	})
 
 	return &L2OutputSubmitter{
 		ctx:             ctx,
+		l1Client:        l1Client,
 		l2OutputService: l2OutputService,
+		verifier:        outputVerifier,
+		registry:        registry,
 	}, nil
 }
 
 func (l *L2OutputSubmitter) Start() error {
-	return l.l2OutputService.Start()
+	if l.l2OutputService != nil {
+		if err := l.l2OutputService.Start(); err != nil {
+			return err
+		}
+	}
+	if l.verifier != nil {
+		if err := l.verifier.Start(); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (l *L2OutputSubmitter) Stop() {
-	_ = l.l2OutputService.Stop()
+	if l.verifier != nil {
+		_ = l.verifier.Stop()
+	}
+	if l.l2OutputService != nil {
+		_ = l.l2OutputService.Stop()
+	}
 }
 
 // dialEthClientWithTimeout attempts to dial the L1 provider using the provided
@@ -6,9 +6,8 @@ import (
 	"sync"
 	"time"
 
-	"github.com/ethereum-optimism/optimism/op-proposer/txmgr"
+	"github.com/ethereum-optimism/optimism/op-service/txmgr"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/log"
 )
@@ -27,27 +26,14 @@ type Driver interface {
 	// returned values are identical nothing needs to be processed.
 	GetBlockRange(ctx context.Context) (*big.Int, *big.Int, error)
 
-	// CraftTx transforms the L2 blocks between start and end into a transaction
-	// using the given nonce.
+	// CraftTxs transforms the L2 blocks between start and end into
+	// transaction candidates, in the order they must be included.
 	//
-	// NOTE: This method SHOULD NOT publish the resulting transaction.
-	CraftTx(
+	// NOTE: This method SHOULD NOT publish the resulting transactions.
+	CraftTxs(
 		ctx context.Context,
-		start, end, nonce *big.Int,
-	) (*types.Transaction, error)
-
-	// UpdateGasPrice signs an otherwise identical txn to the one provided but
-	// with updated gas prices sampled from the existing network conditions.
-	//
-	// NOTE: Thie method SHOULD NOT publish the resulting transaction.
-	UpdateGasPrice(
-		ctx context.Context,
-		tx *types.Transaction,
-	) (*types.Transaction, error)
-
-	// SendTransaction injects a signed transaction into the pending pool for
-	// execution.
-	SendTransaction(ctx context.Context, tx *types.Transaction) error
+		start, end *big.Int,
+	) ([]*txmgr.TxCandidate, error)
 }
 
 type ServiceConfig struct {
@@ -57,12 +43,19 @@ type ServiceConfig struct {
 	PollInterval    time.Duration
 	L1Client        *ethclient.Client
 	TxManagerConfig txmgr.Config
+
+	// ChainID is the L1 chain ID used for transaction signing.
+	ChainID *big.Int
+	// SignerFn signs the transactions of the driver wallet.
+	SignerFn txmgr.SignerFn
+	// CancelPendingTxs cancels the transactions of the driver wallet that are pending in the tx pool on startup.
+	CancelPendingTxs bool
 }
 
 type Service struct {
-	cfg   ServiceConfig
-	txMgr txmgr.TxManager
-	l     log.Logger
+	cfg    ServiceConfig
+	sender *txmgr.Sender
+	l      log.Logger
 
 	ctx    context.Context
 	cancel func()
@@ -70,15 +63,16 @@ type Service struct {
 }
 
 func NewService(cfg ServiceConfig) *Service {
-	txMgr := txmgr.NewSimpleTxManager(
+	sender := txmgr.NewSender(
 		cfg.Driver.Name(), cfg.TxManagerConfig, cfg.L1Client,
+		cfg.ChainID, cfg.Driver.WalletAddr(), cfg.SignerFn,
 	)
 
 	ctx, cancel := context.WithCancel(cfg.Context)
 
 	return &Service{
 		cfg:    cfg,
-		txMgr:  txMgr,
+		sender: sender,
 		l:      cfg.Log,
 		ctx:    ctx,
 		cancel: cancel,
@@ -101,6 +95,13 @@ func (s *Service) eventLoop() {
 	defer s.wg.Done()
 
 	name := s.cfg.Driver.Name()
+
+	if s.cfg.CancelPendingTxs {
+		if err := s.sender.CancelPending(s.ctx); err != nil {
+			s.l.Error(name+" unable to cancel pending txs", "err", err)
+		}
+	}
+
 	ticker := time.NewTicker(s.cfg.PollInterval)
 	defer ticker.Stop()
 
@@ -123,49 +124,30 @@ func (s *Service) eventLoop() {
 			}
 			s.l.Info(name+" block range", "start", start, "end", end)
 
-			// Query for the submitter's current nonce.
-			nonce64, err := s.cfg.L1Client.NonceAt(
-				s.ctx, s.cfg.Driver.WalletAddr(), nil,
-			)
-			if err != nil {
-				s.l.Error(name+" unable to get current nonce",
-					"err", err)
-				continue
-			}
-			nonce := new(big.Int).SetUint64(nonce64)
-
-			tx, err := s.cfg.Driver.CraftTx(
-				s.ctx, start, end, nonce,
-			)
+			candidates, err := s.cfg.Driver.CraftTxs(s.ctx, start, end)
 			if err != nil {
 				s.l.Error(name+" unable to craft tx",
 					"err", err)
 				continue
 			}
-
-			// Construct the a closure that will update the txn with the current
-			// gas prices.
-			updateGasPrice := func(ctx context.Context) (*types.Transaction, error) {
-				s.l.Info(name+" updating batch tx gas price", "start", start,
-					"end", end, "nonce", nonce)
-
-				return s.cfg.Driver.UpdateGasPrice(ctx, tx)
+			txs := make([]txmgr.TxCandidate, len(candidates))
+			for i, candidate := range candidates {
+				txs[i] = *candidate
 			}
 
-			// Wait until one of our submitted transactions confirms. If no
-			// receipt is received it's likely our gas price was too low.
-			receipt, err := s.txMgr.Send(
-				s.ctx, updateGasPrice, s.cfg.Driver.SendTransaction,
-			)
+			// Wait until all of our submitted transactions confirm. The
+			// sender publishes the transactions back to back with
+			// consecutive nonces, and bumps their fees until they confirm.
+			receipts, err := s.sender.SendPipelined(s.ctx, txs)
+			for _, receipt := range receipts {
+				s.l.Info(name+" tx successfully published",
+					"tx_hash", receipt.TxHash)
+			}
 			if err != nil {
 				s.l.Error(name+" unable to publish tx", "err", err)
 				continue
 			}
 
-			// The transaction was successfully submitted.
-			s.l.Info(name+" tx successfully published",
-				"tx_hash", receipt.TxHash)
-
 		case <-s.ctx.Done():
 			s.l.Info(name + " service shutting down")
 			return
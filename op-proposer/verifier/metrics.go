@@ -0,0 +1,86 @@
+package verifier
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	Namespace = "op_proposer"
+	Subsystem = "verifier"
+)
+
+type Metricer interface {
+	RecordOracleLatestBlock(num uint64)
+	RecordOutputChecked(num uint64)
+	RecordOutputMismatch(num uint64)
+}
+
+type Metrics struct {
+	OracleLatestBlock   prometheus.Gauge
+	CheckedBlock        prometheus.Gauge
+	OutputsChecked      prometheus.Counter
+	OutputMismatches    prometheus.Counter
+	LastMismatchedBlock prometheus.Gauge
+}
+
+var _ Metricer = (*Metrics)(nil)
+
+func NewMetrics(r *prometheus.Registry) *Metrics {
+	factory := promauto.With(r)
+	return &Metrics{
+		OracleLatestBlock: factory.NewGauge(prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "oracle_latest_block",
+			Help:      "Latest L2 block number with an output on the L2OutputOracle",
+		}),
+		CheckedBlock: factory.NewGauge(prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "checked_block",
+			Help:      "L2 block number of the last checked output",
+		}),
+		OutputsChecked: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "outputs_checked_total",
+			Help:      "Count of outputs on the L2OutputOracle that were checked against the rollup node",
+		}),
+		OutputMismatches: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "output_mismatches_total",
+			Help:      "Count of outputs on the L2OutputOracle that do not match the output of the rollup node",
+		}),
+		LastMismatchedBlock: factory.NewGauge(prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "last_mismatched_block",
+			Help:      "L2 block number of the last output that did not match the rollup node, 0 if none",
+		}),
+	}
+}
+
+func (m *Metrics) RecordOracleLatestBlock(num uint64) {
+	m.OracleLatestBlock.Set(float64(num))
+}
+
+func (m *Metrics) RecordOutputChecked(num uint64) {
+	m.OutputsChecked.Inc()
+	m.CheckedBlock.Set(float64(num))
+}
+
+func (m *Metrics) RecordOutputMismatch(num uint64) {
+	m.OutputMismatches.Inc()
+	m.LastMismatchedBlock.Set(float64(num))
+}
+
+type noopMetrics struct{}
+
+// NoopMetrics discards all metrics.
+var NoopMetrics Metricer = noopMetrics{}
+
+func (noopMetrics) RecordOracleLatestBlock(num uint64) {}
+func (noopMetrics) RecordOutputChecked(num uint64)     {}
+func (noopMetrics) RecordOutputMismatch(num uint64)    {}
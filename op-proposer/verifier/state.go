@@ -0,0 +1,120 @@
+package verifier
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+)
+
+// BlockRange is an inclusive range of L2 block numbers.
+type BlockRange struct {
+	Start uint64 `json:"start"`
+	End   uint64 `json:"end"`
+}
+
+// Mismatch is an output on the L2OutputOracle that does not match the output computed by the rollup node.
+type Mismatch struct {
+	L2BlockNumber   uint64      `json:"l2BlockNumber"`
+	OracleRoot      eth.Bytes32 `json:"oracleRoot"`
+	OracleTimestamp uint64      `json:"oracleTimestamp"`
+	ExpectedRoot    eth.Bytes32 `json:"expectedRoot"`
+}
+
+// State is the local record of the outputs that were checked, and of the mismatches that were found.
+type State struct {
+	// Checked are the ranges of L2 blocks whose outputs were checked, sorted and non-overlapping.
+	Checked    []BlockRange `json:"checked"`
+	Mismatches []Mismatch   `json:"mismatches"`
+}
+
+// LoadState reads the state from the given file. A missing file is an empty state.
+func LoadState(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &State{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read verifier state: %w", err)
+	}
+	var s State
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to decode verifier state: %w", err)
+	}
+	return &s, nil
+}
+
+// Save writes the state to the given file, replacing the previous state atomically.
+func (s *State) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode verifier state: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write verifier state: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write verifier state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write verifier state: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write verifier state: %w", err)
+	}
+	return nil
+}
+
+// IsChecked returns the end of the checked range that contains num, if any.
+func (s *State) IsChecked(num uint64) (uint64, bool) {
+	for _, r := range s.Checked {
+		if r.Start <= num && num <= r.End {
+			return r.End, true
+		}
+	}
+	return 0, false
+}
+
+// MarkChecked adds the range to the checked ranges, merging it with the ranges it overlaps or touches.
+func (s *State) MarkChecked(r BlockRange) {
+	ranges := append(s.Checked, r)
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].Start < ranges[j].Start })
+	merged := ranges[:1]
+	for _, next := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if next.Start <= last.End+1 {
+			if next.End > last.End {
+				last.End = next.End
+			}
+			continue
+		}
+		merged = append(merged, next)
+	}
+	s.Checked = merged
+}
+
+// Truncate forgets the checks of all blocks after num, e.g. when the outputs after num were deleted from the oracle.
+// It returns true if any check was forgotten.
+func (s *State) Truncate(num uint64) bool {
+	changed := false
+	out := s.Checked[:0]
+	for _, r := range s.Checked {
+		if r.Start > num {
+			changed = true
+			continue
+		}
+		if r.End > num {
+			r.End = num
+			changed = true
+		}
+		out = append(out, r)
+	}
+	s.Checked = out
+	return changed
+}
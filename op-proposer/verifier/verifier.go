@@ -0,0 +1,242 @@
+package verifier
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/ethereum-optimism/optimism/op-bindings/bindings"
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+)
+
+var supportedL2OutputVersion = eth.Bytes32{}
+
+// OutputOracle is the read-only view of the L2OutputOracle contract that the verifier uses.
+type OutputOracle interface {
+	STARTINGBLOCKNUMBER(opts *bind.CallOpts) (*big.Int, error)
+	SUBMISSIONINTERVAL(opts *bind.CallOpts) (*big.Int, error)
+	LatestBlockNumber(opts *bind.CallOpts) (*big.Int, error)
+	GetL2Output(opts *bind.CallOpts, l2BlockNumber *big.Int) (bindings.TypesOutputProposal, error)
+}
+
+// OutputSource computes the output of an L2 block, like the optimism_outputAtBlock method of the rollup node.
+type OutputSource interface {
+	OutputAtBlock(ctx context.Context, blockNum uint64) (*eth.OutputResponse, error)
+}
+
+type Config struct {
+	Log  log.Logger
+	Name string
+
+	L2OO         OutputOracle
+	RollupClient OutputSource
+
+	// AllowNonFinalized enables checking outputs of safe, but non-finalized, L2 blocks.
+	// Otherwise outputs are only checked once the rollup node considers their L2 block finalized.
+	AllowNonFinalized bool
+
+	// PollInterval is the delay between checks for new outputs on the oracle.
+	PollInterval time.Duration
+
+	// StartBlock is the first L2 block to check the output of. Outputs of earlier blocks are not checked.
+	StartBlock uint64
+
+	// StatePath is the file that keeps the record of the checked outputs across restarts. Not persisted if empty.
+	StatePath string
+
+	Metrics Metricer
+}
+
+// Verifier walks the outputs on the L2OutputOracle, including outputs of other proposers,
+// and checks every output against the output that the rollup node computes for the same L2 block.
+type Verifier struct {
+	cfg   Config
+	l     log.Logger
+	state *State
+
+	ctx    context.Context
+	cancel func()
+	wg     sync.WaitGroup
+}
+
+func NewVerifier(cfg Config) (*Verifier, error) {
+	state := &State{}
+	if cfg.StatePath != "" {
+		var err error
+		state, err = LoadState(cfg.StatePath)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if cfg.Metrics == nil {
+		cfg.Metrics = NoopMetrics
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Verifier{
+		cfg:    cfg,
+		l:      cfg.Log,
+		state:  state,
+		ctx:    ctx,
+		cancel: cancel,
+	}, nil
+}
+
+func (v *Verifier) Start() error {
+	v.wg.Add(1)
+	go v.eventLoop()
+	return nil
+}
+
+func (v *Verifier) Stop() error {
+	v.cancel()
+	v.wg.Wait()
+	return nil
+}
+
+func (v *Verifier) eventLoop() {
+	defer v.wg.Done()
+
+	name := v.cfg.Name
+	ticker := time.NewTicker(v.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := v.CheckOutputs(v.ctx); err != nil {
+				v.l.Error(name+" unable to check outputs", "err", err)
+			}
+		case <-v.ctx.Done():
+			v.l.Info(name + " verifier shutting down")
+			return
+		}
+	}
+}
+
+// CheckOutputs checks all outputs on the oracle that were not checked before,
+// up to the latest output of an L2 block that is ready to be checked by the rollup node.
+func (v *Verifier) CheckOutputs(ctx context.Context) error {
+	name := v.cfg.Name
+	callOpts := &bind.CallOpts{Context: ctx}
+	startingBlock, err := v.cfg.L2OO.STARTINGBLOCKNUMBER(callOpts)
+	if err != nil {
+		return fmt.Errorf("failed to get starting block number: %w", err)
+	}
+	interval, err := v.cfg.L2OO.SUBMISSIONINTERVAL(callOpts)
+	if err != nil {
+		return fmt.Errorf("failed to get submission interval: %w", err)
+	}
+	latest, err := v.cfg.L2OO.LatestBlockNumber(callOpts)
+	if err != nil {
+		return fmt.Errorf("failed to get latest block number: %w", err)
+	}
+	if !startingBlock.IsUint64() || !interval.IsUint64() || !latest.IsUint64() || interval.Sign() == 0 {
+		return fmt.Errorf("invalid oracle parameters: starting block %s, interval %s, latest block %s", startingBlock, interval, latest)
+	}
+	start, step, end := startingBlock.Uint64(), interval.Uint64(), latest.Uint64()
+	v.cfg.Metrics.RecordOracleLatestBlock(end)
+
+	// Outputs after the latest output were deleted, and may be proposed again with a different output root.
+	if v.state.Truncate(end) {
+		v.l.Warn(name+" outputs were deleted from the oracle, checking them again once they are proposed again", "latest", end)
+		if err := v.saveState(); err != nil {
+			return err
+		}
+	}
+
+	// The first output is proposed one interval after the starting block.
+	for num := start + step; num <= end; num += step {
+		if num < v.cfg.StartBlock {
+			continue
+		}
+		if checkedEnd, ok := v.state.IsChecked(num); ok {
+			// skip to the first output after the checked range
+			if checkedEnd >= end {
+				break
+			}
+			num = checkedEnd - (checkedEnd-start)%step
+			continue
+		}
+		ready, err := v.checkOutput(ctx, num)
+		if err != nil {
+			return err
+		}
+		if !ready {
+			return nil
+		}
+		// The output commits to the L2 blocks since the previous output.
+		v.state.MarkChecked(BlockRange{Start: num - step + 1, End: num})
+		if err := v.saveState(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkOutput checks the output of the given L2 block. It returns false if the rollup node is not ready to check it yet.
+func (v *Verifier) checkOutput(ctx context.Context, num uint64) (bool, error) {
+	name := v.cfg.Name
+	proposal, err := v.cfg.L2OO.GetL2Output(&bind.CallOpts{Context: ctx}, new(big.Int).SetUint64(num))
+	if err != nil {
+		return false, fmt.Errorf("failed to get output of block %d from oracle: %w", num, err)
+	}
+	output, err := v.cfg.RollupClient.OutputAtBlock(ctx, num)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch output at block %d: %w", num, err)
+	}
+	if output.Version != supportedL2OutputVersion {
+		return false, fmt.Errorf("unsupported l2 output version: %s", output.Version)
+	}
+	if output.BlockRef.Number != num { // sanity check, e.g. in case of bad RPC caching
+		return false, fmt.Errorf("invalid blockNumber: expected %d, blockNumber of block is %d", num, output.BlockRef.Number)
+	}
+	// Only check outputs of L2 blocks that cannot be reorged anymore, like the proposer only proposes those.
+	if !(num <= output.Status.FinalizedL2.Number || (v.cfg.AllowNonFinalized && num <= output.Status.SafeL2.Number)) {
+		v.l.Debug(name+" not checking output yet, L2 block is not ready",
+			"l2_block", output.BlockRef,
+			"l2_safe", output.Status.SafeL2,
+			"l2_finalized", output.Status.FinalizedL2,
+			"allow_non_finalized", v.cfg.AllowNonFinalized)
+		return false, nil
+	}
+
+	oracleRoot := eth.Bytes32(proposal.OutputRoot)
+	if oracleRoot != output.OutputRoot {
+		// Structured alert: the alert key can be matched by log based alerting.
+		v.l.Error(name+" output root mismatch",
+			"alert", "output_root_mismatch",
+			"l2_block", output.BlockRef,
+			"oracle_root", oracleRoot,
+			"oracle_timestamp", proposal.Timestamp,
+			"expected_root", output.OutputRoot,
+			"expected_state_root", output.StateRoot,
+			"expected_withdrawals_root", output.WithdrawalStorageRoot)
+		v.cfg.Metrics.RecordOutputMismatch(num)
+		var timestamp uint64
+		if proposal.Timestamp != nil {
+			timestamp = proposal.Timestamp.Uint64()
+		}
+		v.state.Mismatches = append(v.state.Mismatches, Mismatch{
+			L2BlockNumber:   num,
+			OracleRoot:      oracleRoot,
+			OracleTimestamp: timestamp,
+			ExpectedRoot:    output.OutputRoot,
+		})
+	} else {
+		v.l.Info(name+" output verified", "l2_block", output.BlockRef, "output_root", oracleRoot)
+	}
+	v.cfg.Metrics.RecordOutputChecked(num)
+	return true, nil
+}
+
+func (v *Verifier) saveState() error {
+	if v.cfg.StatePath == "" {
+		return nil
+	}
+	return v.state.Save(v.cfg.StatePath)
+}
@@ -0,0 +1,150 @@
+package verifier
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-bindings/bindings"
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+)
+
+type fakeOracle struct {
+	start, interval, latest uint64
+	outputs                 map[uint64]eth.Bytes32
+}
+
+func (f *fakeOracle) STARTINGBLOCKNUMBER(opts *bind.CallOpts) (*big.Int, error) {
+	return new(big.Int).SetUint64(f.start), nil
+}
+
+func (f *fakeOracle) SUBMISSIONINTERVAL(opts *bind.CallOpts) (*big.Int, error) {
+	return new(big.Int).SetUint64(f.interval), nil
+}
+
+func (f *fakeOracle) LatestBlockNumber(opts *bind.CallOpts) (*big.Int, error) {
+	return new(big.Int).SetUint64(f.latest), nil
+}
+
+func (f *fakeOracle) GetL2Output(opts *bind.CallOpts, l2BlockNumber *big.Int) (bindings.TypesOutputProposal, error) {
+	root, ok := f.outputs[l2BlockNumber.Uint64()]
+	if !ok {
+		return bindings.TypesOutputProposal{}, errors.New("no output")
+	}
+	return bindings.TypesOutputProposal{OutputRoot: root, Timestamp: big.NewInt(1000)}, nil
+}
+
+type fakeRollup struct {
+	finalized uint64
+	calls     []uint64
+}
+
+func outputRoot(num uint64) eth.Bytes32 {
+	return eth.Bytes32{0xaa, byte(num)}
+}
+
+func (f *fakeRollup) OutputAtBlock(ctx context.Context, blockNum uint64) (*eth.OutputResponse, error) {
+	f.calls = append(f.calls, blockNum)
+	return &eth.OutputResponse{
+		OutputRoot: outputRoot(blockNum),
+		BlockRef:   eth.L2BlockRef{Number: blockNum},
+		Status:     &eth.SyncStatus{FinalizedL2: eth.L2BlockRef{Number: f.finalized}},
+	}, nil
+}
+
+type testMetrics struct {
+	checked, mismatches []uint64
+}
+
+func (m *testMetrics) RecordOracleLatestBlock(num uint64) {}
+func (m *testMetrics) RecordOutputChecked(num uint64)     { m.checked = append(m.checked, num) }
+func (m *testMetrics) RecordOutputMismatch(num uint64)    { m.mismatches = append(m.mismatches, num) }
+
+func TestVerifier(t *testing.T) {
+	ctx := context.Background()
+	statePath := filepath.Join(t.TempDir(), "verifier.json")
+	oracle := &fakeOracle{start: 100, interval: 10, latest: 140, outputs: map[uint64]eth.Bytes32{
+		110: outputRoot(110),
+		120: outputRoot(120),
+		130: {0xbb}, // invalid output, e.g. by another proposer
+		140: outputRoot(140),
+	}}
+	rollup := &fakeRollup{finalized: 135}
+	m := &testMetrics{}
+	newVerifier := func() *Verifier {
+		v, err := NewVerifier(Config{
+			Log:          testlog.Logger(t, log.LvlInfo),
+			Name:         "test",
+			L2OO:         oracle,
+			RollupClient: rollup,
+			PollInterval: time.Second,
+			StatePath:    statePath,
+			Metrics:      m,
+		})
+		require.NoError(t, err)
+		return v
+	}
+
+	v := newVerifier()
+	require.NoError(t, v.CheckOutputs(ctx))
+	require.Equal(t, []uint64{110, 120, 130}, m.checked, "outputs are checked up to the finalized L2 block")
+	require.Equal(t, []uint64{130}, m.mismatches)
+	require.Equal(t, []BlockRange{{Start: 101, End: 130}}, v.state.Checked)
+	require.Equal(t, []Mismatch{{L2BlockNumber: 130, OracleRoot: eth.Bytes32{0xbb}, OracleTimestamp: 1000, ExpectedRoot: outputRoot(130)}}, v.state.Mismatches)
+
+	// the checked ranges are persisted, and not checked again after a restart
+	rollup.finalized = 140
+	rollup.calls = nil
+	v = newVerifier()
+	require.NoError(t, v.CheckOutputs(ctx))
+	require.Equal(t, []uint64{140}, rollup.calls)
+	require.Equal(t, []BlockRange{{Start: 101, End: 140}}, v.state.Checked)
+	require.Len(t, v.state.Mismatches, 1)
+
+	// deleted outputs are checked again once they are proposed again
+	oracle.latest = 120
+	require.NoError(t, v.CheckOutputs(ctx))
+	require.Equal(t, []BlockRange{{Start: 101, End: 120}}, v.state.Checked)
+	oracle.latest = 130
+	oracle.outputs[130] = outputRoot(130)
+	rollup.calls = nil
+	require.NoError(t, v.CheckOutputs(ctx))
+	require.Equal(t, []uint64{130}, rollup.calls)
+	require.Len(t, v.state.Mismatches, 1, "the corrected output matches")
+}
+
+func TestVerifierStartBlock(t *testing.T) {
+	oracle := &fakeOracle{start: 0, interval: 10, latest: 30, outputs: map[uint64]eth.Bytes32{
+		10: {0xbb}, 20: outputRoot(20), 30: outputRoot(30),
+	}}
+	rollup := &fakeRollup{finalized: 30}
+	v, err := NewVerifier(Config{Log: testlog.Logger(t, log.LvlInfo), L2OO: oracle, RollupClient: rollup, StartBlock: 15})
+	require.NoError(t, err)
+	require.NoError(t, v.CheckOutputs(context.Background()))
+	require.Equal(t, []uint64{20, 30}, rollup.calls)
+	require.Empty(t, v.state.Mismatches)
+}
+
+func TestStateMarkChecked(t *testing.T) {
+	s := &State{}
+	s.MarkChecked(BlockRange{Start: 30, End: 40})
+	s.MarkChecked(BlockRange{Start: 10, End: 10})
+	s.MarkChecked(BlockRange{Start: 11, End: 20})
+	require.Equal(t, []BlockRange{{Start: 10, End: 20}, {Start: 30, End: 40}}, s.Checked)
+	s.MarkChecked(BlockRange{Start: 15, End: 35})
+	require.Equal(t, []BlockRange{{Start: 10, End: 40}}, s.Checked)
+	end, ok := s.IsChecked(25)
+	require.True(t, ok)
+	require.Equal(t, uint64(40), end)
+	require.True(t, s.Truncate(30))
+	require.Equal(t, []BlockRange{{Start: 10, End: 30}}, s.Checked)
+	require.False(t, s.Truncate(30))
+}
@@ -0,0 +1,78 @@
+package txmgr
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/urfave/cli"
+
+	opservice "github.com/ethereum-optimism/optimism/op-service"
+)
+
+const (
+	MaxGasTipCapFlagName  = "txmgr.max-gas-tip-cap"
+	MaxGasFeeCapFlagName  = "txmgr.max-gas-fee-cap"
+	CancelPendingFlagName = "txmgr.cancel-pending-txs"
+)
+
+func CLIFlags(envPrefix string) []cli.Flag {
+	return []cli.Flag{
+		cli.Float64Flag{
+			Name:   MaxGasTipCapFlagName,
+			Usage:  "Maximum miner tip of published transactions, in gwei. Zero is unlimited",
+			EnvVar: opservice.PrefixEnvVar(envPrefix, "TXMGR_MAX_GAS_TIP_CAP"),
+		},
+		cli.Float64Flag{
+			Name:   MaxGasFeeCapFlagName,
+			Usage:  "Maximum fee cap of published transactions, in gwei. Zero is unlimited",
+			EnvVar: opservice.PrefixEnvVar(envPrefix, "TXMGR_MAX_GAS_FEE_CAP"),
+		},
+		cli.BoolFlag{
+			Name:   CancelPendingFlagName,
+			Usage:  "Cancel the pending transactions of the sender account on startup, e.g. transactions from before a restart that are stuck with too low fees",
+			EnvVar: opservice.PrefixEnvVar(envPrefix, "TXMGR_CANCEL_PENDING_TXS"),
+		},
+	}
+}
+
+type CLIConfig struct {
+	MaxGasTipCapGwei float64
+	MaxGasFeeCapGwei float64
+	CancelPendingTxs bool
+}
+
+func (c CLIConfig) Check() error {
+	if c.MaxGasTipCapGwei < 0 || c.MaxGasFeeCapGwei < 0 {
+		return errors.New("fee caps must not be negative")
+	}
+	if c.MaxGasFeeCapGwei != 0 && c.MaxGasTipCapGwei > c.MaxGasFeeCapGwei {
+		return errors.New("max gas tip cap must not exceed the max gas fee cap")
+	}
+	return nil
+}
+
+// FeeLimits returns the configured fee caps, in wei.
+func (c CLIConfig) FeeLimits() FeeLimits {
+	return FeeLimits{
+		MaxGasTipCap: gweiToWei(c.MaxGasTipCapGwei),
+		MaxGasFeeCap: gweiToWei(c.MaxGasFeeCapGwei),
+	}
+}
+
+// gweiToWei converts the amount in gwei to wei, or returns nil if the amount is zero.
+func gweiToWei(gwei float64) *big.Int {
+	if gwei == 0 {
+		return nil
+	}
+	wei, _ := new(big.Float).Mul(big.NewFloat(gwei), big.NewFloat(params.GWei)).Int(nil)
+	return wei
+}
+
+func ReadCLIConfig(ctx *cli.Context) CLIConfig {
+	return CLIConfig{
+		MaxGasTipCapGwei: ctx.GlobalFloat64(MaxGasTipCapFlagName),
+		MaxGasFeeCapGwei: ctx.GlobalFloat64(MaxGasFeeCapFlagName),
+		CancelPendingTxs: ctx.GlobalBool(CancelPendingFlagName),
+	}
+}
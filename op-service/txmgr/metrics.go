@@ -0,0 +1,150 @@
+package txmgr
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const Subsystem = "txmgr"
+
+// Metricer records the activity of a transaction manager.
+type Metricer interface {
+	RecordNonce(nonce uint64)
+	RecordTxPublished()
+	RecordPublishError()
+	RecordGasBumpCount(times int)
+	RecordTxConfirmed(latency time.Duration)
+	RecordTxReverted()
+	RecordTxReorged()
+	RecordFeesCapped()
+	RecordTxCanceled()
+}
+
+type Metrics struct {
+	CurrentNonce          prometheus.Gauge
+	TxPublished           prometheus.Counter
+	PublishErrors         prometheus.Counter
+	GasBumpCount          prometheus.Gauge
+	TxConfirmationLatency prometheus.Histogram
+	TxReverted            prometheus.Counter
+	TxReorged             prometheus.Counter
+	FeesCapped            prometheus.Counter
+	TxCanceled            prometheus.Counter
+}
+
+var _ Metricer = (*Metrics)(nil)
+
+// NewMetrics registers the transaction manager metrics, in the txmgr subsystem of the given namespace.
+func NewMetrics(ns string, r prometheus.Registerer) *Metrics {
+	factory := promauto.With(r)
+	return &Metrics{
+		CurrentNonce: factory.NewGauge(prometheus.GaugeOpts{
+			Namespace: ns,
+			Subsystem: Subsystem,
+			Name:      "current_nonce",
+			Help:      "Nonce of the latest transaction that was crafted",
+		}),
+		TxPublished: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: ns,
+			Subsystem: Subsystem,
+			Name:      "tx_published_total",
+			Help:      "Number of transactions that were published to L1, including fee bumped replacements",
+		}),
+		PublishErrors: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: ns,
+			Subsystem: Subsystem,
+			Name:      "publish_errors_total",
+			Help:      "Number of transactions that L1 failed to accept",
+		}),
+		GasBumpCount: factory.NewGauge(prometheus.GaugeOpts{
+			Namespace: ns,
+			Subsystem: Subsystem,
+			Name:      "gas_bump_count",
+			Help:      "Number of times the fees were bumped for the latest confirmed transaction",
+		}),
+		TxConfirmationLatency: factory.NewHistogram(prometheus.HistogramOpts{
+			Namespace: ns,
+			Subsystem: Subsystem,
+			Name:      "tx_confirmation_latency_seconds",
+			Help:      "Time from the first publication of a transaction until it reached the confirmation depth",
+			Buckets:   prometheus.ExponentialBuckets(4, 2, 10),
+		}),
+		TxReverted: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: ns,
+			Subsystem: Subsystem,
+			Name:      "tx_reverted_total",
+			Help:      "Number of confirmed transactions that reverted",
+		}),
+		TxReorged: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: ns,
+			Subsystem: Subsystem,
+			Name:      "tx_reorged_total",
+			Help:      "Number of times an included transaction was reorged out, or moved to another block",
+		}),
+		FeesCapped: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: ns,
+			Subsystem: Subsystem,
+			Name:      "fees_capped_total",
+			Help:      "Number of times the fees of a transaction were limited by the configured fee caps",
+		}),
+		TxCanceled: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: ns,
+			Subsystem: Subsystem,
+			Name:      "tx_canceled_total",
+			Help:      "Number of pending transactions that were replaced by a cancellation transaction",
+		}),
+	}
+}
+
+func (m *Metrics) RecordNonce(nonce uint64) {
+	m.CurrentNonce.Set(float64(nonce))
+}
+
+func (m *Metrics) RecordTxPublished() {
+	m.TxPublished.Inc()
+}
+
+func (m *Metrics) RecordPublishError() {
+	m.PublishErrors.Inc()
+}
+
+func (m *Metrics) RecordGasBumpCount(times int) {
+	m.GasBumpCount.Set(float64(times))
+}
+
+func (m *Metrics) RecordTxConfirmed(latency time.Duration) {
+	m.TxConfirmationLatency.Observe(latency.Seconds())
+}
+
+func (m *Metrics) RecordTxReverted() {
+	m.TxReverted.Inc()
+}
+
+func (m *Metrics) RecordTxReorged() {
+	m.TxReorged.Inc()
+}
+
+func (m *Metrics) RecordFeesCapped() {
+	m.FeesCapped.Inc()
+}
+
+func (m *Metrics) RecordTxCanceled() {
+	m.TxCanceled.Inc()
+}
+
+type noopMetrics struct{}
+
+// NoopMetrics discards all metrics, it is used when no Metricer is configured.
+var NoopMetrics Metricer = noopMetrics{}
+
+func (noopMetrics) RecordNonce(nonce uint64)                {}
+func (noopMetrics) RecordTxPublished()                      {}
+func (noopMetrics) RecordPublishError()                     {}
+func (noopMetrics) RecordGasBumpCount(times int)            {}
+func (noopMetrics) RecordTxConfirmed(latency time.Duration) {}
+func (noopMetrics) RecordTxReverted()                       {}
+func (noopMetrics) RecordTxReorged()                        {}
+func (noopMetrics) RecordFeesCapped()                       {}
+func (noopMetrics) RecordTxCanceled()                       {}
@@ -0,0 +1,44 @@
+package txmgr
+
+import (
+	"context"
+	"sync"
+)
+
+// NonceManager hands out sequential nonces to concurrently submitted transactions.
+// When a transaction fails, the nonce manager is reset rather than handing out its nonce again:
+// the failed transaction may still be in the tx pool and be mined, so its nonce cannot be reused
+// for other data. The next nonce is then fetched from the tx pool of the L1 node, which includes
+// the failed transaction if it is still pending, or fills the nonce gap if it is not.
+// The zero value is ready to use.
+type NonceManager struct {
+	mu sync.Mutex
+	// next is the next nonce to hand out. It is nil if it has to be fetched from L1.
+	next *uint64
+}
+
+// Acquire returns the nonce to use for the next transaction.
+// On startup, or after a reset, the nonce is fetched with the given function.
+func (n *NonceManager) Acquire(ctx context.Context, fetch func(ctx context.Context) (uint64, error)) (uint64, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.next == nil {
+		nonce, err := fetch(ctx)
+		if err != nil {
+			return 0, err
+		}
+		n.next = &nonce
+	}
+	nonce := *n.next
+	*n.next += 1
+	return nonce, nil
+}
+
+// Reset forgets the next nonce, it is fetched from L1 again.
+// This is used when the nonces may be out of sync with L1, e.g. when a transaction failed,
+// or when a nonce was used by another transaction.
+func (n *NonceManager) Reset() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.next = nil
+}
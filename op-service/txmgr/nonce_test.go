@@ -0,0 +1,38 @@
+package txmgr_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-service/txmgr"
+)
+
+func TestNonceManager(t *testing.T) {
+	ctx := context.Background()
+	fetched := uint64(10)
+	fetches := 0
+	fetch := func(ctx context.Context) (uint64, error) {
+		fetches++
+		return fetched, nil
+	}
+	acquire := func(n *txmgr.NonceManager) uint64 {
+		nonce, err := n.Acquire(ctx, fetch)
+		require.NoError(t, err)
+		return nonce
+	}
+
+	var n txmgr.NonceManager
+	require.Equal(t, uint64(10), acquire(&n))
+	require.Equal(t, uint64(11), acquire(&n))
+	require.Equal(t, uint64(12), acquire(&n))
+	require.Equal(t, 1, fetches, "nonces are pipelined without fetching them again")
+
+	// after a reset the nonce is fetched again
+	fetched = 20
+	n.Reset()
+	require.Equal(t, uint64(20), acquire(&n))
+	require.Equal(t, uint64(21), acquire(&n))
+	require.Equal(t, 2, fetches)
+}
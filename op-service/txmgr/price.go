@@ -0,0 +1,109 @@
+package txmgr
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// FeeBumpPercent is the minimum percentage by which the fees of a resubmitted tx are increased,
+// to replace the previous tx with the same nonce in the tx pool of the L1 nodes.
+const FeeBumpPercent = 10
+
+// GasPriceSource provides the L1 fee market conditions used to price transactions.
+//
+// NOTE: This is a subset of bind.ContractTransactor.
+type GasPriceSource interface {
+	// SuggestGasTipCap returns the miner tip for timely inclusion of a transaction.
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+
+	// HeaderByNumber returns the header of the given block number, or of
+	// the latest block if number is nil.
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// FeeLimits caps the fees of published transactions, to bound the costs during
+// L1 fee spikes. A nil cap is unlimited.
+type FeeLimits struct {
+	// MaxGasTipCap is the maximum miner tip, in wei.
+	MaxGasTipCap *big.Int
+
+	// MaxGasFeeCap is the maximum fee cap, in wei.
+	MaxGasFeeCap *big.Int
+}
+
+// Apply limits the tip and fee cap to the configured caps. It returns whether
+// either of them was capped.
+func (l FeeLimits) Apply(gasTipCap, gasFeeCap *big.Int) (*big.Int, *big.Int, bool) {
+	capped := false
+	if l.MaxGasTipCap != nil && gasTipCap.Cmp(l.MaxGasTipCap) > 0 {
+		gasTipCap = new(big.Int).Set(l.MaxGasTipCap)
+		capped = true
+	}
+	if l.MaxGasFeeCap != nil && gasFeeCap.Cmp(l.MaxGasFeeCap) > 0 {
+		gasFeeCap = new(big.Int).Set(l.MaxGasFeeCap)
+		capped = true
+	}
+	// The tip can never exceed the fee cap.
+	if gasTipCap.Cmp(gasFeeCap) > 0 {
+		gasTipCap = new(big.Int).Set(gasFeeCap)
+	}
+	return gasTipCap, gasFeeCap, capped
+}
+
+// Exceeded returns whether the fees of the transaction exceed the configured caps.
+func (l FeeLimits) Exceeded(tx *types.Transaction) bool {
+	return (l.MaxGasTipCap != nil && tx.GasTipCap().Cmp(l.MaxGasTipCap) > 0) ||
+		(l.MaxGasFeeCap != nil && tx.GasFeeCap().Cmp(l.MaxGasFeeCap) > 0)
+}
+
+// SuggestGasPriceCaps queries L1 for a tip and fee cap that are suitable for
+// timely inclusion of a transaction. The fee cap is computed by CalcGasFeeCap.
+// The caps are not limited by any FeeLimits.
+func SuggestGasPriceCaps(ctx context.Context, backend GasPriceSource) (*big.Int, *big.Int, error) {
+	gasTipCap, err := backend.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get suggested gas tip cap: %w", err)
+	}
+	head, err := backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get L1 head block for fee cap: %w", err)
+	}
+	if head.BaseFee == nil {
+		return nil, nil, fmt.Errorf("L1 head block %s has no base fee", head.Hash())
+	}
+	return gasTipCap, CalcGasFeeCap(head.BaseFee, gasTipCap), nil
+}
+
+// BumpGasPriceCaps returns the fees for a replacement of tx, given the currently
+// suggested tip and fee cap. The fees are bumped by at least FeeBumpPercent over
+// the fees of tx, such that the replacement is accepted by the tx pool.
+func BumpGasPriceCaps(tx *types.Transaction, gasTipCap, gasFeeCap *big.Int) (*big.Int, *big.Int) {
+	if bumped := bumpFee(tx.GasTipCap()); gasTipCap.Cmp(bumped) < 0 {
+		gasTipCap = bumped
+	}
+	if bumped := bumpFee(tx.GasFeeCap()); gasFeeCap.Cmp(bumped) < 0 {
+		gasFeeCap = bumped
+	}
+	return gasTipCap, gasFeeCap
+}
+
+// bumpFee increases the fee by FeeBumpPercent, rounded up.
+func bumpFee(fee *big.Int) *big.Int {
+	bumped := new(big.Int).Mul(fee, big.NewInt(100+FeeBumpPercent))
+	bumped.Add(bumped, big.NewInt(99))
+	return bumped.Div(bumped, big.NewInt(100))
+}
+
+// CalcGasFeeCap deterministically computes the recommended gas fee cap given
+// the base fee and gasTipCap. The resulting gasFeeCap is equal to:
+//
+//	gasTipCap + 2*baseFee.
+func CalcGasFeeCap(baseFee, gasTipCap *big.Int) *big.Int {
+	return new(big.Int).Add(
+		gasTipCap,
+		new(big.Int).Mul(baseFee, big.NewInt(2)),
+	)
+}
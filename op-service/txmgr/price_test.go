@@ -0,0 +1,42 @@
+package txmgr_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-service/txmgr"
+)
+
+func TestBumpGasPriceCaps(t *testing.T) {
+	tx := types.NewTx(&types.DynamicFeeTx{GasTipCap: big.NewInt(100), GasFeeCap: big.NewInt(1001)})
+
+	// lower suggested fees are bumped by 10% over the previous fees, rounded up
+	tip, fee := txmgr.BumpGasPriceCaps(tx, big.NewInt(50), big.NewInt(500))
+	require.Equal(t, big.NewInt(110), tip)
+	require.Equal(t, big.NewInt(1102), fee)
+
+	// higher suggested fees are used as is
+	tip, fee = txmgr.BumpGasPriceCaps(tx, big.NewInt(200), big.NewInt(2000))
+	require.Equal(t, big.NewInt(200), tip)
+	require.Equal(t, big.NewInt(2000), fee)
+}
+
+func TestFeeLimits(t *testing.T) {
+	unlimited := txmgr.FeeLimits{}
+	tip, fee, capped := unlimited.Apply(big.NewInt(100), big.NewInt(1000))
+	require.False(t, capped)
+	require.Equal(t, big.NewInt(100), tip)
+	require.Equal(t, big.NewInt(1000), fee)
+
+	limits := txmgr.FeeLimits{MaxGasTipCap: big.NewInt(50), MaxGasFeeCap: big.NewInt(40)}
+	tip, fee, capped = limits.Apply(big.NewInt(100), big.NewInt(1000))
+	require.True(t, capped)
+	require.Equal(t, big.NewInt(40), tip, "the tip is limited to the fee cap")
+	require.Equal(t, big.NewInt(40), fee)
+
+	require.False(t, limits.Exceeded(types.NewTx(&types.DynamicFeeTx{GasTipCap: big.NewInt(40), GasFeeCap: big.NewInt(40)})))
+	require.True(t, limits.Exceeded(types.NewTx(&types.DynamicFeeTx{GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(41)})))
+}
@@ -0,0 +1,461 @@
+package txmgr
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// networkTimeout is how long a single network request of the Sender can take.
+const networkTimeout = 10 * time.Second
+
+// ErrNonceUsed signals that the nonce of a transaction was used by another
+// transaction, e.g. by a transaction from before a restart that was mined after all.
+var ErrNonceUsed = errors.New("nonce was used by another transaction")
+
+// ErrFeeBumpCapped signals that the fees of a transaction cannot be bumped
+// enough to replace the published transaction, within the configured caps.
+var ErrFeeBumpCapped = errors.New("fee bump exceeds the configured caps")
+
+// L1Client is the subset of the L1 RPC that is used by the Sender.
+//
+// NOTE: This is a subset of *ethclient.Client.
+type L1Client interface {
+	ReceiptSource
+	GasPriceSource
+
+	// NonceAt returns the nonce of the account at the given block number,
+	// or at the latest block if blockNumber is nil.
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+
+	// PendingNonceAt returns the nonce of the account, including the
+	// transactions in the tx pool.
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+
+	// EstimateGas estimates the gas limit of the call.
+	EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error)
+
+	// SendTransaction injects a signed transaction into the tx pool.
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+}
+
+// SignerFn signs a transaction with the key of the sender account.
+type SignerFn func(rawTx types.TxData) (*types.Transaction, error)
+
+// PrivateKeySignerFn returns a SignerFn that signs with the given private key.
+func PrivateKeySignerFn(key *ecdsa.PrivateKey, chainID *big.Int) SignerFn {
+	signer := types.LatestSignerForChainID(chainID)
+	return func(rawTx types.TxData) (*types.Transaction, error) {
+		return types.SignNewTx(key, signer, rawTx)
+	}
+}
+
+// TxCandidate is a transaction to be crafted, signed and published by the Sender.
+type TxCandidate struct {
+	// To is the recipient of the transaction, nil for a contract creation.
+	To *common.Address
+
+	// TxData is the calldata of the transaction.
+	TxData []byte
+
+	// GasLimit is the gas limit of the transaction. It is estimated if zero.
+	GasLimit uint64
+
+	// Value is the amount of wei sent along with the transaction, may be nil.
+	Value *big.Int
+}
+
+// Sender crafts, signs and publishes transactions of a single account, on top
+// of a SimpleTxManager. It manages the nonces of the account, prices the
+// transactions with EIP-1559 fees within the configured caps, and replaces the
+// published transactions with fee bumped ones until they confirm.
+type Sender struct {
+	name    string
+	cfg     Config
+	backend L1Client
+	txMgr   *SimpleTxManager
+	chainID *big.Int
+	from    common.Address
+	signer  SignerFn
+	nonces  NonceManager
+	l       log.Logger
+	metrics Metricer
+}
+
+// NewSender initializes a new Sender of transactions from the given account.
+func NewSender(name string, cfg Config, backend L1Client, chainID *big.Int, from common.Address, signer SignerFn) *Sender {
+	txMgr := NewSimpleTxManager(name, cfg, backend)
+	return &Sender{
+		name:    name,
+		cfg:     cfg,
+		backend: backend,
+		txMgr:   txMgr,
+		chainID: chainID,
+		from:    from,
+		signer:  signer,
+		l:       txMgr.l,
+		metrics: txMgr.metrics,
+	}
+}
+
+// From returns the address of the sender account.
+func (s *Sender) From() common.Address {
+	return s.from
+}
+
+// Send crafts the candidate into a transaction with the next nonce of the
+// sender account, and publishes it with incrementally higher fees until it
+// confirms. This is a blocking method, which may be canceled using the passed
+// context.
+//
+// Send may be called concurrently: every transaction gets the next nonce. On
+// startup, and after a transaction failed, the nonce is fetched from the tx
+// pool of the L1 node: transactions that are still pending, e.g. from before
+// a restart or a transaction that timed out, keep their nonces, so they cannot
+// be mined in addition to a different transaction with the same nonce.
+func (s *Sender) Send(ctx context.Context, candidate TxCandidate) (*types.Receipt, error) {
+	nonce, err := s.acquireNonce(ctx)
+	if err != nil {
+		return nil, err
+	}
+	receipt, _, err := s.sendWithNonce(ctx, candidate, nonce)
+	return receipt, err
+}
+
+// SendPipelined publishes the candidates back to back, with consecutive nonces
+// in the order of the candidates, without waiting for the confirmation of the
+// previous candidate. This is used when a candidate depends on the inclusion of
+// the previous candidates. It blocks until all candidates confirmed, and returns
+// the receipts in the order of the candidates.
+//
+// If a candidate fails to confirm, the later candidates are canceled, as they
+// depend on it, and the error of the first failed candidate is returned
+// together with the receipts of the confirmed candidates before it. The
+// published transactions of the later candidates are replaced by empty
+// transfers to the sender account itself, so they are not mined after the
+// failed candidate. The replacements are not awaited: they confirm once the
+// nonce of the failed candidate is used.
+func (s *Sender) SendPipelined(ctx context.Context, candidates []TxCandidate) ([]*types.Receipt, error) {
+	pCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	receipts := make([]*types.Receipt, len(candidates))
+	published := make([]*types.Transaction, len(candidates))
+	errs := make([]error, len(candidates))
+	var wg sync.WaitGroup
+	for i, candidate := range candidates {
+		// Nonces are acquired in order, before the candidates are published concurrently.
+		nonce, err := s.acquireNonce(pCtx)
+		if err != nil {
+			errs[i] = err
+			cancel()
+			break
+		}
+		wg.Add(1)
+		go func(i int, candidate TxCandidate, nonce uint64) {
+			defer wg.Done()
+			receipts[i], published[i], errs[i] = s.sendWithNonce(pCtx, candidate, nonce)
+			if errs[i] != nil && !errors.Is(errs[i], ErrReverted) {
+				cancel()
+			}
+		}(i, candidate, nonce)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil && !errors.Is(err, ErrReverted) {
+			s.replacePublished(published[i+1:], receipts[i+1:])
+			break
+		}
+	}
+	for i, err := range errs {
+		if err != nil {
+			return receipts[:i], fmt.Errorf("failed to send candidate %d of %d: %w", i+1, len(candidates), err)
+		}
+	}
+	return receipts, nil
+}
+
+// acquireNonce returns the nonce of the next transaction of the sender account.
+func (s *Sender) acquireNonce(ctx context.Context) (uint64, error) {
+	nonce, err := s.nonces.Acquire(ctx, s.fetchNonce)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get nonce: %w", err)
+	}
+	s.metrics.RecordNonce(nonce)
+	return nonce, nil
+}
+
+// sendWithNonce sends the candidate with the acquired nonce. If the transaction
+// failed, the nonce manager is reset, to fetch the next nonce from the tx pool.
+// The last published transaction of the candidate is returned, if any.
+func (s *Sender) sendWithNonce(ctx context.Context, candidate TxCandidate, nonce uint64) (*types.Receipt, *types.Transaction, error) {
+	receipt, published, err := s.send(ctx, candidate, nonce)
+	if err != nil && !errors.Is(err, ErrReverted) {
+		s.nonces.Reset()
+	}
+	return receipt, published, err
+}
+
+// replacePublished replaces the published transactions that did not confirm
+// by empty transfers to the sender account itself, without waiting for their
+// confirmation. The nonce manager is reset afterwards, to fetch the next nonce
+// after the replacements from the tx pool.
+func (s *Sender) replacePublished(published []*types.Transaction, receipts []*types.Receipt) {
+	defer s.nonces.Reset()
+	for i, tx := range published {
+		if tx == nil || receipts[i] != nil {
+			continue
+		}
+		if err := s.publishCancel(tx); err != nil {
+			s.l.Error(s.name+" unable to cancel pipelined transaction", "nonce", tx.Nonce(), "err", err)
+		}
+	}
+}
+
+// publishCancel publishes an empty transfer to the sender account itself, with
+// the nonce of the given published transaction and fees bumped over its fees.
+// It does not wait for the confirmation of the transfer.
+func (s *Sender) publishCancel(tx *types.Transaction) error {
+	// The context of the canceled transactions may be done already.
+	ctx, cancel := context.WithTimeout(context.Background(), networkTimeout)
+	defer cancel()
+	gasTipCap, gasFeeCap, err := s.bumpedFees(ctx, tx)
+	if err != nil {
+		return err
+	}
+	replacement, err := s.signer(&types.DynamicFeeTx{
+		ChainID:   s.chainID,
+		Nonce:     tx.Nonce(),
+		To:        &s.from,
+		GasTipCap: gasTipCap,
+		GasFeeCap: gasFeeCap,
+		Gas:       params.TxGas,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to sign replacement: %w", err)
+	}
+	if err := s.backend.SendTransaction(ctx, replacement); err != nil {
+		return fmt.Errorf("failed to publish replacement: %w", err)
+	}
+	s.l.Info(s.name+" canceled pipelined transaction", "nonce", tx.Nonce(), "tx_hash", tx.Hash(), "replacement", replacement.Hash())
+	s.metrics.RecordTxCanceled()
+	return nil
+}
+
+// Cancel replaces the pending transaction with the given nonce by an empty
+// transfer to the sender account itself, with incrementally higher fees until
+// it confirms. ErrNonceUsed is returned if the nonce was used by the pending
+// transaction.
+//
+// NOTE: Cancel does not coordinate with concurrent calls to Send, nonces handed
+// out by Send should be canceled only after Send returned.
+func (s *Sender) Cancel(ctx context.Context, nonce uint64) (*types.Receipt, error) {
+	s.l.Info(s.name+" canceling transaction", "nonce", nonce)
+	receipt, _, err := s.send(ctx, TxCandidate{To: &s.from, GasLimit: params.TxGas}, nonce)
+	if err != nil {
+		return receipt, err
+	}
+	s.metrics.RecordTxCanceled()
+	return receipt, nil
+}
+
+// CancelPending cancels all transactions of the sender account that are
+// pending in the tx pool, e.g. transactions left over from before a restart
+// that are stuck with too low fees. Transactions that were mined in the
+// meantime are not canceled.
+func (s *Sender) CancelPending(ctx context.Context) error {
+	cCtx, cancel := context.WithTimeout(ctx, networkTimeout)
+	latest, err := s.backend.NonceAt(cCtx, s.from, nil)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("failed to get nonce: %w", err)
+	}
+	pending, err := s.fetchNonce(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get pending nonce: %w", err)
+	}
+	defer s.nonces.Reset()
+	for nonce := latest; nonce < pending; nonce++ {
+		if _, err := s.Cancel(ctx, nonce); err != nil && !errors.Is(err, ErrNonceUsed) {
+			return fmt.Errorf("failed to cancel transaction with nonce %d: %w", nonce, err)
+		}
+	}
+	return nil
+}
+
+// send crafts the candidate into a transaction with the given nonce, and publishes it until it confirms.
+// The last transaction that was accepted by the tx pool is returned as well, if any.
+func (s *Sender) send(ctx context.Context, candidate TxCandidate, nonce uint64) (*types.Receipt, *types.Transaction, error) {
+	tx, err := s.craftTx(ctx, candidate, nonce)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create tx: %w", err)
+	}
+
+	// Construct a closure that will update the txn with the current gas prices.
+	// Every resubmission bumps the fees of the previously published tx, to replace it in the tx pool.
+	var mu sync.Mutex
+	first := true
+	updateGasPrice := func(ctx context.Context) (*types.Transaction, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if first {
+			first = false
+			return tx, nil
+		}
+		bumped, err := s.bumpTx(ctx, tx)
+		if err != nil {
+			return nil, err
+		}
+		tx = bumped
+		return tx, nil
+	}
+	var nonceTooLow bool
+	var published *types.Transaction
+	sendTx := func(ctx context.Context, tx *types.Transaction) error {
+		err := s.backend.SendTransaction(ctx, tx)
+		mu.Lock()
+		defer mu.Unlock()
+		if err == nil {
+			published = tx
+		} else if strings.Contains(err.Error(), core.ErrNonceTooLow.Error()) {
+			nonceTooLow = true
+		}
+		return err
+	}
+
+	receipt, err := s.txMgr.Send(ctx, updateGasPrice, sendTx)
+	mu.Lock()
+	defer mu.Unlock()
+	if err != nil {
+		s.l.Warn(s.name+" unable to publish tx", "nonce", nonce, "err", err)
+		if receipt == nil && nonceTooLow {
+			return nil, published, fmt.Errorf("%w: %v", ErrNonceUsed, err)
+		}
+		return receipt, published, err
+	}
+	s.l.Info(s.name+" tx successfully published", "tx_hash", receipt.TxHash, "nonce", nonce, "block", receipt.BlockNumber)
+	return receipt, published, nil
+}
+
+// fetchNonce fetches the nonce of the sender account, including the transactions in the tx pool.
+func (s *Sender) fetchNonce(ctx context.Context) (uint64, error) {
+	ctx, cancel := context.WithTimeout(ctx, networkTimeout)
+	defer cancel()
+	return s.backend.PendingNonceAt(ctx, s.from)
+}
+
+// gasPriceCaps queries L1 for the current fee market conditions, and limits the fees to the configured caps.
+func (s *Sender) gasPriceCaps(ctx context.Context) (*big.Int, *big.Int, error) {
+	ctx, cancel := context.WithTimeout(ctx, networkTimeout)
+	defer cancel()
+	return SuggestGasPriceCaps(ctx, s.backend)
+}
+
+// applyFeeLimits limits the fees to the configured caps.
+func (s *Sender) applyFeeLimits(gasTipCap, gasFeeCap *big.Int) (*big.Int, *big.Int) {
+	gasTipCap, gasFeeCap, capped := s.cfg.FeeLimits.Apply(gasTipCap, gasFeeCap)
+	if capped {
+		s.l.Warn(s.name+" fees are limited by the configured caps", "gasTipCap", gasTipCap, "gasFeeCap", gasFeeCap)
+		s.metrics.RecordFeesCapped()
+	}
+	return gasTipCap, gasFeeCap
+}
+
+// craftTx creates the signed transaction of the candidate, with the given nonce.
+// It queries L1 for the current fee market conditions.
+// NOTE: This method SHOULD NOT publish the resulting transaction.
+func (s *Sender) craftTx(ctx context.Context, candidate TxCandidate, nonce uint64) (*types.Transaction, error) {
+	gasTipCap, gasFeeCap, err := s.gasPriceCaps(ctx)
+	if err != nil {
+		return nil, err
+	}
+	gasTipCap, gasFeeCap = s.applyFeeLimits(gasTipCap, gasFeeCap)
+
+	rawTx := &types.DynamicFeeTx{
+		ChainID:   s.chainID,
+		Nonce:     nonce,
+		To:        candidate.To,
+		GasTipCap: gasTipCap,
+		GasFeeCap: gasFeeCap,
+		Gas:       candidate.GasLimit,
+		Value:     candidate.Value,
+		Data:      candidate.TxData,
+	}
+	if rawTx.Gas == 0 {
+		cCtx, cancel := context.WithTimeout(ctx, networkTimeout)
+		gas, err := s.backend.EstimateGas(cCtx, ethereum.CallMsg{
+			From:      s.from,
+			To:        candidate.To,
+			GasTipCap: gasTipCap,
+			GasFeeCap: gasFeeCap,
+			Value:     candidate.Value,
+			Data:      candidate.TxData,
+		})
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("failed to estimate gas: %w", err)
+		}
+		rawTx.Gas = gas
+	}
+	s.l.Info(s.name+" creating tx", "to", rawTx.To, "from", s.from, "nonce", nonce)
+
+	return s.signer(rawTx)
+}
+
+// bumpTx signs an otherwise identical txn to the one provided but with
+// updated gas prices sampled from the existing network conditions.
+// The fees are bumped by at least FeeBumpPercent over the fees of the provided txn,
+// such that the new txn replaces the provided txn if it is stuck in the tx pool.
+// ErrFeeBumpCapped is returned if the configured caps do not allow such a bump,
+// as the tx pool would reject the new txn.
+//
+// NOTE: This method SHOULD NOT publish the resulting transaction.
+func (s *Sender) bumpTx(ctx context.Context, tx *types.Transaction) (*types.Transaction, error) {
+	gasTipCap, gasFeeCap, err := s.bumpedFees(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	rawTx := &types.DynamicFeeTx{
+		ChainID:   s.chainID,
+		Nonce:     tx.Nonce(),
+		To:        tx.To(),
+		GasTipCap: gasTipCap,
+		GasFeeCap: gasFeeCap,
+		Gas:       tx.Gas(),
+		Value:     tx.Value(),
+		Data:      tx.Data(),
+	}
+	s.l.Trace(s.name+" updating gas price", "nonce", tx.Nonce(), "tip_cap", gasTipCap, "fee_cap", gasFeeCap)
+
+	return s.signer(rawTx)
+}
+
+// bumpedFees returns the fees of a replacement of the provided txn, sampled from
+// the existing network conditions and bumped by at least FeeBumpPercent over the
+// fees of the provided txn. ErrFeeBumpCapped is returned if the configured caps
+// do not allow such a bump.
+func (s *Sender) bumpedFees(ctx context.Context, tx *types.Transaction) (*big.Int, *big.Int, error) {
+	gasTipCap, gasFeeCap, err := s.gasPriceCaps(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	gasTipCap, gasFeeCap = s.applyFeeLimits(BumpGasPriceCaps(tx, gasTipCap, gasFeeCap))
+	if gasTipCap.Cmp(bumpFee(tx.GasTipCap())) < 0 || gasFeeCap.Cmp(bumpFee(tx.GasFeeCap())) < 0 {
+		return nil, nil, fmt.Errorf("%w: gasTipCap %v, gasFeeCap %v", ErrFeeBumpCapped, gasTipCap, gasFeeCap)
+	}
+	return gasTipCap, gasFeeCap, nil
+}
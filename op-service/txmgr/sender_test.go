@@ -0,0 +1,499 @@
+package txmgr_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-service/txmgr"
+)
+
+// mockL1 is a minimal L1 chain with a tx pool of the sender account. Published
+// transactions are mined in nonce order, if they are accepted by the mine function.
+type mockL1 struct {
+	mu sync.Mutex
+
+	gasTipCap *big.Int
+	baseFee   *big.Int
+	nonce     uint64 // nonce of the sender account as of the latest block
+	height    uint64
+
+	// mine decides whether a published tx is mined. Every tx is mined if nil.
+	mine func(tx *types.Transaction) bool
+	// sendErr is returned when publishing a tx, if not nil.
+	sendErr error
+	// sendErrFn is called when publishing a tx, and the tx is rejected if it returns an error.
+	sendErrFn func(tx *types.Transaction) error
+
+	sent     []*types.Transaction
+	pool     map[uint64]*types.Transaction
+	receipts map[common.Hash]*types.Receipt
+}
+
+func newMockL1() *mockL1 {
+	return &mockL1{
+		gasTipCap: big.NewInt(10),
+		baseFee:   big.NewInt(100),
+		pool:      make(map[uint64]*types.Transaction),
+		receipts:  make(map[common.Hash]*types.Receipt),
+	}
+}
+
+func (b *mockL1) BlockNumber(ctx context.Context) (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.height, nil
+}
+
+func (b *mockL1) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.receipts[txHash], nil
+}
+
+func (b *mockL1) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.gasTipCap, nil
+}
+
+func (b *mockL1) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return &types.Header{Number: new(big.Int).SetUint64(b.height), BaseFee: b.baseFee}, nil
+}
+
+func (b *mockL1) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.nonce, nil
+}
+
+// PendingNonceAt returns the nonce after the executable transactions in the pool.
+func (b *mockL1) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	nonce := b.nonce
+	for b.pool[nonce] != nil {
+		nonce++
+	}
+	return nonce, nil
+}
+
+func (b *mockL1) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	return params.TxGas + uint64(len(msg.Data))*params.TxDataNonZeroGasEIP2028, nil
+}
+
+func (b *mockL1) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.sendErr != nil {
+		return b.sendErr
+	}
+	if b.sendErrFn != nil {
+		if err := b.sendErrFn(tx); err != nil {
+			return err
+		}
+	}
+	if tx.Nonce() < b.nonce {
+		return core.ErrNonceTooLow
+	}
+	if prev := b.pool[tx.Nonce()]; prev != nil &&
+		(tx.GasTipCap().Cmp(bumpedBy10(prev.GasTipCap())) < 0 || tx.GasFeeCap().Cmp(bumpedBy10(prev.GasFeeCap())) < 0) {
+		return errReplaceUnderpriced
+	}
+	b.sent = append(b.sent, tx)
+	b.pool[tx.Nonce()] = tx
+	b.mineExecutable()
+	return nil
+}
+
+var errReplaceUnderpriced = errors.New("replacement transaction underpriced")
+
+func bumpedBy10(fee *big.Int) *big.Int {
+	return new(big.Int).Div(new(big.Int).Mul(fee, big.NewInt(110)), big.NewInt(100))
+}
+
+// mineExecutable mines the transactions of the pool in nonce order, until a
+// transaction is missing or not accepted by the mine function.
+func (b *mockL1) mineExecutable() {
+	for {
+		tx := b.pool[b.nonce]
+		if tx == nil || (b.mine != nil && !b.mine(tx)) {
+			return
+		}
+		delete(b.pool, b.nonce)
+		b.nonce++
+		b.height++
+		b.receipts[tx.Hash()] = &types.Receipt{
+			TxHash:      tx.Hash(),
+			BlockNumber: new(big.Int).SetUint64(b.height),
+			Status:      types.ReceiptStatusSuccessful,
+		}
+	}
+}
+
+// addStuckTxs adds txs with the given nonces to the pool, with fees too low to be mined.
+func (b *mockL1) addStuckTxs(nonces ...uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, nonce := range nonces {
+		b.pool[nonce] = types.NewTx(&types.DynamicFeeTx{Nonce: nonce, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(1)})
+	}
+}
+
+// notStuck mines txs that are not added by addStuckTxs.
+func notStuck(tx *types.Transaction) bool {
+	return tx.GasFeeCap().Cmp(big.NewInt(1)) > 0
+}
+
+// setMine replaces the mine function, and mines the pool with it.
+func (b *mockL1) setMine(mine func(tx *types.Transaction) bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.mine = mine
+	b.mineExecutable()
+}
+
+func (b *mockL1) sentTxs() []*types.Transaction {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]*types.Transaction(nil), b.sent...)
+}
+
+func newTestSender(t *testing.T, l1 *mockL1, cfg txmgr.Config) *txmgr.Sender {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	chainID := big.NewInt(900)
+	return txmgr.NewSender("TEST", cfg, l1, chainID, crypto.PubkeyToAddress(key.PublicKey), txmgr.PrivateKeySignerFn(key, chainID))
+}
+
+func senderConfig() txmgr.Config {
+	return txmgr.Config{
+		ResubmissionTimeout:       50 * time.Millisecond,
+		ReceiptQueryInterval:      10 * time.Millisecond,
+		NumConfirmations:          1,
+		SafeAbortNonceTooLowCount: 3,
+	}
+}
+
+// TestSenderSend asserts that concurrently sent candidates get sequential
+// nonces, and are priced and estimated by the Sender.
+func TestSenderSend(t *testing.T) {
+	l1 := newMockL1()
+	l1.nonce = 5
+	sender := newTestSender(t, l1, senderConfig())
+	to := common.Address{0x42}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			receipt, err := sender.Send(context.Background(), txmgr.TxCandidate{To: &to, TxData: []byte{1, 2, 3}})
+			require.NoError(t, err)
+			require.NotNil(t, receipt)
+		}()
+	}
+	wg.Wait()
+
+	sent := l1.sentTxs()
+	require.Len(t, sent, 3)
+	nonces := make(map[uint64]bool)
+	for _, tx := range sent {
+		nonces[tx.Nonce()] = true
+		require.Equal(t, &to, tx.To())
+		require.Equal(t, big.NewInt(10), tx.GasTipCap())
+		require.Equal(t, big.NewInt(210), tx.GasFeeCap())
+		require.Equal(t, params.TxGas+3*params.TxDataNonZeroGasEIP2028, tx.Gas())
+	}
+	require.Equal(t, map[uint64]bool{5: true, 6: true, 7: true}, nonces)
+}
+
+// TestSenderBumpsFees asserts that a tx that is not mined is replaced with a
+// tx with the same nonce, and fees bumped by at least FeeBumpPercent.
+func TestSenderBumpsFees(t *testing.T) {
+	l1 := newMockL1()
+	l1.mine = func(tx *types.Transaction) bool {
+		return tx.GasFeeCap().Cmp(big.NewInt(250)) >= 0
+	}
+	sender := newTestSender(t, l1, senderConfig())
+
+	receipt, err := sender.Send(context.Background(), txmgr.TxCandidate{To: &common.Address{}, GasLimit: params.TxGas})
+	require.NoError(t, err)
+
+	sent := l1.sentTxs()
+	require.Len(t, sent, 3)
+	require.Equal(t, sent[2].Hash(), receipt.TxHash)
+	for i, tx := range sent {
+		require.Equal(t, uint64(0), tx.Nonce())
+		require.Equal(t, params.TxGas, tx.Gas())
+		if i > 0 {
+			prev := sent[i-1]
+			require.GreaterOrEqual(t, tx.GasTipCap().Cmp(new(big.Int).Div(new(big.Int).Mul(prev.GasTipCap(), big.NewInt(110)), big.NewInt(100))), 0)
+			require.GreaterOrEqual(t, tx.GasFeeCap().Cmp(new(big.Int).Div(new(big.Int).Mul(prev.GasFeeCap(), big.NewInt(110)), big.NewInt(100))), 0)
+		}
+	}
+}
+
+// TestSenderFeeLimits asserts that the fees of crafted and bumped txs are
+// limited to the configured caps, and that no replacement is published if the
+// caps do not allow a sufficient fee bump.
+func TestSenderFeeLimits(t *testing.T) {
+	l1 := newMockL1()
+	l1.mine = func(tx *types.Transaction) bool { return false }
+	published := 0
+	l1.sendErrFn = func(tx *types.Transaction) error {
+		published++
+		return nil
+	}
+	cfg := senderConfig()
+	metrics := newTestMetrics()
+	cfg.Metrics = metrics
+	cfg.FeeLimits = txmgr.FeeLimits{MaxGasTipCap: big.NewInt(5), MaxGasFeeCap: big.NewInt(220)}
+	sender := newTestSender(t, l1, cfg)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	_, err := sender.Send(ctx, txmgr.TxCandidate{To: &common.Address{}, GasLimit: params.TxGas})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	sent := l1.sentTxs()
+	require.Len(t, sent, 1)
+	require.Equal(t, big.NewInt(5), sent[0].GasTipCap())
+	require.Equal(t, big.NewInt(210), sent[0].GasFeeCap())
+	l1.mu.Lock()
+	require.Equal(t, 1, published, "capped replacements are not published")
+	l1.mu.Unlock()
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	require.Greater(t, metrics.capped, 0)
+}
+
+// TestSenderNonceAfterFailure asserts that the nonce of a tx that failed to be
+// published is used by the next tx, and that the nonce is fetched again after
+// it was used by another tx.
+func TestSenderNonceAfterFailure(t *testing.T) {
+	l1 := newMockL1()
+	l1.nonce = 3
+	sender := newTestSender(t, l1, senderConfig())
+	candidate := txmgr.TxCandidate{To: &common.Address{}, GasLimit: params.TxGas}
+
+	l1.sendErr = errors.New("tx pool is full")
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	_, err := sender.Send(ctx, candidate)
+	cancel()
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	l1.sendErr = nil
+	_, err = sender.Send(context.Background(), candidate)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), l1.sentTxs()[0].Nonce())
+
+	// nonce 4 was used by another tx in the meantime
+	l1.mu.Lock()
+	l1.nonce = 5
+	l1.sendErr = core.ErrNonceTooLow
+	l1.mu.Unlock()
+	_, err = sender.Send(context.Background(), candidate)
+	require.ErrorIs(t, err, txmgr.ErrNonceUsed)
+
+	l1.sendErr = nil
+	_, err = sender.Send(context.Background(), candidate)
+	require.NoError(t, err)
+	sent := l1.sentTxs()
+	require.Equal(t, uint64(5), sent[len(sent)-1].Nonce())
+}
+
+// TestSenderTimedOutTxKeepsNonce asserts that the nonce of a tx that timed out,
+// but is still pending in the tx pool, is not used for a different tx.
+func TestSenderTimedOutTxKeepsNonce(t *testing.T) {
+	l1 := newMockL1()
+	l1.mine = func(tx *types.Transaction) bool { return false }
+	sender := newTestSender(t, l1, senderConfig())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	_, err := sender.Send(ctx, txmgr.TxCandidate{To: &common.Address{}, TxData: []byte{1}, GasLimit: params.TxGas + 100})
+	cancel()
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	l1.setMine(nil)
+	receipt, err := sender.Send(context.Background(), txmgr.TxCandidate{To: &common.Address{}, TxData: []byte{2}, GasLimit: params.TxGas + 100})
+	require.NoError(t, err)
+	sent := l1.sentTxs()
+	last := sent[len(sent)-1]
+	require.Equal(t, receipt.TxHash, last.Hash())
+	require.Equal(t, []byte{2}, last.Data())
+	require.Equal(t, uint64(1), last.Nonce(), "the nonce of the pending tx is not reused")
+	for _, tx := range sent[:len(sent)-1] {
+		require.Equal(t, uint64(0), tx.Nonce())
+		require.Equal(t, []byte{1}, tx.Data())
+	}
+}
+
+// TestSenderFillsNonceGap asserts that the nonce of a pipelined tx that was
+// never published is used by the next tx, so the later pipelined txs are not
+// blocked by the nonce gap.
+func TestSenderFillsNonceGap(t *testing.T) {
+	l1 := newMockL1()
+	l1.mine = func(tx *types.Transaction) bool { return false }
+	l1.sendErrFn = func(tx *types.Transaction) error {
+		if tx.Data()[0] == 2 {
+			return errors.New("tx pool is full")
+		}
+		return nil
+	}
+	sender := newTestSender(t, l1, senderConfig())
+	candidate := func(data byte) txmgr.TxCandidate {
+		return txmgr.TxCandidate{To: &common.Address{}, TxData: []byte{data}, GasLimit: params.TxGas + 100}
+	}
+
+	first := make(chan error)
+	go func() {
+		_, err := sender.Send(context.Background(), candidate(1))
+		first <- err
+	}()
+	require.Eventually(t, func() bool { return len(l1.sentTxs()) > 0 }, time.Second, 10*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	_, err := sender.Send(ctx, candidate(2))
+	cancel()
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	l1.setMine(nil)
+	_, err = sender.Send(context.Background(), candidate(3))
+	require.NoError(t, err)
+	require.NoError(t, <-first)
+
+	nonces := make(map[byte]uint64)
+	for _, tx := range l1.sentTxs() {
+		nonces[tx.Data()[0]] = tx.Nonce()
+	}
+	require.Equal(t, map[byte]uint64{1: 0, 3: 1}, nonces)
+}
+
+// TestSenderRestartWithPendingTxs asserts that a new Sender does not replace
+// the pending txs of the account, e.g. from before a restart, which would be
+// rejected as underpriced, but uses the next nonce after them.
+func TestSenderRestartWithPendingTxs(t *testing.T) {
+	l1 := newMockL1()
+	l1.nonce = 5
+	l1.mine = notStuck
+	l1.addStuckTxs(5, 6)
+	sender := newTestSender(t, l1, senderConfig())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := sender.Send(ctx, txmgr.TxCandidate{To: &common.Address{}, GasLimit: params.TxGas})
+	require.ErrorIs(t, err, context.DeadlineExceeded, "the tx is queued behind the stuck txs")
+	sent := l1.sentTxs()
+	require.NotEmpty(t, sent)
+	for _, tx := range sent {
+		require.Equal(t, uint64(7), tx.Nonce())
+	}
+}
+
+// TestSenderCancelPending asserts that all pending txs of the sender account
+// are replaced by empty self transfers.
+func TestSenderCancelPending(t *testing.T) {
+	l1 := newMockL1()
+	l1.nonce = 5
+	l1.mine = notStuck
+	l1.addStuckTxs(5, 6)
+	cfg := senderConfig()
+	metrics := newTestMetrics()
+	cfg.Metrics = metrics
+	sender := newTestSender(t, l1, cfg)
+
+	require.NoError(t, sender.CancelPending(context.Background()))
+
+	sent := l1.sentTxs()
+	require.Len(t, sent, 2)
+	for i, tx := range sent {
+		require.Equal(t, uint64(5+i), tx.Nonce())
+		require.Equal(t, sender.From(), *tx.To())
+		require.Equal(t, params.TxGas, tx.Gas())
+		require.Empty(t, tx.Data())
+		require.Zero(t, tx.Value().Sign())
+	}
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	require.Equal(t, 2, metrics.canceled)
+}
+
+// TestSenderSendPipelined asserts that pipelined candidates get consecutive
+// nonces in the order of the candidates, and that the later candidates are
+// canceled when a candidate fails to confirm: their published txs are replaced,
+// so they are not mined once the failed candidate is.
+func TestSenderSendPipelined(t *testing.T) {
+	l1 := newMockL1()
+	l1.nonce = 2
+	cfg := senderConfig()
+	metrics := newTestMetrics()
+	cfg.Metrics = metrics
+	sender := newTestSender(t, l1, cfg)
+	candidates := make([]txmgr.TxCandidate, 3)
+	for i := range candidates {
+		candidates[i] = txmgr.TxCandidate{To: &common.Address{}, TxData: []byte{byte(i + 1)}, GasLimit: params.TxGas + 100}
+	}
+
+	receipts, err := sender.SendPipelined(context.Background(), candidates)
+	require.NoError(t, err)
+	require.Len(t, receipts, 3)
+	sent := l1.sentTxs()
+	require.Len(t, sent, 3)
+	for _, tx := range sent {
+		require.Equal(t, uint64(2)+uint64(tx.Data()[0])-1, tx.Nonce())
+	}
+
+	// the second candidate is not mined in time, so the third one cannot be either
+	l1.setMine(func(tx *types.Transaction) bool { return len(tx.Data()) > 0 && tx.Data()[0] == 1 })
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	receipts, err = sender.SendPipelined(ctx, candidates)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.ErrorContains(t, err, "candidate 2 of 3")
+	require.Len(t, receipts, 1)
+	require.Equal(t, uint64(1), receipts[0].Status)
+
+	// the third candidate is replaced in the tx pool by an empty self transfer
+	l1.mu.Lock()
+	second, third := l1.pool[6], l1.pool[7]
+	l1.mu.Unlock()
+	require.Equal(t, []byte{2}, second.Data(), "the failed candidate itself may still be mined")
+	require.Equal(t, sender.From(), *third.To())
+	require.Empty(t, third.Data())
+	metrics.mu.Lock()
+	require.Equal(t, 1, metrics.canceled)
+	metrics.mu.Unlock()
+
+	// once the failed candidate is mined, the replacement is mined instead of the third candidate
+	l1.setMine(nil)
+	for _, tx := range l1.sentTxs() {
+		if tx.Nonce() == 7 && len(tx.Data()) > 0 {
+			receipt, err := l1.TransactionReceipt(context.Background(), tx.Hash())
+			require.NoError(t, err)
+			require.Nil(t, receipt, "the canceled candidate is not mined")
+		}
+	}
+	receipt, err := l1.TransactionReceipt(context.Background(), third.Hash())
+	require.NoError(t, err)
+	require.NotNil(t, receipt)
+
+	// the next candidate uses the nonce after the replacement
+	_, err = sender.Send(context.Background(), candidates[0])
+	require.NoError(t, err)
+	sent = l1.sentTxs()
+	require.Equal(t, uint64(8), sent[len(sent)-1].Nonce())
+}
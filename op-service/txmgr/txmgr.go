@@ -2,7 +2,7 @@ package txmgr
 
 import (
 	"context"
-	"math/big"
+	"errors"
 	"strings"
 	"sync"
 	"time"
@@ -12,6 +12,9 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 )
 
+// ErrReverted signals that a mined transaction reverted.
+var ErrReverted = errors.New("transaction reverted")
+
 // UpdateGasPriceSendTxFunc defines a function signature for publishing a
 // desired tx with a specific gas price. Implementations of this signature
 // should also return promptly when the context is canceled.
@@ -21,7 +24,7 @@ type SendTransactionFunc = func(ctx context.Context, tx *types.Transaction) erro
 
 // Config houses parameters for altering the behavior of a SimpleTxManager.
 type Config struct {
-	// Log is a local logging instance.
+	// Log is a local logging instance. The root logger is used if nil.
 	Log log.Logger
 
 	// Name the name of the driver to appear in log lines.
@@ -46,6 +49,14 @@ type Config struct {
 	// are required to give up on a tx at a particular nonce without receiving
 	// confirmation.
 	SafeAbortNonceTooLowCount uint64
+
+	// FeeLimits caps the fees of published transactions. Transactions with
+	// fees above the caps are not published.
+	FeeLimits FeeLimits
+
+	// Metrics records the activity of the tx manager. Metrics are discarded
+	// if nil.
+	Metrics Metricer
 }
 
 // TxManager is an interface that allows callers to reliably publish txs,
@@ -56,7 +67,8 @@ type TxManager interface {
 	// until an invocation of sendTx returns (called with differing gas
 	// prices). The method may be canceled using the passed context.
 	//
-	// NOTE: Send should be called by AT MOST one caller at a time.
+	// NOTE: Send may be called concurrently, but the caller is responsible
+	// for assigning a distinct nonce to every transaction.
 	Send(
 		ctx context.Context,
 		updateGasPrice UpdateGasPriceFunc,
@@ -86,6 +98,7 @@ type SimpleTxManager struct {
 	cfg     Config
 	backend ReceiptSource
 	l       log.Logger
+	metrics Metricer
 }
 
 // NewSimpleTxManager initializes a new SimpleTxManager with the passed Config.
@@ -96,11 +109,21 @@ func NewSimpleTxManager(
 		panic("txmgr: NumConfirmations cannot be zero")
 	}
 
+	l := cfg.Log
+	if l == nil {
+		l = log.Root()
+	}
+	metrics := cfg.Metrics
+	if metrics == nil {
+		metrics = NoopMetrics
+	}
+
 	return &SimpleTxManager{
 		name:    name,
 		cfg:     cfg,
 		backend: backend,
-		l:       cfg.Log,
+		l:       l,
+		metrics: metrics,
 	}
 }
 
@@ -109,7 +132,11 @@ func NewSimpleTxManager(
 // invocation of sendTx returns (called with differing gas prices). The method
 // may be canceled using the passed context.
 //
-// NOTE: Send should be called by AT MOST one caller at a time.
+// If the confirmed transaction reverted, its receipt is returned together
+// with ErrReverted.
+//
+// NOTE: Send may be called concurrently, but the caller is responsible for
+// assigning a distinct nonce to every transaction.
 func (m *SimpleTxManager) Send(
 	ctx context.Context,
 	updateGasPrice UpdateGasPriceFunc,
@@ -131,6 +158,9 @@ func (m *SimpleTxManager) Send(
 	defer cancel()
 
 	sendState := NewSendState(m.cfg.SafeAbortNonceTooLowCount)
+	start := time.Now()
+	// bumps counts the resubmissions, it is only accessed by the event loop.
+	bumps := 0
 
 	// Create a closure that will block on passed sendTx function in the
 	// background, returning the first successfully mined receipt back to
@@ -145,6 +175,12 @@ func (m *SimpleTxManager) Send(
 				strings.Contains(err.Error(), "context canceled") {
 				return
 			}
+			// The caps do not allow a replacement, the published
+			// transaction will have to confirm.
+			if errors.Is(err, ErrFeeBumpCapped) {
+				m.l.Warn(name+" not replacing transaction", "err", err)
+				return
+			}
 			m.l.Error(name+" unable to update txn gas price", "err", err)
 			return
 		}
@@ -153,6 +189,17 @@ func (m *SimpleTxManager) Send(
 		nonce := tx.Nonce()
 		gasTipCap := tx.GasTipCap()
 		gasFeeCap := tx.GasFeeCap()
+
+		// Never publish a transaction with fees above the configured caps,
+		// the last transaction within the caps will have to confirm.
+		if m.cfg.FeeLimits.Exceeded(tx) {
+			m.l.Warn(name+" not publishing transaction, fees exceed the configured caps",
+				"txHash", txHash, "nonce", nonce, "gasTipCap", gasTipCap, "gasFeeCap", gasFeeCap,
+				"maxGasTipCap", m.cfg.FeeLimits.MaxGasTipCap, "maxGasFeeCap", m.cfg.FeeLimits.MaxGasFeeCap)
+			m.metrics.RecordFeesCapped()
+			return
+		}
+
 		m.l.Info(name+" publishing transaction", "txHash", txHash,
 			"nonce", nonce, "gasTipCap", gasTipCap, "gasFeeCap", gasFeeCap)
 
@@ -165,12 +212,14 @@ func (m *SimpleTxManager) Send(
 				return
 			}
 			m.l.Error(name+" unable to publish transaction", "err", err)
+			m.metrics.RecordPublishError()
 			if sendState.ShouldAbortImmediately() {
 				cancel()
 			}
 			// TODO(conner): add retry?
 			return
 		}
+		m.metrics.RecordTxPublished()
 
 		m.l.Info(name+" transaction published successfully", "hash", txHash,
 			"nonce", nonce, "gasTipCap", gasTipCap, "gasFeeCap", gasFeeCap)
@@ -179,7 +228,7 @@ func (m *SimpleTxManager) Send(
 		// back to the main event loop if found.
 		receipt, err := waitMined(
 			m.l, ctxc, m.backend, tx, m.cfg.ReceiptQueryInterval,
-			m.cfg.NumConfirmations, sendState,
+			m.cfg.NumConfirmations, sendState, m.metrics,
 		)
 		if err != nil {
 			m.l.Debug(name+" send tx failed", "hash", txHash,
@@ -223,6 +272,7 @@ func (m *SimpleTxManager) Send(
 			}
 
 			// Submit and wait for the bumped traction to confirm.
+			bumps++
 			wg.Add(1)
 			go sendTxAsync()
 
@@ -233,6 +283,12 @@ func (m *SimpleTxManager) Send(
 
 		// The transaction has confirmed.
 		case receipt := <-receiptChan:
+			m.metrics.RecordGasBumpCount(bumps)
+			m.metrics.RecordTxConfirmed(time.Since(start))
+			if receipt.Status == types.ReceiptStatusFailed {
+				m.metrics.RecordTxReverted()
+				return receipt, ErrReverted
+			}
 			return receipt, nil
 		}
 	}
@@ -249,7 +305,7 @@ func WaitMined(
 	queryInterval time.Duration,
 	numConfirmations uint64,
 ) (*types.Receipt, error) {
-	return waitMined(log.New(), ctx, backend, tx, queryInterval, numConfirmations, nil)
+	return waitMined(log.Root(), ctx, backend, tx, queryInterval, numConfirmations, nil, NoopMetrics)
 }
 
 // waitMined implements the core functionality of WaitMined, with the option to
@@ -262,6 +318,7 @@ func waitMined(
 	queryInterval time.Duration,
 	numConfirmations uint64,
 	sendState *SendState,
+	metrics Metricer,
 ) (*types.Receipt, error) {
 
 	queryTicker := time.NewTicker(queryInterval)
@@ -269,6 +326,10 @@ func waitMined(
 
 	txHash := tx.Hash()
 
+	// includedIn tracks the block the transaction was last seen in, to detect
+	// reorgs while waiting for confirmations.
+	var includedIn *types.Receipt
+
 	for {
 		receipt, err := backend.TransactionReceipt(ctx, txHash)
 		switch {
@@ -277,6 +338,16 @@ func waitMined(
 				sendState.TxMined(txHash)
 			}
 
+			// The confirmations are counted again from the new block if the
+			// transaction was reorged into another block.
+			if includedIn != nil && includedIn.BlockHash != receipt.BlockHash {
+				l.Warn("Transaction was reorged into another block", "txHash", txHash,
+					"oldBlock", includedIn.BlockHash, "oldHeight", includedIn.BlockNumber,
+					"newBlock", receipt.BlockHash, "newHeight", receipt.BlockNumber)
+				metrics.RecordTxReorged()
+			}
+			includedIn = receipt
+
 			txHeight := receipt.BlockNumber.Uint64()
 			tipHeight, err := backend.BlockNumber(ctx)
 			if err != nil {
@@ -297,7 +368,10 @@ func waitMined(
 			// tipHeight. The equation is rewritten in this form to avoid
 			// underflows.
 			if txHeight+numConfirmations <= tipHeight+1 {
-				l.Info("Transaction confirmed", "txHash", txHash)
+				reverted := receipt.Status == types.ReceiptStatusFailed
+				l.Info("Transaction confirmed",
+					"txHash", txHash,
+					"reverted", reverted)
 				return receipt, nil
 			}
 
@@ -314,6 +388,12 @@ func waitMined(
 			if sendState != nil {
 				sendState.TxNotMined(txHash)
 			}
+			if includedIn != nil {
+				l.Warn("Transaction was reorged out", "txHash", txHash,
+					"block", includedIn.BlockHash, "height", includedIn.BlockNumber)
+				metrics.RecordTxReorged()
+				includedIn = nil
+			}
 			l.Trace("Transaction not yet mined", "hash", txHash)
 		}
 
@@ -324,14 +404,3 @@ func waitMined(
 		}
 	}
 }
-
-// CalcGasFeeCap deterministically computes the recommended gas fee cap given
-// the base fee and gasTipCap. The resulting gasFeeCap is equal to:
-//
-//	gasTipCap + 2*baseFee.
-func CalcGasFeeCap(baseFee, gasTipCap *big.Int) *big.Int {
-	return new(big.Int).Add(
-		gasTipCap,
-		new(big.Int).Mul(baseFee, big.NewInt(2)),
-	)
-}
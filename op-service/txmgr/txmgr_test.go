@@ -8,7 +8,7 @@ import (
 	"testing"
 	"time"
 
-	"github.com/ethereum-optimism/optimism/bss-core/txmgr"
+	"github.com/ethereum-optimism/optimism/op-service/txmgr"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -597,3 +597,170 @@ func TestWaitMinedReturnsReceiptAfterFailure(t *testing.T) {
 	require.NotNil(t, receipt)
 	require.Equal(t, receipt.TxHash, txHash)
 }
+
+// testMetrics counts the recorded tx manager metrics.
+type testMetrics struct {
+	txmgr.Metricer
+	mu         sync.Mutex
+	published  int
+	reorged    int
+	capped     int
+	reverted   int
+	canceled   int
+	lastNonces []uint64
+}
+
+func newTestMetrics() *testMetrics {
+	return &testMetrics{Metricer: txmgr.NoopMetrics}
+}
+
+func (m *testMetrics) RecordNonce(nonce uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastNonces = append(m.lastNonces, nonce)
+}
+
+func (m *testMetrics) RecordTxPublished() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.published++
+}
+
+func (m *testMetrics) RecordTxReorged() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reorged++
+}
+
+func (m *testMetrics) RecordFeesCapped() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.capped++
+}
+
+func (m *testMetrics) RecordTxReverted() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reverted++
+}
+
+func (m *testMetrics) RecordTxCanceled() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.canceled++
+}
+
+// TestTxMgrDoesntPublishAboveFeeCaps asserts that Send never publishes a
+// transaction with fees above the configured caps.
+func TestTxMgrDoesntPublishAboveFeeCaps(t *testing.T) {
+	t.Parallel()
+
+	cfg := configWithNumConfs(1)
+	cfg.ResubmissionTimeout = 50 * time.Millisecond
+	metrics := newTestMetrics()
+	cfg.Metrics = metrics
+	gasPricer := newGasPricer(3)
+	_, cfg.FeeLimits.MaxGasFeeCap = gasPricer.feesForEpoch(2)
+	h := newTestHarnessWithConfig(cfg)
+
+	updateGasPrice := func(ctx context.Context) (*types.Transaction, error) {
+		gasTipCap, gasFeeCap := gasPricer.sample()
+		return types.NewTx(&types.DynamicFeeTx{
+			GasTipCap: gasTipCap,
+			GasFeeCap: gasFeeCap,
+		}), nil
+	}
+
+	sendTx := func(ctx context.Context, tx *types.Transaction) error {
+		require.LessOrEqual(t, tx.GasFeeCap().Cmp(cfg.FeeLimits.MaxGasFeeCap), 0)
+		if gasPricer.shouldMine(tx.GasFeeCap()) {
+			txHash := tx.Hash()
+			h.backend.mine(&txHash, tx.GasFeeCap())
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	receipt, err := h.mgr.Send(ctx, updateGasPrice, sendTx)
+	require.Equal(t, context.DeadlineExceeded, err)
+	require.Nil(t, receipt)
+
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	require.Equal(t, 2, metrics.published)
+	require.Greater(t, metrics.capped, 0)
+}
+
+type scriptedReceipt struct {
+	receipt *types.Receipt
+	tip     uint64
+}
+
+// reorgBackend returns the scripted receipts and tip heights, one per receipt
+// query, to simulate reorgs of an included transaction.
+type reorgBackend struct {
+	mu     sync.Mutex
+	script []scriptedReceipt
+	tip    uint64
+}
+
+func (b *reorgBackend) BlockNumber(ctx context.Context) (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.tip, nil
+}
+
+func (b *reorgBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	next := b.script[0]
+	if len(b.script) > 1 {
+		b.script = b.script[1:]
+	}
+	b.tip = next.tip
+	return next.receipt, nil
+}
+
+func receiptAt(height uint64, blockHash common.Hash) *types.Receipt {
+	return &types.Receipt{
+		BlockNumber: new(big.Int).SetUint64(height),
+		BlockHash:   blockHash,
+		Status:      types.ReceiptStatusSuccessful,
+	}
+}
+
+// TestTxMgrDetectsReorgs asserts that the confirmations of a transaction are
+// counted from the latest block it was included in, and that reorgs of an
+// included transaction are recorded.
+func TestTxMgrDetectsReorgs(t *testing.T) {
+	t.Parallel()
+
+	backend := &reorgBackend{script: []scriptedReceipt{
+		{receiptAt(10, common.Hash{0xa}), 10},
+		{nil, 10}, // reorged out
+		{receiptAt(11, common.Hash{0xb}), 11},
+		{receiptAt(12, common.Hash{0xc}), 12}, // reorged into another block
+		{receiptAt(12, common.Hash{0xc}), 13},
+	}}
+	cfg := configWithNumConfs(2)
+	metrics := newTestMetrics()
+	cfg.Metrics = metrics
+	mgr := txmgr.NewSimpleTxManager("TEST", cfg, backend)
+
+	updateGasPrice := func(ctx context.Context) (*types.Transaction, error) {
+		return types.NewTx(&types.DynamicFeeTx{}), nil
+	}
+	sendTx := func(ctx context.Context, tx *types.Transaction) error {
+		return nil
+	}
+
+	receipt, err := mgr.Send(context.Background(), updateGasPrice, sendTx)
+	require.NoError(t, err)
+	require.Equal(t, common.Hash{0xc}, receipt.BlockHash)
+
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	require.Equal(t, 2, metrics.reorged)
+}
@@ -79,6 +79,12 @@ var (
 		HTTPErrorCode: 429,
 	}
 
+	ErrBlockOutOfRange = &RPCErr{
+		Code:          JSONRPCErrorInternal - 17,
+		Message:       "block is out of range",
+		HTTPErrorCode: 400,
+	}
+
 	ErrBackendUnexpectedJSONRPC = errors.New("backend returned an unexpected JSON-RPC response")
 )
 
@@ -473,6 +479,17 @@ func sortBatchRPCResponse(req []*RPCReq, res []*RPCRes) {
 type BackendGroup struct {
 	Name     string
 	Backends []*Backend
+	// Consensus is set if the group is consensus aware:
+	// requests are then only routed to the backends that have the consensus head.
+	Consensus *ConsensusPoller
+}
+
+// backends returns the backends to route requests to, in order of preference.
+func (b *BackendGroup) backends() []*Backend {
+	if b.Consensus != nil {
+		return b.Consensus.GetConsensusGroup()
+	}
+	return b.Backends
 }
 
 func (b *BackendGroup) Forward(ctx context.Context, rpcReqs []*RPCReq, isBatch bool) ([]*RPCRes, error) {
@@ -482,7 +499,56 @@ func (b *BackendGroup) Forward(ctx context.Context, rpcReqs []*RPCReq, isBatch b
 
 	rpcRequestsTotal.Inc()
 
-	for _, back := range b.Backends {
+	if b.Consensus != nil {
+		return b.forwardConsensus(ctx, rpcReqs, isBatch)
+	}
+	return b.forward(ctx, rpcReqs, isBatch)
+}
+
+// forwardConsensus answers eth_blockNumber with the consensus head, and rewrites references to the latest block
+// to the consensus head, such that clients never see the chain go backwards when switching between backends.
+// The remaining requests are forwarded to the consensus group.
+func (b *BackendGroup) forwardConsensus(ctx context.Context, rpcReqs []*RPCReq, isBatch bool) ([]*RPCRes, error) {
+	latest := b.Consensus.GetLatestBlock()
+	if len(b.Consensus.GetConsensusGroup()) == 0 {
+		RecordUnserviceableRequest(ctx, RPCRequestSourceHTTP)
+		return nil, ErrNoBackends
+	}
+
+	responses := make([]*RPCRes, len(rpcReqs))
+	forwardReqs := make([]*RPCReq, 0, len(rpcReqs))
+	forwardIdx := make([]int, 0, len(rpcReqs))
+	for i, req := range rpcReqs {
+		if req.Method == "eth_blockNumber" {
+			RecordRPCForward(ctx, BackendProxyd, req.Method, RPCRequestSourceHTTP)
+			responses[i] = NewRPCRes(req.ID, latest.Number)
+			continue
+		}
+		rewritten, err := RewriteRequest(req, latest.Number)
+		if err != nil {
+			RecordRPCError(ctx, BackendProxyd, req.Method, err)
+			responses[i] = NewRPCErrorRes(req.ID, err)
+			continue
+		}
+		forwardReqs = append(forwardReqs, rewritten)
+		forwardIdx = append(forwardIdx, i)
+	}
+	if len(forwardReqs) == 0 {
+		return responses, nil
+	}
+
+	res, err := b.forward(ctx, forwardReqs, isBatch)
+	if err != nil {
+		return nil, err
+	}
+	for i, idx := range forwardIdx {
+		responses[idx] = res[i]
+	}
+	return responses, nil
+}
+
+func (b *BackendGroup) forward(ctx context.Context, rpcReqs []*RPCReq, isBatch bool) ([]*RPCRes, error) {
+	for _, back := range b.backends() {
 		res, err := back.Forward(ctx, rpcReqs, isBatch)
 		if errors.Is(err, ErrMethodNotWhitelisted) {
 			return nil, err
@@ -523,7 +589,7 @@ func (b *BackendGroup) Forward(ctx context.Context, rpcReqs []*RPCReq, isBatch b
 }
 
 func (b *BackendGroup) ProxyWS(ctx context.Context, clientConn *websocket.Conn, methodWhitelist *StringSet) (*WSProxier, error) {
-	for _, back := range b.Backends {
+	for _, back := range b.backends() {
 		proxier, err := back.ProxyWS(clientConn, methodWhitelist)
 		if errors.Is(err, ErrBackendOffline) {
 			log.Warn(
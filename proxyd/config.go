@@ -93,6 +93,10 @@ type BackendsConfig map[string]*BackendConfig
 
 type BackendGroupConfig struct {
 	Backends []string `toml:"backends"`
+
+	ConsensusAware          bool         `toml:"consensus_aware"`
+	ConsensusPollerInterval TOMLDuration `toml:"consensus_poller_interval"`
+	ConsensusMaxBlockLag    uint64       `toml:"consensus_max_block_lag"`
 }
 
 type BackendGroupsConfig map[string]*BackendGroupConfig
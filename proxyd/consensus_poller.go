@@ -0,0 +1,345 @@
+package proxyd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	defaultConsensusPollerInterval = 1 * time.Second
+	consensusPollTimeout           = 5 * time.Second
+)
+
+// BlockRef identifies a block by number and hash.
+type BlockRef struct {
+	Number hexutil.Uint64 `json:"number"`
+	Hash   common.Hash    `json:"hash"`
+}
+
+// backendState is the chain state of a backend, as of the last poll.
+type backendState struct {
+	latest    BlockRef
+	safe      BlockRef
+	finalized BlockRef
+	err       error
+}
+
+// ConsensusPoller polls the backends of a group for their latest, safe and finalized blocks,
+// and agrees on a consensus head: the highest block that the backends which are not lagging behind all have,
+// with the block hash that most of them agree on.
+// Only the backends that have the consensus head are part of the consensus group that requests are routed to.
+//
+// The consensus head does not move backwards, unless none of the backends still has it.
+type ConsensusPoller struct {
+	bg          *BackendGroup
+	interval    time.Duration
+	maxBlockLag uint64
+
+	mu        sync.RWMutex
+	latest    BlockRef
+	safe      BlockRef
+	finalized BlockRef
+	group     []*Backend
+
+	quit chan struct{}
+	done chan struct{}
+}
+
+type ConsensusOpt func(cp *ConsensusPoller)
+
+// WithConsensusPollerInterval sets the interval at which the backends are polled.
+func WithConsensusPollerInterval(interval time.Duration) ConsensusOpt {
+	return func(cp *ConsensusPoller) {
+		cp.interval = interval
+	}
+}
+
+// WithConsensusMaxBlockLag sets the number of blocks a backend may lag behind the highest backend,
+// and still be considered for the consensus head.
+func WithConsensusMaxBlockLag(lag uint64) ConsensusOpt {
+	return func(cp *ConsensusPoller) {
+		cp.maxBlockLag = lag
+	}
+}
+
+func NewConsensusPoller(bg *BackendGroup, opts ...ConsensusOpt) *ConsensusPoller {
+	cp := &ConsensusPoller{
+		bg:       bg,
+		interval: defaultConsensusPollerInterval,
+		quit:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(cp)
+	}
+	return cp
+}
+
+// Start polls the backends immediately, and then at every interval, until Stop is called.
+func (cp *ConsensusPoller) Start() {
+	go func() {
+		defer close(cp.done)
+		ticker := time.NewTicker(cp.interval)
+		defer ticker.Stop()
+
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), consensusPollTimeout)
+			cp.UpdateBackendGroupConsensus(ctx)
+			cancel()
+
+			select {
+			case <-ticker.C:
+			case <-cp.quit:
+				return
+			}
+		}
+	}()
+}
+
+func (cp *ConsensusPoller) Stop() {
+	close(cp.quit)
+	<-cp.done
+}
+
+// GetConsensusGroup returns the backends that have the consensus head, in the order of the backend group.
+func (cp *ConsensusPoller) GetConsensusGroup() []*Backend {
+	cp.mu.RLock()
+	defer cp.mu.RUnlock()
+	return cp.group
+}
+
+// GetLatestBlock returns the consensus head.
+func (cp *ConsensusPoller) GetLatestBlock() BlockRef {
+	cp.mu.RLock()
+	defer cp.mu.RUnlock()
+	return cp.latest
+}
+
+// GetSafeBlock returns the lowest safe block of the consensus group.
+func (cp *ConsensusPoller) GetSafeBlock() BlockRef {
+	cp.mu.RLock()
+	defer cp.mu.RUnlock()
+	return cp.safe
+}
+
+// GetFinalizedBlock returns the lowest finalized block of the consensus group.
+func (cp *ConsensusPoller) GetFinalizedBlock() BlockRef {
+	cp.mu.RLock()
+	defer cp.mu.RUnlock()
+	return cp.finalized
+}
+
+// UpdateBackendGroupConsensus polls all backends, and updates the consensus head and group.
+func (cp *ConsensusPoller) UpdateBackendGroupConsensus(ctx context.Context) {
+	states := make(map[*Backend]*backendState, len(cp.bg.Backends))
+	var wg sync.WaitGroup
+	var statesMu sync.Mutex
+	for _, be := range cp.bg.Backends {
+		wg.Add(1)
+		go func(be *Backend) {
+			defer wg.Done()
+			state := cp.pollBackend(ctx, be)
+			statesMu.Lock()
+			states[be] = state
+			statesMu.Unlock()
+		}(be)
+	}
+	wg.Wait()
+
+	cp.mu.RLock()
+	current := cp.latest
+	cp.mu.RUnlock()
+
+	// Only the online backends that are not lagging behind are candidates for the consensus head.
+	var highest uint64
+	for be, state := range states {
+		if state.err == nil && be.Online() && uint64(state.latest.Number) > highest {
+			highest = uint64(state.latest.Number)
+		}
+	}
+	var candidates []*Backend
+	for _, be := range cp.bg.Backends {
+		state := states[be]
+		if state.err != nil || !be.Online() {
+			continue
+		}
+		if uint64(state.latest.Number)+cp.maxBlockLag < highest {
+			log.Warn("backend is lagging behind", "group", cp.bg.Name, "name", be.Name,
+				"latest", uint64(state.latest.Number), "highest", highest)
+			continue
+		}
+		candidates = append(candidates, be)
+	}
+
+	// Don't move the consensus head backwards, if any of the candidates still has it.
+	var ahead []*Backend
+	for _, be := range candidates {
+		if states[be].latest.Number >= current.Number {
+			ahead = append(ahead, be)
+		}
+	}
+	if len(ahead) > 0 {
+		candidates = ahead
+	} else if len(candidates) > 0 {
+		log.Warn("consensus head moves backwards, no backend has the current consensus head",
+			"group", cp.bg.Name, "current", uint64(current.Number))
+	}
+
+	latest, group := cp.agreeOnHead(ctx, candidates, states)
+
+	var safe, finalized BlockRef
+	for i, be := range group {
+		state := states[be]
+		if i == 0 || state.safe.Number < safe.Number {
+			safe = state.safe
+		}
+		if i == 0 || state.finalized.Number < finalized.Number {
+			finalized = state.finalized
+		}
+	}
+
+	cp.mu.Lock()
+	if len(group) > 0 {
+		cp.latest = latest
+		cp.safe = safe
+		cp.finalized = finalized
+	}
+	cp.group = group
+	RecordConsensusState(cp.bg.Name, cp.latest, cp.safe, cp.finalized, len(group))
+	cp.mu.Unlock()
+
+	inGroup := make(map[*Backend]bool, len(group))
+	for _, be := range group {
+		inGroup[be] = true
+	}
+	for _, be := range cp.bg.Backends {
+		RecordBackendConsensusState(be.Name, states[be].latest, inGroup[be])
+	}
+	if len(group) == 0 {
+		log.Warn("no backends in consensus", "group", cp.bg.Name)
+		return
+	}
+	log.Debug("updated consensus", "group", cp.bg.Name, "latest", uint64(latest.Number), "hash", latest.Hash,
+		"safe", uint64(safe.Number), "finalized", uint64(finalized.Number), "group_size", len(group))
+}
+
+// agreeOnHead proposes the lowest latest block of the candidates as consensus head,
+// and returns it with the hash that most candidates have at that height, and the candidates that have that hash.
+// Candidates with another block at that height are on a fork, and are left out.
+func (cp *ConsensusPoller) agreeOnHead(ctx context.Context, candidates []*Backend, states map[*Backend]*backendState) (BlockRef, []*Backend) {
+	if len(candidates) == 0 {
+		return BlockRef{}, nil
+	}
+	proposed := states[candidates[0]].latest.Number
+	for _, be := range candidates[1:] {
+		if n := states[be].latest.Number; n < proposed {
+			proposed = n
+		}
+	}
+
+	hashes := make([]common.Hash, len(candidates))
+	var wg sync.WaitGroup
+	for i, be := range candidates {
+		// the latest block was just fetched, no need to fetch it again
+		if states[be].latest.Number == proposed {
+			hashes[i] = states[be].latest.Hash
+			continue
+		}
+		wg.Add(1)
+		go func(i int, be *Backend) {
+			defer wg.Done()
+			ref, err := cp.fetchBlock(ctx, be, hexutil.EncodeUint64(uint64(proposed)))
+			if err != nil {
+				log.Warn("error fetching proposed consensus block", "group", cp.bg.Name, "name", be.Name,
+					"number", uint64(proposed), "err", err)
+				return
+			}
+			hashes[i] = ref.Hash
+		}(i, be)
+	}
+	wg.Wait()
+
+	// The hash with the most votes wins, ties go to the hash of the backend that comes first in the group.
+	votes := make(map[common.Hash]int)
+	var winner common.Hash
+	for _, hash := range hashes {
+		if hash == (common.Hash{}) {
+			continue
+		}
+		votes[hash]++
+		if votes[hash] > votes[winner] {
+			winner = hash
+		}
+	}
+	var group []*Backend
+	for i, be := range candidates {
+		if hashes[i] == winner && winner != (common.Hash{}) {
+			group = append(group, be)
+		} else if hashes[i] != (common.Hash{}) {
+			log.Warn("backend is on a fork", "group", cp.bg.Name, "name", be.Name,
+				"number", uint64(proposed), "hash", hashes[i], "consensus_hash", winner)
+		}
+	}
+	return BlockRef{Number: proposed, Hash: winner}, group
+}
+
+func (cp *ConsensusPoller) pollBackend(ctx context.Context, be *Backend) *backendState {
+	state := new(backendState)
+	for _, tag := range []string{"latest", "safe", "finalized"} {
+		ref, err := cp.fetchBlock(ctx, be, tag)
+		if err != nil {
+			log.Warn("error polling backend", "group", cp.bg.Name, "name", be.Name, "block", tag, "err", err)
+			RecordConsensusPollError(be.Name)
+			state.err = err
+			return state
+		}
+		switch tag {
+		case "latest":
+			state.latest = ref
+		case "safe":
+			state.safe = ref
+		case "finalized":
+			state.finalized = ref
+		}
+	}
+	return state
+}
+
+var errBlockNotFound = errors.New("block not found")
+
+// fetchBlock fetches the number and hash of the given block from the backend.
+// An unknown safe or finalized block, as reported before any block is safe or finalized, is returned as zero block.
+func (cp *ConsensusPoller) fetchBlock(ctx context.Context, be *Backend, block string) (BlockRef, error) {
+	req := &RPCReq{
+		JSONRPC: JSONRPCVersion,
+		Method:  "eth_getBlockByNumber",
+		Params:  mustMarshalJSON([]interface{}{block, false}),
+		ID:      json.RawMessage("1"),
+	}
+	res, err := be.doForward(ctx, []*RPCReq{req}, false)
+	if err != nil {
+		return BlockRef{}, err
+	}
+	if res[0].IsError() {
+		return BlockRef{}, res[0].Error
+	}
+	if res[0].Result == nil {
+		if block == "safe" || block == "finalized" {
+			return BlockRef{}, nil
+		}
+		return BlockRef{}, errBlockNotFound
+	}
+	var ref BlockRef
+	if err := json.Unmarshal(mustMarshalJSON(res[0].Result), &ref); err != nil {
+		return BlockRef{}, fmt.Errorf("invalid block: %w", err)
+	}
+	return ref, nil
+}
@@ -0,0 +1,209 @@
+package integration_tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/proxyd"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+// chainHandler serves the blocks of a mock chain, and responds to all other methods with the params it was called with.
+type chainHandler struct {
+	mu        sync.Mutex
+	latest    uint64
+	safe      uint64
+	finalized uint64
+	// fork distinguishes the block hashes of diverging chains
+	fork byte
+}
+
+func (c *chainHandler) set(latest, safe, finalized uint64, fork byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.latest, c.safe, c.finalized, c.fork = latest, safe, finalized, fork
+}
+
+func (c *chainHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	req, err := proxyd.ParseRPCReq(mustReadAll(r))
+	if err != nil {
+		panic(err)
+	}
+	var result interface{}
+	if req.Method == "eth_getBlockByNumber" {
+		var params []interface{}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			panic(err)
+		}
+		var num uint64
+		switch tag := params[0].(string); tag {
+		case "latest":
+			num = c.latest
+		case "safe":
+			num = c.safe
+		case "finalized":
+			num = c.finalized
+		default:
+			num = hexutil.MustDecodeUint64(tag)
+		}
+		if num <= c.latest {
+			result = map[string]interface{}{
+				"number": hexutil.Uint64(num),
+				"hash":   common.Hash{c.fork, byte(num)},
+			}
+		}
+	} else {
+		result = req.Params
+	}
+	res := &proxyd.RPCRes{JSONRPC: proxyd.JSONRPCVersion, Result: result, ID: req.ID}
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		panic(err)
+	}
+}
+
+func mustReadAll(r *http.Request) []byte {
+	var body json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		panic(err)
+	}
+	return body
+}
+
+func TestConsensus(t *testing.T) {
+	chains := []*chainHandler{{}, {}, {}}
+	nodes := make([]*MockBackend, len(chains))
+	for i, chain := range chains {
+		chain.set(10, 8, 4, 0)
+		nodes[i] = NewMockBackend(chain)
+		defer nodes[i].Close()
+		require.NoError(t, os.Setenv(fmt.Sprintf("NODE%d_URL", i+1), nodes[i].URL()))
+	}
+
+	config := ReadConfig("consensus")
+	client := NewProxydClient("http://127.0.0.1:8545")
+	shutdown, err := proxyd.Start(config)
+	require.NoError(t, err)
+	defer shutdown()
+
+	// requireConsensus waits for the poller to agree on the expected head
+	requireConsensus := func(expected uint64) {
+		require.Eventually(t, func() bool {
+			res, code, err := client.SendRPC("eth_blockNumber", nil)
+			require.NoError(t, err)
+			return code == 200 && string(canonicalizeJSON(t, res)) == string(canonicalizeJSON(t,
+				[]byte(fmt.Sprintf(`{"jsonrpc":"2.0","result":"%s","id":999}`, hexutil.Uint64(expected)))))
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+	// forwarded counts the eth_getBalance requests forwarded to a node, and resets its recorded requests
+	forwarded := func(node int) int {
+		count := 0
+		for _, req := range nodes[node].Requests() {
+			if parsed, err := proxyd.ParseRPCReq(req.Body); err == nil && parsed.Method == "eth_getBalance" {
+				count++
+			}
+		}
+		nodes[node].Reset()
+		return count
+	}
+	// requireForwardedTo checks which node served the request, and with which params
+	requireForwardedTo := func(node int, res []byte, params string) {
+		RequireEqualJSON(t, []byte(fmt.Sprintf(`{"jsonrpc":"2.0","result":%s,"id":999}`, params)), res)
+		for i := range nodes {
+			if i == node {
+				require.Equal(t, 1, forwarded(i), "node%d", i+1)
+			} else {
+				require.Zero(t, forwarded(i), "node%d", i+1)
+			}
+		}
+	}
+	getBalance := func(block string) []byte {
+		res, code, err := client.SendRPC("eth_getBalance", []interface{}{"0x0000000000000000000000000000000000000001", block})
+		require.NoError(t, err)
+		require.Equal(t, 200, code)
+		return res
+	}
+
+	t.Run("all nodes agree", func(t *testing.T) {
+		requireConsensus(10)
+		res := getBalance("latest")
+		requireForwardedTo(0, res, `["0x0000000000000000000000000000000000000001","0xa"]`)
+	})
+
+	t.Run("latest is rewritten to the lowest head", func(t *testing.T) {
+		chains[1].set(12, 8, 4, 0)
+		chains[2].set(11, 8, 4, 0)
+		// the consensus head is the lowest head of the nodes, node1 still serves requests
+		requireConsensus(10)
+		res := getBalance("latest")
+		requireForwardedTo(0, res, `["0x0000000000000000000000000000000000000001","0xa"]`)
+		res = getBalance("0x5")
+		requireForwardedTo(0, res, `["0x0000000000000000000000000000000000000001","0x5"]`)
+	})
+
+	t.Run("blocks after the consensus head are out of range", func(t *testing.T) {
+		res, code, err := client.SendRPC("eth_getBlockByNumber", []interface{}{"0xc", false})
+		require.NoError(t, err)
+		require.Equal(t, 400, code)
+		RequireEqualJSON(t, []byte(`{"jsonrpc":"2.0","error":{"code":-32017,"message":"block is out of range"},"id":999}`), res)
+	})
+
+	t.Run("lagging node is left out", func(t *testing.T) {
+		chains[1].set(17, 8, 4, 0)
+		chains[2].set(16, 8, 4, 0)
+		requireConsensus(16)
+		res := getBalance("latest")
+		requireForwardedTo(1, res, `["0x0000000000000000000000000000000000000001","0x10"]`)
+	})
+
+	t.Run("forked node is left out", func(t *testing.T) {
+		chains[0].set(16, 8, 4, 1)
+		// node1 caught up, but is on a fork: node2 and node3 agree on the hash at height 16
+		require.Eventually(t, func() bool {
+			getBalance("latest")
+			forwarded(0)
+			return forwarded(1) > 0
+		}, 5*time.Second, 10*time.Millisecond)
+		require.Never(t, func() bool {
+			getBalance("latest")
+			forwarded(1)
+			return forwarded(0) > 0
+		}, 200*time.Millisecond, 10*time.Millisecond)
+	})
+
+	t.Run("consensus head does not go backwards", func(t *testing.T) {
+		chains[0].set(17, 8, 4, 0)
+		chains[2].set(15, 8, 4, 0)
+		// node1 and node2 have the current consensus head, node3 is left out until it catches up
+		requireConsensus(17)
+		res := getBalance("latest")
+		requireForwardedTo(0, res, `["0x0000000000000000000000000000000000000001","0x11"]`)
+		require.Never(t, func() bool {
+			getBalance("latest")
+			forwarded(0)
+			return forwarded(2) > 0
+		}, 200*time.Millisecond, 10*time.Millisecond)
+	})
+
+	t.Run("no nodes in consensus", func(t *testing.T) {
+		for _, n := range nodes {
+			n.SetHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(503)
+			}))
+		}
+		require.Eventually(t, func() bool {
+			res, code, err := client.SendRPC("eth_blockNumber", nil)
+			require.NoError(t, err)
+			return code == 503 && string(canonicalizeJSON(t, res)) == string(canonicalizeJSON(t, []byte(noBackendsResponse)))
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+}
@@ -242,6 +242,62 @@ var (
 		Name:      "rate_limit_take_errors",
 		Help:      "Count of errors taking frontend rate limits",
 	})
+
+	consensusLatestBlock = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: MetricsNamespace,
+		Name:      "group_consensus_latest_block",
+		Help:      "Consensus latest block number of the backend group",
+	}, []string{
+		"backend_group_name",
+	})
+
+	consensusSafeBlock = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: MetricsNamespace,
+		Name:      "group_consensus_safe_block",
+		Help:      "Consensus safe block number of the backend group",
+	}, []string{
+		"backend_group_name",
+	})
+
+	consensusFinalizedBlock = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: MetricsNamespace,
+		Name:      "group_consensus_finalized_block",
+		Help:      "Consensus finalized block number of the backend group",
+	}, []string{
+		"backend_group_name",
+	})
+
+	consensusGroupCount = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: MetricsNamespace,
+		Name:      "group_consensus_count",
+		Help:      "Number of backends in the consensus group",
+	}, []string{
+		"backend_group_name",
+	})
+
+	backendLatestBlock = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: MetricsNamespace,
+		Name:      "backend_latest_block",
+		Help:      "Latest block number of the backend, as of the last consensus poll",
+	}, []string{
+		"backend_name",
+	})
+
+	backendInConsensus = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: MetricsNamespace,
+		Name:      "backend_in_consensus",
+		Help:      "1 if the backend is in the consensus group of its backend group, 0 otherwise",
+	}, []string{
+		"backend_name",
+	})
+
+	consensusPollErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: MetricsNamespace,
+		Name:      "consensus_poll_errors_total",
+		Help:      "Count of errors polling the chain state of backends",
+	}, []string{
+		"backend_name",
+	})
 )
 
 func RecordRedisError(source string) {
@@ -302,3 +358,23 @@ func RecordCacheMiss(method string) {
 func RecordBatchSize(size int) {
 	batchSizeHistogram.Observe(float64(size))
 }
+
+func RecordConsensusState(groupName string, latest, safe, finalized BlockRef, count int) {
+	consensusLatestBlock.WithLabelValues(groupName).Set(float64(latest.Number))
+	consensusSafeBlock.WithLabelValues(groupName).Set(float64(safe.Number))
+	consensusFinalizedBlock.WithLabelValues(groupName).Set(float64(finalized.Number))
+	consensusGroupCount.WithLabelValues(groupName).Set(float64(count))
+}
+
+func RecordBackendConsensusState(backendName string, latest BlockRef, inConsensus bool) {
+	backendLatestBlock.WithLabelValues(backendName).Set(float64(latest.Number))
+	var v float64
+	if inConsensus {
+		v = 1
+	}
+	backendInConsensus.WithLabelValues(backendName).Set(v)
+}
+
+func RecordConsensusPollError(backendName string) {
+	consensusPollErrorsTotal.WithLabelValues(backendName).Inc()
+}
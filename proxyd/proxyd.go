@@ -161,6 +161,14 @@ func Start(config *Config) (func(), error) {
 			Name:     bgName,
 			Backends: backends,
 		}
+		if bg.ConsensusAware {
+			opts := []ConsensusOpt{WithConsensusMaxBlockLag(bg.ConsensusMaxBlockLag)}
+			if bg.ConsensusPollerInterval != 0 {
+				opts = append(opts, WithConsensusPollerInterval(time.Duration(bg.ConsensusPollerInterval)))
+			}
+			group.Consensus = NewConsensusPoller(group, opts...)
+			log.Info("configured consensus aware backend group", "name", bgName)
+		}
 		backendGroups[bgName] = group
 	}
 
@@ -253,6 +261,12 @@ func Start(config *Config) (func(), error) {
 		return nil, fmt.Errorf("error creating server: %w", err)
 	}
 
+	for _, bg := range backendGroups {
+		if bg.Consensus != nil {
+			bg.Consensus.Start()
+		}
+	}
+
 	if config.Metrics.Enabled {
 		addr := fmt.Sprintf("%s:%d", config.Metrics.Host, config.Metrics.Port)
 		log.Info("starting metrics server", "addr", addr)
@@ -304,6 +318,11 @@ func Start(config *Config) (func(), error) {
 			gasPriceLVC.Stop()
 		}
 		srv.Shutdown()
+		for _, bg := range backendGroups {
+			if bg.Consensus != nil {
+				bg.Consensus.Stop()
+			}
+		}
 		if err := lim.FlushBackendWSConns(backendNames); err != nil {
 			log.Error("error flushing backend ws conns", "err", err)
 		}
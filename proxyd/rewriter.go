@@ -0,0 +1,131 @@
+package proxyd
+
+import (
+	"encoding/json"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+type blockParam struct {
+	// pos is the index of the block parameter in the request params.
+	pos int
+	// optional is true if the block parameter defaults to latest when omitted.
+	optional bool
+}
+
+// blockParams maps the methods that take a block number or tag to the position of that parameter.
+var blockParams = map[string]blockParam{
+	"eth_getBalance":                          {pos: 1},
+	"eth_getCode":                             {pos: 1},
+	"eth_getTransactionCount":                 {pos: 1},
+	"eth_call":                                {pos: 1, optional: true},
+	"eth_estimateGas":                         {pos: 1, optional: true},
+	"eth_getStorageAt":                        {pos: 2},
+	"eth_getProof":                            {pos: 2},
+	"eth_getBlockByNumber":                    {pos: 0},
+	"eth_getBlockTransactionCountByNumber":    {pos: 0},
+	"eth_getUncleCountByBlockNumber":          {pos: 0},
+	"eth_getTransactionByBlockNumberAndIndex": {pos: 0},
+	"eth_getUncleByBlockNumberAndIndex":       {pos: 0},
+}
+
+// RewriteRequest rewrites references to the latest block in the request to the given block number,
+// such that the request is served at the same height by every backend.
+// It returns the request itself if there is nothing to rewrite, and never modifies the request in place:
+// the original request is still used as cache key.
+// ErrBlockOutOfRange is returned if the request references a block number after latest.
+//
+// eth_newFilter is passed through: a filter that follows latest must keep reporting the logs
+// of later blocks to eth_getFilterChanges, rather than being frozen at the current block.
+func RewriteRequest(req *RPCReq, latest hexutil.Uint64) (*RPCReq, error) {
+	if req.Method == "eth_getLogs" {
+		return rewriteRange(req, latest)
+	}
+	bp, ok := blockParams[req.Method]
+	if !ok {
+		return req, nil
+	}
+	var params []json.RawMessage
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		// leave invalid params to the backend to report
+		return req, nil
+	}
+	if len(params) <= bp.pos {
+		if !bp.optional || len(params) < bp.pos {
+			return req, nil
+		}
+		params = append(params, mustMarshalJSON(latest))
+		return withParams(req, params), nil
+	}
+	var block string
+	if err := json.Unmarshal(params[bp.pos], &block); err != nil {
+		// block hashes and EIP-1898 block objects are left as is
+		return req, nil
+	}
+	rewritten, changed, err := rewriteBlock(block, latest)
+	if err != nil || !changed {
+		return req, err
+	}
+	params[bp.pos] = mustMarshalJSON(rewritten)
+	return withParams(req, params), nil
+}
+
+// rewriteRange rewrites the fromBlock and toBlock fields of a log filter, which both default to latest.
+func rewriteRange(req *RPCReq, latest hexutil.Uint64) (*RPCReq, error) {
+	var params []map[string]json.RawMessage
+	if err := json.Unmarshal(req.Params, &params); err != nil || len(params) != 1 {
+		return req, nil
+	}
+	filter := params[0]
+	if _, ok := filter["blockHash"]; ok {
+		return req, nil
+	}
+	changed := false
+	for _, field := range []string{"fromBlock", "toBlock"} {
+		block := "latest"
+		if raw, ok := filter[field]; ok {
+			if err := json.Unmarshal(raw, &block); err != nil {
+				return req, nil
+			}
+		}
+		rewritten, ok, err := rewriteBlock(block, latest)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			filter[field] = mustMarshalJSON(rewritten)
+			changed = true
+		}
+	}
+	if !changed {
+		return req, nil
+	}
+	return withParams(req, params), nil
+}
+
+// rewriteBlock returns the block number to use instead of the given block tag or number, if it is changed.
+func rewriteBlock(block string, latest hexutil.Uint64) (hexutil.Uint64, bool, error) {
+	switch block {
+	case "latest":
+		return latest, true, nil
+	case "earliest", "pending", "safe", "finalized":
+		return 0, false, nil
+	}
+	num, err := decodeBlockInput(block)
+	if err != nil {
+		return 0, false, nil
+	}
+	if num > uint64(latest) {
+		return 0, false, ErrBlockOutOfRange
+	}
+	return 0, false, nil
+}
+
+func withParams(req *RPCReq, params interface{}) *RPCReq {
+	return &RPCReq{
+		JSONRPC: req.JSONRPC,
+		Method:  req.Method,
+		Params:  mustMarshalJSON(params),
+		ID:      req.ID,
+	}
+}
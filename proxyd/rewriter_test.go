@@ -0,0 +1,55 @@
+package proxyd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRewriteRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		params string
+		out    string
+		err    error
+	}{
+		{"latest block param", "eth_getBalance", `["0x01","latest"]`, `["0x01","0x64"]`, nil},
+		{"block number param", "eth_getBalance", `["0x01","0x10"]`, `["0x01","0x10"]`, nil},
+		{"block number at latest", "eth_getBlockByNumber", `["0x64",false]`, `["0x64",false]`, nil},
+		{"block number after latest", "eth_getBlockByNumber", `["0x65",false]`, ``, ErrBlockOutOfRange},
+		{"other tags are kept", "eth_getStorageAt", `["0x01","0x0","pending"]`, `["0x01","0x0","pending"]`, nil},
+		{"block hash is kept", "eth_getCode", `["0x01",{"blockHash":"0x01"}]`, `["0x01",{"blockHash":"0x01"}]`, nil},
+		{"omitted optional block param", "eth_call", `[{"to":"0x01"}]`, `[{"to":"0x01"},"0x64"]`, nil},
+		{"omitted required block param", "eth_getBalance", `["0x01"]`, `["0x01"]`, nil},
+		{"no block param", "eth_chainId", `[]`, `[]`, nil},
+		{"invalid params", "eth_getBalance", `{}`, `{}`, nil},
+		{"log range", "eth_getLogs", `[{"fromBlock":"0x10","toBlock":"latest"}]`, `[{"fromBlock":"0x10","toBlock":"0x64"}]`, nil},
+		{"log range defaults", "eth_getLogs", `[{"address":"0x01"}]`, `[{"address":"0x01","fromBlock":"0x64","toBlock":"0x64"}]`, nil},
+		{"log range after latest", "eth_getLogs", `[{"fromBlock":"0x10","toBlock":"0x65"}]`, ``, ErrBlockOutOfRange},
+		{"log block hash", "eth_getLogs", `[{"blockHash":"0x01"}]`, `[{"blockHash":"0x01"}]`, nil},
+		{"new filter defaults", "eth_newFilter", `[{"address":"0x01"}]`, `[{"address":"0x01"}]`, nil},
+		{"new filter range", "eth_newFilter", `[{"fromBlock":"0x10","toBlock":"latest"}]`, `[{"fromBlock":"0x10","toBlock":"latest"}]`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &RPCReq{
+				JSONRPC: JSONRPCVersion,
+				Method:  tt.method,
+				Params:  json.RawMessage(tt.params),
+				ID:      json.RawMessage("1"),
+			}
+			out, err := RewriteRequest(req, 100)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.JSONEq(t, tt.out, string(out.Params))
+			require.Equal(t, tt.method, out.Method)
+			require.Equal(t, req.ID, out.ID)
+			require.Equal(t, tt.params, string(req.Params), "request is not modified in place")
+		})
+	}
+}
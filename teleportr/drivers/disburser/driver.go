@@ -11,7 +11,7 @@ import (
 
 	"github.com/cenkalti/backoff"
 	"github.com/ethereum-optimism/optimism/bss-core/metrics"
-	"github.com/ethereum-optimism/optimism/bss-core/txmgr"
+	"github.com/ethereum-optimism/optimism/op-service/txmgr"
 	"github.com/ethereum-optimism/optimism/teleportr/bindings/deposit"
 	"github.com/ethereum-optimism/optimism/teleportr/bindings/disburse"
 	"github.com/ethereum-optimism/optimism/teleportr/db"